@@ -22,12 +22,15 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common/errorreport"
 	coreinit "github.com/GoogleCloudPlatform/khi/pkg/core/init"
 	coreinspection "github.com/GoogleCloudPlatform/khi/pkg/core/inspection"
+	"github.com/GoogleCloudPlatform/khi/pkg/core/inspection/batch"
 	"github.com/GoogleCloudPlatform/khi/pkg/lifecycle"
 	"github.com/GoogleCloudPlatform/khi/pkg/parameters"
 	"github.com/GoogleCloudPlatform/khi/pkg/server"
@@ -84,6 +87,9 @@ func main() {
 
 func run() int {
 	defer errorreport.CheckAndReportPanic()
+	if exitCode, ok := runSubcommand(os.Args[1:]); ok {
+		return exitCode
+	}
 	defer func() {
 		err := coreinit.CallInitExtension(func(e coreinit.InitExtension) error {
 			return e.BeforeTerminate()
@@ -128,6 +134,14 @@ func run() int {
 		slog.Error(fmt.Sprintf("Failed to construct the IOConfig from parameter\n%v", err))
 		return 1
 	}
+	// Temporary files are created in a folder owned by this process, not to let the Janitor delete the files of other processes sharing the temporary folder.
+	processTemporaryFolder, err := os.MkdirTemp(ioconfig.TemporaryFolder, "khi-run-*")
+	if err != nil {
+		slog.Error(fmt.Sprintf("Failed to create the temporary folder for this process\n%v", err))
+		return 1
+	}
+	defer os.RemoveAll(processTemporaryFolder)
+	ioconfig.TemporaryFolder = processTemporaryFolder
 	inspectionServer, err := coreinspection.NewServer(ioconfig)
 	if err != nil {
 		slog.Error(fmt.Sprintf("Failed to construct the inspection server due to unexpected error\n%v", err))
@@ -157,13 +171,28 @@ func run() int {
 			serverMode = gin.DebugMode
 		}
 
-		uploadFileStoreFolder := "/tmp"
+		uploadFileStoreFolder := filepath.Join(os.TempDir(), "khi-upload")
 
 		if parameters.Common.UploadFileStoreFolder != nil {
 			uploadFileStoreFolder = *parameters.Common.UploadFileStoreFolder
 		}
 
 		upload.DefaultUploadFileStore = upload.NewUploadFileStore(upload.NewLocalUploadFileStoreProvider(uploadFileStoreFolder))
+		upload.TemporaryFolder = ioconfig.TemporaryFolder
+
+		if !*parameters.Server.ViewerMode {
+			err = inspectionServer.EnablePersistence(context.Background())
+			if err != nil {
+				slog.Error(fmt.Sprintf("Failed to restore inspections from the data destination folder\n%v", err))
+				return 1
+			}
+			janitor := coreinspection.NewJanitor(inspectionServer, coreinspection.RetentionPolicy{
+				MaxAge:              time.Duration(*parameters.Retention.MaxAgeInHours) * time.Hour,
+				MaxInspectionCount:  *parameters.Retention.MaxInspectionCount,
+				MaxTotalSizeInBytes: int64(*parameters.Retention.MaxTotalSizeInBytes),
+			}, uploadFileStoreFolder)
+			janitor.Start(context.Background(), time.Duration(*parameters.Retention.CheckIntervalInSeconds)*time.Second)
+		}
 
 		err = coreinit.CallInitExtension(func(e coreinit.InitExtension) error {
 			return e.ConfigureKHIWebServerFactory(server.DefaultServerFactory)
@@ -202,6 +231,13 @@ func run() int {
 	} else {
 		slog.Info("Starting Kubernetes History Inspector as job mode...")
 
+		if *parameters.Job.JobSpec != "" {
+			// Cancel the jobs on the terminate signals instead of exiting immediately, so that the manifest is still written with the results of the jobs.
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+			defer stop()
+			return runJobSpec(ctx, inspectionServer, *parameters.Job.JobSpec)
+		}
+
 		go func() {
 			queryParametersInJson := *parameters.Job.InspectionValues
 			var values map[string]any
@@ -279,3 +315,28 @@ func run() int {
 	}
 	return <-exitCh
 }
+
+// runJobSpec runs the inspections described in the job spec file and returns the exit code.
+// The exit code is 1 when any of the jobs didn't succeed, including the jobs cancelled by the given context.
+func runJobSpec(ctx context.Context, inspectionServer *coreinspection.InspectionTaskServer, jobSpecPath string) int {
+	spec, err := batch.LoadJobSpec(jobSpecPath)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Failed to load the job spec\n%v", err))
+		return 1
+	}
+	manifest, err := batch.NewRunner(inspectionServer, spec).Run(ctx)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Failed to run the job spec\n%v", err))
+		return 1
+	}
+	slog.Info(fmt.Sprintf("All jobs finished. succeeded: %d, failed: %d, cancelled: %d", manifest.Succeeded, manifest.Failed, manifest.Cancelled))
+	for _, result := range manifest.Jobs {
+		if result.Status != batch.JobStatusSucceeded {
+			slog.Error(fmt.Sprintf("job %s %s: %s", result.Name, result.Status, result.Error))
+		}
+	}
+	if !manifest.AllSucceeded() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/khifile"
+)
+
+var mergeSubcommand = &subcommand{
+	description: "Merges multiple .khi files into a single .khi file.",
+	arguments:   "--output <output.khi> [flags] <input.khi>...",
+	run:         runMerge,
+}
+
+func runMerge(flags *flag.FlagSet, args []string) int {
+	output := flags.String("output", "", "The path of the merged .khi file.")
+	clusterNames := flags.String("cluster-names", "", "Comma separated cluster names prefixed to the resource paths of the input files in the same order. Leave an item empty not to prefix the paths of the file.")
+	temporaryFolder := flags.String("temporary-folder", os.TempDir(), "The folder to store temporary files while merging.")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	inputs := flags.Args()
+	if *output == "" || len(inputs) == 0 {
+		flags.Usage()
+		return 2
+	}
+	names := make([]string, len(inputs))
+	if *clusterNames != "" {
+		names = strings.Split(*clusterNames, ",")
+		if len(names) != len(inputs) {
+			slog.Error(fmt.Sprintf("%d cluster names are given for %d input files", len(names), len(inputs)))
+			return 2
+		}
+	}
+
+	sources := make([]*khifile.MergeSource, 0, len(inputs))
+	defer func() {
+		for _, source := range sources {
+			source.Reader.Close()
+		}
+	}()
+	for i, input := range inputs {
+		reader, err := khifile.Open(input)
+		if err != nil {
+			slog.Error(fmt.Sprintf("Failed to open the input file\n%v", err))
+			return 1
+		}
+		sources = append(sources, &khifile.MergeSource{Reader: reader, ClusterName: names[i]})
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Failed to create the output file\n%v", err))
+		return 1
+	}
+	size, err := khifile.MergeTo(context.Background(), file, sources, *temporaryFolder)
+	if err = errors.Join(err, file.Close()); err != nil {
+		slog.Error(fmt.Sprintf("Failed to merge the input files\n%v", err))
+		return 1
+	}
+	slog.Info(fmt.Sprintf("Merged %d files into %s (%d bytes)", len(inputs), *output, size))
+	return 0
+}
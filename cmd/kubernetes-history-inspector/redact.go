@@ -0,0 +1,91 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/khifile"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/redaction"
+)
+
+var redactSubcommand = &subcommand{
+	description: "Writes a .khi file with the sensitive data in the input .khi file redacted by the rules in the redaction config.",
+	arguments:   "--config <redaction.yaml> --output <output.khi> [flags] <input.khi>",
+	run:         runRedact,
+}
+
+func runRedact(flags *flag.FlagSet, args []string) int {
+	configPath := flags.String("config", "", "The path to the redaction config YAML.")
+	output := flags.String("output", "", "The path of the redacted .khi file.")
+	mappingOutput := flags.String("mapping-output", "", "The path to write the JSON mapping from the pseudonyms to the original values. Keep this file private.")
+	temporaryFolder := flags.String("temporary-folder", os.TempDir(), "The folder to store temporary files while redacting.")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *configPath == "" || *output == "" || flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	config, err := redaction.LoadConfig(*configPath)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Failed to load the redaction config\n%v", err))
+		return 2
+	}
+	redactor, err := redaction.NewRedactor(config)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Failed to initialize the redactor\n%v", err))
+		return 1
+	}
+	if *mappingOutput != "" {
+		redactor.RecordPseudonyms()
+	}
+
+	reader, err := khifile.Open(flags.Arg(0))
+	if err != nil {
+		slog.Error(fmt.Sprintf("Failed to open the input file\n%v", err))
+		return 1
+	}
+	defer reader.Close()
+	file, err := os.Create(*output)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Failed to create the output file\n%v", err))
+		return 1
+	}
+	size, err := khifile.RewriteTo(context.Background(), file, reader, redactor, *temporaryFolder)
+	if err = errors.Join(err, file.Close()); err != nil {
+		slog.Error(fmt.Sprintf("Failed to redact the input file\n%v", err))
+		return 1
+	}
+	if *mappingOutput != "" {
+		mapping, err := json.MarshalIndent(redactor.Pseudonyms(), "", "  ")
+		if err != nil {
+			slog.Error(fmt.Sprintf("Failed to serialize the pseudonym mapping\n%v", err))
+			return 1
+		}
+		if err := os.WriteFile(*mappingOutput, mapping, 0600); err != nil {
+			slog.Error(fmt.Sprintf("Failed to write the pseudonym mapping\n%v", err))
+			return 1
+		}
+	}
+	slog.Info(fmt.Sprintf("Redacted %s into %s (%d bytes)", flags.Arg(0), *output, size))
+	return 0
+}
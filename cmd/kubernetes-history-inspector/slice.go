@@ -0,0 +1,98 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/khifile"
+)
+
+var sliceSubcommand = &subcommand{
+	description: "Writes a .khi file containing only the part of the input .khi file in the time range or under the resource paths.",
+	arguments:   "--output <output.khi> [flags] <input.khi>",
+	run:         runSlice,
+}
+
+func runSlice(flags *flag.FlagSet, args []string) int {
+	output := flags.String("output", "", "The path of the sliced .khi file.")
+	startTime := flags.String("start-time", "", "The beginning of the time range to keep in RFC3339 format.")
+	endTime := flags.String("end-time", "", "The end of the time range to keep in RFC3339 format.")
+	resourcePathPrefixes := flags.String("resource-path-prefixes", "", "Comma separated resource paths to keep with their descendants. (e.g. `core/v1#pod#my-namespace`)")
+	namespaces := flags.String("namespaces", "", "Comma separated namespaces to keep the resources in them.")
+	temporaryFolder := flags.String("temporary-folder", os.TempDir(), "The folder to store temporary files while slicing.")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *output == "" || flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	options := &khifile.SliceOptions{
+		ResourcePathPrefixes: splitNonEmpty(*resourcePathPrefixes),
+		Namespaces:           splitNonEmpty(*namespaces),
+	}
+	var err error
+	if options.StartTime, err = parseOptionalTime(*startTime); err != nil {
+		slog.Error(fmt.Sprintf("Failed to parse --start-time\n%v", err))
+		return 2
+	}
+	if options.EndTime, err = parseOptionalTime(*endTime); err != nil {
+		slog.Error(fmt.Sprintf("Failed to parse --end-time\n%v", err))
+		return 2
+	}
+
+	reader, err := khifile.Open(flags.Arg(0))
+	if err != nil {
+		slog.Error(fmt.Sprintf("Failed to open the input file\n%v", err))
+		return 1
+	}
+	defer reader.Close()
+	file, err := os.Create(*output)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Failed to create the output file\n%v", err))
+		return 1
+	}
+	size, err := khifile.SliceTo(context.Background(), file, reader, options, *temporaryFolder)
+	if err = errors.Join(err, file.Close()); err != nil {
+		slog.Error(fmt.Sprintf("Failed to slice the input file\n%v", err))
+		return 1
+	}
+	slog.Info(fmt.Sprintf("Sliced %s into %s (%d bytes)", flags.Arg(0), *output, size))
+	return 0
+}
+
+// splitNonEmpty splits the comma separated value. It returns nil for an empty string.
+func splitNonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// parseOptionalTime parses the time in RFC3339 format. It returns the zero time for an empty string.
+func parseOptionalTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,55 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// subcommand is an offline command run instead of the server or the job mode when its name is given as the first argument.
+type subcommand struct {
+	// description is the one line description shown in the help.
+	description string
+	// arguments is the syntax of the arguments shown in the help.
+	arguments string
+	// run runs the command with the arguments following the subcommand name and returns the exit code.
+	run func(flags *flag.FlagSet, args []string) int
+}
+
+// subcommands is the list of subcommands keyed by their names.
+var subcommands = map[string]*subcommand{
+	"merge":  mergeSubcommand,
+	"redact": redactSubcommand,
+	"slice":  sliceSubcommand,
+}
+
+// runSubcommand runs the subcommand specified in the arguments. It returns false when the first argument is not a subcommand name.
+func runSubcommand(args []string) (int, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	command, found := subcommands[args[0]]
+	if !found {
+		return 0, false
+	}
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "%s\n\nUsage: %s %s %s\n", command.description, os.Args[0], args[0], command.arguments)
+		flags.PrintDefaults()
+	}
+	return command.run(flags, args[1:]), true
+}
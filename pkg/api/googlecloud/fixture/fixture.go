@@ -0,0 +1,89 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fixture records the responses of Google Cloud APIs to a fixture file and replays them later without accessing the network.
+// It is used to re-run an inspection deterministically for debugging parsers or in integration tests.
+//
+// A fixture file is a sequence of JSON objects, one for each API call. Responses are looked up with the key computed from the method and the request,
+// so calls made concurrently by tasks can be replayed in a different order from the recorded one.
+package fixture
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+// record is a recorded API call written in a fixture file.
+type record struct {
+	// Key identifies the request. Responses recorded with the same key are replayed in the recorded order.
+	Key string `json:"key"`
+	// Method is the full gRPC method name or the HTTP method and URL of the request, only for humans reading the fixture.
+	Method string        `json:"method"`
+	GRPC   *grpcResponse `json:"grpc,omitempty"`
+	HTTP   *httpResponse `json:"http,omitempty"`
+}
+
+// grpcResponse is the result of a unary gRPC call.
+type grpcResponse struct {
+	// Message is the response message serialized in the protobuf binary format.
+	// The binary format is used because the JSON format can't serialize Any fields of types unknown to the binary, e.g. protoPayload of LogEntry.
+	Message      []byte     `json:"message,omitempty"`
+	Code         codes.Code `json:"code"`
+	ErrorMessage string     `json:"errorMessage,omitempty"`
+}
+
+// httpResponse is the response of an HTTP request.
+type httpResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+}
+
+// grpcKey returns the key of a gRPC request.
+func grpcKey(method string, req any) (string, error) {
+	message, ok := req.(proto.Message)
+	if !ok {
+		return "", fmt.Errorf("request of %s is not a proto message: %T", method, req)
+	}
+	serialized, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize the request of %s: %w", method, err)
+	}
+	return hashKey("grpc", method, serialized), nil
+}
+
+// httpKey returns the key of an HTTP request.
+func httpKey(method string, body []byte) string {
+	return hashKey("http", method, body)
+}
+
+func hashKey(kind string, method string, request []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(kind))
+	hash.Write([]byte{0})
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write(request)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// httpMethod returns the readable method name of an HTTP request used in the fixture.
+func httpMethod(req *http.Request) string {
+	return fmt.Sprintf("%s %s", req.Method, req.URL.String())
+}
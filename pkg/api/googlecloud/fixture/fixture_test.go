@@ -0,0 +1,235 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fixture
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+const listLogEntriesMethod = "/google.logging.v2.LoggingServiceV2/ListLogEntries"
+
+// fakeListLogEntriesInvoker returns a grpc.UnaryInvoker returning a page for each page token, or the given error.
+func fakeListLogEntriesInvoker(pages map[string]*loggingpb.ListLogEntriesResponse, err error) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if err != nil {
+			return err
+		}
+		page := pages[req.(*loggingpb.ListLogEntriesRequest).PageToken]
+		reply.(*loggingpb.ListLogEntriesResponse).Entries = page.Entries
+		reply.(*loggingpb.ListLogEntriesResponse).NextPageToken = page.NextPageToken
+		return nil
+	}
+}
+
+func unexpectedInvoker(t *testing.T) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		t.Errorf("invoker must not be called in the replay mode: %s", method)
+		return nil
+	}
+}
+
+func TestRecordAndReplayGRPC(t *testing.T) {
+	pages := map[string]*loggingpb.ListLogEntriesResponse{
+		"": {
+			Entries:       []*loggingpb.LogEntry{{InsertId: "foo"}},
+			NextPageToken: "page-2",
+		},
+		"page-2": {
+			Entries: []*loggingpb.LogEntry{{InsertId: "bar"}},
+		},
+	}
+	requests := []*loggingpb.ListLogEntriesRequest{
+		{ResourceNames: []string{"projects/test-project"}, Filter: "severity>=ERROR"},
+		{ResourceNames: []string{"projects/test-project"}, Filter: "severity>=ERROR", PageToken: "page-2"},
+	}
+	fixtureBuffer := &bytes.Buffer{}
+	recorder := NewRecorder(fixtureBuffer)
+	recordingInterceptor := recorder.UnaryClientInterceptor()
+	for _, req := range requests {
+		err := recordingInterceptor(t.Context(), listLogEntriesMethod, req, &loggingpb.ListLogEntriesResponse{}, nil, fakeListLogEntriesInvoker(pages, nil))
+		if err != nil {
+			t.Fatalf("recording interceptor returned an unexpected error: %v", err)
+		}
+	}
+	deniedRequest := &loggingpb.ListLogEntriesRequest{ResourceNames: []string{"projects/denied-project"}}
+	err := recordingInterceptor(t.Context(), listLogEntriesMethod, deniedRequest, &loggingpb.ListLogEntriesResponse{}, nil, fakeListLogEntriesInvoker(nil, status.Error(codes.PermissionDenied, "denied")))
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("recording interceptor must return the error of the call as is, got %v", err)
+	}
+
+	replayer, err := NewReplayer(fixtureBuffer)
+	if err != nil {
+		t.Fatalf("NewReplayer() returned an unexpected error: %v", err)
+	}
+	replayingInterceptor := replayer.UnaryClientInterceptor()
+	// Replay pages in the reverse order to check responses are looked up with the requests.
+	for i := len(requests) - 1; i >= 0; i-- {
+		got := &loggingpb.ListLogEntriesResponse{}
+		err := replayingInterceptor(t.Context(), listLogEntriesMethod, requests[i], got, nil, unexpectedInvoker(t))
+		if err != nil {
+			t.Fatalf("replaying interceptor returned an unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(pages[requests[i].PageToken], got, protocmp.Transform()); diff != "" {
+			t.Errorf("replayed response mismatch (-want +got):\n%s", diff)
+		}
+	}
+	err = replayingInterceptor(t.Context(), listLogEntriesMethod, deniedRequest, &loggingpb.ListLogEntriesResponse{}, nil, unexpectedInvoker(t))
+	if s := status.Convert(err); s.Code() != codes.PermissionDenied || s.Message() != "denied" {
+		t.Errorf("replaying interceptor must return the recorded error, got %v", err)
+	}
+	unknownRequest := &loggingpb.ListLogEntriesRequest{ResourceNames: []string{"projects/unknown-project"}}
+	err = replayingInterceptor(t.Context(), listLogEntriesMethod, unknownRequest, &loggingpb.ListLogEntriesResponse{}, nil, unexpectedInvoker(t))
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("replaying interceptor must return FailedPrecondition for unrecorded requests, got %v", err)
+	}
+}
+
+func TestReplayer_RepeatsLastResponse(t *testing.T) {
+	req := &loggingpb.ListLogEntriesRequest{Filter: "foo"}
+	fixtureBuffer := &bytes.Buffer{}
+	recordingInterceptor := NewRecorder(fixtureBuffer).UnaryClientInterceptor()
+	recordingInterceptor(t.Context(), listLogEntriesMethod, req, &loggingpb.ListLogEntriesResponse{}, nil, fakeListLogEntriesInvoker(nil, status.Error(codes.Unavailable, "retry")))
+	recordingInterceptor(t.Context(), listLogEntriesMethod, req, &loggingpb.ListLogEntriesResponse{}, nil, fakeListLogEntriesInvoker(map[string]*loggingpb.ListLogEntriesResponse{
+		"": {NextPageToken: "last"},
+	}, nil))
+
+	replayer, err := NewReplayer(fixtureBuffer)
+	if err != nil {
+		t.Fatalf("NewReplayer() returned an unexpected error: %v", err)
+	}
+	replayingInterceptor := replayer.UnaryClientInterceptor()
+	wantCodes := []codes.Code{codes.Unavailable, codes.OK, codes.OK}
+	for i, wantCode := range wantCodes {
+		got := &loggingpb.ListLogEntriesResponse{}
+		err := replayingInterceptor(t.Context(), listLogEntriesMethod, req, got, nil, unexpectedInvoker(t))
+		if status.Code(err) != wantCode {
+			t.Errorf("call #%d: got code %v, want %v", i, status.Code(err), wantCode)
+		}
+		if wantCode == codes.OK && got.NextPageToken != "last" {
+			t.Errorf("call #%d: got NextPageToken %q, want %q", i, got.NextPageToken, "last")
+		}
+	}
+}
+
+func TestRecordAndReplayHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Write([]byte(`{"path":"` + r.URL.Path + `","body":"` + string(body) + `"}`))
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{method: http.MethodGet, path: "/environments", wantStatus: http.StatusOK, wantBody: `{"path":"/environments","body":""}`},
+		{method: http.MethodPost, path: "/environments", body: "foo", wantStatus: http.StatusOK, wantBody: `{"path":"/environments","body":"foo"}`},
+		{method: http.MethodGet, path: "/missing", wantStatus: http.StatusNotFound, wantBody: `{"path":"/missing","body":""}`},
+	}
+	newRequest := func(method, path, body string) *http.Request {
+		var reader io.Reader
+		if body != "" {
+			reader = strings.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(t.Context(), method, server.URL+path, reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	assertResponse := func(resp *http.Response, wantStatus int, wantBody string) {
+		t.Helper()
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != wantStatus {
+			t.Errorf("got status %d, want %d", resp.StatusCode, wantStatus)
+		}
+		if string(body) != wantBody {
+			t.Errorf("got body %q, want %q", string(body), wantBody)
+		}
+		if got := resp.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("got Content-Type %q, want application/json", got)
+		}
+	}
+
+	fixtureBuffer := &bytes.Buffer{}
+	recordingClient := &http.Client{Transport: NewRecorder(fixtureBuffer).RoundTripper(http.DefaultTransport)}
+	for _, tc := range testCases {
+		resp, err := recordingClient.Do(newRequest(tc.method, tc.path, tc.body))
+		if err != nil {
+			t.Fatalf("recording %s %s returned an unexpected error: %v", tc.method, tc.path, err)
+		}
+		assertResponse(resp, tc.wantStatus, tc.wantBody)
+	}
+	server.Close()
+
+	replayer, err := NewReplayer(fixtureBuffer)
+	if err != nil {
+		t.Fatalf("NewReplayer() returned an unexpected error: %v", err)
+	}
+	replayingClient := &http.Client{Transport: replayer.RoundTripper()}
+	for i := len(testCases) - 1; i >= 0; i-- {
+		tc := testCases[i]
+		resp, err := replayingClient.Do(newRequest(tc.method, tc.path, tc.body))
+		if err != nil {
+			t.Fatalf("replaying %s %s returned an unexpected error: %v", tc.method, tc.path, err)
+		}
+		assertResponse(resp, tc.wantStatus, tc.wantBody)
+	}
+	if _, err := replayingClient.Do(newRequest(http.MethodGet, "/unknown", "")); err == nil {
+		t.Errorf("replaying an unrecorded request must return an error")
+	}
+}
+
+func TestNewReplayer_Error(t *testing.T) {
+	testCases := []struct {
+		name    string
+		fixture string
+	}{
+		{name: "malformed JSON", fixture: `{"key":`},
+		{name: "missing key", fixture: `{"method":"GET /","http":{"statusCode":200}}`},
+		{name: "missing response", fixture: `{"key":"foo","method":"GET /"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewReplayer(strings.NewReader(tc.fixture)); err == nil {
+				t.Errorf("NewReplayer() must return an error for %s", tc.fixture)
+			}
+		})
+	}
+}
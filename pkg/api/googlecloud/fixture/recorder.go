@@ -0,0 +1,157 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fixture
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Recorder writes the responses of API calls to a fixture.
+// Failures on recording are logged but never fail the API calls.
+type Recorder struct {
+	lock   sync.Mutex
+	writer io.Writer
+}
+
+// NewRecorder returns a Recorder writing the fixture to the given writer.
+// Each call is written with a single Write call, so the fixture written to a file is readable even when the process is terminated while recording.
+func NewRecorder(writer io.Writer) *Recorder {
+	return &Recorder{
+		writer: writer,
+	}
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor recording the responses of unary calls including every page of list calls.
+func (r *Recorder) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		callErr := invoker(ctx, method, req, reply, cc, opts...)
+		key, err := grpcKey(method, req)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to record the gRPC call", "method", method, "error", err)
+			return callErr
+		}
+		response := &grpcResponse{}
+		if callErr != nil {
+			s := status.Convert(callErr)
+			response.Code = s.Code()
+			response.ErrorMessage = s.Message()
+		} else if message, ok := reply.(proto.Message); ok {
+			response.Message, err = proto.Marshal(message)
+			if err != nil {
+				slog.WarnContext(ctx, "failed to serialize the gRPC response", "method", method, "error", err)
+				return callErr
+			}
+		}
+		r.write(ctx, &record{
+			Key:    key,
+			Method: method,
+			GRPC:   response,
+		})
+		return callErr
+	}
+}
+
+// RoundTripper returns a http.RoundTripper recording the responses returned from the given base RoundTripper.
+func (r *Recorder) RoundTripper(base http.RoundTripper) http.RoundTripper {
+	return &recordingRoundTripper{
+		recorder: r,
+		base:     base,
+	}
+}
+
+func (r *Recorder) write(ctx context.Context, rec *record) {
+	serialized, err := json.Marshal(rec)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to serialize the fixture record", "method", rec.Method, "error", err)
+		return
+	}
+	serialized = append(serialized, '\n')
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, err := r.writer.Write(serialized); err != nil {
+		slog.WarnContext(ctx, "failed to write the fixture record", "method", rec.Method, "error", err)
+	}
+}
+
+type recordingRoundTripper struct {
+	recorder *Recorder
+	base     http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		// Transport errors are not recorded because they are not the responses of the API.
+		return nil, err
+	}
+	responseBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response body of %s: %w", httpMethod(req), err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	method := httpMethod(req)
+	r.recorder.write(req.Context(), &record{
+		Key:    httpKey(method, requestBody),
+		Method: method,
+		HTTP: &httpResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       responseBody,
+		},
+	})
+	return resp, nil
+}
+
+// readRequestBody reads the body of the request without consuming it.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the request body of %s: %w", httpMethod(req), err)
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the request body of %s: %w", httpMethod(req), err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+var _ http.RoundTripper = (*recordingRoundTripper)(nil)
@@ -0,0 +1,156 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fixture
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// recordedResponses is the list of responses recorded for a key.
+type recordedResponses struct {
+	records []*record
+	next    int
+}
+
+// pop returns the next response. The last response is returned repeatedly once all responses are returned.
+func (r *recordedResponses) pop() *record {
+	result := r.records[r.next]
+	if r.next < len(r.records)-1 {
+		r.next++
+	}
+	return result
+}
+
+// Replayer serves the responses recorded in a fixture instead of calling APIs.
+type Replayer struct {
+	lock      sync.Mutex
+	responses map[string]*recordedResponses
+}
+
+// NewReplayer reads the fixture written by Recorder from the given reader and returns a Replayer serving it.
+func NewReplayer(reader io.Reader) (*Replayer, error) {
+	replayer := &Replayer{
+		responses: map[string]*recordedResponses{},
+	}
+	decoder := json.NewDecoder(reader)
+	for i := 0; ; i++ {
+		rec := &record{}
+		err := decoder.Decode(rec)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the record #%d in the fixture: %w", i, err)
+		}
+		if rec.Key == "" || (rec.GRPC == nil) == (rec.HTTP == nil) {
+			return nil, fmt.Errorf("the record #%d in the fixture is invalid", i)
+		}
+		responses, found := replayer.responses[rec.Key]
+		if !found {
+			responses = &recordedResponses{}
+			replayer.responses[rec.Key] = responses
+		}
+		responses.records = append(responses.records, rec)
+	}
+	return replayer, nil
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor serving the recorded responses without invoking the calls.
+func (r *Replayer) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		key, err := grpcKey(method, req)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		rec := r.find(key)
+		if rec == nil || rec.GRPC == nil {
+			// FailedPrecondition is not retried by the clients unlike Unavailable.
+			return status.Errorf(codes.FailedPrecondition, "no response for %s is recorded in the fixture", method)
+		}
+		if rec.GRPC.Code != codes.OK {
+			return status.Error(rec.GRPC.Code, rec.GRPC.ErrorMessage)
+		}
+		message, ok := reply.(proto.Message)
+		if !ok {
+			return status.Errorf(codes.Internal, "response of %s is not a proto message: %T", method, reply)
+		}
+		if err := proto.Unmarshal(rec.GRPC.Message, message); err != nil {
+			return status.Errorf(codes.Internal, "failed to deserialize the recorded response of %s: %v", method, err)
+		}
+		return nil
+	}
+}
+
+// RoundTripper returns a http.RoundTripper serving the recorded responses without sending the requests.
+func (r *Replayer) RoundTripper() http.RoundTripper {
+	return &replayingRoundTripper{
+		replayer: r,
+	}
+}
+
+func (r *Replayer) find(key string) *record {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	responses, found := r.responses[key]
+	if !found {
+		return nil
+	}
+	return responses.pop()
+}
+
+type replayingRoundTripper struct {
+	replayer *Replayer
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *replayingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	method := httpMethod(req)
+	rec := r.replayer.find(httpKey(method, requestBody))
+	if rec == nil || rec.HTTP == nil {
+		return nil, fmt.Errorf("no response for %s is recorded in the fixture", method)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.HTTP.StatusCode, http.StatusText(rec.HTTP.StatusCode)),
+		StatusCode:    rec.HTTP.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.HTTP.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(rec.HTTP.Body)),
+		ContentLength: int64(len(rec.HTTP.Body)),
+		Request:       req,
+	}, nil
+}
+
+var _ http.RoundTripper = (*replayingRoundTripper)(nil)
@@ -0,0 +1,95 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logconvert
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"unicode"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// SplitLogEntriesJSON reads LogEntries exported from Cloud Logging in JSON and calls the callback with the JSON of each LogEntry.
+// It accepts a JSON array of LogEntries (e.g. the output of `gcloud logging read --format=json`) and JSON lines of LogEntries (e.g. the files written to Cloud Storage by log sinks).
+func SplitLogEntriesJSON(reader io.Reader, callback func(entryJSON []byte) error) error {
+	bufferedReader := bufio.NewReader(reader)
+	isArray, err := startsWithArray(bufferedReader)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bufferedReader)
+	if isArray {
+		if _, err := decoder.Token(); err != nil {
+			return fmt.Errorf("failed to read the beginning of the JSON array: %w", err)
+		}
+	}
+	for index := 0; !isArray || decoder.More(); index++ {
+		var entryJSON json.RawMessage
+		err := decoder.Decode(&entryJSON)
+		if !isArray && errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the LogEntry at index %d: %w", index, err)
+		}
+		if err := callback(entryJSON); err != nil {
+			return err
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("failed to read the end of the JSON array: %w", err)
+	}
+	return nil
+}
+
+// LogEntryFromJSON converts the JSON representation of a LogEntry into the LogEntry protobuf message.
+// Unknown fields are ignored to accept exports from newer versions of Cloud Logging.
+func LogEntryFromJSON(entryJSON []byte) (*loggingpb.LogEntry, error) {
+	opt := protojson.UnmarshalOptions{
+		DiscardUnknown: true,
+		Resolver:       protoregistry.GlobalTypes,
+	}
+	entry := &loggingpb.LogEntry{}
+	if err := opt.Unmarshal(entryJSON, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// startsWithArray returns true when the first non space character of the reader is the beginning of a JSON array.
+func startsWithArray(reader *bufio.Reader) (bool, error) {
+	for {
+		r, _, err := reader.ReadRune()
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if unicode.IsSpace(r) {
+			continue
+		}
+		if err := reader.UnreadRune(); err != nil {
+			return false, err
+		}
+		return r == '[', nil
+	}
+}
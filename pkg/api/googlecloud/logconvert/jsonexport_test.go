@@ -0,0 +1,117 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logconvert
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genproto/googleapis/cloud/audit"
+)
+
+func TestSplitLogEntriesJSON(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "JSON array",
+			input: `[
+  {"insertId": "1"},
+  {"insertId": "2"}
+]`,
+			want: []string{`{"insertId": "1"}`, `{"insertId": "2"}`},
+		},
+		{
+			name:  "JSON lines",
+			input: "{\"insertId\": \"1\"}\n{\"insertId\": \"2\"}\n",
+			want:  []string{`{"insertId": "1"}`, `{"insertId": "2"}`},
+		},
+		{
+			name:  "empty array",
+			input: " []",
+			want:  []string{},
+		},
+		{
+			name:  "empty input",
+			input: "",
+			want:  []string{},
+		},
+		{
+			name:    "broken JSON",
+			input:   `{"insertId": `,
+			wantErr: true,
+		},
+		{
+			name:    "unterminated array",
+			input:   `[{"insertId": "1"}`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := []string{}
+			err := SplitLogEntriesJSON(strings.NewReader(tc.input), func(entryJSON []byte) error {
+				got = append(got, string(entryJSON))
+				return nil
+			})
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("SplitLogEntriesJSON() returned no error, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SplitLogEntriesJSON() returned an unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("SplitLogEntriesJSON() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLogEntryFromJSON(t *testing.T) {
+	entry, err := LogEntryFromJSON([]byte(`{
+		"insertId": "insert-1",
+		"logName": "projects/my-project/logs/cloudaudit.googleapis.com%2Factivity",
+		"protoPayload": {
+			"@type": "type.googleapis.com/google.cloud.audit.AuditLog",
+			"methodName": "io.k8s.core.v1.pods.create"
+		},
+		"severity": "NOTICE",
+		"timestamp": "2025-01-01T00:00:00.123456Z",
+		"unknownField": "ignored"
+	}`))
+	if err != nil {
+		t.Fatalf("LogEntryFromJSON() returned an unexpected error: %v", err)
+	}
+	if entry.GetInsertId() != "insert-1" {
+		t.Errorf("InsertId = %q, want %q", entry.GetInsertId(), "insert-1")
+	}
+	if got := entry.GetTimestamp().AsTime().Format("2006-01-02T15:04:05.000000Z07:00"); got != "2025-01-01T00:00:00.123456Z" {
+		t.Errorf("Timestamp = %q, want %q", got, "2025-01-01T00:00:00.123456Z")
+	}
+	auditLog := &audit.AuditLog{}
+	if err := entry.GetProtoPayload().UnmarshalTo(auditLog); err != nil {
+		t.Fatalf("failed to read the protoPayload: %v", err)
+	}
+	if auditLog.GetMethodName() != "io.k8s.core.v1.pods.create" {
+		t.Errorf("MethodName = %q, want %q", auditLog.GetMethodName(), "io.k8s.core.v1.pods.create")
+	}
+}
@@ -0,0 +1,315 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// severityLevels is the order of LogSeverity used in comparisons of severity.
+var severityLevels = map[string]int{
+	"DEFAULT":   0,
+	"DEBUG":     100,
+	"INFO":      200,
+	"NOTICE":    300,
+	"WARNING":   400,
+	"ERROR":     500,
+	"CRITICAL":  600,
+	"ALERT":     700,
+	"EMERGENCY": 800,
+}
+
+// timestampLayouts are the layouts of timestamps accepted in comparisons of timestamps.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04:05.999999999-0700",
+	"2006-01-02",
+}
+
+type expression interface {
+	match(entry map[string]any) bool
+}
+
+type andExpression []expression
+
+func (e andExpression) match(entry map[string]any) bool {
+	for _, operand := range e {
+		if !operand.match(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+type orExpression []expression
+
+func (e orExpression) match(entry map[string]any) bool {
+	for _, operand := range e {
+		if operand.match(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+type notExpression struct {
+	operand expression
+}
+
+func (e *notExpression) match(entry map[string]any) bool {
+	return !e.operand.match(entry)
+}
+
+// textSearch is a global restriction matching log entries containing the text in any field.
+type textSearch struct {
+	// text is the lower case text to search.
+	text string
+}
+
+func (e *textSearch) match(entry map[string]any) bool {
+	return containsText(entry, e.text)
+}
+
+type logIDFunction struct {
+	logID string
+}
+
+func (e *logIDFunction) match(entry map[string]any) bool {
+	logName, ok := entry["logName"].(string)
+	return ok && logIDMatches(logName, e.logID)
+}
+
+// comparison is a comparison of a field with a value. It matches when any value found at the path satisfies the comparison.
+type comparison struct {
+	path     []string
+	operator string
+	value    valueExpression
+}
+
+func (e *comparison) match(entry map[string]any) bool {
+	fieldValues := lookupField(entry, e.path)
+	for _, fieldValue := range fieldValues {
+		if e.value.matchValue(func(literal *valueLiteral) bool {
+			return e.compare(fieldValue, literal)
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *comparison) compare(fieldValue any, literal *valueLiteral) bool {
+	switch e.operator {
+	case ":":
+		return literal.text == "*" || containsText(fieldValue, strings.ToLower(literal.text))
+	case "=":
+		return equals(fieldValue, literal.text)
+	case "!=":
+		return !equals(fieldValue, literal.text)
+	case "=~":
+		text, ok := scalarToString(fieldValue)
+		return ok && literal.regex.MatchString(text)
+	case "!~":
+		text, ok := scalarToString(fieldValue)
+		return ok && !literal.regex.MatchString(text)
+	default:
+		order, ok := compareOrdered(e.path, fieldValue, literal.text)
+		if !ok {
+			return false
+		}
+		switch e.operator {
+		case "<":
+			return order < 0
+		case "<=":
+			return order <= 0
+		case ">":
+			return order > 0
+		case ">=":
+			return order >= 0
+		}
+		return false
+	}
+}
+
+// valueExpression is the right hand side of a comparison.
+type valueExpression interface {
+	// matchValue returns true when the expression is satisfied with the given function comparing the field with a literal.
+	matchValue(compare func(literal *valueLiteral) bool) bool
+}
+
+type valueLiteral struct {
+	text string
+	// regex is the compiled text used for the regular expression operators.
+	regex *regexp.Regexp
+}
+
+func (v *valueLiteral) matchValue(compare func(literal *valueLiteral) bool) bool {
+	return compare(v)
+}
+
+type valueAndExpression []valueExpression
+
+func (v valueAndExpression) matchValue(compare func(literal *valueLiteral) bool) bool {
+	for _, operand := range v {
+		if !operand.matchValue(compare) {
+			return false
+		}
+	}
+	return true
+}
+
+type valueOrExpression []valueExpression
+
+func (v valueOrExpression) matchValue(compare func(literal *valueLiteral) bool) bool {
+	for _, operand := range v {
+		if operand.matchValue(compare) {
+			return true
+		}
+	}
+	return false
+}
+
+type valueNotExpression struct {
+	operand valueExpression
+}
+
+func (v *valueNotExpression) matchValue(compare func(literal *valueLiteral) bool) bool {
+	return !v.operand.matchValue(compare)
+}
+
+// lookupField returns the values found at the path. Arrays in the path are expanded and every element is looked up.
+func lookupField(value any, path []string) []any {
+	if array, ok := value.([]any); ok {
+		result := []any{}
+		for _, element := range array {
+			result = append(result, lookupField(element, path)...)
+		}
+		return result
+	}
+	if len(path) == 0 {
+		if value == nil {
+			return nil
+		}
+		return []any{value}
+	}
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+	child, found := object[path[0]]
+	if !found {
+		return nil
+	}
+	return lookupField(child, path[1:])
+}
+
+// containsText returns true when the value or any value nested in it contains the lower case text ignoring the case.
+func containsText(value any, text string) bool {
+	switch v := value.(type) {
+	case map[string]any:
+		for _, child := range v {
+			if containsText(child, text) {
+				return true
+			}
+		}
+		return false
+	case []any:
+		for _, child := range v {
+			if containsText(child, text) {
+				return true
+			}
+		}
+		return false
+	default:
+		s, ok := scalarToString(v)
+		return ok && strings.Contains(strings.ToLower(s), text)
+	}
+}
+
+func equals(fieldValue any, text string) bool {
+	switch v := fieldValue.(type) {
+	case string:
+		return v == text
+	case float64:
+		f, err := strconv.ParseFloat(text, 64)
+		return err == nil && f == v
+	case bool:
+		b, err := strconv.ParseBool(text)
+		return err == nil && b == v
+	default:
+		return false
+	}
+}
+
+// compareOrdered compares the field value with the text as severities, timestamps, numbers or strings in this order of preference.
+// It returns false when they are not comparable.
+func compareOrdered(path []string, fieldValue any, text string) (int, bool) {
+	s, ok := scalarToString(fieldValue)
+	if !ok {
+		return 0, false
+	}
+	if len(path) == 1 && path[0] == "severity" {
+		fieldLevel, fieldFound := severityLevels[strings.ToUpper(s)]
+		level, found := severityLevels[strings.ToUpper(text)]
+		if fieldFound && found {
+			return fieldLevel - level, true
+		}
+	}
+	if fieldTime, err := parseTimestamp(s); err == nil {
+		if t, err := parseTimestamp(text); err == nil {
+			return fieldTime.Compare(t), true
+		}
+	}
+	if fieldNumber, err := strconv.ParseFloat(s, 64); err == nil {
+		if number, err := strconv.ParseFloat(text, 64); err == nil {
+			switch {
+			case fieldNumber < number:
+				return -1, true
+			case fieldNumber > number:
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+	}
+	return strings.Compare(s, text), true
+}
+
+func parseTimestamp(s string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a timestamp", s)
+}
+
+func scalarToString(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
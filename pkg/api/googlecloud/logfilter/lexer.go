@@ -0,0 +1,146 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfilter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+	// tokenOperator is a comparison operator (`=`, `!=`, `<`, `<=`, `>`, `>=`, `:`, `=~` or `!~`).
+	tokenOperator
+	// tokenWord is an unquoted text like field paths, keywords or values. A word can contain quoted field path segments (e.g. `labels."k8s-pod/app"`).
+	tokenWord
+	// tokenString is a quoted text. The text of the token is unquoted.
+	tokenString
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// position is the offset of the token in the filter used in error messages.
+	position int
+}
+
+// operators are the comparison operators ordered from the longest to match them greedily.
+var operators = []string{"=~", "!~", "!=", "<=", ">=", "=", "<", ">", ":"}
+
+// tokenize splits the filter into tokens. Comments starting with `--` are removed.
+func tokenize(filter string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(filter)
+	i := 0
+	for i < len(runes) {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, text: "(", position: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, text: ")", position: i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", position: i})
+			i++
+		case c == '"':
+			text, next, err := readQuotedString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, position: i})
+			i = next
+		case isOperatorRune(c):
+			operator := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(string(runes[i:min(i+2, len(runes))]), candidate) {
+					operator = candidate
+					break
+				}
+			}
+			if operator == "" {
+				return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: operator, position: i})
+			i += len(operator)
+		default:
+			start := i
+			var word strings.Builder
+			for i < len(runes) && !isWordTerminator(runes[i]) {
+				if runes[i] == '"' {
+					// Quoted field path segments follow a dot. (e.g. `labels."k8s-pod/app"`)
+					if !strings.HasSuffix(word.String(), ".") {
+						break
+					}
+					_, next, err := readQuotedString(runes, i)
+					if err != nil {
+						return nil, err
+					}
+					word.WriteString(string(runes[i:next]))
+					i = next
+					continue
+				}
+				word.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: word.String(), position: start})
+		}
+	}
+	tokens = append(tokens, token{kind: tokenEOF, position: len(runes)})
+	return tokens, nil
+}
+
+// readQuotedString reads the quoted string starting at the given index and returns the unquoted text and the index next to the closing quote.
+// Only `\"` and `\\` are unescaped. Other backslashes are kept as they are to be used in regular expressions (e.g. `"\.pods\."`).
+func readQuotedString(runes []rune, start int) (string, int, error) {
+	var text strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+				text.WriteRune(runes[i+1])
+				i++
+				continue
+			}
+			text.WriteRune(runes[i])
+		case '"':
+			return text.String(), i + 1, nil
+		default:
+			text.WriteRune(runes[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted string starting at %d", start)
+}
+
+func isOperatorRune(c rune) bool {
+	return c == '=' || c == '!' || c == '<' || c == '>' || c == ':'
+}
+
+func isWordTerminator(c rune) bool {
+	return unicode.IsSpace(c) || c == '(' || c == ')' || c == ',' || isOperatorRune(c)
+}
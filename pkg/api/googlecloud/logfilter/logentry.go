@@ -0,0 +1,39 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfilter
+
+import (
+	"encoding/json"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// FieldsFromLogEntry converts the LogEntry into the JSON representation with the canonical field names to be given to Filter.Match.
+func FieldsFromLogEntry(entry *loggingpb.LogEntry) (map[string]any, error) {
+	opt := protojson.MarshalOptions{
+		Resolver: protoregistry.GlobalTypes,
+	}
+	entryJSON, err := opt.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(entryJSON, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
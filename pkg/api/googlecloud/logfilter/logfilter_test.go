@@ -0,0 +1,181 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfilter
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"google.golang.org/genproto/googleapis/api/monitoredres"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func mustDecodeEntry(t *testing.T, entryJSON string) map[string]any {
+	t.Helper()
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(entryJSON), &entry); err != nil {
+		t.Fatalf("failed to decode the test entry: %v", err)
+	}
+	return entry
+}
+
+func TestFilterMatch(t *testing.T) {
+	auditLog := `{
+		"logName": "projects/my-project/logs/cloudaudit.googleapis.com%2Factivity",
+		"timestamp": "2025-01-01T00:00:30.123456Z",
+		"severity": "NOTICE",
+		"resource": {"type": "k8s_cluster", "labels": {"cluster_name": "my-cluster", "project_id": "my-project"}},
+		"labels": {"k8s-pod/app": "nginx"},
+		"protoPayload": {
+			"methodName": "io.k8s.core.v1.pods.create",
+			"resourceName": "core/v1/namespaces/default/pods/nginx",
+			"status": {"code": 0}
+		}
+	}`
+	testCases := []struct {
+		name   string
+		filter string
+		entry  string
+		want   bool
+	}{
+		{name: "empty filter", filter: "", entry: auditLog, want: true},
+		{name: "equality", filter: `resource.type="k8s_cluster"`, entry: auditLog, want: true},
+		{name: "equality not matching", filter: `resource.type="k8s_container"`, entry: auditLog, want: false},
+		{name: "equality with an unquoted value", filter: `resource.labels.cluster_name=my-cluster`, entry: auditLog, want: true},
+		{name: "not equal", filter: `resource.type!="k8s_container"`, entry: auditLog, want: true},
+		{name: "has operator ignores the case", filter: `protoPayload.methodName:"PODS"`, entry: auditLog, want: true},
+		{name: "has operator with a value list", filter: `protoPayload.methodName: ("create" OR "update" OR "patch" OR "delete")`, entry: auditLog, want: true},
+		{name: "has operator with a value list not matching", filter: `protoPayload.methodName:("update" OR "delete")`, entry: auditLog, want: false},
+		{name: "equality with a value list", filter: `resource.type=("gke_cluster" OR "k8s_cluster")`, entry: auditLog, want: true},
+		{name: "existence", filter: `protoPayload.status:*`, entry: auditLog, want: true},
+		{name: "existence of a missing field", filter: `jsonPayload.status:*`, entry: auditLog, want: false},
+		{name: "has empty string of an object", filter: `protoPayload.status:""`, entry: auditLog, want: true},
+		{name: "regular expression", filter: `protoPayload.methodName=~"\.(pods|deployments)\."`, entry: auditLog, want: true},
+		{name: "negated regular expression", filter: `-protoPayload.methodName=~"\.(pods)\."`, entry: auditLog, want: false},
+		{name: "not regular expression operator", filter: `protoPayload.methodName!~"\.(deployments)\."`, entry: auditLog, want: true},
+		{name: "negation of a missing field", filter: `-jsonPayload.message:"foo"`, entry: auditLog, want: true},
+		{name: "NOT keyword", filter: `NOT (protoPayload.resourceName:"/namespaces/")`, entry: auditLog, want: false},
+		{name: "snake case field name", filter: `log_name="projects/my-project/logs/cloudaudit.googleapis.com%2Factivity"`, entry: auditLog, want: true},
+		{name: "quoted field path segment", filter: `labels."k8s-pod/app"="nginx"`, entry: auditLog, want: true},
+		{name: "LOG_ID with an encoded log ID", filter: `LOG_ID("cloudaudit.googleapis.com%2Factivity")`, entry: auditLog, want: true},
+		{name: "LOG_ID with a log ID not encoded", filter: `LOG_ID("cloudaudit.googleapis.com/activity")`, entry: auditLog, want: true},
+		{name: "LOG_ID not matching", filter: `LOG_ID("events")`, entry: auditLog, want: false},
+		{name: "severity comparison", filter: `severity>=INFO`, entry: auditLog, want: true},
+		{name: "severity comparison not matching", filter: `severity>=WARNING`, entry: auditLog, want: false},
+		{name: "numeric comparison", filter: `protoPayload.status.code<1`, entry: auditLog, want: true},
+		{name: "numeric equality", filter: `protoPayload.status.code=0`, entry: auditLog, want: true},
+		{
+			name: "time range",
+			filter: `timestamp >= "2025-01-01T00:00:00+0000"
+timestamp < "2025-01-01T00:01:00+0000"`,
+			entry: auditLog,
+			want:  true,
+		},
+		{
+			name: "time range not matching",
+			filter: `timestamp >= "2025-01-01T09:01:00+0900"
+timestamp < "2025-01-01T09:02:00+0900"`,
+			entry: auditLog,
+			want:  false,
+		},
+		{name: "global restriction", filter: `"NGINX"`, entry: auditLog, want: true},
+		{name: "global restriction not matching", filter: `"apache"`, entry: auditLog, want: false},
+		{name: "OR has higher precedence than AND", filter: `LOG_ID("events") OR LOG_ID("cloudaudit.googleapis.com%2Factivity") resource.type="k8s_cluster"`, entry: auditLog, want: true},
+		{name: "explicit AND", filter: `resource.type="k8s_cluster" AND resource.labels.cluster_name="other-cluster"`, entry: auditLog, want: false},
+		{
+			name: "comments",
+			filter: `resource.type="k8s_cluster"
+-- resource.type="k8s_container"
+resource.labels.cluster_name="my-cluster" -- trailing comment`,
+			entry: auditLog,
+			want:  true,
+		},
+		{
+			name: "generated kubernetes audit log query",
+			filter: `resource.type="k8s_cluster"
+resource.labels.cluster_name="my-cluster"
+protoPayload.methodName: ("create" OR "update" OR "patch" OR "delete")
+protoPayload.methodName=~"\.(pods)\."
+(protoPayload.resourceName:("/namespaces/default") OR NOT (protoPayload.resourceName:"/namespaces/"))
+`,
+			entry: auditLog,
+			want:  true,
+		},
+		{
+			name:   "array elements",
+			filter: `jsonPayload.items.name="b"`,
+			entry:  `{"jsonPayload": {"items": [{"name": "a"}, {"name": "b"}]}}`,
+			want:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := Parse(tc.filter)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned an unexpected error: %v", tc.filter, err)
+			}
+			if got := filter.Match(mustDecodeEntry(t, tc.entry)); got != tc.want {
+				t.Errorf("Match() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParse_Error(t *testing.T) {
+	testCases := []struct {
+		name   string
+		filter string
+	}{
+		{name: "unterminated string", filter: `resource.type="k8s_cluster`},
+		{name: "missing closing parenthesis", filter: `(resource.type="k8s_cluster"`},
+		{name: "unexpected closing parenthesis", filter: `resource.type="k8s_cluster")`},
+		{name: "missing value", filter: `resource.type=`},
+		{name: "invalid regular expression", filter: `protoPayload.methodName=~"("`},
+		{name: "unsupported function", filter: `SEARCH("foo")`},
+		{name: "unknown operator", filter: `resource.type ! "k8s_cluster"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Parse(tc.filter); err == nil {
+				t.Errorf("Parse(%q) returned no error, want an error", tc.filter)
+			}
+		})
+	}
+}
+
+func TestFieldsFromLogEntry(t *testing.T) {
+	entry := &loggingpb.LogEntry{
+		LogName:   "projects/foo/logs/events",
+		Timestamp: timestamppb.New(time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)),
+		Resource: &monitoredres.MonitoredResource{
+			Type:   "k8s_cluster",
+			Labels: map[string]string{"cluster_name": "bar"},
+		},
+		Payload: &loggingpb.LogEntry_TextPayload{TextPayload: "hello"},
+	}
+	fields, err := FieldsFromLogEntry(entry)
+	if err != nil {
+		t.Fatalf("FieldsFromLogEntry() returned an unexpected error: %v", err)
+	}
+	filter, err := Parse(`resource.labels.cluster_name="bar" timestamp>="2025-01-01T00:00:00Z" text_payload:"hello" LOG_ID("events")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !filter.Match(fields) {
+		t.Errorf("the filter must match the fields converted from the LogEntry: %v", fields)
+	}
+}
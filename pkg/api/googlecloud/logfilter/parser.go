@@ -0,0 +1,341 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logfilter evaluates Cloud Logging query language filters against log entries without Cloud Logging.
+// It supports the subset of the language used in the queries generated by KHI: comparisons, boolean operators, comments, value lists, global restrictions and the LOG_ID function.
+// See https://cloud.google.com/logging/docs/view/logging-query-language
+package logfilter
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Filter is a parsed Cloud Logging filter.
+type Filter struct {
+	root expression
+}
+
+// Parse parses the given Cloud Logging filter. An empty filter matches any log entry.
+func Parse(filter string) (*Filter, error) {
+	tokens, err := tokenize(filter)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseConjunction()
+	if err != nil {
+		return nil, err
+	}
+	if current := p.peek(); current.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", current.text, current.position)
+	}
+	return &Filter{root: root}, nil
+}
+
+// Match returns true when the log entry matches the filter.
+// The log entry is the JSON representation of a LogEntry decoded into Go values. (e.g. the result of json.Unmarshal into map[string]any)
+func (f *Filter) Match(entry map[string]any) bool {
+	return f.root.match(entry)
+}
+
+type parser struct {
+	tokens   []token
+	position int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.position]
+}
+
+func (p *parser) peekNext() token {
+	if p.position+1 < len(p.tokens) {
+		return p.tokens[p.position+1]
+	}
+	return p.tokens[len(p.tokens)-1]
+}
+
+func (p *parser) next() token {
+	current := p.tokens[p.position]
+	if current.kind != tokenEOF {
+		p.position++
+	}
+	return current
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	current := p.peek()
+	return current.kind == tokenWord && current.text == keyword
+}
+
+// parseConjunction parses expressions joined with AND or whitespaces until the end of the filter or a closing parenthesis.
+// OR has higher precedence than AND in Cloud Logging query language.
+func (p *parser) parseConjunction() (expression, error) {
+	operands := andExpression{}
+	for {
+		current := p.peek()
+		if current.kind == tokenEOF || current.kind == tokenRightParen {
+			break
+		}
+		if p.isKeyword("AND") {
+			p.next()
+			continue
+		}
+		operand, err := p.parseDisjunction()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return operands, nil
+}
+
+func (p *parser) parseDisjunction() (expression, error) {
+	first, err := p.parseNegation()
+	if err != nil {
+		return nil, err
+	}
+	operands := orExpression{first}
+	for p.isKeyword("OR") {
+		p.next()
+		operand, err := p.parseNegation()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return operands, nil
+}
+
+func (p *parser) parseNegation() (expression, error) {
+	current := p.peek()
+	if p.isKeyword("NOT") {
+		p.next()
+		operand, err := p.parseNegation()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpression{operand: operand}, nil
+	}
+	if current.kind == tokenWord && strings.HasPrefix(current.text, "-") {
+		if current.text == "-" {
+			p.next()
+		} else {
+			p.tokens[p.position].text = current.text[1:]
+		}
+		operand, err := p.parseNegation()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpression{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (expression, error) {
+	current := p.next()
+	switch current.kind {
+	case tokenLeftParen:
+		inner, err := p.parseConjunction()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRightParen {
+			return nil, fmt.Errorf("missing closing parenthesis for the parenthesis at %d", current.position)
+		}
+		return inner, nil
+	case tokenWord:
+		if p.peek().kind == tokenLeftParen {
+			return p.parseFunction(current)
+		}
+		if p.peek().kind == tokenOperator {
+			return p.parseComparison(current)
+		}
+		return &textSearch{text: strings.ToLower(current.text)}, nil
+	case tokenString:
+		return &textSearch{text: strings.ToLower(current.text)}, nil
+	default:
+		return nil, fmt.Errorf("unexpected %q at %d", current.text, current.position)
+	}
+}
+
+func (p *parser) parseFunction(name token) (expression, error) {
+	p.next() // (
+	arguments := []string{}
+	for {
+		current := p.next()
+		switch current.kind {
+		case tokenString, tokenWord:
+			arguments = append(arguments, current.text)
+		case tokenComma:
+		case tokenRightParen:
+			switch name.text {
+			case "LOG_ID":
+				if len(arguments) != 1 {
+					return nil, fmt.Errorf("LOG_ID at %d requires 1 argument but %d were given", name.position, len(arguments))
+				}
+				return &logIDFunction{logID: arguments[0]}, nil
+			default:
+				return nil, fmt.Errorf("unsupported function %s at %d", name.text, name.position)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %q in the arguments of %s at %d", current.text, name.text, current.position)
+		}
+	}
+}
+
+func (p *parser) parseComparison(field token) (expression, error) {
+	path, err := parseFieldPath(field.text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid field path at %d: %w", field.position, err)
+	}
+	operator := p.next().text
+	value, err := p.parseValue(operator)
+	if err != nil {
+		return nil, err
+	}
+	return &comparison{path: path, operator: operator, value: value}, nil
+}
+
+// parseValue parses the right hand side of a comparison. It's a value or a parenthesized expression of values. (e.g. `("foo" OR "bar")`)
+func (p *parser) parseValue(operator string) (valueExpression, error) {
+	current := p.next()
+	switch current.kind {
+	case tokenString, tokenWord:
+		return newValueLiteral(current.text, operator)
+	case tokenLeftParen:
+		operands := valueAndExpression{}
+		for {
+			switch {
+			case p.peek().kind == tokenRightParen:
+				p.next()
+				if len(operands) == 1 {
+					return operands[0], nil
+				}
+				return operands, nil
+			case p.peek().kind == tokenEOF:
+				return nil, fmt.Errorf("missing closing parenthesis for the parenthesis at %d", current.position)
+			case p.isKeyword("AND"):
+				p.next()
+			default:
+				operand, err := p.parseValueDisjunction(operator)
+				if err != nil {
+					return nil, err
+				}
+				operands = append(operands, operand)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("missing value at %d", current.position)
+	}
+}
+
+func (p *parser) parseValueDisjunction(operator string) (valueExpression, error) {
+	first, err := p.parseValueNegation(operator)
+	if err != nil {
+		return nil, err
+	}
+	operands := valueOrExpression{first}
+	for p.isKeyword("OR") {
+		p.next()
+		operand, err := p.parseValueNegation(operator)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return operands, nil
+}
+
+func (p *parser) parseValueNegation(operator string) (valueExpression, error) {
+	if p.isKeyword("NOT") {
+		p.next()
+		operand, err := p.parseValueNegation(operator)
+		if err != nil {
+			return nil, err
+		}
+		return &valueNotExpression{operand: operand}, nil
+	}
+	return p.parseValue(operator)
+}
+
+// parseFieldPath splits the field path into segments. Quoted segments can contain dots. (e.g. `labels."k8s-pod/app"`)
+// The first segment written in snake_case is converted to camelCase because Cloud Logging accepts both for the fields of LogEntry.
+func parseFieldPath(fieldPath string) ([]string, error) {
+	segments := []string{}
+	var segment strings.Builder
+	runes := []rune(fieldPath)
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '"':
+			text, next, err := readQuotedString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			segment.WriteString(text)
+			i = next - 1
+		case '.':
+			segments = append(segments, segment.String())
+			segment.Reset()
+		default:
+			segment.WriteRune(runes[i])
+		}
+	}
+	segments = append(segments, segment.String())
+	for _, s := range segments {
+		if s == "" {
+			return nil, fmt.Errorf("empty segment in %q", fieldPath)
+		}
+	}
+	segments[0] = snakeToCamelCase(segments[0])
+	return segments, nil
+}
+
+func snakeToCamelCase(s string) string {
+	words := strings.Split(s, "_")
+	for i := 1; i < len(words); i++ {
+		if words[i] != "" {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+	}
+	return strings.Join(words, "")
+}
+
+func newValueLiteral(text string, operator string) (*valueLiteral, error) {
+	literal := &valueLiteral{text: text}
+	if operator == "=~" || operator == "!~" {
+		regex, err := regexp.Compile(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", text, err)
+		}
+		literal.regex = regex
+	}
+	return literal, nil
+}
+
+// logIDMatches returns true when the log name is the log of the given log ID. The log ID can be written with or without URL encoding.
+func logIDMatches(logName string, logID string) bool {
+	return strings.HasSuffix(logName, "/logs/"+logID) || strings.HasSuffix(logName, "/logs/"+url.PathEscape(logID))
+}
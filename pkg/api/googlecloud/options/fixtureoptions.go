@@ -0,0 +1,83 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud/fixture"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+	"google.golang.org/grpc"
+)
+
+// cloudPlatformScope is the OAuth scope used for the HTTP clients created while recording a fixture.
+// The HTTP clients are created outside of the API client libraries, so their default scopes are not applied.
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// RecordFixture returns a googlecloud.ClientFactoryOption to record every response of the Google Cloud APIs to the given fixture.Recorder.
+func RecordFixture(recorder *fixture.Recorder) googlecloud.ClientFactoryOption {
+	grpcModifier := func(opts []option.ClientOption, c googlecloud.ResourceContainer) ([]option.ClientOption, error) {
+		return append(opts, option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(recorder.UnaryClientInterceptor()))), nil
+	}
+	httpModifier := func(opts []option.ClientOption, c googlecloud.ResourceContainer) ([]option.ClientOption, error) {
+		transportOpts := append([]option.ClientOption{option.WithScopes(cloudPlatformScope)}, opts...)
+		transport, err := htransport.NewTransport(context.Background(), http.DefaultTransport, transportOpts...)
+		if err != nil {
+			return nil, err
+		}
+		// The options given before are applied by the transport. They must not be passed to the client because some of them are incompatible with option.WithHTTPClient.
+		return []option.ClientOption{
+			option.WithHTTPClient(&http.Client{Transport: recorder.RoundTripper(transport)}),
+		}, nil
+	}
+	return fromFixtureModifiers(grpcModifier, httpModifier)
+}
+
+// ReplayFixture returns a googlecloud.ClientFactoryOption to serve the responses recorded in the given fixture.Replayer instead of calling the Google Cloud APIs.
+// Credentials are not used in this mode.
+func ReplayFixture(replayer *fixture.Replayer) googlecloud.ClientFactoryOption {
+	// These modifiers discard the options given before because the credentials must not be combined with option.WithoutAuthentication or option.WithHTTPClient.
+	grpcModifier := func(opts []option.ClientOption, c googlecloud.ResourceContainer) ([]option.ClientOption, error) {
+		return []option.ClientOption{
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(replayer.UnaryClientInterceptor())),
+		}, nil
+	}
+	httpModifier := func(opts []option.ClientOption, c googlecloud.ResourceContainer) ([]option.ClientOption, error) {
+		return []option.ClientOption{
+			option.WithHTTPClient(&http.Client{Transport: replayer.RoundTripper()}),
+		}, nil
+	}
+	return fromFixtureModifiers(grpcModifier, httpModifier)
+}
+
+// fromFixtureModifiers returns a googlecloud.ClientFactoryOption adding the given modifiers to the clients using gRPC and HTTP respectively.
+// These modifiers are added as client specific modifiers to be applied after the modifiers for all clients.
+func fromFixtureModifiers(grpcModifier, httpModifier googlecloud.ClientFactoryOptionsModifiers) googlecloud.ClientFactoryOption {
+	return func(s *googlecloud.ClientFactory) error {
+		s.ContainerClusterManagerClientOptions = append(s.ContainerClusterManagerClientOptions, grpcModifier)
+		s.GKEHubMembershipClientOptions = append(s.GKEHubMembershipClientOptions, grpcModifier)
+		s.GKEMultiCloudAWSClustersClientOptions = append(s.GKEMultiCloudAWSClustersClientOptions, grpcModifier)
+		s.GKEMultiCloudAzureClustersClientOptions = append(s.GKEMultiCloudAzureClustersClientOptions, grpcModifier)
+		s.LoggingClientOptions = append(s.LoggingClientOptions, grpcModifier)
+		s.RegionsClientOptions = append(s.RegionsClientOptions, httpModifier)
+		s.ComposerServiceOptions = append(s.ComposerServiceOptions, httpModifier)
+		s.GKEOnPremServiceOptions = append(s.GKEOnPremServiceOptions, httpModifier)
+		return nil
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud/fixture"
+	"google.golang.org/grpc"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper.
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRecordFixture(t *testing.T) {
+	clientFactory := googlecloud.ClientFactory{}
+	err := RecordFixture(fixture.NewRecorder(io.Discard))(&clientFactory)
+	if err != nil {
+		t.Fatalf("RecordFixture returned an unexpected error: %v", err)
+	}
+	if len(clientFactory.ClientOptions) != 0 {
+		t.Errorf("RecordFixture must not add options for all clients, got %d", len(clientFactory.ClientOptions))
+	}
+	for name, modifiers := range map[string][]googlecloud.ClientFactoryOptionsModifiers{
+		"ContainerClusterManagerClientOptions":    clientFactory.ContainerClusterManagerClientOptions,
+		"GKEHubMembershipClientOptions":           clientFactory.GKEHubMembershipClientOptions,
+		"GKEMultiCloudAWSClustersClientOptions":   clientFactory.GKEMultiCloudAWSClustersClientOptions,
+		"GKEMultiCloudAzureClustersClientOptions": clientFactory.GKEMultiCloudAzureClustersClientOptions,
+		"LoggingClientOptions":                    clientFactory.LoggingClientOptions,
+		"RegionsClientOptions":                    clientFactory.RegionsClientOptions,
+		"ComposerServiceOptions":                  clientFactory.ComposerServiceOptions,
+		"GKEOnPremServiceOptions":                 clientFactory.GKEOnPremServiceOptions,
+	} {
+		if len(modifiers) != 1 {
+			t.Errorf("RecordFixture must add 1 modifier to %s, got %d", name, len(modifiers))
+		}
+	}
+}
+
+func TestReplayFixture(t *testing.T) {
+	const environmentsURL = "https://composer.googleapis.com/v1/projects/test-project/locations/us-central1/environments?alt=json&prettyPrint=false"
+	listLogEntriesRequest := &loggingpb.ListLogEntriesRequest{
+		ResourceNames: []string{"projects/test-project"},
+		Filter:        `resource.type="k8s_cluster"`,
+	}
+
+	// Write the fixture without accessing the network.
+	fixtureBuffer := &bytes.Buffer{}
+	recorder := fixture.NewRecorder(fixtureBuffer)
+	err := recorder.UnaryClientInterceptor()(t.Context(), "/google.logging.v2.LoggingServiceV2/ListLogEntries", listLogEntriesRequest, &loggingpb.ListLogEntriesResponse{}, nil,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			reply.(*loggingpb.ListLogEntriesResponse).Entries = []*loggingpb.LogEntry{{InsertId: "recorded-entry"}}
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordingTransport := recorder.RoundTripper(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"environments":[{"name":"recorded-environment"}]}`)),
+		}, nil
+	}))
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, environmentsURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := recordingTransport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	replayer, err := fixture.NewReplayer(fixtureBuffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The option given to all clients must be discarded not to use credentials.
+	clientFactory, err := googlecloud.NewClientFactory(TokenSource(&mockTokenSource{}), QuotaProject("quota-project"), ReplayFixture(replayer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loggingClient, err := clientFactory.LoggingClient(t.Context(), googlecloud.Project("test-project"))
+	if err != nil {
+		t.Fatalf("LoggingClient returned an unexpected error: %v", err)
+	}
+	defer loggingClient.Close()
+	entry, err := loggingClient.ListLogEntries(t.Context(), listLogEntriesRequest).Next()
+	if err != nil {
+		t.Fatalf("ListLogEntries returned an unexpected error: %v", err)
+	}
+	if entry.InsertId != "recorded-entry" {
+		t.Errorf("got the entry %q, want recorded-entry", entry.InsertId)
+	}
+
+	composerService, err := clientFactory.ComposerService(t.Context(), googlecloud.Project("test-project"))
+	if err != nil {
+		t.Fatalf("ComposerService returned an unexpected error: %v", err)
+	}
+	environments, err := composerService.Projects.Locations.Environments.List("projects/test-project/locations/us-central1").Do()
+	if err != nil {
+		t.Fatalf("Environments.List returned an unexpected error: %v", err)
+	}
+	if len(environments.Environments) != 1 || environments.Environments[0].Name != "recorded-environment" {
+		t.Errorf("got environments %v, want recorded-environment", environments.Environments)
+	}
+}
@@ -16,9 +16,12 @@ package defaultinit
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"os"
 
 	"cloud.google.com/go/profiler"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud/fixture"
 	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud/legacy"
 	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud/oauth"
 	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud/options"
@@ -28,10 +31,12 @@ import (
 	"github.com/GoogleCloudPlatform/khi/pkg/core/inspection/logger"
 	"github.com/GoogleCloudPlatform/khi/pkg/generated"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/k8s"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/redaction"
 	"github.com/GoogleCloudPlatform/khi/pkg/parameters"
 	"github.com/GoogleCloudPlatform/khi/pkg/server"
 	"github.com/GoogleCloudPlatform/khi/pkg/server/option"
 	googlecloudcommon_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/googlecloudcommon/contract"
+	inspectioncore_impl "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/impl"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
@@ -75,6 +80,8 @@ var _ option.Option = (*oauthServerOption)(nil)
 
 type DefaultInitExtension struct {
 	taskServer *coreinspection.InspectionTaskServer
+	// fixtureFile is the file recording the responses of Google Cloud APIs. It's nil unless --api-fixture-record is set.
+	fixtureFile *os.File
 }
 
 // BeforeAll implements coreinit.InitExtension.
@@ -92,6 +99,8 @@ func (d *DefaultInitExtension) ConfigureParameterStore() error {
 	parameters.AddStore(parameters.Job)
 	parameters.AddStore(parameters.Auth)
 	parameters.AddStore(parameters.Debug)
+	parameters.AddStore(parameters.Retention)
+	parameters.AddStore(parameters.Redaction)
 	return nil
 }
 
@@ -130,6 +139,53 @@ func (d *DefaultInitExtension) ConfigureInspectionTaskServer(taskServer *coreins
 	if *parameters.Auth.AccessToken != "" {
 		taskServer.AddRunContextOption(coreinspection.RunContextOptionArrayElementFromValue(googlecloudcommon_contract.APIClientFactoryOptionsContextKey, options.TokenSource(legacy.NewRawTokenTokenSource(*parameters.Auth.AccessToken))))
 	}
+	if err := d.configureAPIFixture(taskServer); err != nil {
+		return err
+	}
+	if *parameters.Redaction.ConfigPath != "" {
+		config, err := redaction.LoadConfig(*parameters.Redaction.ConfigPath)
+		if err != nil {
+			return err
+		}
+		redactor, err := redaction.NewRedactor(config)
+		if err != nil {
+			return err
+		}
+		if err := taskServer.AddTask(inspectioncore_impl.NewRedactingSerializeTask(redactor)); err != nil {
+			return err
+		}
+		slog.Info("Inspection results are redacted with the config " + *parameters.Redaction.ConfigPath)
+	}
+	return nil
+}
+
+// configureAPIFixture adds the option to record or replay the responses of Google Cloud APIs when it's requested with the flags.
+// Logs are fetched with the fixed time partitions in both modes to make the requests replayed the same as the recorded ones.
+func (d *DefaultInitExtension) configureAPIFixture(taskServer *coreinspection.InspectionTaskServer) error {
+	if recordPath := *parameters.Debug.APIFixtureRecordPath; recordPath != "" {
+		fixtureFile, err := os.Create(recordPath)
+		if err != nil {
+			return fmt.Errorf("failed to create the API fixture file %s: %w", recordPath, err)
+		}
+		d.fixtureFile = fixtureFile
+		taskServer.AddRunContextOption(coreinspection.RunContextOptionArrayElementFromValue(googlecloudcommon_contract.APIClientFactoryOptionsContextKey, options.RecordFixture(fixture.NewRecorder(fixtureFile))))
+		taskServer.AddRunContextOption(coreinspection.RunContextOptionFromValue(googlecloudcommon_contract.FixedLogFetchTimePartitionsContextKey, true))
+		slog.Info("Responses of Google Cloud APIs are recorded to " + recordPath)
+	}
+	if replayPath := *parameters.Debug.APIFixtureReplayPath; replayPath != "" {
+		fixtureFile, err := os.Open(replayPath)
+		if err != nil {
+			return fmt.Errorf("failed to open the API fixture file %s: %w", replayPath, err)
+		}
+		defer fixtureFile.Close()
+		replayer, err := fixture.NewReplayer(fixtureFile)
+		if err != nil {
+			return fmt.Errorf("failed to load the API fixture file %s: %w", replayPath, err)
+		}
+		taskServer.AddRunContextOption(coreinspection.RunContextOptionArrayElementFromValue(googlecloudcommon_contract.APIClientFactoryOptionsContextKey, options.ReplayFixture(replayer)))
+		taskServer.AddRunContextOption(coreinspection.RunContextOptionFromValue(googlecloudcommon_contract.FixedLogFetchTimePartitionsContextKey, true))
+		slog.Info("Responses of Google Cloud APIs are replayed from " + replayPath)
+	}
 	return nil
 }
 
@@ -154,6 +210,9 @@ func (d *DefaultInitExtension) ConfigureKHIWebServerFactory(serverFactory *serve
 
 // BeforeTerminate implements coreinit.InitExtension.
 func (d *DefaultInitExtension) BeforeTerminate() error {
+	if d.fixtureFile != nil {
+		return d.fixtureFile.Close()
+	}
 	return nil
 }
 
@@ -0,0 +1,267 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/worker"
+	coreinspection "github.com/GoogleCloudPlatform/khi/pkg/core/inspection"
+	inspectioncore_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/contract"
+)
+
+// JobStatus is the final status of a job.
+type JobStatus string
+
+const (
+	JobStatusSucceeded JobStatus = "SUCCEEDED"
+	JobStatusFailed    JobStatus = "FAILED"
+	JobStatusCancelled JobStatus = "CANCELLED"
+)
+
+// JobResult is the result of a job written in the manifest.
+type JobResult struct {
+	Name           string    `json:"name"`
+	InspectionType string    `json:"inspectionType"`
+	InspectionID   string    `json:"inspectionId,omitempty"`
+	Destination    string    `json:"destination"`
+	Status         JobStatus `json:"status"`
+	Error          string    `json:"error,omitempty"`
+	StartTime      time.Time `json:"startTime"`
+	EndTime        time.Time `json:"endTime"`
+	SizeInBytes    int64     `json:"sizeInBytes"`
+}
+
+// Manifest is the machine readable summary of a batch run.
+type Manifest struct {
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	Succeeded int       `json:"succeeded"`
+	Failed    int       `json:"failed"`
+	Cancelled int       `json:"cancelled"`
+	// Jobs is the list of the job results in the order of the job spec.
+	Jobs []*JobResult `json:"jobs"`
+}
+
+// AllSucceeded returns true when every job in the batch succeeded.
+func (m *Manifest) AllSucceeded() bool {
+	return m.Failed == 0 && m.Cancelled == 0
+}
+
+// Runner runs the jobs in a JobSpec on an InspectionTaskServer.
+type Runner struct {
+	server *coreinspection.InspectionTaskServer
+	spec   *JobSpec
+	now    func() time.Time
+}
+
+// NewRunner returns a Runner running the jobs in the given spec.
+func NewRunner(server *coreinspection.InspectionTaskServer, spec *JobSpec) *Runner {
+	return &Runner{
+		server: server,
+		spec:   spec,
+		now:    time.Now,
+	}
+}
+
+// Run runs every job with the concurrency given in the spec and returns the manifest after all jobs are finished.
+// Failures of each job are recorded in the manifest and don't stop the other jobs. The returned error is non-nil only when the batch itself can't run.
+// The manifest is also written to the path specified in the spec if it is given.
+func (r *Runner) Run(ctx context.Context) (*Manifest, error) {
+	startTime := r.now()
+	jobs, err := r.spec.ResolveJobs(startTime)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{
+		StartTime: startTime,
+		Jobs:      make([]*JobResult, len(jobs)),
+	}
+	pool := worker.NewPool(r.spec.Concurrency)
+	var finishedCount int
+	var finishedCountLock sync.Mutex
+	for i, job := range jobs {
+		pool.Run(func() {
+			result := r.runJob(ctx, job)
+			manifest.Jobs[i] = result
+			finishedCountLock.Lock()
+			finishedCount++
+			count := finishedCount
+			finishedCountLock.Unlock()
+			if result.Status == JobStatusSucceeded {
+				slog.InfoContext(ctx, fmt.Sprintf("[%d/%d] job %s %s: %s (%d bytes, %s)", count, len(jobs), job.Name, result.Status, result.Destination, result.SizeInBytes, result.EndTime.Sub(result.StartTime).Round(time.Millisecond)))
+			} else {
+				slog.ErrorContext(ctx, fmt.Sprintf("[%d/%d] job %s %s: %s", count, len(jobs), job.Name, result.Status, result.Error))
+			}
+		})
+	}
+	pool.Wait()
+
+	manifest.EndTime = r.now()
+	for _, result := range manifest.Jobs {
+		switch result.Status {
+		case JobStatusSucceeded:
+			manifest.Succeeded++
+		case JobStatusCancelled:
+			manifest.Cancelled++
+		default:
+			manifest.Failed++
+		}
+	}
+	if r.spec.Manifest != "" {
+		if err := writeManifest(r.spec.Manifest, manifest); err != nil {
+			return manifest, err
+		}
+	}
+	return manifest, nil
+}
+
+// runJob runs an inspection of the job and writes its result to the destination.
+func (r *Runner) runJob(ctx context.Context, job *Job) *JobResult {
+	result := &JobResult{
+		Name:           job.Name,
+		InspectionType: job.InspectionType,
+		Destination:    job.Destination,
+		StartTime:      r.now(),
+	}
+	slog.InfoContext(ctx, fmt.Sprintf("job %s started with inspection type %s", job.Name, job.InspectionType))
+	size, err := r.runInspection(ctx, job, result)
+	result.EndTime = r.now()
+	switch {
+	case err == nil:
+		result.Status = JobStatusSucceeded
+		result.SizeInBytes = size
+	case ctx.Err() != nil:
+		result.Status = JobStatusCancelled
+		result.Error = err.Error()
+	default:
+		result.Status = JobStatusFailed
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (r *Runner) runInspection(ctx context.Context, job *Job, result *JobResult) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	inspectionID, err := r.server.CreateInspection(job.InspectionType)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create an inspection with type %s: %w", job.InspectionType, err)
+	}
+	result.InspectionID = inspectionID
+	// The result is copied to the destination. Delete the inspection not to keep the data of all jobs until the batch ends.
+	defer func() {
+		if err := r.server.DeleteInspection(ctx, inspectionID); err != nil {
+			slog.WarnContext(ctx, fmt.Sprintf("failed to delete the inspection %s of job %s", inspectionID, job.Name), "error", err)
+		}
+	}()
+
+	runner := r.server.GetInspection(inspectionID)
+	features, err := resolveFeatures(runner, job.Features)
+	if err != nil {
+		return 0, err
+	}
+	if err := runner.SetFeatureList(features); err != nil {
+		return 0, fmt.Errorf("failed to set features %v: %w", features, err)
+	}
+	if err := runner.Run(ctx, &inspectioncore_contract.InspectionRequest{
+		Values: job.Values,
+	}); err != nil {
+		return 0, fmt.Errorf("failed to run the inspection: %w", err)
+	}
+	waitFinished(runner)
+
+	inspectionResult, err := runner.Result()
+	if err != nil {
+		return 0, fmt.Errorf("inspection failed: %w", err)
+	}
+	reader, err := inspectionResult.ResultStore.GetReader()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the inspection result reader: %w", err)
+	}
+	defer reader.Close()
+	return writeDestination(job.Destination, reader)
+}
+
+// resolveFeatures returns the feature IDs to enable. `ALL` is expanded to every feature available for the inspection.
+func resolveFeatures(runner *coreinspection.InspectionTaskRunner, features []string) ([]string, error) {
+	if len(features) != 1 || strings.ToUpper(features[0]) != "ALL" {
+		return features, nil
+	}
+	availableFeatures, err := runner.FeatureList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain the feature list: %w", err)
+	}
+	result := make([]string, 0, len(availableFeatures))
+	for _, feature := range availableFeatures {
+		result = append(result, feature.Id)
+	}
+	return result, nil
+}
+
+// waitFinished waits for the runner to finish including the post processes after the task graph completion.
+func waitFinished(runner *coreinspection.InspectionTaskRunner) {
+	for {
+		changed := runner.Notifier().Changed()
+		if runner.Finished() {
+			return
+		}
+		<-changed
+	}
+}
+
+// writeDestination copies the inspection result to the destination path, creating its parent folders.
+func writeDestination(destination string, reader io.Reader) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create the folder of the destination %s: %w", destination, err)
+	}
+	file, err := os.OpenFile(destination, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open the destination file %s: %w", destination, err)
+	}
+	size, err := io.Copy(file, reader)
+	if err != nil {
+		return 0, errors.Join(fmt.Errorf("failed to write the destination file %s: %w", destination, err), file.Close())
+	}
+	if err := file.Close(); err != nil {
+		return 0, fmt.Errorf("failed to close the destination file %s: %w", destination, err)
+	}
+	return size, nil
+}
+
+func writeManifest(path string, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize the manifest: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create the folder of the manifest %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write the manifest %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	coreinspection "github.com/GoogleCloudPlatform/khi/pkg/core/inspection"
+	"github.com/GoogleCloudPlatform/khi/pkg/core/inspection/logger"
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	inspectiontaskbase "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/taskbase"
+	coretask "github.com/GoogleCloudPlatform/khi/pkg/core/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/core/task/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	inspectioncore_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/contract"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func createTestInspectionServer(t *testing.T) *coreinspection.InspectionTaskServer {
+	t.Helper()
+	logger.InitGlobalKHILogger()
+	server, err := coreinspection.NewServer(&inspectioncore_contract.IOConfig{
+		DataDestination: t.TempDir(),
+		TemporaryFolder: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("NewServer() returned an unexpected error: %v", err)
+	}
+	tasks := []coretask.UntypedTask{
+		inspectiontaskbase.NewProgressReportableInspectionTask(taskid.NewDefaultImplementationID[any]("feature-success"), []taskid.UntypedTaskReference{}, func(ctx context.Context, taskMode inspectioncore_contract.InspectionTaskModeType, tp *inspectionmetadata.TaskProgressMetadata) (any, error) {
+			return nil, nil
+		}, inspectioncore_contract.FeatureTaskLabel("success", "", enum.LogTypeAudit, 10, false, "success"), coretask.NewSubsequentTaskRefsTaskLabel(inspectioncore_contract.SerializerTaskID.Ref())),
+		inspectiontaskbase.NewProgressReportableInspectionTask(taskid.NewDefaultImplementationID[any]("feature-error"), []taskid.UntypedTaskReference{}, func(ctx context.Context, taskMode inspectioncore_contract.InspectionTaskModeType, tp *inspectionmetadata.TaskProgressMetadata) (any, error) {
+			if taskMode == inspectioncore_contract.TaskModeRun {
+				return nil, fmt.Errorf("test error")
+			}
+			return nil, nil
+		}, inspectioncore_contract.FeatureTaskLabel("error", "", enum.LogTypeAudit, 10, false, "error"), coretask.NewSubsequentTaskRefsTaskLabel(inspectioncore_contract.SerializerTaskID.Ref())),
+	}
+	for _, task := range tasks {
+		if err := server.AddTask(task); err != nil {
+			t.Fatalf("AddTask() returned an unexpected error: %v", err)
+		}
+	}
+	for _, inspectionType := range []string{"success", "error"} {
+		if err := server.AddInspectionType(coreinspection.InspectionType{Id: inspectionType, Name: inspectionType}); err != nil {
+			t.Fatalf("AddInspectionType() returned an unexpected error: %v", err)
+		}
+	}
+	return server
+}
+
+func TestRunner_Run(t *testing.T) {
+	server := createTestInspectionServer(t)
+	outputFolder := t.TempDir()
+	manifestPath := filepath.Join(outputFolder, "manifest.json")
+	spec := &JobSpec{
+		Concurrency: 2,
+		Manifest:    manifestPath,
+		Defaults: JobDefinition{
+			Features:    []string{"ALL"},
+			Destination: filepath.Join(outputFolder, "{{.Date}}", "{{.Name}}.khi"),
+		},
+		Jobs: []*JobDefinition{
+			{Name: "success-1", InspectionType: "success"},
+			{Name: "error", InspectionType: "error"},
+			{Name: "success-2", InspectionType: "success", Features: []string{"feature-success#default"}},
+			{Name: "unknown", InspectionType: "unknown"},
+		},
+	}
+	runner := NewRunner(server, spec)
+	runner.now = func() time.Time { return time.Date(2025, time.January, 2, 3, 4, 5, 0, time.UTC) }
+
+	got, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() returned an unexpected error: %v", err)
+	}
+
+	want := &Manifest{
+		StartTime: runner.now(),
+		EndTime:   runner.now(),
+		Succeeded: 2,
+		Failed:    2,
+		Jobs: []*JobResult{
+			{Name: "success-1", InspectionType: "success", Destination: filepath.Join(outputFolder, "20250102", "success-1.khi"), Status: JobStatusSucceeded, StartTime: runner.now(), EndTime: runner.now()},
+			{Name: "error", InspectionType: "error", Destination: filepath.Join(outputFolder, "20250102", "error.khi"), Status: JobStatusFailed, StartTime: runner.now(), EndTime: runner.now()},
+			{Name: "success-2", InspectionType: "success", Destination: filepath.Join(outputFolder, "20250102", "success-2.khi"), Status: JobStatusSucceeded, StartTime: runner.now(), EndTime: runner.now()},
+			{Name: "unknown", InspectionType: "unknown", Destination: filepath.Join(outputFolder, "20250102", "unknown.khi"), Status: JobStatusFailed, StartTime: runner.now(), EndTime: runner.now()},
+		},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(JobResult{}, "InspectionID", "Error", "SizeInBytes")); diff != "" {
+		t.Errorf("Run() mismatch (-want +got):\n%s", diff)
+	}
+	if got.AllSucceeded() {
+		t.Errorf("AllSucceeded() = true, want false")
+	}
+
+	for _, result := range got.Jobs {
+		stat, err := os.Stat(result.Destination)
+		if result.Status != JobStatusSucceeded {
+			if result.Error == "" {
+				t.Errorf("job %s failed without an error message", result.Name)
+			}
+			if err == nil {
+				t.Errorf("job %s failed but wrote the destination file", result.Name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("failed to stat the destination of job %s: %v", result.Name, err)
+			continue
+		}
+		if stat.Size() == 0 || stat.Size() != result.SizeInBytes {
+			t.Errorf("size of the destination of job %s = %d, want non-zero %d", result.Name, stat.Size(), result.SizeInBytes)
+		}
+	}
+
+	if remaining := server.GetAllRunners(); len(remaining) != 0 {
+		t.Errorf("GetAllRunners() returned %d inspections, want the finished inspections to be deleted", len(remaining))
+	}
+
+	manifestData, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("failed to read the manifest: %v", err)
+	}
+	var writtenManifest Manifest
+	if err := json.Unmarshal(manifestData, &writtenManifest); err != nil {
+		t.Fatalf("failed to parse the manifest: %v", err)
+	}
+	if diff := cmp.Diff(got, &writtenManifest); diff != "" {
+		t.Errorf("written manifest mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunner_Run_Cancelled(t *testing.T) {
+	server := createTestInspectionServer(t)
+	manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+	spec := &JobSpec{
+		Concurrency: 1,
+		Manifest:    manifestPath,
+		Jobs: []*JobDefinition{
+			{Name: "a", InspectionType: "success", Features: []string{"ALL"}, Destination: filepath.Join(t.TempDir(), "a.khi")},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	got, err := NewRunner(server, spec).Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() returned an unexpected error: %v", err)
+	}
+	if got.Cancelled != 1 || got.Jobs[0].Status != JobStatusCancelled {
+		t.Errorf("Run() returned cancelled count %d and status %s, want 1 and %s", got.Cancelled, got.Jobs[0].Status, JobStatusCancelled)
+	}
+	if _, err := os.Stat(manifestPath); err != nil {
+		t.Errorf("the manifest was not written after the cancellation: %v", err)
+	}
+}
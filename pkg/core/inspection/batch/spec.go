@@ -0,0 +1,189 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package batch runs multiple inspections described in a job spec file without the web server.
+package batch
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConcurrency is the count of inspections run in parallel when the job spec doesn't specify it.
+const DefaultConcurrency = 1
+
+// JobSpec is the root of a job spec file. The file can be written in YAML or JSON.
+type JobSpec struct {
+	// Concurrency is the maximum count of inspections run in parallel.
+	Concurrency int `yaml:"concurrency"`
+	// Manifest is the path of the JSON file where the results of the jobs are written. No manifest is written when this is empty.
+	Manifest string `yaml:"manifest"`
+	// Defaults is the default values of the fields of every job.
+	Defaults JobDefinition `yaml:"defaults"`
+	// Jobs is the list of inspections to run.
+	Jobs []*JobDefinition `yaml:"jobs"`
+}
+
+// JobDefinition describes an inspection to run.
+type JobDefinition struct {
+	// Name is the unique name of the job used in the logs and the manifest.
+	Name string `yaml:"name"`
+	// InspectionType is the ID of the inspection type.
+	InspectionType string `yaml:"inspectionType"`
+	// Features is the list of feature IDs to enable. `ALL` enables every feature available for the inspection type.
+	Features []string `yaml:"features"`
+	// Values is the parameters given to the inspection. Values in the defaults are merged with the values of each job.
+	Values map[string]any `yaml:"values"`
+	// Destination is the template of the path where the .khi file is written.
+	// It is a text/template receiving DestinationTemplateInput.
+	Destination string `yaml:"destination"`
+}
+
+// DestinationTemplateInput is the data given to the destination path template.
+type DestinationTemplateInput struct {
+	// Name is the name of the job.
+	Name string
+	// Index is the 0-based index of the job in the job spec.
+	Index int
+	// InspectionType is the ID of the inspection type.
+	InspectionType string
+	// Date is the date when the batch started in YYYYMMDD format.
+	Date string
+	// Time is the time when the batch started in HHMMSS format.
+	Time string
+	// Values is the merged parameters given to the inspection.
+	Values map[string]any
+}
+
+// Job is a JobDefinition merged with the defaults and resolved the destination.
+type Job struct {
+	Index          int
+	Name           string
+	InspectionType string
+	Features       []string
+	Values         map[string]any
+	Destination    string
+}
+
+// LoadJobSpec reads the job spec file at the given path.
+func LoadJobSpec(path string) (*JobSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the job spec file %s: %w", path, err)
+	}
+	return ParseJobSpec(data)
+}
+
+// ParseJobSpec parses the job spec written in YAML or JSON.
+func ParseJobSpec(data []byte) (*JobSpec, error) {
+	var spec JobSpec
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("failed to parse the job spec: %w", err)
+	}
+	if spec.Concurrency < 0 {
+		return nil, fmt.Errorf("concurrency must not be negative but %d was given", spec.Concurrency)
+	}
+	if spec.Concurrency == 0 {
+		spec.Concurrency = DefaultConcurrency
+	}
+	if len(spec.Jobs) == 0 {
+		return nil, fmt.Errorf("the job spec has no job")
+	}
+	return &spec, nil
+}
+
+// ResolveJobs merges each job with the defaults and renders the destination paths with the given batch start time.
+// It returns an error when any job lacks a required field, or when names or destinations are duplicated.
+func (s *JobSpec) ResolveJobs(startTime time.Time) ([]*Job, error) {
+	result := make([]*Job, 0, len(s.Jobs))
+	names := map[string]int{}
+	destinations := map[string]string{}
+	for i, definition := range s.Jobs {
+		job := &Job{
+			Index:          i,
+			Name:           definition.Name,
+			InspectionType: definition.InspectionType,
+			Features:       definition.Features,
+			Values:         map[string]any{},
+		}
+		if job.Name == "" {
+			job.Name = fmt.Sprintf("job-%d", i)
+		}
+		if job.InspectionType == "" {
+			job.InspectionType = s.Defaults.InspectionType
+		}
+		if len(job.Features) == 0 {
+			job.Features = s.Defaults.Features
+		}
+		maps.Copy(job.Values, s.Defaults.Values)
+		maps.Copy(job.Values, definition.Values)
+		destinationTemplate := definition.Destination
+		if destinationTemplate == "" {
+			destinationTemplate = s.Defaults.Destination
+		}
+
+		if job.InspectionType == "" {
+			return nil, fmt.Errorf("job %s has no inspectionType", job.Name)
+		}
+		if len(job.Features) == 0 {
+			return nil, fmt.Errorf("job %s has no features", job.Name)
+		}
+		if destinationTemplate == "" {
+			return nil, fmt.Errorf("job %s has no destination", job.Name)
+		}
+		if previous, found := names[job.Name]; found {
+			return nil, fmt.Errorf("job name %s is used by job %d and job %d", job.Name, previous, i)
+		}
+		names[job.Name] = i
+
+		destination, err := renderDestination(destinationTemplate, &DestinationTemplateInput{
+			Name:           job.Name,
+			Index:          i,
+			InspectionType: job.InspectionType,
+			Date:           startTime.Format("20060102"),
+			Time:           startTime.Format("150405"),
+			Values:         job.Values,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to render the destination of job %s: %w", job.Name, err)
+		}
+		if previous, found := destinations[destination]; found {
+			return nil, fmt.Errorf("job %s and job %s are writing the same destination %s", previous, job.Name, destination)
+		}
+		destinations[destination] = job.Name
+		job.Destination = destination
+		result = append(result, job)
+	}
+	return result, nil
+}
+
+func renderDestination(destinationTemplate string, input *DestinationTemplateInput) (string, error) {
+	tmpl, err := template.New("destination").Option("missingkey=error").Parse(destinationTemplate)
+	if err != nil {
+		return "", err
+	}
+	var result bytes.Buffer
+	if err := tmpl.Execute(&result, input); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
@@ -0,0 +1,223 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseJobSpec(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		want    *JobSpec
+		wantErr bool
+	}{
+		{
+			name: "yaml",
+			input: `concurrency: 2
+manifest: /tmp/out/manifest.json
+defaults:
+  inspectionType: gcp-gke
+  features: [ALL]
+  values:
+    project_id: foo
+jobs:
+- name: cluster-a
+  values:
+    cluster_name: a
+  destination: /tmp/out/{{.Name}}.khi
+`,
+			want: &JobSpec{
+				Concurrency: 2,
+				Manifest:    "/tmp/out/manifest.json",
+				Defaults: JobDefinition{
+					InspectionType: "gcp-gke",
+					Features:       []string{"ALL"},
+					Values:         map[string]any{"project_id": "foo"},
+				},
+				Jobs: []*JobDefinition{
+					{
+						Name:        "cluster-a",
+						Values:      map[string]any{"cluster_name": "a"},
+						Destination: "/tmp/out/{{.Name}}.khi",
+					},
+				},
+			},
+		},
+		{
+			name:  "json with the default concurrency",
+			input: `{"jobs":[{"name":"a","inspectionType":"foo","features":["bar"],"destination":"a.khi"}]}`,
+			want: &JobSpec{
+				Concurrency: DefaultConcurrency,
+				Jobs: []*JobDefinition{
+					{
+						Name:           "a",
+						InspectionType: "foo",
+						Features:       []string{"bar"},
+						Destination:    "a.khi",
+					},
+				},
+			},
+		},
+		{
+			name:    "no job",
+			input:   `concurrency: 1`,
+			wantErr: true,
+		},
+		{
+			name:    "negative concurrency",
+			input:   `{"concurrency":-1,"jobs":[{"name":"a"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			input:   `{"jobs":[{"name":"a","destinaton":"a.khi"}]}`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseJobSpec([]byte(tc.input))
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("ParseJobSpec() returned nil error, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseJobSpec() returned an unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ParseJobSpec() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestJobSpec_ResolveJobs(t *testing.T) {
+	startTime := time.Date(2025, time.January, 2, 3, 4, 5, 0, time.UTC)
+	testCases := []struct {
+		name    string
+		spec    *JobSpec
+		want    []*Job
+		wantErr bool
+	}{
+		{
+			name: "merge defaults",
+			spec: &JobSpec{
+				Defaults: JobDefinition{
+					InspectionType: "gcp-gke",
+					Features:       []string{"ALL"},
+					Values:         map[string]any{"project_id": "foo", "cluster_name": "default"},
+					Destination:    "/out/{{.Date}}-{{.Time}}/{{.Values.cluster_name}}.khi",
+				},
+				Jobs: []*JobDefinition{
+					{
+						Name:   "a",
+						Values: map[string]any{"cluster_name": "a"},
+					},
+					{
+						InspectionType: "gcp-composer",
+						Features:       []string{"feature-1"},
+						Destination:    "/out/{{.Index}}-{{.Name}}-{{.InspectionType}}.khi",
+					},
+				},
+			},
+			want: []*Job{
+				{
+					Index:          0,
+					Name:           "a",
+					InspectionType: "gcp-gke",
+					Features:       []string{"ALL"},
+					Values:         map[string]any{"project_id": "foo", "cluster_name": "a"},
+					Destination:    "/out/20250102-030405/a.khi",
+				},
+				{
+					Index:          1,
+					Name:           "job-1",
+					InspectionType: "gcp-composer",
+					Features:       []string{"feature-1"},
+					Values:         map[string]any{"project_id": "foo", "cluster_name": "default"},
+					Destination:    "/out/1-job-1-gcp-composer.khi",
+				},
+			},
+		},
+		{
+			name: "missing inspection type",
+			spec: &JobSpec{
+				Jobs: []*JobDefinition{{Name: "a", Features: []string{"ALL"}, Destination: "a.khi"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing destination",
+			spec: &JobSpec{
+				Jobs: []*JobDefinition{{Name: "a", InspectionType: "foo", Features: []string{"ALL"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicated name",
+			spec: &JobSpec{
+				Defaults: JobDefinition{InspectionType: "foo", Features: []string{"ALL"}},
+				Jobs: []*JobDefinition{
+					{Name: "a", Destination: "a.khi"},
+					{Name: "a", Destination: "b.khi"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicated destination",
+			spec: &JobSpec{
+				Defaults: JobDefinition{InspectionType: "foo", Features: []string{"ALL"}, Destination: "out.khi"},
+				Jobs: []*JobDefinition{
+					{Name: "a"},
+					{Name: "b"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing value in the destination template",
+			spec: &JobSpec{
+				Defaults: JobDefinition{InspectionType: "foo", Features: []string{"ALL"}, Destination: "{{.Values.cluster_name}}.khi"},
+				Jobs:     []*JobDefinition{{Name: "a"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.spec.ResolveJobs(startTime)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("ResolveJobs() returned nil error, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveJobs() returned an unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ResolveJobs() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -32,6 +32,7 @@ import (
 type FileFormTaskBuilder struct {
 	FormTaskBuilderBase[upload.UploadResult]
 	verifier upload.UploadFileVerifier
+	optional bool
 }
 
 func NewFileFormTaskBuilder(id taskid.TaskImplementationID[upload.UploadResult], priority int, label string, verifier upload.UploadFileVerifier) *FileFormTaskBuilder {
@@ -53,6 +54,13 @@ func (b *FileFormTaskBuilder) WithDescription(description string) *FileFormTaskB
 	return b
 }
 
+// AsOptional makes the form not to block the inspection while no file is uploaded.
+// Tasks depending on the form must check the status of the upload result before reading the file.
+func (b *FileFormTaskBuilder) AsOptional() *FileFormTaskBuilder {
+	b.optional = true
+	return b
+}
+
 func (b *FileFormTaskBuilder) Build(labelOpts ...common_task.LabelOpt) common_task.Task[upload.UploadResult] {
 	return common_task.NewTask(b.FormTaskBuilderBase.id, b.FormTaskBuilderBase.dependencies, func(ctx context.Context) (upload.UploadResult, error) {
 		metadata := khictx.MustGetValue(ctx, inspectioncore_contract.InspectionRunMetadata)
@@ -74,6 +82,9 @@ func (b *FileFormTaskBuilder) Build(labelOpts ...common_task.LabelOpt) common_ta
 		b.FormTaskBuilderBase.SetupBaseFormField(&field.ParameterFormFieldBase)
 
 		field = setFormHintsFromUploadResult(uploadResult, field)
+		if b.optional {
+			field = setOptionalFormHintsFromUploadResult(uploadResult, field)
+		}
 		formFields, found := typedmap.Get(metadata, inspectionmetadata.FormFieldSetMetadataKey)
 		if !found {
 			return upload.UploadResult{}, fmt.Errorf("failed to get form fields from metadata")
@@ -107,6 +118,15 @@ func setFormHintsFromUploadResult(result upload.UploadResult, field inspectionme
 	return field
 }
 
+// setOptionalFormHintsFromUploadResult replaces the error hint shown while waiting a file to be uploaded for optional forms.
+func setOptionalFormHintsFromUploadResult(result upload.UploadResult, field inspectionmetadata.FileParameterFormField) inspectionmetadata.FileParameterFormField {
+	if result.Status == upload.UploadStatusWaiting && result.UploadError == nil && result.VerificationError == nil {
+		field.Hint = "This field is optional. Upload a file only when it's needed."
+		field.HintType = inspectionmetadata.Info
+	}
+	return field
+}
+
 // GenerateUploadIDWithTaskContext generates the upload ID from form ID and task ID.
 func GenerateUploadIDWithTaskContext(ctx context.Context, formId string) string {
 	inspectionID := khictx.MustGetValue(ctx, inspectioncore_contract.InspectionTaskInspectionID)
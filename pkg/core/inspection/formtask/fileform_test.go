@@ -175,3 +175,43 @@ func TestSetFormHintsFromUploadResult(t *testing.T) {
 		})
 	}
 }
+
+func TestSetOptionalFormHintsFromUploadResult(t *testing.T) {
+	testCases := []struct {
+		name         string
+		uploadResult upload.UploadResult
+		wantHintType inspectionmetadata.ParameterHintType
+	}{
+		{
+			name:         "waiting status is not an error",
+			uploadResult: upload.UploadResult{Status: upload.UploadStatusWaiting},
+			wantHintType: inspectionmetadata.Info,
+		},
+		{
+			name:         "verification error is kept",
+			uploadResult: upload.UploadResult{Status: upload.UploadStatusWaiting, VerificationError: errors.New("verification error")},
+			wantHintType: inspectionmetadata.Error,
+		},
+		{
+			name:         "uploading status is kept",
+			uploadResult: upload.UploadResult{Status: upload.UploadStatusUploading},
+			wantHintType: inspectionmetadata.Error,
+		},
+		{
+			name:         "completed status is kept",
+			uploadResult: upload.UploadResult{Status: upload.UploadStatusCompleted},
+			wantHintType: inspectionmetadata.None,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			field := setFormHintsFromUploadResult(tc.uploadResult, inspectionmetadata.FileParameterFormField{
+				ParameterFormFieldBase: inspectionmetadata.ParameterFormFieldBase{HintType: inspectionmetadata.None},
+			})
+			result := setOptionalFormHintsFromUploadResult(tc.uploadResult, field)
+			if result.HintType != tc.wantHintType {
+				t.Errorf("HintType = %v, want %v", result.HintType, tc.wantHintType)
+			}
+		})
+	}
+}
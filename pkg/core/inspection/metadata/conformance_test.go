@@ -42,7 +42,7 @@ func TestConformance(t *testing.T) {
 
 func TestErrorMetadataConformance(t *testing.T) {
 	ConformanceMetadataTypeTest(t, &ErrorMessageSetMetadata{
-		[]*ErrorMessage{
+		ErrorMessages: []*ErrorMessage{
 			{},
 		},
 	})
@@ -27,6 +27,7 @@ type ErrorMessage struct {
 // ErrorMessageSetMetadata is a metadata type containing errors exposed to frontend.
 type ErrorMessageSetMetadata struct {
 	ErrorMessages []*ErrorMessage `json:"errorMessages"`
+	notifier      ChangeNotifier
 }
 
 // Labels implements metadata.Metadata.
@@ -39,7 +40,12 @@ func (e *ErrorMessageSetMetadata) ToSerializable() interface{} {
 	return e
 }
 
-var _ Metadata = (*ErrorMessageSetMetadata)(nil)
+// Notifier implements ObservableMetadata.
+func (e *ErrorMessageSetMetadata) Notifier() *ChangeNotifier {
+	return &e.notifier
+}
+
+var _ ObservableMetadata = (*ErrorMessageSetMetadata)(nil)
 
 // AddErrorMessage stores a new ErrorMessage. Duplicated error message will be ignored.
 func (e *ErrorMessageSetMetadata) AddErrorMessage(newError *ErrorMessage) {
@@ -49,6 +55,7 @@ func (e *ErrorMessageSetMetadata) AddErrorMessage(newError *ErrorMessage) {
 		}
 	}
 	e.ErrorMessages = append(e.ErrorMessages, newError)
+	e.notifier.NotifyChange()
 }
 
 func NewUnauthorizedErrorMessage() *ErrorMessage {
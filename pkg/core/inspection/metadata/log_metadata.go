@@ -16,6 +16,7 @@ package inspectionmetadata
 
 import (
 	"bytes"
+	"io"
 	"sort"
 	"sync"
 
@@ -27,6 +28,7 @@ import (
 type LogMetadata struct {
 	logBuffers map[string]*bytes.Buffer
 	lock       sync.Mutex
+	notifier   ChangeNotifier
 }
 
 // NewLogMetadata instanciates an empty LogMetadata.
@@ -36,6 +38,16 @@ func NewLogMetadata() *LogMetadata {
 	}
 }
 
+// NewLogMetadataFromSerializable instanciates a LogMetadata from the result of ToSerializable.
+// This is used to restore the logs of an inspection persisted before.
+func NewLogMetadataFromSerializable(items []SerializableLogItem) *LogMetadata {
+	result := NewLogMetadata()
+	for _, item := range items {
+		result.logBuffers[item.Id] = bytes.NewBufferString(item.Log)
+	}
+	return result
+}
+
 // SerializableLogItem is a log data for a specific task.
 type SerializableLogItem struct {
 	Id   string `json:"id"`
@@ -53,6 +65,43 @@ func (l *LogMetadata) GetTaskLogBuffer(taskID taskid.UntypedTaskImplementationID
 	return l.logBuffers[taskID.String()]
 }
 
+// GetTaskLogWriter returns an io.Writer appending logs to the log buffer of the specified task.
+// Unlike writing the buffer returned from GetTaskLogBuffer, writes from the returned writer notify the subscribers of the LogMetadata.
+func (l *LogMetadata) GetTaskLogWriter(taskID taskid.UntypedTaskImplementationID) io.Writer {
+	return &taskLogWriter{
+		metadata: l,
+		buffer:   l.GetTaskLogBuffer(taskID),
+	}
+}
+
+// LogsSince returns the logs appended after the given lengths of logs for each task ID.
+// Tasks without any new log are omitted. The given map is updated to the current lengths of logs.
+func (l *LogMetadata) LogsSince(readLengths map[string]int) []SerializableLogItem {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	result := []SerializableLogItem{}
+	for _, key := range l.sortedKeys() {
+		log := l.logBuffers[key].String()
+		readLength := readLengths[key]
+		if readLength >= len(log) {
+			continue
+		}
+		result = append(result, SerializableLogItem{
+			Id:   key,
+			Name: key,
+			Log:  log[readLength:],
+		})
+		readLengths[key] = len(log)
+	}
+	return result
+}
+
+// Notifier implements ObservableMetadata.
+// It is notified when logs are written from the writers returned from GetTaskLogWriter.
+func (l *LogMetadata) Notifier() *ChangeNotifier {
+	return &l.notifier
+}
+
 // Labels implements Metadata.
 func (l *LogMetadata) Labels() *typedmap.ReadonlyTypedMap {
 	return NewLabelSet(
@@ -63,13 +112,9 @@ func (l *LogMetadata) Labels() *typedmap.ReadonlyTypedMap {
 // ToSerializable implements Metadata.
 // It returns a slice of SerializableLogItem, sorted by task ID.
 func (l *LogMetadata) ToSerializable() interface{} {
-	// Get keys and sort them to ensure a stable order.
-	keys := make([]string, 0, len(l.logBuffers))
-	for k := range l.logBuffers {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	keys := l.sortedKeys()
 	result := make([]SerializableLogItem, len(keys))
 	for i, key := range keys {
 		result[i] = SerializableLogItem{
@@ -81,4 +126,29 @@ func (l *LogMetadata) ToSerializable() interface{} {
 	return result
 }
 
-var _ Metadata = (*LogMetadata)(nil)
+// sortedKeys returns the task IDs sorted to ensure a stable order. lock must be held by the caller.
+func (l *LogMetadata) sortedKeys() []string {
+	keys := make([]string, 0, len(l.logBuffers))
+	for k := range l.logBuffers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var _ ObservableMetadata = (*LogMetadata)(nil)
+
+// taskLogWriter is the io.Writer returned from LogMetadata.GetTaskLogWriter.
+type taskLogWriter struct {
+	metadata *LogMetadata
+	buffer   *bytes.Buffer
+}
+
+// Write implements io.Writer.
+func (w *taskLogWriter) Write(p []byte) (int, error) {
+	w.metadata.lock.Lock()
+	n, err := w.buffer.Write(p)
+	w.metadata.lock.Unlock()
+	w.metadata.notifier.NotifyChange()
+	return n, err
+}
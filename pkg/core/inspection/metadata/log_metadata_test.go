@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/core/task/taskid"
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestLogMetadata(t *testing.T) {
@@ -80,3 +81,54 @@ func TestLogMetadata(t *testing.T) {
 		t.Errorf("expected second item to have log %q, but got %q", logMessage1, items[1].Log)
 	}
 }
+
+func TestNewLogMetadataFromSerializable(t *testing.T) {
+	items := []SerializableLogItem{
+		{Id: "task1", Name: "task1", Log: "hello from task 1"},
+		{Id: "task2", Name: "task2", Log: "hello from task 2"},
+	}
+
+	logMetadata := NewLogMetadataFromSerializable(items)
+
+	got, ok := logMetadata.ToSerializable().([]SerializableLogItem)
+	if !ok {
+		t.Fatalf("ToSerializable() did not return []SerializableLogItem")
+	}
+	if len(got) != len(items) {
+		t.Fatalf("expected %d log items, but got %d", len(items), len(got))
+	}
+	for i, item := range items {
+		if got[i] != item {
+			t.Errorf("expected item %d to be %+v, but got %+v", i, item, got[i])
+		}
+	}
+}
+
+func TestLogMetadata_LogsSince(t *testing.T) {
+	logMetadata := NewLogMetadata()
+	writer1 := logMetadata.GetTaskLogWriter(taskid.NewDefaultImplementationID[any]("task1"))
+	writer2 := logMetadata.GetTaskLogWriter(taskid.NewDefaultImplementationID[any]("task2"))
+	readLengths := map[string]int{}
+
+	writer1.Write([]byte("foo"))
+	writer2.Write([]byte("bar"))
+	want := []SerializableLogItem{
+		{Id: "task1#default", Name: "task1#default", Log: "foo"},
+		{Id: "task2#default", Name: "task2#default", Log: "bar"},
+	}
+	if diff := cmp.Diff(want, logMetadata.LogsSince(readLengths)); diff != "" {
+		t.Errorf("LogsSince() mismatch (-want +got):\n%s", diff)
+	}
+
+	writer1.Write([]byte("baz"))
+	want = []SerializableLogItem{
+		{Id: "task1#default", Name: "task1#default", Log: "baz"},
+	}
+	if diff := cmp.Diff(want, logMetadata.LogsSince(readLengths)); diff != "" {
+		t.Errorf("LogsSince() mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]SerializableLogItem{}, logMetadata.LogsSince(readLengths)); diff != "" {
+		t.Errorf("LogsSince() without new logs mismatch (-want +got):\n%s", diff)
+	}
+}
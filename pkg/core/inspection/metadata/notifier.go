@@ -0,0 +1,68 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspectionmetadata
+
+import "sync"
+
+// ChangeNotifier broadcasts change notifications to any number of subscribers.
+// Subscribers get a channel from Changed before reading the current state, and wait for the channel to be closed by the next NotifyChange call.
+// The zero value is ready to use.
+type ChangeNotifier struct {
+	lock      sync.Mutex
+	changed   chan struct{}
+	forwardTo []*ChangeNotifier
+}
+
+// Changed returns a channel closed when NotifyChange is called next time.
+func (n *ChangeNotifier) Changed() <-chan struct{} {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.changed == nil {
+		n.changed = make(chan struct{})
+	}
+	return n.changed
+}
+
+// NotifyChange wakes up all subscribers waiting on the channel returned from Changed and the notifiers registered with Forward.
+// Calling it on a nil ChangeNotifier does nothing.
+func (n *ChangeNotifier) NotifyChange() {
+	if n == nil {
+		return
+	}
+	n.lock.Lock()
+	if n.changed != nil {
+		close(n.changed)
+		n.changed = nil
+	}
+	forwardTo := n.forwardTo
+	n.lock.Unlock()
+	for _, dest := range forwardTo {
+		dest.NotifyChange()
+	}
+}
+
+// Forward makes the given notifier to be notified whenever this notifier is notified.
+func (n *ChangeNotifier) Forward(dest *ChangeNotifier) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.forwardTo = append(n.forwardTo, dest)
+}
+
+// ObservableMetadata is a Metadata notifying its changes.
+type ObservableMetadata interface {
+	Metadata
+	// Notifier returns the ChangeNotifier notified when the metadata is changed.
+	Notifier() *ChangeNotifier
+}
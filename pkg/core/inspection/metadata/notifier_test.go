@@ -0,0 +1,119 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspectionmetadata
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/core/task/taskid"
+)
+
+// isClosed returns true if the given channel is already closed.
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestChangeNotifier(t *testing.T) {
+	var notifier ChangeNotifier
+	var forwarded ChangeNotifier
+	notifier.Forward(&forwarded)
+
+	changed := notifier.Changed()
+	forwardedChanged := forwarded.Changed()
+	if isClosed(changed) {
+		t.Fatalf("Changed() returned a closed channel before any change")
+	}
+	notifier.NotifyChange()
+	if !isClosed(changed) {
+		t.Errorf("the channel returned from Changed() was not closed after NotifyChange()")
+	}
+	if !isClosed(forwardedChanged) {
+		t.Errorf("the forwarded notifier was not notified")
+	}
+	if isClosed(notifier.Changed()) {
+		t.Errorf("Changed() returned a closed channel after NotifyChange()")
+	}
+	// NotifyChange must be callable without any subscriber and on nil.
+	notifier.NotifyChange()
+	var nilNotifier *ChangeNotifier
+	nilNotifier.NotifyChange()
+}
+
+func TestObservableMetadataNotifiesChanges(t *testing.T) {
+	testCases := []struct {
+		name     string
+		metadata ObservableMetadata
+		change   func(t *testing.T, m ObservableMetadata)
+	}{
+		{
+			name:     "Progress.ResolveTask",
+			metadata: NewProgress(),
+			change: func(t *testing.T, m ObservableMetadata) {
+				if err := m.(*Progress).ResolveTask("foo"); err != nil {
+					t.Fatalf("ResolveTask() returned an unexpected error: %v", err)
+				}
+			},
+		},
+		{
+			name:     "Progress.MarkDone",
+			metadata: NewProgress(),
+			change: func(t *testing.T, m ObservableMetadata) {
+				if err := m.(*Progress).MarkDone(); err != nil {
+					t.Fatalf("MarkDone() returned an unexpected error: %v", err)
+				}
+			},
+		},
+		{
+			name:     "TaskProgressMetadata.Update",
+			metadata: NewProgress(),
+			change: func(t *testing.T, m ObservableMetadata) {
+				tp, err := m.(*Progress).GetOrCreateTaskProgress("foo")
+				if err != nil {
+					t.Fatalf("GetOrCreateTaskProgress() returned an unexpected error: %v", err)
+				}
+				m.Notifier().Changed() // GetOrCreateTaskProgress notified. Renew the channel.
+				tp.Update(0.5, "foo")
+			},
+		},
+		{
+			name:     "ErrorMessageSetMetadata.AddErrorMessage",
+			metadata: NewErrorMessageSetMetadata(),
+			change: func(t *testing.T, m ObservableMetadata) {
+				m.(*ErrorMessageSetMetadata).AddErrorMessage(NewUnauthorizedErrorMessage())
+			},
+		},
+		{
+			name:     "LogMetadata.GetTaskLogWriter",
+			metadata: NewLogMetadata(),
+			change: func(t *testing.T, m ObservableMetadata) {
+				m.(*LogMetadata).GetTaskLogWriter(taskid.NewDefaultImplementationID[any]("foo")).Write([]byte("log"))
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			changed := tc.metadata.Notifier().Changed()
+			tc.change(t, tc.metadata)
+			if !isClosed(changed) {
+				t.Errorf("the change was not notified")
+			}
+		})
+	}
+}
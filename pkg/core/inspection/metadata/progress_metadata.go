@@ -44,6 +44,8 @@ type TaskProgressMetadata struct {
 	Message       string  `json:"message"`
 	Percentage    float32 `json:"percentage"`
 	Indeterminate bool    `json:"indeterminate"`
+	// notifier is the ChangeNotifier of the Progress containing this task progress. This is nil for a standalone TaskProgressMetadata.
+	notifier *ChangeNotifier
 }
 
 // NewTaskProgressMetadata creates and initializes a new TaskProgress object with the given ID.
@@ -62,12 +64,20 @@ func (tp *TaskProgressMetadata) Update(percentage float32, message string) {
 	tp.Percentage = percentage
 	tp.Message = message
 	tp.Indeterminate = false
+	tp.NotifyChange()
 }
 
 // MarkIndeterminate updates TaskProgress field to be indeterminate mode
 func (tp *TaskProgressMetadata) MarkIndeterminate() {
 	tp.Indeterminate = true
 	tp.Percentage = 0
+	tp.NotifyChange()
+}
+
+// NotifyChange notifies the subscribers of the Progress containing this task progress.
+// Call this after modifying the fields directly.
+func (tp *TaskProgressMetadata) NotifyChange() {
+	tp.notifier.NotifyChange()
 }
 
 // Progress aggregates the progress of all tasks in an inspection run.
@@ -79,11 +89,12 @@ type Progress struct {
 	totalTaskCount    int                     `json:"-"`
 	resolvedTaskCount int                     `json:"-"`
 	lock              sync.Mutex              `json:"-"`
+	notifier          ChangeNotifier          `json:"-"`
 }
 
 // NewProgress creates and initializes a new Progress object.
 func NewProgress() *Progress {
-	return &Progress{
+	progress := &Progress{
 		Phase:             TaskPhaseRunning,
 		TaskProgresses:    make([]*TaskProgressMetadata, 0),
 		TotalProgress:     NewTaskProgressMetadata("Total"),
@@ -91,6 +102,8 @@ func NewProgress() *Progress {
 		resolvedTaskCount: 0,
 		totalTaskCount:    0,
 	}
+	progress.TotalProgress.notifier = &progress.notifier
+	return progress
 }
 
 // Labels implements Metadata.
@@ -105,11 +118,20 @@ func (p *Progress) ToSerializable() interface{} {
 	return p
 }
 
+var _ ObservableMetadata = (*Progress)(nil)
+
+// Notifier implements ObservableMetadata.
+// It is notified on phase changes and updates of the task progresses.
+func (p *Progress) Notifier() *ChangeNotifier {
+	return &p.notifier
+}
+
 // SetTotalTaskCount sets the total number of tasks that will be tracked.
 // This is used to calculate the overall progress percentage.
 func (p *Progress) SetTotalTaskCount(count int) {
 	p.totalTaskCount = count
 	p.updateTotalTaskProgress()
+	p.notifier.NotifyChange()
 }
 
 // GetOrCreateTaskProgress retrieves the TaskProgress for a given task ID.
@@ -127,7 +149,9 @@ func (p *Progress) GetOrCreateTaskProgress(id string) (*TaskProgressMetadata, er
 		}
 	}
 	taskProgress := NewTaskProgressMetadata(id)
+	taskProgress.notifier = &p.notifier
 	p.TaskProgresses = append(p.TaskProgresses, taskProgress)
+	p.notifier.NotifyChange()
 	return taskProgress, nil
 }
 
@@ -149,6 +173,7 @@ func (p *Progress) ResolveTask(id string) error {
 	p.TaskProgresses = newTaskProgress
 	p.resolvedTaskCount += 1
 	p.updateTotalTaskProgress()
+	p.notifier.NotifyChange()
 	return nil
 }
 
@@ -165,6 +190,7 @@ func (p *Progress) MarkDone() error {
 	p.resolvedTaskCount = p.totalTaskCount
 	p.TaskProgresses = make([]*TaskProgressMetadata, 0)
 	p.updateTotalTaskProgress()
+	p.notifier.NotifyChange()
 	return nil
 }
 
@@ -179,6 +205,7 @@ func (p *Progress) MarkCancelled() error {
 	}
 	p.Phase = TaskPhaseCancelled
 	p.TaskProgresses = make([]*TaskProgressMetadata, 0)
+	p.notifier.NotifyChange()
 	return nil
 }
 
@@ -193,6 +220,7 @@ func (p *Progress) MarkError() error {
 	}
 	p.Phase = TaskPhaseError
 	p.TaskProgresses = make([]*TaskProgressMetadata, 0)
+	p.notifier.NotifyChange()
 	return nil
 }
 
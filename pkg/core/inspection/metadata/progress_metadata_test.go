@@ -35,7 +35,7 @@ func TestGetTaskProgress(t *testing.T) {
 		Label:      "foo",
 	}
 
-	if diff := cmp.Diff(expected, tp); diff != "" {
+	if diff := cmp.Diff(expected, tp, cmpopts.IgnoreUnexported(TaskProgressMetadata{})); diff != "" {
 		t.Errorf("generated task progress is not containing the expected state\n%s", diff)
 	}
 
@@ -65,7 +65,7 @@ func TestResolveTasks(t *testing.T) {
 				Label: "bar",
 			},
 		},
-	}, progress, cmpopts.IgnoreUnexported(Progress{})); diff != "" {
+	}, progress, cmpopts.IgnoreUnexported(Progress{}, TaskProgressMetadata{})); diff != "" {
 		t.Errorf("The result status is not in the expected status\n%s", diff)
 	}
 }
@@ -81,7 +81,7 @@ func TestDoneClearTasks(t *testing.T) {
 		Phase:          "DONE",
 		TotalProgress:  &TaskProgressMetadata{Id: "Total", Label: "Total", Message: "2 of 2 tasks complete", Percentage: 1},
 		TaskProgresses: []*TaskProgressMetadata{},
-	}, progress, cmpopts.IgnoreUnexported(Progress{})); diff != "" {
+	}, progress, cmpopts.IgnoreUnexported(Progress{}, TaskProgressMetadata{})); diff != "" {
 		t.Errorf("The result status is not in the expected status\n%s", diff)
 	}
 }
@@ -97,7 +97,7 @@ func TestCancelClearTasks(t *testing.T) {
 		Phase:          "CANCELLED",
 		TaskProgresses: []*TaskProgressMetadata{},
 		TotalProgress:  &TaskProgressMetadata{Id: "Total", Label: "Total", Message: "0 of 2 tasks complete"},
-	}, progress, cmpopts.IgnoreUnexported(Progress{})); diff != "" {
+	}, progress, cmpopts.IgnoreUnexported(Progress{}, TaskProgressMetadata{})); diff != "" {
 		t.Errorf("The result status is not in the expected status\n%s", diff)
 	}
 }
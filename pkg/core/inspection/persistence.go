@@ -0,0 +1,278 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coreinspection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/typedmap"
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	coretask "github.com/GoogleCloudPlatform/khi/pkg/core/task"
+	inspectioncore_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/contract"
+)
+
+// InspectionIndexFileName is the name of the index file of finished inspections placed in the data destination folder.
+const InspectionIndexFileName = "inspections.json"
+
+// PersistedInspection is an entry of the InspectionIndex.
+// It contains the metadata needed to serve a finished inspection without running it again.
+type PersistedInspection struct {
+	ID               string    `json:"id"`
+	InspectionTypeID string    `json:"inspectionTypeId"`
+	EnabledFeatures  []string  `json:"enabledFeatures"`
+	CreationTime     time.Time `json:"creationTime"`
+	// ResultFileName is the name of the .khi file relative to the data destination folder. Empty when the inspection didn't produce any result.
+	ResultFileName string                                      `json:"resultFileName"`
+	Header         *inspectionmetadata.HeaderMetadata          `json:"header"`
+	Progress       *inspectionmetadata.Progress                `json:"progress"`
+	Errors         *inspectionmetadata.ErrorMessageSetMetadata `json:"errors"`
+	Queries        []*inspectionmetadata.QueryItem             `json:"queries"`
+	Plan           *inspectionmetadata.InspectionPlanMetadata  `json:"plan"`
+	Logs           []inspectionmetadata.SerializableLogItem    `json:"logs"`
+}
+
+// InspectionIndex stores PersistedInspection entries in a JSON file.
+type InspectionIndex struct {
+	path string
+	lock sync.Mutex
+}
+
+// NewInspectionIndex returns an InspectionIndex reading and writing the index file at the given path.
+func NewInspectionIndex(path string) *InspectionIndex {
+	return &InspectionIndex{
+		path: path,
+	}
+}
+
+// List returns all entries in the index. It returns an empty list when the index file doesn't exist yet.
+func (i *InspectionIndex) List() ([]*PersistedInspection, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	return i.read()
+}
+
+// Put adds the given entry to the index or replaces the entry with the same ID.
+func (i *InspectionIndex) Put(entry *PersistedInspection) error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	entries, err := i.read()
+	if err != nil {
+		return err
+	}
+	entries = slices.DeleteFunc(entries, func(e *PersistedInspection) bool { return e.ID == entry.ID })
+	entries = append(entries, entry)
+	return i.write(entries)
+}
+
+// Remove deletes the entry with the given ID from the index. It does nothing when the entry doesn't exist.
+func (i *InspectionIndex) Remove(id string) error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	entries, err := i.read()
+	if err != nil {
+		return err
+	}
+	entries = slices.DeleteFunc(entries, func(e *PersistedInspection) bool { return e.ID == id })
+	return i.write(entries)
+}
+
+func (i *InspectionIndex) read() ([]*PersistedInspection, error) {
+	data, err := os.ReadFile(i.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*PersistedInspection{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the inspection index %s: %w", i.path, err)
+	}
+	var entries []*PersistedInspection
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse the inspection index %s: %w", i.path, err)
+	}
+	return entries, nil
+}
+
+// write replaces the index file with the given entries. It writes a temporary file first and renames it to avoid leaving a broken index on crash.
+func (i *InspectionIndex) write(entries []*PersistedInspection) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to serialize the inspection index: %w", err)
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(i.path), "."+filepath.Base(i.path)+"-")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary file for the inspection index: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write the inspection index: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write the inspection index: %w", err)
+	}
+	if err := os.Rename(tmpFile.Name(), i.path); err != nil {
+		return fmt.Errorf("failed to replace the inspection index %s: %w", i.path, err)
+	}
+	return nil
+}
+
+// restoredTaskRunner is a coretask.TaskRunner returning the result of an inspection finished before the server restarted.
+type restoredTaskRunner struct {
+	result *typedmap.ReadonlyTypedMap
+	err    error
+	waiter chan interface{}
+}
+
+var _ coretask.TaskRunner = (*restoredTaskRunner)(nil)
+
+func newRestoredTaskRunner(ioConfig *inspectioncore_contract.IOConfig, entry *PersistedInspection) *restoredTaskRunner {
+	waiter := make(chan interface{})
+	close(waiter)
+	runner := &restoredTaskRunner{
+		waiter: waiter,
+	}
+	if entry.Progress == nil || entry.Progress.Phase != inspectionmetadata.TaskPhaseDone || entry.ResultFileName == "" {
+		phase := "unknown"
+		if entry.Progress != nil {
+			phase = string(entry.Progress.Phase)
+		}
+		runner.err = fmt.Errorf("inspection %s was finished without result (phase: %s)", entry.ID, phase)
+		return runner
+	}
+	result := typedmap.NewTypedMap()
+	store := inspectioncore_contract.NewFileSystemInspectionResultRepository(filepath.Join(ioConfig.DataDestination, entry.ResultFileName))
+	typedmap.Set(result, typedmap.NewTypedKey[inspectioncore_contract.Store](inspectioncore_contract.SerializerTaskID.ReferenceIDString()), inspectioncore_contract.Store(store))
+	runner.result = result.AsReadonly()
+	return runner
+}
+
+// Run implements coretask.TaskRunner.
+func (r *restoredTaskRunner) Run(ctx context.Context) error {
+	return fmt.Errorf("restored inspection can't be run again")
+}
+
+// Wait implements coretask.TaskRunner.
+func (r *restoredTaskRunner) Wait() <-chan interface{} {
+	return r.waiter
+}
+
+// Result implements coretask.TaskRunner.
+func (r *restoredTaskRunner) Result() (*typedmap.ReadonlyTypedMap, error) {
+	return r.result, r.err
+}
+
+// newPersistedInspection collects the metadata of a finished inspection runner to be stored in the InspectionIndex.
+func newPersistedInspection(runner *InspectionTaskRunner, store inspectioncore_contract.Store) *PersistedInspection {
+	enabledFeatures := []string{}
+	for feature, enabled := range runner.enabledFeatures {
+		if enabled {
+			enabledFeatures = append(enabledFeatures, feature)
+		}
+	}
+	slices.Sort(enabledFeatures)
+	entry := &PersistedInspection{
+		ID:               runner.ID,
+		InspectionTypeID: runner.currentInspectionType,
+		EnabledFeatures:  enabledFeatures,
+		CreationTime:     runner.inspectionCreationTime,
+		Queries:          []*inspectionmetadata.QueryItem{},
+		Logs:             []inspectionmetadata.SerializableLogItem{},
+	}
+	if fileStore, ok := store.(*inspectioncore_contract.FileSystemStore); ok {
+		if relPath, err := filepath.Rel(runner.ioconfig.DataDestination, fileStore.FilePath()); err == nil && !strings.HasPrefix(relPath, "..") {
+			entry.ResultFileName = relPath
+		}
+	}
+	if header, found := typedmap.Get(runner.metadata, inspectionmetadata.HeaderMetadataKey); found {
+		entry.Header = header
+	}
+	if progress, found := typedmap.Get(runner.metadata, inspectionmetadata.ProgressMetadataKey); found {
+		entry.Progress = progress
+	}
+	if errorSet, found := typedmap.Get(runner.metadata, inspectionmetadata.ErrorMessageSetMetadataKey); found {
+		entry.Errors = errorSet
+	}
+	if query, found := typedmap.Get(runner.metadata, inspectionmetadata.QueryMetadataKey); found {
+		if queries, ok := query.ToSerializable().([]*inspectionmetadata.QueryItem); ok {
+			entry.Queries = queries
+		}
+	}
+	if plan, found := typedmap.Get(runner.metadata, inspectionmetadata.InspectionPlanMetadataKey); found {
+		entry.Plan = plan
+	}
+	if logMetadata, found := typedmap.Get(runner.metadata, inspectionmetadata.LogMetadataKey); found {
+		if logs, ok := logMetadata.ToSerializable().([]inspectionmetadata.SerializableLogItem); ok {
+			entry.Logs = logs
+		}
+	}
+	return entry
+}
+
+// newRestoredInspectionRunner creates a finished InspectionTaskRunner from an entry of the InspectionIndex.
+func newRestoredInspectionRunner(server *InspectionTaskServer, ioConfig *inspectioncore_contract.IOConfig, entry *PersistedInspection) *InspectionTaskRunner {
+	runner := NewInspectionRunner(server, ioConfig, entry.ID, server.runContextOptions...)
+	runner.inspectionCreationTime = entry.CreationTime
+	// The inspection type can be missing after upgrading KHI. The runner is still usable to get the result even if the feature list is not available.
+	if server.GetInspectionType(entry.InspectionTypeID) != nil {
+		if err := runner.SetInspectionType(entry.InspectionTypeID); err == nil {
+			runner.SetFeatureList(entry.EnabledFeatures)
+		}
+	}
+	runner.currentInspectionType = entry.InspectionTypeID
+
+	metadata := typedmap.NewTypedMap()
+	header := entry.Header
+	if header == nil {
+		header = &inspectionmetadata.HeaderMetadata{}
+	}
+	typedmap.Set(metadata, inspectionmetadata.HeaderMetadataKey, header)
+	progress := entry.Progress
+	if progress == nil {
+		progress = inspectionmetadata.NewProgress()
+		progress.MarkError()
+	}
+	typedmap.Set(metadata, inspectionmetadata.ProgressMetadataKey, progress)
+	errorSet := entry.Errors
+	if errorSet == nil {
+		errorSet = inspectionmetadata.NewErrorMessageSetMetadata()
+	}
+	typedmap.Set(metadata, inspectionmetadata.ErrorMessageSetMetadataKey, errorSet)
+	query := inspectionmetadata.NewQueryMetadata()
+	for _, q := range entry.Queries {
+		query.SetQuery(q.Id, q.Name, q.Query)
+	}
+	typedmap.Set(metadata, inspectionmetadata.QueryMetadataKey, query)
+	plan := entry.Plan
+	if plan == nil {
+		plan = inspectionmetadata.NewInspectionPlanMetadata("")
+	}
+	typedmap.Set(metadata, inspectionmetadata.InspectionPlanMetadataKey, plan)
+	typedmap.Set(metadata, inspectionmetadata.LogMetadataKey, inspectionmetadata.NewLogMetadataFromSerializable(entry.Logs))
+	typedmap.Set(metadata, inspectionmetadata.FormFieldSetMetadataKey, inspectionmetadata.NewFormFieldSetMetadata())
+
+	runner.metadata = metadata.AsReadonly()
+	runner.runner = newRestoredTaskRunner(ioConfig, entry)
+	runner.cancel = func() {}
+	runner.finished = true
+	return runner
+}
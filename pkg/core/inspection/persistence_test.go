@@ -0,0 +1,197 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coreinspection
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/typedmap"
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	inspectioncore_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/contract"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestInspectionIndex(t *testing.T) {
+	index := NewInspectionIndex(filepath.Join(t.TempDir(), InspectionIndexFileName))
+
+	entries, err := index.List()
+	if err != nil {
+		t.Fatalf("List() returned an unexpected error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("List() returned %d entries for a missing index file, want 0", len(entries))
+	}
+
+	for _, id := range []string{"inspection-1", "inspection-2", "inspection-1"} {
+		if err := index.Put(&PersistedInspection{ID: id, ResultFileName: id + ".khi"}); err != nil {
+			t.Fatalf("Put(%s) returned an unexpected error: %v", id, err)
+		}
+	}
+	if err := index.Remove("inspection-2"); err != nil {
+		t.Fatalf("Remove() returned an unexpected error: %v", err)
+	}
+	if err := index.Remove("inspection-3"); err != nil {
+		t.Fatalf("Remove() for a missing entry returned an unexpected error: %v", err)
+	}
+
+	entries, err = index.List()
+	if err != nil {
+		t.Fatalf("List() returned an unexpected error: %v", err)
+	}
+	want := []*PersistedInspection{{ID: "inspection-1", ResultFileName: "inspection-1.khi"}}
+	if diff := cmp.Diff(want, entries, cmpopts.IgnoreUnexported(inspectionmetadata.Progress{}, inspectionmetadata.QueryMetadata{})); diff != "" {
+		t.Errorf("List() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestInspectionTaskServer_EnablePersistence(t *testing.T) {
+	dataDestination := t.TempDir()
+	ioConfig := &inspectioncore_contract.IOConfig{
+		DataDestination: dataDestination,
+		TemporaryFolder: t.TempDir(),
+	}
+	if err := os.WriteFile(filepath.Join(dataDestination, "inspection-1.khi"), []byte("KHI-data"), 0644); err != nil {
+		t.Fatalf("failed to write the test result file: %v", err)
+	}
+	doneProgress := inspectionmetadata.NewProgress()
+	doneProgress.SetTotalTaskCount(1)
+	doneProgress.MarkDone()
+	errorProgress := inspectionmetadata.NewProgress()
+	errorProgress.SetTotalTaskCount(1)
+	errorProgress.MarkError()
+	index := NewInspectionIndex(filepath.Join(dataDestination, InspectionIndexFileName))
+	persisted := []*PersistedInspection{
+		{
+			ID:             "inspection-1",
+			ResultFileName: "inspection-1.khi",
+			Header:         &inspectionmetadata.HeaderMetadata{InspectionType: "foo", SuggestedFileName: "foo.khi"},
+			Progress:       doneProgress,
+			Queries:        []*inspectionmetadata.QueryItem{{Id: "q1", Name: "query 1", Query: "resource.type=\"k8s_cluster\""}},
+		},
+		{
+			ID:       "inspection-2",
+			Header:   &inspectionmetadata.HeaderMetadata{InspectionType: "foo"},
+			Progress: errorProgress,
+		},
+		{
+			ID:             "inspection-3",
+			ResultFileName: "inspection-3.khi",
+			Progress:       doneProgress,
+		},
+	}
+	for _, entry := range persisted {
+		if err := index.Put(entry); err != nil {
+			t.Fatalf("Put() returned an unexpected error: %v", err)
+		}
+	}
+
+	server, err := NewServer(ioConfig)
+	if err != nil {
+		t.Fatalf("NewServer() returned an unexpected error: %v", err)
+	}
+	if err := server.EnablePersistence(context.Background()); err != nil {
+		t.Fatalf("EnablePersistence() returned an unexpected error: %v", err)
+	}
+
+	t.Run("restores an inspection with its result", func(t *testing.T) {
+		runner := server.GetInspection("inspection-1")
+		if runner == nil {
+			t.Fatalf("inspection-1 was not restored")
+		}
+		if !runner.Started() {
+			t.Errorf("Started() = false, want true")
+		}
+		<-runner.Wait()
+		result, err := runner.Result()
+		if err != nil {
+			t.Fatalf("Result() returned an unexpected error: %v", err)
+		}
+		reader, err := result.ResultStore.GetReader()
+		if err != nil {
+			t.Fatalf("GetReader() returned an unexpected error: %v", err)
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("failed to read the result: %v", err)
+		}
+		if string(data) != "KHI-data" {
+			t.Errorf("result data = %q, want %q", string(data), "KHI-data")
+		}
+		metadata, err := runner.GetCurrentMetadata()
+		if err != nil {
+			t.Fatalf("GetCurrentMetadata() returned an unexpected error: %v", err)
+		}
+		header, found := typedmap.Get(metadata, inspectionmetadata.HeaderMetadataKey)
+		if !found || header.SuggestedFileName != "foo.khi" {
+			t.Errorf("header metadata = %v, want the persisted header", header)
+		}
+		query, found := typedmap.Get(metadata, inspectionmetadata.QueryMetadataKey)
+		if !found {
+			t.Fatalf("query metadata was not found")
+		}
+		if diff := cmp.Diff(persisted[0].Queries, query.ToSerializable()); diff != "" {
+			t.Errorf("query metadata mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("restores an inspection finished with an error", func(t *testing.T) {
+		runner := server.GetInspection("inspection-2")
+		if runner == nil {
+			t.Fatalf("inspection-2 was not restored")
+		}
+		if _, err := runner.Result(); err == nil {
+			t.Errorf("Result() returned no error, want an error")
+		}
+		metadata, err := runner.Metadata()
+		if err != nil {
+			t.Fatalf("Metadata() returned an unexpected error: %v", err)
+		}
+		if _, found := metadata["header"]; !found {
+			t.Errorf("Metadata() doesn't contain the header")
+		}
+	})
+
+	t.Run("drops an inspection with missing result file", func(t *testing.T) {
+		if server.GetInspection("inspection-3") != nil {
+			t.Errorf("inspection-3 was restored without its result file")
+		}
+		entries, err := index.List()
+		if err != nil {
+			t.Fatalf("List() returned an unexpected error: %v", err)
+		}
+		if len(entries) != 2 {
+			t.Errorf("List() returned %d entries, want 2", len(entries))
+		}
+	})
+
+	t.Run("generates an inspection ID not used by the restored inspections", func(t *testing.T) {
+		if err := server.AddInspectionType(InspectionType{Id: "foo", Name: "foo"}); err != nil {
+			t.Fatalf("AddInspectionType() returned an unexpected error: %v", err)
+		}
+		id, err := server.CreateInspection("foo")
+		if err != nil {
+			t.Fatalf("CreateInspection() returned an unexpected error: %v", err)
+		}
+		if id != "inspection-3" {
+			t.Errorf("CreateInspection() = %s, want inspection-3", id)
+		}
+	})
+}
@@ -50,6 +50,7 @@ func (i *IndeterminateUpdator) Start(msg string) error {
 	}
 	cancellable, cancel := context.WithCancel(context.Background())
 	i.Progress.Message = msg
+	i.Progress.NotifyChange()
 	i.context = cancellable
 	i.cancel = cancel
 	go func() {
@@ -57,9 +58,11 @@ func (i *IndeterminateUpdator) Start(msg string) error {
 			select {
 			case <-i.context.Done():
 				i.Progress.Indeterminate = false
+				i.Progress.NotifyChange()
 				return
 			case <-time.After(i.Interval):
 				i.Progress.Message = fmt.Sprintf("%s%s", msg, i.workingIndicator(itr))
+				i.Progress.NotifyChange()
 				itr++
 			}
 		}
@@ -49,7 +49,7 @@ func NewProgressUpdator(progress *inspectionmetadata.TaskProgressMetadata, inter
 // and then continues to call it at the specified interval until Done is called.
 // It returns an error if the updator has already been started.
 func (p *ProgressUpdator) Start(ctx context.Context) error {
-	p.OnTick(p.Progress)
+	p.tick()
 	cancellable, cancel := context.WithCancel(ctx)
 	p.cancel = cancel
 	p.context = cancellable
@@ -59,7 +59,7 @@ func (p *ProgressUpdator) Start(ctx context.Context) error {
 			case <-p.context.Done():
 				return
 			case <-time.After(p.Interval):
-				p.OnTick(p.Progress)
+				p.tick()
 				itr++
 			}
 		}
@@ -76,3 +76,9 @@ func (p *ProgressUpdator) Done() error {
 	p.cancel()
 	return nil
 }
+
+// tick calls OnTick and notifies the change because OnTick usually modifies the fields of the progress directly.
+func (p *ProgressUpdator) tick() {
+	p.OnTick(p.Progress)
+	p.Progress.NotifyChange()
+}
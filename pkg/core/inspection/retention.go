@@ -0,0 +1,284 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coreinspection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
+)
+
+// checkpointFolderPrefix is the prefix of the folders in the temporary folder storing the checkpoints of inspections, followed by the inspection ID.
+const checkpointFolderPrefix = "khi-checkpoint-"
+
+// orphanedTemporaryFileMinAge is the minimum age of a temporary file to be regarded as orphaned when no inspection is running.
+const orphanedTemporaryFileMinAge = time.Hour
+
+// RetentionPolicy is the set of limits applied to the inspection results and the uploaded files.
+// A zero value of each field means no limit.
+type RetentionPolicy struct {
+	// MaxAge is the maximum age of the inspections and the uploaded files.
+	MaxAge time.Duration
+	// MaxInspectionCount is the maximum count of the finished inspections kept in the server.
+	MaxInspectionCount int
+	// MaxTotalSizeInBytes is the maximum total size of the inspection results and the uploaded files.
+	MaxTotalSizeInBytes int64
+}
+
+// retainedItem is an inspection or an uploaded file subject to the RetentionPolicy.
+type retainedItem struct {
+	name         string
+	time         time.Time
+	size         int64
+	isInspection bool
+	delete       func(ctx context.Context) error
+}
+
+// Janitor deletes inspections and files exceeding the RetentionPolicy, and orphaned temporary files left by crashed or cancelled inspections.
+// The temporary folder in the IOConfig of the server must be used only by this process, because every file in it is subject to the cleanup.
+type Janitor struct {
+	server            *InspectionTaskServer
+	policy            RetentionPolicy
+	uploadStoreFolder string
+	now               func() time.Time
+}
+
+// NewJanitor returns a Janitor enforcing the given policy on the inspections of the server and the files in the upload store folder.
+// The upload store folder is ignored when it's empty.
+func NewJanitor(server *InspectionTaskServer, policy RetentionPolicy, uploadStoreFolder string) *Janitor {
+	return &Janitor{
+		server:            server,
+		policy:            policy,
+		uploadStoreFolder: uploadStoreFolder,
+		now:               time.Now,
+	}
+}
+
+// Start runs Cleanup periodically with the given interval until the context is cancelled.
+func (j *Janitor) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := j.Cleanup(ctx); err != nil {
+				slog.ErrorContext(ctx, "failed to enforce the retention policy", "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Cleanup deletes the inspections and the uploaded files exceeding the retention policy and the orphaned temporary files.
+// Items are deleted from the oldest one. Running inspections are never deleted.
+func (j *Janitor) Cleanup(ctx context.Context) error {
+	running := false
+	for _, runner := range j.server.GetAllRunners() {
+		if runner.Started() && !runner.Finished() {
+			running = true
+			break
+		}
+	}
+
+	items, err := j.listInspections()
+	if err != nil {
+		return err
+	}
+	// Uploaded files can be in use by a running inspection but there is no way to know which inspection uses which file.
+	if !running {
+		uploadedFiles, err := j.listUploadedFiles()
+		if err != nil {
+			return err
+		}
+		items = append(items, uploadedFiles...)
+	}
+
+	var errs []error
+	for _, item := range j.itemsToDelete(items) {
+		if err := item.delete(ctx); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		slog.InfoContext(ctx, fmt.Sprintf("%s was deleted by the retention policy", item.name))
+	}
+	if err := j.deleteOrphanedTemporaryFiles(ctx, running); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// itemsToDelete returns the items exceeding the retention policy.
+func (j *Janitor) itemsToDelete(items []*retainedItem) []*retainedItem {
+	// Sort items from the newest to keep newer items within the limits.
+	slices.SortStableFunc(items, func(a, b *retainedItem) int {
+		return b.time.Compare(a.time)
+	})
+	result := []*retainedItem{}
+	now := j.now()
+	var totalSize int64
+	inspectionCount := 0
+	for _, item := range items {
+		totalSize += item.size
+		if item.isInspection {
+			inspectionCount++
+		}
+		if j.policy.MaxAge > 0 && now.Sub(item.time) > j.policy.MaxAge ||
+			j.policy.MaxInspectionCount > 0 && item.isInspection && inspectionCount > j.policy.MaxInspectionCount ||
+			j.policy.MaxTotalSizeInBytes > 0 && totalSize > j.policy.MaxTotalSizeInBytes {
+			result = append(result, item)
+			// Deleted items are not counted for the limits.
+			totalSize -= item.size
+			if item.isInspection {
+				inspectionCount--
+			}
+		}
+	}
+	return result
+}
+
+// listInspections returns the finished or not started inspections in the server.
+func (j *Janitor) listInspections() ([]*retainedItem, error) {
+	result := []*retainedItem{}
+	for _, runner := range j.server.GetAllRunners() {
+		if runner.Started() && !runner.Finished() {
+			continue
+		}
+		var size int64
+		if resultFilePath := j.server.resultFilePath(runner); resultFilePath != "" {
+			stat, err := os.Stat(resultFilePath)
+			if err == nil {
+				size = stat.Size()
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("failed to get the size of %s: %w", resultFilePath, err)
+			}
+		}
+		id := runner.ID
+		result = append(result, &retainedItem{
+			name:         fmt.Sprintf("inspection %s", id),
+			time:         runner.CreationTime(),
+			size:         size,
+			isInspection: true,
+			delete: func(ctx context.Context) error {
+				return j.server.DeleteInspection(ctx, id)
+			},
+		})
+	}
+	return result, nil
+}
+
+// listUploadedFiles returns the files written by the upload store in the upload store folder.
+func (j *Janitor) listUploadedFiles() ([]*retainedItem, error) {
+	result := []*retainedItem{}
+	if j.uploadStoreFolder == "" {
+		return result, nil
+	}
+	entries, err := os.ReadDir(j.uploadStoreFolder)
+	if errors.Is(err, os.ErrNotExist) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the upload store folder %s: %w", j.uploadStoreFolder, err)
+	}
+	for _, entry := range entries {
+		// The upload store folder can be shared with other files. Only the files written by the upload store are deleted.
+		if !entry.Type().IsRegular() || !strings.HasPrefix(entry.Name(), upload.LocalUploadFileNamePrefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // the file can be removed after listing.
+		}
+		path := filepath.Join(j.uploadStoreFolder, entry.Name())
+		result = append(result, &retainedItem{
+			name: fmt.Sprintf("uploaded file %s", path),
+			time: info.ModTime(),
+			size: info.Size(),
+			delete: func(ctx context.Context) error {
+				if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("failed to delete the uploaded file %s: %w", path, err)
+				}
+				return nil
+			},
+		})
+	}
+	return result, nil
+}
+
+// deleteOrphanedTemporaryFiles deletes the temporary files left in the temporary folder of this process.
+// Files are regarded as orphaned only when no inspection is running, because there is no way to know which inspection uses which file.
+func (j *Janitor) deleteOrphanedTemporaryFiles(ctx context.Context, running bool) error {
+	temporaryFolder := j.server.ioConfig.TemporaryFolder
+	if temporaryFolder == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(temporaryFolder)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list the temporary folder %s: %w", temporaryFolder, err)
+	}
+	now := j.now()
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), checkpointFolderPrefix) {
+			if err := j.deleteOrphanedCheckpointFolder(ctx, filepath.Join(temporaryFolder, entry.Name())); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if running || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if now.Sub(info.ModTime()) <= orphanedTemporaryFileMinAge {
+			continue
+		}
+		path := filepath.Join(temporaryFolder, entry.Name())
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("failed to delete the orphaned temporary file %s: %w", path, err))
+			continue
+		}
+		slog.DebugContext(ctx, fmt.Sprintf("orphaned temporary file %s was deleted", path))
+	}
+	return errors.Join(errs...)
+}
+
+// deleteOrphanedCheckpointFolder deletes the checkpoint folder when its inspection is not known by the server, e.g. the folder left by the previous server process.
+func (j *Janitor) deleteOrphanedCheckpointFolder(ctx context.Context, path string) error {
+	inspectionID := strings.TrimPrefix(filepath.Base(path), checkpointFolderPrefix)
+	if j.server.GetInspection(inspectionID) != nil {
+		return nil
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to delete the orphaned checkpoint folder %s: %w", path, err)
+	}
+	slog.DebugContext(ctx, fmt.Sprintf("orphaned checkpoint folder %s was deleted", path))
+	return nil
+}
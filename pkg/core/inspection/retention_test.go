@@ -0,0 +1,234 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coreinspection
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	inspectioncore_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/contract"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJanitor_Cleanup(t *testing.T) {
+	now := time.Date(2025, time.January, 10, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name              string
+		policy            RetentionPolicy
+		wantInspections   []string
+		wantUploadedFiles []string
+	}{
+		{
+			name:              "no limit",
+			policy:            RetentionPolicy{},
+			wantInspections:   []string{"inspection-1", "inspection-2", "inspection-3"},
+			wantUploadedFiles: []string{"foreign.log", "khi-upload-1", "khi-upload-2"},
+		},
+		{
+			name:              "max age",
+			policy:            RetentionPolicy{MaxAge: 30 * time.Hour},
+			wantInspections:   []string{"inspection-2", "inspection-3"},
+			wantUploadedFiles: []string{"foreign.log", "khi-upload-2"},
+		},
+		{
+			name:              "max inspection count",
+			policy:            RetentionPolicy{MaxInspectionCount: 1},
+			wantInspections:   []string{"inspection-3"},
+			wantUploadedFiles: []string{"foreign.log", "khi-upload-1", "khi-upload-2"},
+		},
+		{
+			name:              "max total size",
+			policy:            RetentionPolicy{MaxTotalSizeInBytes: 250},
+			wantInspections:   []string{"inspection-3"},
+			wantUploadedFiles: []string{"foreign.log", "khi-upload-2"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			uploadFolder := t.TempDir()
+			server, err := NewServer(&inspectioncore_contract.IOConfig{
+				DataDestination: t.TempDir(),
+				TemporaryFolder: t.TempDir(),
+			})
+			if err != nil {
+				t.Fatalf("NewServer() returned an unexpected error: %v", err)
+			}
+			// Items ordered from the newest: inspection-3(0h), upload-2(12h), inspection-2(24h), upload-1(36h), inspection-1(48h). Each of them is 100 bytes.
+			for i, id := range []string{"inspection-1", "inspection-2", "inspection-3"} {
+				addFinishedInspection(t, server, &PersistedInspection{ID: id, CreationTime: now.Add(-time.Duration(48-i*24) * time.Hour)}, 100)
+			}
+			for i, name := range []string{"khi-upload-1", "khi-upload-2"} {
+				writeFileWithModTime(t, filepath.Join(uploadFolder, name), 100, now.Add(-time.Duration(36-i*24)*time.Hour))
+			}
+			// A file not written by the upload store must survive regardless of its age and size.
+			writeFileWithModTime(t, filepath.Join(uploadFolder, "foreign.log"), 1000, now.Add(-72*time.Hour))
+
+			janitor := NewJanitor(server, tc.policy, uploadFolder)
+			janitor.now = func() time.Time { return now }
+			if err := janitor.Cleanup(context.Background()); err != nil {
+				t.Fatalf("Cleanup() returned an unexpected error: %v", err)
+			}
+
+			gotInspections := []string{}
+			for _, runner := range server.GetAllRunners() {
+				gotInspections = append(gotInspections, runner.ID)
+			}
+			slices.Sort(gotInspections)
+			if diff := cmp.Diff(tc.wantInspections, gotInspections); diff != "" {
+				t.Errorf("remaining inspections mismatch (-want +got):\n%s", diff)
+			}
+			gotUploadedFiles := []string{}
+			entries, err := os.ReadDir(uploadFolder)
+			if err != nil {
+				t.Fatalf("failed to read the upload folder: %v", err)
+			}
+			for _, entry := range entries {
+				gotUploadedFiles = append(gotUploadedFiles, entry.Name())
+			}
+			if diff := cmp.Diff(tc.wantUploadedFiles, gotUploadedFiles); diff != "" {
+				t.Errorf("remaining uploaded files mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestJanitor_CleanupOrphanedTemporaryFiles(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name      string
+		running   bool
+		wantFiles []string
+	}{
+		{
+			name:      "no inspection is running",
+			wantFiles: []string{"khi-recent"},
+		},
+		{
+			name:      "an inspection is running",
+			running:   true,
+			wantFiles: []string{"foo", "khi-old", "khi-recent"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// The temporary folder of this process is placed in the folder shared with other processes.
+			sharedFolder := t.TempDir()
+			temporaryFolder := filepath.Join(sharedFolder, "khi-run-1")
+			if err := os.Mkdir(temporaryFolder, 0755); err != nil {
+				t.Fatalf("failed to create the temporary folder: %v", err)
+			}
+			server, err := NewServer(&inspectioncore_contract.IOConfig{
+				DataDestination: t.TempDir(),
+				TemporaryFolder: temporaryFolder,
+			})
+			if err != nil {
+				t.Fatalf("NewServer() returned an unexpected error: %v", err)
+			}
+			if tc.running {
+				running := NewInspectionRunner(server, server.ioConfig, "inspection-1")
+				running.runner = newRestoredTaskRunner(server.ioConfig, &PersistedInspection{ID: "inspection-1"})
+				server.inspections["inspection-1"] = running
+			}
+			janitor := NewJanitor(server, RetentionPolicy{}, "")
+			janitor.now = func() time.Time { return now }
+
+			files := map[string]time.Time{
+				filepath.Join(temporaryFolder, "foo"):          now.Add(-5 * time.Hour),
+				filepath.Join(temporaryFolder, "khi-old"):      now.Add(-2 * time.Hour),
+				filepath.Join(temporaryFolder, "khi-recent"):   now,
+				filepath.Join(sharedFolder, "khi-other-owner"): now.Add(-5 * time.Hour),
+			}
+			for path, modTime := range files {
+				if err := os.WriteFile(path, []byte("foo"), 0644); err != nil {
+					t.Fatalf("failed to write the test temporary file: %v", err)
+				}
+				if err := os.Chtimes(path, modTime, modTime); err != nil {
+					t.Fatalf("failed to change the time of the test temporary file: %v", err)
+				}
+			}
+
+			if err := janitor.Cleanup(context.Background()); err != nil {
+				t.Fatalf("Cleanup() returned an unexpected error: %v", err)
+			}
+
+			gotFiles := []string{}
+			entries, err := os.ReadDir(temporaryFolder)
+			if err != nil {
+				t.Fatalf("failed to read the temporary folder: %v", err)
+			}
+			for _, entry := range entries {
+				gotFiles = append(gotFiles, entry.Name())
+			}
+			if diff := cmp.Diff(tc.wantFiles, gotFiles); diff != "" {
+				t.Errorf("remaining temporary files mismatch (-want +got):\n%s", diff)
+			}
+			if _, err := os.Stat(filepath.Join(sharedFolder, "khi-other-owner")); err != nil {
+				t.Errorf("temporary file out of the temporary folder of this process was deleted: %v", err)
+			}
+		})
+	}
+}
+
+func TestJanitor_CleanupOrphanedCheckpointFolders(t *testing.T) {
+	temporaryFolder := t.TempDir()
+	server, err := NewServer(&inspectioncore_contract.IOConfig{
+		DataDestination: t.TempDir(),
+		TemporaryFolder: temporaryFolder,
+	})
+	if err != nil {
+		t.Fatalf("NewServer() returned an unexpected error: %v", err)
+	}
+	server.inspections["inspection-1"] = NewInspectionRunner(server, server.ioConfig, "inspection-1")
+	for _, id := range []string{"inspection-1", "inspection-2"} {
+		folder := filepath.Join(temporaryFolder, checkpointFolderPrefix+id)
+		if err := os.MkdirAll(folder, 0755); err != nil {
+			t.Fatalf("failed to create the test checkpoint folder: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(folder, "checkpoint"), []byte("foo"), 0644); err != nil {
+			t.Fatalf("failed to write the test checkpoint: %v", err)
+		}
+	}
+
+	janitor := NewJanitor(server, RetentionPolicy{}, "")
+	if err := janitor.Cleanup(context.Background()); err != nil {
+		t.Fatalf("Cleanup() returned an unexpected error: %v", err)
+	}
+
+	gotFiles := []string{}
+	entries, err := os.ReadDir(temporaryFolder)
+	if err != nil {
+		t.Fatalf("failed to read the temporary folder: %v", err)
+	}
+	for _, entry := range entries {
+		gotFiles = append(gotFiles, entry.Name())
+	}
+	if diff := cmp.Diff([]string{"khi-checkpoint-inspection-1"}, gotFiles); diff != "" {
+		t.Errorf("remaining checkpoint folders mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func writeFileWithModTime(t *testing.T, path string, size int, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatalf("failed to write the test file: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("failed to change the time of the test file: %v", err)
+	}
+}
@@ -15,12 +15,13 @@
 package coreinspection
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"slices"
 	"sync"
 	"time"
@@ -61,6 +62,14 @@ type InspectionTaskRunner struct {
 	ioconfig               *inspectioncore_contract.IOConfig
 	runContextOptions      []RunContextOption
 	inspectionCreationTime time.Time
+	// finished is true after the inspection run was ended and its result was persisted.
+	finished bool
+	// resumable is true when the last run was ended with an error or a cancellation. Such inspection can be run again with Resume.
+	resumable bool
+	// lastRequest is the request given to the last run. Resume runs the inspection again with this request.
+	lastRequest *inspectioncore_contract.InspectionRequest
+	// changeNotifier is notified when the inspection is started, finished or any observable metadata of the run is changed.
+	changeNotifier inspectionmetadata.ChangeNotifier
 }
 
 // NewInspectionRunner creates a new InspectionTaskRunner.
@@ -83,6 +92,9 @@ func NewInspectionRunner(server *InspectionTaskServer, ioConfig *inspectioncore_
 		runContextOptions:      options,
 	}
 	runner.addDefaultRunContextOptions()
+	if server != nil {
+		runner.changeNotifier.Forward(&server.changeNotifier)
+	}
 	return runner
 }
 
@@ -98,7 +110,10 @@ func (i *InspectionTaskRunner) addDefaultRunContextOptions() {
 		RunContextOptionFromValue(inspectioncore_contract.GlobalSharedMap, inspectionRunnerGlobalSharedMap),
 		RunContextOptionFromValue(inspectioncore_contract.CurrentIOConfig, i.ioconfig),
 		RunContextOptionFromFunc(inspectioncore_contract.CurrentHistoryBuilder, func(ctx context.Context, mode inspectioncore_contract.InspectionTaskModeType) (*history.Builder, error) {
-			return history.NewBuilder(i.ioconfig.TemporaryFolder), nil
+			return history.NewBuilderWithCodec(i.ioconfig.TemporaryFolder, i.ioconfig.CompressionCodec)
+		}),
+		RunContextOptionFromFunc(inspectioncore_contract.InspectionCheckpointFolder, func(ctx context.Context, mode inspectioncore_contract.InspectionTaskModeType) (string, error) {
+			return i.checkpointFolder(), nil
 		}),
 	}
 
@@ -107,9 +122,42 @@ func (i *InspectionTaskRunner) addDefaultRunContextOptions() {
 
 // Started returns true if the inspection has been started.
 func (i *InspectionTaskRunner) Started() bool {
+	i.runnerLock.Lock()
+	defer i.runnerLock.Unlock()
 	return i.runner != nil
 }
 
+// Finished returns true if the inspection has been started and its run was ended.
+func (i *InspectionTaskRunner) Finished() bool {
+	i.runnerLock.Lock()
+	defer i.runnerLock.Unlock()
+	return i.finished
+}
+
+// Notifier returns the ChangeNotifier notified when the inspection is started, finished or its metadata is changed.
+func (i *InspectionTaskRunner) Notifier() *inspectionmetadata.ChangeNotifier {
+	return &i.changeNotifier
+}
+
+// forwardMetadataChanges makes the changes of the observable metadata of the current run to notify the subscribers of this runner.
+func (i *InspectionTaskRunner) forwardMetadataChanges() {
+	for _, key := range i.metadata.Keys() {
+		metadata, found := typedmap.Get(i.metadata, typedmap.NewTypedKey[inspectionmetadata.Metadata](key))
+		if !found {
+			continue
+		}
+		if observable, ok := metadata.(inspectionmetadata.ObservableMetadata); ok {
+			observable.Notifier().Forward(&i.changeNotifier)
+		}
+	}
+	i.changeNotifier.NotifyChange()
+}
+
+// CreationTime returns the time when the inspection was created.
+func (i *InspectionTaskRunner) CreationTime() time.Time {
+	return i.inspectionCreationTime
+}
+
 // SetInspectionType sets the type of inspection and initializes the available tasks.
 // It filters the root task set from the server to get tasks relevant to the specified inspectionType.
 func (i *InspectionTaskRunner) SetInspectionType(inspectionType string) error {
@@ -232,6 +280,27 @@ func (i *InspectionTaskRunner) Run(ctx context.Context, req *inspectioncore_cont
 	if i.runner != nil {
 		return fmt.Errorf("this task is already started")
 	}
+	return i.run(ctx, req)
+}
+
+// Resume runs the inspection finished with an error or a cancellation again with the same request.
+// Tasks can skip the work done in the previous run with the checkpoints stored in the folder given with inspectioncore_contract.InspectionCheckpointFolder.
+func (i *InspectionTaskRunner) Resume(ctx context.Context) error {
+	defer i.runnerLock.Unlock()
+	i.runnerLock.Lock()
+	if !i.finished || !i.resumable || i.lastRequest == nil {
+		return fmt.Errorf("inspection %s: %w", i.ID, ErrInspectionNotResumable)
+	}
+	i.runner = nil
+	i.cancel = nil
+	i.finished = false
+	i.resumable = false
+	return i.run(ctx, i.lastRequest)
+}
+
+// run starts a run of the inspection. The caller must hold runnerLock.
+func (i *InspectionTaskRunner) run(ctx context.Context, req *inspectioncore_contract.InspectionRequest) error {
+	i.lastRequest = req
 	currentInspectionType := i.inspectionServer.GetInspectionType(i.currentInspectionType)
 	runnableTaskGraph, err := i.resolveTaskGraph()
 	if err != nil {
@@ -263,11 +332,14 @@ func (i *InspectionTaskRunner) Run(ctx context.Context, req *inspectioncore_cont
 	i.runner = runner
 
 	i.metadata = runMetadata
+	i.forwardMetadataChanges()
 	lifecycle.Default.NotifyInspectionStart(khictx.MustGetValue(runCtx, inspectioncore_contract.InspectionTaskRunID), currentInspectionType.Name)
 
 	err = i.runner.Run(cancelableCtx)
 	if err != nil {
 		i.cleanupAfterAnyRun(runCtx, runnableTaskGraph)
+		i.finished = true
+		i.resumable = true
 		return err
 	}
 	go func() {
@@ -279,7 +351,10 @@ func (i *InspectionTaskRunner) Run(ctx context.Context, req *inspectioncore_cont
 		}
 		status := ""
 		resultSize := 0
+		resumable := false
+		var resultStore inspectioncore_contract.Store
 		if result, err := i.runner.Result(); err != nil {
+			resumable = true
 			if errors.Is(cancelableCtx.Err(), context.Canceled) {
 				progress.MarkCancelled()
 				status = "cancel"
@@ -291,6 +366,10 @@ func (i *InspectionTaskRunner) Run(ctx context.Context, req *inspectioncore_cont
 		} else {
 			progress.MarkDone()
 			status = "done"
+			// Checkpoints are no longer needed because a successful inspection can't be resumed.
+			if err := i.removeCheckpoints(); err != nil {
+				slog.WarnContext(runCtx, "failed to remove the checkpoints", "error", err)
+			}
 
 			history, found := typedmap.Get(result, typedmap.NewTypedKey[inspectioncore_contract.Store](inspectioncore_contract.SerializerTaskID.ReferenceIDString()))
 			if !found {
@@ -299,12 +378,19 @@ func (i *InspectionTaskRunner) Run(ctx context.Context, req *inspectioncore_cont
 			if history == nil {
 				slog.ErrorContext(runCtx, "Failed to get the serializer result. Result is nil!")
 			} else {
+				resultStore = history
 				resultSize, err = history.GetInspectionResultSizeInBytes()
 				if err != nil {
 					slog.ErrorContext(runCtx, fmt.Sprintf("Failed to get the serialized result size\n%s", err))
 				}
 			}
 		}
+		i.inspectionServer.persistInspection(runCtx, i, resultStore)
+		i.runnerLock.Lock()
+		i.finished = true
+		i.resumable = resumable
+		i.runnerLock.Unlock()
+		i.changeNotifier.NotifyChange()
 		lifecycle.Default.NotifyInspectionEnd(khictx.MustGetValue(runCtx, inspectioncore_contract.InspectionTaskRunID), currentInspectionType.Name, status, resultSize)
 	}()
 	return nil
@@ -398,12 +484,12 @@ func (i *InspectionTaskRunner) makeLoggers(ctx context.Context, minLevel slog.Le
 		inspectionID := i.ID
 		runID := khictx.MustGetValue(ctx, inspectioncore_contract.InspectionTaskRunID)
 		taskID := def.UntypedID()
-		logger.RegisterTaskLogger(inspectionID, taskID, runID, i.makeLogger(minLevel, logMetadata.GetTaskLogBuffer(taskID)))
+		logger.RegisterTaskLogger(inspectionID, taskID, runID, i.makeLogger(minLevel, logMetadata.GetTaskLogWriter(taskID)))
 	}
 	return logMetadata
 }
 
-func (i *InspectionTaskRunner) makeLogger(minLevel slog.Level, logBuffer *bytes.Buffer) slog.Handler {
+func (i *InspectionTaskRunner) makeLogger(minLevel slog.Level, logWriter io.Writer) slog.Handler {
 	stdoutWithColor := true
 	if parameters.Debug.NoColor != nil && *parameters.Debug.NoColor {
 		stdoutWithColor = false
@@ -412,7 +498,7 @@ func (i *InspectionTaskRunner) makeLogger(minLevel slog.Level, logBuffer *bytes.
 
 	return logger.NewTeeHandler(
 		logger.NewThrottleFilter(logThrottleCount, logger.NewSeverityFilter(minLevel, logger.NewKHIFormatLogger(os.Stdout, stdoutWithColor))),
-		logger.NewThrottleFilter(logThrottleCount, logger.NewSeverityFilter(minLevel, logger.NewKHIFormatLogger(logBuffer, false))),
+		logger.NewThrottleFilter(logThrottleCount, logger.NewSeverityFilter(minLevel, logger.NewKHIFormatLogger(logWriter, false))),
 	)
 }
 
@@ -441,6 +527,26 @@ func (i *InspectionTaskRunner) Wait() <-chan interface{} {
 	return i.runner.Wait()
 }
 
+// checkpointFolder returns the folder storing the checkpoints of this inspection. It returns an empty string when no temporary folder is configured.
+func (i *InspectionTaskRunner) checkpointFolder() string {
+	if i.ioconfig == nil || i.ioconfig.TemporaryFolder == "" {
+		return ""
+	}
+	return filepath.Join(i.ioconfig.TemporaryFolder, checkpointFolderPrefix+i.ID)
+}
+
+// removeCheckpoints removes the checkpoint folder of this inspection.
+func (i *InspectionTaskRunner) removeCheckpoints() error {
+	folder := i.checkpointFolder()
+	if folder == "" {
+		return nil
+	}
+	if err := os.RemoveAll(folder); err != nil {
+		return fmt.Errorf("failed to remove the checkpoints of inspection %s: %w", i.ID, err)
+	}
+	return nil
+}
+
 func (i *InspectionTaskRunner) resolveTaskGraph() (*coretask.TaskSet, error) {
 	if i.featureTasks == nil || i.availableTasks == nil {
 		return nil, fmt.Errorf("this runner is not ready for resolving graph")
@@ -0,0 +1,55 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coreinspection
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	inspectioncore_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/contract"
+)
+
+func TestInspectionTaskRunner_StartedDuringResume(t *testing.T) {
+	server, err := NewServer(&inspectioncore_contract.IOConfig{
+		DataDestination: t.TempDir(),
+		TemporaryFolder: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("NewServer() returned an unexpected error: %v", err)
+	}
+	runner := NewInspectionRunner(server, server.ioConfig, "inspection-1")
+	runner.runner = newRestoredTaskRunner(server.ioConfig, &PersistedInspection{ID: "inspection-1"})
+	runner.finished = true
+	runner.resumable = true
+	runner.lastRequest = &inspectioncore_contract.InspectionRequest{}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			runner.Started()
+		}
+	}()
+	// The runner has no inspection type, so the resumed run fails after clearing the previous run.
+	if err := runner.Resume(context.Background()); err == nil {
+		t.Errorf("Resume() returned nil error, want an error")
+	}
+	wg.Wait()
+	if runner.Started() {
+		t.Errorf("Started() = true after the failed resume, want false")
+	}
+}
@@ -15,16 +15,32 @@
 package coreinspection
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common/idgenerator"
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
 	coretask "github.com/GoogleCloudPlatform/khi/pkg/core/task"
 	inspectioncore_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/contract"
 	inspectioncore_impl "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/impl"
 	"golang.org/x/exp/slices"
 )
 
+// ErrInspectionNotFound is returned when the given inspection ID is not known by the InspectionTaskServer.
+var ErrInspectionNotFound = errors.New("inspection was not found")
+
+// ErrInspectionRunning is returned when an operation can't be done because the inspection is still running.
+var ErrInspectionRunning = errors.New("inspection is running")
+
+// ErrInspectionNotResumable is returned when resuming an inspection not finished with an error or a cancellation in this server process.
+var ErrInspectionNotResumable = errors.New("inspection is not resumable")
+
 type InspectionRegistrationFunc = func(registry InspectionTaskRegistry) error
 
 type InspectionType struct {
@@ -64,11 +80,18 @@ type InspectionTaskServer struct {
 	inspectionTypes []*InspectionType
 	// inspections are generated inspection task runers
 	inspections           map[string]*InspectionTaskRunner
+	inspectionsLock       sync.RWMutex
 	inspectionIDGenerator idgenerator.IDGenerator
 
 	ioConfig *inspectioncore_contract.IOConfig
 
 	runContextOptions []RunContextOption
+
+	// index persists finished inspections. Inspections are not persisted when this is nil.
+	index *InspectionIndex
+
+	// changeNotifier is notified when an inspection is added, deleted or changed.
+	changeNotifier inspectionmetadata.ChangeNotifier
 }
 
 func NewServer(ioConfig *inspectioncore_contract.IOConfig) (*InspectionTaskServer, error) {
@@ -118,7 +141,13 @@ func (s *InspectionTaskServer) AddTask(task coretask.UntypedTask) error {
 
 // CreateInspection generates an inspection and returns inspection ID
 func (s *InspectionTaskServer) CreateInspection(inspectionType string) (string, error) {
+	s.inspectionsLock.Lock()
+	defer s.inspectionsLock.Unlock()
 	id := s.inspectionIDGenerator.Generate()
+	// The ID generator restarts from the beginning after restarting the server. Skip IDs used by the restored inspections.
+	for s.inspections[id] != nil {
+		id = s.inspectionIDGenerator.Generate()
+	}
 	inspectionTask := NewInspectionRunner(s, s.ioConfig, id, s.runContextOptions...)
 	err := inspectionTask.SetInspectionType(inspectionType)
 	if err != nil {
@@ -130,6 +159,8 @@ func (s *InspectionTaskServer) CreateInspection(inspectionType string) (string,
 
 // Inspection returns an instance of an Inspection queried with given inspection ID.
 func (s *InspectionTaskServer) GetInspection(inspectionID string) *InspectionTaskRunner {
+	s.inspectionsLock.RLock()
+	defer s.inspectionsLock.RUnlock()
 	return s.inspections[inspectionID]
 }
 
@@ -147,6 +178,8 @@ func (s *InspectionTaskServer) GetInspectionType(inspectionTypeId string) *Inspe
 }
 
 func (s *InspectionTaskServer) GetAllRunners() []*InspectionTaskRunner {
+	s.inspectionsLock.RLock()
+	defer s.inspectionsLock.RUnlock()
 	inspections := []*InspectionTaskRunner{}
 	for _, value := range s.inspections {
 		inspections = append(inspections, value)
@@ -164,4 +197,104 @@ func (s *InspectionTaskServer) AddRunContextOption(option RunContextOption) {
 	s.runContextOptions = append(s.runContextOptions, option)
 }
 
+// Notifier returns the ChangeNotifier notified when an inspection is added, deleted, started, finished or its metadata is changed.
+func (s *InspectionTaskServer) Notifier() *inspectionmetadata.ChangeNotifier {
+	return &s.changeNotifier
+}
+
+// EnablePersistence makes the server to record finished inspections in the index file in the data destination folder,
+// and restores the inspections recorded in the index by the previous server process.
+// This must be called after registering inspection types and tasks to restore the feature list of the inspections.
+func (s *InspectionTaskServer) EnablePersistence(ctx context.Context) error {
+	index := NewInspectionIndex(filepath.Join(s.ioConfig.DataDestination, InspectionIndexFileName))
+	entries, err := index.List()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.ResultFileName != "" {
+			if _, err := os.Stat(filepath.Join(s.ioConfig.DataDestination, entry.ResultFileName)); err != nil {
+				slog.WarnContext(ctx, fmt.Sprintf("removing inspection %s from the index because its result file is not available", entry.ID), "error", err)
+				if err := index.Remove(entry.ID); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		runner := newRestoredInspectionRunner(s, s.ioConfig, entry)
+		s.inspectionsLock.Lock()
+		s.inspections[entry.ID] = runner
+		s.inspectionsLock.Unlock()
+	}
+	s.index = index
+	s.changeNotifier.NotifyChange()
+	slog.InfoContext(ctx, fmt.Sprintf("restored %d inspections from the inspection index", len(s.GetAllRunners())))
+	return nil
+}
+
+// DeleteInspection removes the inspection from the server and deletes its result file from the data destination folder.
+// It returns ErrInspectionRunning for an inspection not finished yet. Cancel it and wait for its end before deleting it.
+func (s *InspectionTaskServer) DeleteInspection(ctx context.Context, inspectionID string) error {
+	s.inspectionsLock.Lock()
+	runner, found := s.inspections[inspectionID]
+	if !found {
+		s.inspectionsLock.Unlock()
+		return fmt.Errorf("inspection %s: %w", inspectionID, ErrInspectionNotFound)
+	}
+	if runner.Started() && !runner.Finished() {
+		s.inspectionsLock.Unlock()
+		return fmt.Errorf("inspection %s: %w", inspectionID, ErrInspectionRunning)
+	}
+	delete(s.inspections, inspectionID)
+	s.inspectionsLock.Unlock()
+	s.changeNotifier.NotifyChange()
+
+	if s.index != nil {
+		if err := s.index.Remove(inspectionID); err != nil {
+			return err
+		}
+	}
+	if resultFilePath := s.resultFilePath(runner); resultFilePath != "" {
+		if err := os.Remove(resultFilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to delete the result file of inspection %s: %w", inspectionID, err)
+		}
+	}
+	if err := runner.removeCheckpoints(); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, fmt.Sprintf("inspection %s was deleted", inspectionID))
+	return nil
+}
+
+// resultFilePath returns the path of the result file of the given inspection when it is stored in the data destination folder.
+// It returns an empty string when the inspection has no result file.
+func (s *InspectionTaskServer) resultFilePath(runner *InspectionTaskRunner) string {
+	if !runner.Started() {
+		return ""
+	}
+	result, err := runner.Result()
+	if err != nil {
+		return ""
+	}
+	fileStore, ok := result.ResultStore.(*inspectioncore_contract.FileSystemStore)
+	if !ok {
+		return ""
+	}
+	relPath, err := filepath.Rel(s.ioConfig.DataDestination, fileStore.FilePath())
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return ""
+	}
+	return fileStore.FilePath()
+}
+
+// persistInspection records the finished inspection in the index when persistence is enabled.
+func (s *InspectionTaskServer) persistInspection(ctx context.Context, runner *InspectionTaskRunner, store inspectioncore_contract.Store) {
+	if s.index == nil {
+		return
+	}
+	if err := s.index.Put(newPersistedInspection(runner, store)); err != nil {
+		slog.ErrorContext(ctx, fmt.Sprintf("failed to persist inspection %s", runner.ID), "error", err)
+	}
+}
+
 var _ InspectionTaskRegistry = (*InspectionTaskServer)(nil)
@@ -0,0 +1,112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coreinspection
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	inspectioncore_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/contract"
+)
+
+// addFinishedInspection adds a finished inspection with a result file of the given size to the server.
+func addFinishedInspection(t *testing.T, server *InspectionTaskServer, entry *PersistedInspection, resultSize int) {
+	t.Helper()
+	if entry.Progress == nil {
+		progress := inspectionmetadata.NewProgress()
+		progress.SetTotalTaskCount(1)
+		progress.MarkDone()
+		entry.Progress = progress
+	}
+	if entry.ResultFileName == "" {
+		entry.ResultFileName = entry.ID + ".khi"
+	}
+	if err := os.WriteFile(filepath.Join(server.ioConfig.DataDestination, entry.ResultFileName), make([]byte, resultSize), 0644); err != nil {
+		t.Fatalf("failed to write the test result file: %v", err)
+	}
+	server.inspections[entry.ID] = newRestoredInspectionRunner(server, server.ioConfig, entry)
+}
+
+func TestInspectionTaskServer_DeleteInspection(t *testing.T) {
+	dataDestination := t.TempDir()
+	server, err := NewServer(&inspectioncore_contract.IOConfig{
+		DataDestination: dataDestination,
+		TemporaryFolder: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("NewServer() returned an unexpected error: %v", err)
+	}
+	if err := server.EnablePersistence(context.Background()); err != nil {
+		t.Fatalf("EnablePersistence() returned an unexpected error: %v", err)
+	}
+	entry := &PersistedInspection{ID: "inspection-1"}
+	addFinishedInspection(t, server, entry, 10)
+	if err := server.index.Put(entry); err != nil {
+		t.Fatalf("Put() returned an unexpected error: %v", err)
+	}
+	running := NewInspectionRunner(server, server.ioConfig, "inspection-2")
+	running.runner = newRestoredTaskRunner(server.ioConfig, entry)
+	server.inspections["inspection-2"] = running
+
+	testCases := []struct {
+		name         string
+		inspectionID string
+		wantErr      error
+	}{
+		{
+			name:         "deletes a finished inspection",
+			inspectionID: "inspection-1",
+		},
+		{
+			name:         "returns ErrInspectionNotFound for deleted inspection",
+			inspectionID: "inspection-1",
+			wantErr:      ErrInspectionNotFound,
+		},
+		{
+			name:         "returns ErrInspectionRunning for running inspection",
+			inspectionID: "inspection-2",
+			wantErr:      ErrInspectionRunning,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := server.DeleteInspection(context.Background(), tc.inspectionID)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("DeleteInspection() returned %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+
+	if server.GetInspection("inspection-1") != nil {
+		t.Errorf("inspection-1 is still in the server")
+	}
+	if server.GetInspection("inspection-2") == nil {
+		t.Errorf("running inspection-2 was removed from the server")
+	}
+	if _, err := os.Stat(filepath.Join(dataDestination, "inspection-1.khi")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("result file of inspection-1 still exists: %v", err)
+	}
+	entries, err := server.index.List()
+	if err != nil {
+		t.Fatalf("List() returned an unexpected error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("List() returned %d entries, want 0", len(entries))
+	}
+}
@@ -15,11 +15,13 @@
 package binarychunk
 
 import (
+	"bytes"
 	"context"
 	"crypto/md5"
 	"encoding/binary"
 	"fmt"
 	"io"
+	"io/fs"
 	"sync"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common"
@@ -96,7 +98,18 @@ func (b *Builder) Read(ref *BinaryReference) ([]byte, error) {
 	return bw.Read(ref)
 }
 
-// Build amends all the binary buffers to the given writer in KHI format. Returns the written byte size.
+// ChunkCodecs returns the compression codec of each binary chunk written by Build.
+func (b *Builder) ChunkCodecs() []Codec {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	codecs := make([]Codec, len(b.bufferWriters))
+	for i := range codecs {
+		codecs[i] = b.compressor.Codec()
+	}
+	return codecs
+}
+
+// Build amends all the binary buffers to the given writer in KHI format. Each chunk is prefixed with its compressed size in uint64 big endian. Returns the written byte size.
 func (b *Builder) Build(ctx context.Context, writer io.Writer, progress *inspectionmetadata.TaskProgressMetadata) (int, error) {
 	allBinarySize := 0
 	b.lock.Lock()
@@ -119,21 +132,24 @@ func (b *Builder) Build(ctx context.Context, writer io.Writer, progress *inspect
 			if err != nil {
 				return 0, err
 			}
-			readResult, err := io.ReadAll(compressedReader)
+			compressedReader, compressedSize, err := sizedReader(compressedReader)
 			if err != nil {
 				return 0, err
 			}
-			sizeInBytesBinary := make([]byte, 4)
-			binary.BigEndian.PutUint32(sizeInBytesBinary, uint32(len(readResult)))
+			sizeInBytesBinary := make([]byte, 8)
+			binary.BigEndian.PutUint64(sizeInBytesBinary, uint64(compressedSize))
 			if writtenSize, err := writer.Write(sizeInBytesBinary); err != nil {
 				return 0, err
 			} else {
 				allBinarySize += writtenSize
 			}
-			if writtenSize, err := writer.Write(readResult); err != nil {
+			// Copy the compressed chunk to the writer without reading it on memory.
+			if writtenSize, err := io.Copy(writer, compressedReader); err != nil {
 				return 0, err
+			} else if writtenSize != compressedSize {
+				return 0, fmt.Errorf("compressed chunk %d size changed while writing: %d != %d", i, writtenSize, compressedSize)
 			} else {
-				allBinarySize += writtenSize
+				allBinarySize += int(writtenSize)
 			}
 			binaryWriter.Dispose()
 		}
@@ -142,6 +158,27 @@ func (b *Builder) Build(ctx context.Context, writer io.Writer, progress *inspect
 	return allBinarySize, nil
 }
 
+// sizedReader returns the size of the compressed data without reading it on memory when the reader is a file or a buffer.
+// Otherwise, it reads all the data and returns a new reader of the read data.
+func sizedReader(reader io.Reader) (io.Reader, int64, error) {
+	switch r := reader.(type) {
+	case interface{ Stat() (fs.FileInfo, error) }:
+		stat, err := r.Stat()
+		if err != nil {
+			return nil, 0, err
+		}
+		return reader, stat.Size(), nil
+	case interface{ Len() int }:
+		return reader, int64(r.Len()), nil
+	default:
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, 0, err
+		}
+		return bytes.NewReader(data), int64(len(data)), nil
+	}
+}
+
 func (b *Builder) calcStringHash(source []byte) string {
 	return fmt.Sprintf("%x", md5.Sum(source))
 }
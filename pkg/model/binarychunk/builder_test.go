@@ -40,6 +40,11 @@ func (*testCompressorWaitForSecond) CompressAll(ctx context.Context, reader io.R
 	return bytes.NewBuffer([]byte{1, 2, 3, 4}), nil
 }
 
+// Codec implements Compressor.
+func (*testCompressorWaitForSecond) Codec() Codec {
+	return CodecGzip
+}
+
 // Dispose implements Compressor.
 func (*testCompressorWaitForSecond) Dispose() error {
 	return nil
@@ -76,7 +81,7 @@ func TestBuilder(t *testing.T) {
 		// Forcibly override the chunk size to reduce test time
 		b.maxChunkSize = 1024 * 1024 * 50
 		randBuf := make([]byte, 1024*1024*25)
-		sizeReadBuffer := make([]byte, 4)
+		sizeReadBuffer := make([]byte, 8)
 		for i := 0; i < 4; i++ {
 			rand.Read(randBuf)
 			b.Write(randBuf)
@@ -97,7 +102,7 @@ func TestBuilder(t *testing.T) {
 				t.Errorf("err was not a nil:%v", err)
 			}
 
-			size := binary.BigEndian.Uint32(sizeReadBuffer)
+			size := binary.BigEndian.Uint64(sizeReadBuffer)
 			compressedBuffer := make([]byte, size)
 			_, err = result.Read(compressedBuffer)
 			if err != nil {
@@ -0,0 +1,85 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binarychunk
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Codec is the compression algorithm of a binary chunk. The value is recorded in the .khi file for each chunk.
+type Codec uint8
+
+const (
+	// CodecGzip compresses chunks with gzip. This is the default codec supported by all viewers.
+	CodecGzip Codec = 0
+	// CodecZstd compresses chunks with zstd. It's faster and smaller than gzip, but viewers decompressing chunks on browsers can't read it.
+	CodecZstd Codec = 1
+)
+
+// codecNames is the list of codec names used in parameters.
+var codecNames = map[Codec]string{
+	CodecGzip: "gzip",
+	CodecZstd: "zstd",
+}
+
+// String returns the name of the codec.
+func (c Codec) String() string {
+	if name, found := codecNames[c]; found {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", c)
+}
+
+// ParseCodec returns the Codec from its name.
+func ParseCodec(name string) (Codec, error) {
+	for codec, codecName := range codecNames {
+		if codecName == name {
+			return codec, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown compression codec %q", name)
+}
+
+// NewFileSystemCompressor returns the Compressor of the codec writing compressed chunks in the temporary folder.
+func NewFileSystemCompressor(codec Codec, temporaryFolder string) (Compressor, error) {
+	switch codec {
+	case CodecGzip:
+		return NewFileSystemGzipCompressor(temporaryFolder), nil
+	case CodecZstd:
+		return NewFileSystemZstdCompressor(temporaryFolder), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression codec %s", codec)
+	}
+}
+
+// NewDecompressor returns the reader decompressing the chunk compressed with the codec.
+func NewDecompressor(codec Codec, reader io.Reader) (io.ReadCloser, error) {
+	switch codec {
+	case CodecGzip:
+		return gzip.NewReader(reader)
+	case CodecZstd:
+		decoder, err := zstd.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression codec %s", codec)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binarychunk
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCodec(t *testing.T) {
+	testCases := []struct {
+		name    string
+		want    Codec
+		wantErr bool
+	}{
+		{name: "gzip", want: CodecGzip},
+		{name: "zstd", want: CodecZstd},
+		{name: "brotli", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseCodec(tc.name)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("ParseCodec(%q) returned no error, want an error", tc.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCodec(%q) returned an unexpected error: %v", tc.name, err)
+			}
+			if got != tc.want {
+				t.Errorf("ParseCodec(%q) = %v, want %v", tc.name, got, tc.want)
+			}
+			if got.String() != tc.name {
+				t.Errorf("String() = %q, want %q", got.String(), tc.name)
+			}
+		})
+	}
+}
+
+func TestFileSystemCompressor(t *testing.T) {
+	for _, codec := range []Codec{CodecGzip, CodecZstd} {
+		t.Run(codec.String(), func(t *testing.T) {
+			compressor, err := NewFileSystemCompressor(codec, t.TempDir())
+			if err != nil {
+				t.Fatalf("NewFileSystemCompressor() returned an unexpected error: %v", err)
+			}
+			defer compressor.Dispose()
+			if compressor.Codec() != codec {
+				t.Errorf("Codec() = %v, want %v", compressor.Codec(), codec)
+			}
+			source := bytes.Repeat([]byte("foo bar "), 1000)
+			compressed, err := compressor.CompressAll(context.Background(), bytes.NewReader(source))
+			if err != nil {
+				t.Fatalf("CompressAll() returned an unexpected error: %v", err)
+			}
+			decompressor, err := NewDecompressor(codec, compressed)
+			if err != nil {
+				t.Fatalf("NewDecompressor() returned an unexpected error: %v", err)
+			}
+			defer decompressor.Close()
+			got, err := io.ReadAll(decompressor)
+			if err != nil {
+				t.Fatalf("failed to decompress: %v", err)
+			}
+			if diff := cmp.Diff(source, got); diff != "" {
+				t.Errorf("decompressed data mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
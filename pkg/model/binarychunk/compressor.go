@@ -21,39 +21,31 @@ import (
 	"io"
 	"log/slog"
 	"os"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 type Compressor interface {
 	// CompressAll reads all bytes from given reader and returns a reader for the compressed buffer.
 	CompressAll(ctx context.Context, reader io.Reader) (io.Reader, error)
+	// Codec returns the compression algorithm used by this Compressor.
+	Codec() Codec
 	// Dispose releases all allocated resource in Compressor.
 	Dispose() error
 }
 
-type FileSystemGzipCompressor struct {
+// fileSystemCompressor compresses chunks into temporary files with the compressing writer.
+type fileSystemCompressor struct {
 	temporaryFolder string
 	disposed        bool
 	openedFiles     []*os.File
+	newWriter       func(writer io.Writer) (io.WriteCloser, error)
 }
 
-var _ Compressor = (*FileSystemGzipCompressor)(nil)
-
-func NewFileSystemGzipCompressor(temporaryFolder string) *FileSystemGzipCompressor {
-	return &FileSystemGzipCompressor{
-		temporaryFolder: temporaryFolder,
-		disposed:        false,
-		openedFiles:     make([]*os.File, 0),
-	}
-}
-
-func (c *FileSystemGzipCompressor) CompressAll(ctx context.Context, reader io.Reader) (io.Reader, error) {
+func (c *fileSystemCompressor) CompressAll(ctx context.Context, reader io.Reader) (io.Reader, error) {
 	if c.disposed {
 		return nil, fmt.Errorf("instance is already disposed.")
 	}
-	readResult, err := io.ReadAll(reader)
-	if err != nil {
-		return nil, err
-	}
 	slog.DebugContext(ctx, fmt.Sprintf("Received folder:%s", c.temporaryFolder))
 	tmpfile, err := os.CreateTemp(c.temporaryFolder, "khi-c-")
 	if err != nil {
@@ -62,19 +54,17 @@ func (c *FileSystemGzipCompressor) CompressAll(ctx context.Context, reader io.Re
 	slog.DebugContext(ctx, fmt.Sprintf("Created a temporary file:%s", tmpfile.Name()))
 	defer tmpfile.Close()
 
-	gzipWriter := gzip.NewWriter(tmpfile)
-
-	_, err = gzipWriter.Write(readResult)
+	compressWriter, err := c.newWriter(tmpfile)
 	if err != nil {
 		return nil, err
 	}
 
-	err = gzipWriter.Flush()
+	_, err = io.Copy(compressWriter, reader)
 	if err != nil {
 		return nil, err
 	}
 
-	err = gzipWriter.Close()
+	err = compressWriter.Close()
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +77,7 @@ func (c *FileSystemGzipCompressor) CompressAll(ctx context.Context, reader io.Re
 	return readerFile, nil
 }
 
-func (c *FileSystemGzipCompressor) Dispose() error {
+func (c *fileSystemCompressor) Dispose() error {
 	errors := make([]error, 0)
 	for _, file := range c.openedFiles {
 		err := file.Close()
@@ -100,3 +90,53 @@ func (c *FileSystemGzipCompressor) Dispose() error {
 	}
 	return nil
 }
+
+// FileSystemGzipCompressor is the Compressor of CodecGzip.
+type FileSystemGzipCompressor struct {
+	fileSystemCompressor
+}
+
+var _ Compressor = (*FileSystemGzipCompressor)(nil)
+
+func NewFileSystemGzipCompressor(temporaryFolder string) *FileSystemGzipCompressor {
+	return &FileSystemGzipCompressor{
+		fileSystemCompressor: fileSystemCompressor{
+			temporaryFolder: temporaryFolder,
+			disposed:        false,
+			openedFiles:     make([]*os.File, 0),
+			newWriter: func(writer io.Writer) (io.WriteCloser, error) {
+				return gzip.NewWriter(writer), nil
+			},
+		},
+	}
+}
+
+// Codec implements Compressor.
+func (c *FileSystemGzipCompressor) Codec() Codec {
+	return CodecGzip
+}
+
+// FileSystemZstdCompressor is the Compressor of CodecZstd.
+type FileSystemZstdCompressor struct {
+	fileSystemCompressor
+}
+
+var _ Compressor = (*FileSystemZstdCompressor)(nil)
+
+func NewFileSystemZstdCompressor(temporaryFolder string) *FileSystemZstdCompressor {
+	return &FileSystemZstdCompressor{
+		fileSystemCompressor: fileSystemCompressor{
+			temporaryFolder: temporaryFolder,
+			disposed:        false,
+			openedFiles:     make([]*os.File, 0),
+			newWriter: func(writer io.Writer) (io.WriteCloser, error) {
+				return zstd.NewWriter(writer)
+			},
+		},
+	}
+}
+
+// Codec implements Compressor.
+func (c *FileSystemZstdCompressor) Codec() Codec {
+	return CodecZstd
+}
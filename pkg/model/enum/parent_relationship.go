@@ -31,7 +31,12 @@ const (
 	RelationshipSerialPort            ParentRelationship = 11
 	RelationshipAirflowTaskInstance   ParentRelationship = 12
 	RelationshipCSMAccessLog          ParentRelationship = 13 // Added since 0.49
-	relationshipUnusedEnd                                     // Add items above. This field is used for counting items in this enum to test.
+	RelationshipNodeState             ParentRelationship = 14
+	RelationshipVolume                ParentRelationship = 15
+	RelationshipReplicaScaling        ParentRelationship = 16
+	RelationshipJobExecution          ParentRelationship = 17
+	RelationshipRollout               ParentRelationship = 18
+	relationshipUnusedEnd                                // Add items above. This field is used for counting items in this enum to test.
 )
 
 // EnumParentRelationshipLength is the count of ParentRelationship enum elements.
@@ -537,4 +542,219 @@ var ParentRelationships = map[ParentRelationship]ParentRelationshipFrontendMetad
 			},
 		},
 	},
+	RelationshipNodeState: {
+		Visible:              true,
+		EnumKeyName:          "RelationshipNodeState",
+		Label:                "nodestate",
+		LongName:             "Node spec and status timeline",
+		LabelColor:           "#FFFFFF",
+		LabelBackgroundColor: "#1A73E8",
+		Hint:                 "Schedulability, taints, resources, version or addresses of the node",
+		SortPriority:         1800, // between serial port logs and conditions
+		Description:          "A timeline showing the changes of a field in `.spec` or `.status` of the parent Node, like cordons, taints, capacity, kubelet version and addresses",
+		GeneratableRevisions: []GeneratableRevisionInfo{
+			{
+				State:         RevisionStateNodeSchedulable,
+				SourceLogType: LogTypeAudit,
+				Description:   "`.spec.unschedulable` of the node is not true",
+			},
+			{
+				State:         RevisionStateNodeUnschedulable,
+				SourceLogType: LogTypeAudit,
+				Description:   "The node is cordoned with `.spec.unschedulable`",
+			},
+			{
+				State:         RevisionStateNodeTainted,
+				SourceLogType: LogTypeAudit,
+				Description:   "The taint with the key and effect of the timeline name is applied to the node",
+			},
+			{
+				State:         RevisionStateExisting,
+				SourceLogType: LogTypeAudit,
+				Description:   "The value of the capacity, the kubelet version or the addresses of the node",
+			},
+			{
+				State:         RevisionStateDeleted,
+				SourceLogType: LogTypeAudit,
+				Description:   "The taint is removed or the node is deleted",
+			},
+		},
+	},
+	RelationshipVolume: {
+		Visible:              true,
+		EnumKeyName:          "RelationshipVolume",
+		Label:                "volume",
+		LongName:             "Volume timeline",
+		LabelColor:           "#FFFFFF",
+		LabelBackgroundColor: "#8D6E63",
+		Hint:                 "PersistentVolume, PersistentVolumeClaim or VolumeAttachment associated with this resource",
+		SortPriority:         8500, // later than pod binding
+		Description:          "A timeline showing the binding between PersistentVolumeClaims and PersistentVolumes, Pods using PersistentVolumeClaims and VolumeAttachments attaching volumes to nodes",
+		GeneratableRevisions: []GeneratableRevisionInfo{
+			{
+				State:         RevisionStateVolumePending,
+				SourceLogType: LogTypeAudit,
+				Description:   "The PersistentVolumeClaim or the PersistentVolume is in the `Pending` phase",
+			},
+			{
+				State:         RevisionStateVolumeAvailable,
+				SourceLogType: LogTypeAudit,
+				Description:   "The PersistentVolume is in the `Available` phase",
+			},
+			{
+				State:         RevisionStateVolumeBound,
+				SourceLogType: LogTypeAudit,
+				Description:   "The PersistentVolumeClaim or the PersistentVolume is in the `Bound` phase",
+			},
+			{
+				State:         RevisionStateVolumeReleased,
+				SourceLogType: LogTypeAudit,
+				Description:   "The PersistentVolume is in the `Released` phase",
+			},
+			{
+				State:         RevisionStateVolumeLost,
+				SourceLogType: LogTypeAudit,
+				Description:   "The PersistentVolumeClaim is in the `Lost` phase",
+			},
+			{
+				State:         RevisionStateVolumeFailed,
+				SourceLogType: LogTypeAudit,
+				Description:   "The PersistentVolume is in the `Failed` phase",
+			},
+			{
+				State:         RevisionStateVolumeAttached,
+				SourceLogType: LogTypeAudit,
+				Description:   "The VolumeAttachment reports `.status.attached` is true",
+			},
+			{
+				State:         RevisionStateVolumeDetached,
+				SourceLogType: LogTypeAudit,
+				Description:   "The VolumeAttachment reports `.status.attached` is false",
+			},
+			{
+				State:         RevisionStateExisting,
+				SourceLogType: LogTypeAudit,
+				Description:   "The Pod uses the PersistentVolumeClaim in its volumes",
+			},
+			{
+				State:         RevisionStateDeleted,
+				SourceLogType: LogTypeAudit,
+				Description:   "The related resource is deleted",
+			},
+		},
+	},
+	RelationshipReplicaScaling: {
+		Visible:              true,
+		EnumKeyName:          "RelationshipReplicaScaling",
+		Label:                "scaling",
+		LongName:             "Replica scaling timeline",
+		LabelColor:           "#FFFFFF",
+		LabelBackgroundColor: "#0077CC",
+		Hint:                 "Scaling decisions of HorizontalPodAutoscaler or replicas written to the scale subresource",
+		SortPriority:         2500, // between conditions and operations
+		Description:          "A timeline showing desired and current replicas decided by a HorizontalPodAutoscaler, or replicas written to the scale subresource of its target",
+		GeneratableRevisions: []GeneratableRevisionInfo{
+			{
+				State:         RevisionStateScalingUp,
+				SourceLogType: LogTypeAudit,
+				Description:   "The desired replicas is more than the current replicas",
+			},
+			{
+				State:         RevisionStateScalingDown,
+				SourceLogType: LogTypeAudit,
+				Description:   "The desired replicas is less than the current replicas",
+			},
+			{
+				State:         RevisionStateScalingStable,
+				SourceLogType: LogTypeAudit,
+				Description:   "The desired replicas is same as the current replicas",
+			},
+		},
+	},
+	RelationshipJobExecution: {
+		Visible:              true,
+		EnumKeyName:          "RelationshipJobExecution",
+		Label:                "run",
+		LongName:             "Job execution timeline",
+		LabelColor:           "#FFFFFF",
+		LabelBackgroundColor: "#6A1B9A",
+		Hint:                 "Execution state of the Job, or Jobs and schedules of the CronJob",
+		SortPriority:         2600, // between replica scaling and operations
+		Description:          "A timeline showing the execution state of a Job, a Job run started from a CronJob or the schedule of a CronJob",
+		GeneratableRevisions: []GeneratableRevisionInfo{
+			{
+				State:         RevisionStateJobRunning,
+				SourceLogType: LogTypeAudit,
+				Description:   "The Job is running or retrying after failures",
+			},
+			{
+				State:         RevisionStateJobSucceeded,
+				SourceLogType: LogTypeAudit,
+				Description:   "The Job has the `Complete` condition, or the CronJob updated `.status.lastSuccessfulTime` when the Job finished",
+			},
+			{
+				State:         RevisionStateJobFailed,
+				SourceLogType: LogTypeAudit,
+				Description:   "The Job has the `Failed` condition",
+			},
+			{
+				State:         RevisionStateJobResultUnknown,
+				SourceLogType: LogTypeAudit,
+				Description:   "The Job run of the CronJob finished without updating `.status.lastSuccessfulTime`. The execution timeline of the Job shows the result when its logs are available",
+			},
+			{
+				State:         RevisionStateJobSuspended,
+				SourceLogType: LogTypeAudit,
+				Description:   "The Job or the CronJob is suspended with `.spec.suspend`",
+			},
+			{
+				State:         RevisionStateJobScheduleMissed,
+				SourceLogType: LogTypeAudit,
+				Description:   "The CronJob had not started a Job at the scheduled time when a later log was written, and then moved on to a later schedule",
+			},
+			{
+				State:         RevisionStateExisting,
+				SourceLogType: LogTypeAudit,
+				Description:   "The CronJob is scheduling Jobs",
+			},
+			{
+				State:         RevisionStateDeleted,
+				SourceLogType: LogTypeAudit,
+				Description:   "The Job or the CronJob is deleted",
+			},
+		},
+	},
+	RelationshipRollout: {
+		Visible:              true,
+		EnumKeyName:          "RelationshipRollout",
+		Label:                "rollout",
+		LongName:             "Rollout timeline",
+		LabelColor:           "#FFFFFF",
+		LabelBackgroundColor: "#00897B",
+		Hint:                 "Rollout progress of the Deployment, StatefulSet or DaemonSet",
+		SortPriority:         2700, // between job executions and operations
+		Description:          "A timeline showing the progress of rollouts started by changes of the pod template in a Deployment, StatefulSet or DaemonSet",
+		GeneratableRevisions: []GeneratableRevisionInfo{
+			{
+				State:         RevisionStateRolloutInProgress,
+				SourceLogType: LogTypeAudit,
+				Description:   "The pod template was changed and the workload is updating its pods",
+			},
+			{
+				State:         RevisionStateRolloutCompleted,
+				SourceLogType: LogTypeAudit,
+				Description:   "All the pods are updated and available",
+			},
+			{
+				State:         RevisionStateRolloutStalled,
+				SourceLogType: LogTypeAudit,
+				Description:   "The Deployment reports `ProgressDeadlineExceeded` on the `Progressing` condition",
+			},
+			{
+				State:         RevisionStateDeleted,
+				SourceLogType: LogTypeAudit,
+				Description:   "The workload is deleted during its rollout",
+			},
+		},
+	},
 }
@@ -79,11 +79,13 @@ func TestParentRelationshipOrderIsValid(t *testing.T) {
 			expectedOrder: []ParentRelationship{
 				RelationshipChild,
 				RelationshipSerialPort,
+				RelationshipNodeState,
 				RelationshipResourceCondition,
 				RelationshipOperation,
 				RelationshipNodeComponent,
 				RelationshipOwnerReference,
 				RelationshipPodBinding,
+				RelationshipVolume,
 			},
 		},
 		{
@@ -96,6 +98,18 @@ func TestParentRelationshipOrderIsValid(t *testing.T) {
 				RelationshipNetworkEndpointGroup,
 			},
 		},
+		{
+			name: "order of workload subresources",
+			expectedOrder: []ParentRelationship{
+				RelationshipChild,
+				RelationshipResourceCondition,
+				RelationshipReplicaScaling,
+				RelationshipJobExecution,
+				RelationshipRollout,
+				RelationshipOperation,
+				RelationshipOwnerReference,
+			},
+		},
 	}
 	for _, tc := range testCase {
 		t.Run(tc.name, func(t *testing.T) {
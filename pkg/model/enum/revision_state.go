@@ -58,6 +58,35 @@ const (
 	RevisionAutoscalerNoError   RevisionState = 30 // Added since 0.49
 	RevisionAutoscalerHasErrors RevisionState = 31
 
+	RevisionStateNodeSchedulable   RevisionState = 32
+	RevisionStateNodeUnschedulable RevisionState = 33
+	RevisionStateNodeTainted       RevisionState = 34
+
+	RevisionStateVolumePending   RevisionState = 35
+	RevisionStateVolumeAvailable RevisionState = 36
+	RevisionStateVolumeBound     RevisionState = 37
+	RevisionStateVolumeReleased  RevisionState = 38
+	RevisionStateVolumeLost      RevisionState = 39
+	RevisionStateVolumeFailed    RevisionState = 40
+	RevisionStateVolumeAttached  RevisionState = 41
+	RevisionStateVolumeDetached  RevisionState = 42
+
+	RevisionStateScalingUp     RevisionState = 43
+	RevisionStateScalingDown   RevisionState = 44
+	RevisionStateScalingStable RevisionState = 45
+
+	RevisionStateJobRunning        RevisionState = 46
+	RevisionStateJobSucceeded      RevisionState = 47
+	RevisionStateJobFailed         RevisionState = 48
+	RevisionStateJobSuspended      RevisionState = 49
+	RevisionStateJobScheduleMissed RevisionState = 50
+
+	RevisionStateRolloutInProgress RevisionState = 51
+	RevisionStateRolloutCompleted  RevisionState = 52
+	RevisionStateRolloutStalled    RevisionState = 53
+
+	RevisionStateJobResultUnknown RevisionState = 54
+
 	revisionStateUnusedEnd // Adds items above. This value is used for counting items in this enum to test.
 )
 
@@ -269,4 +298,142 @@ var RevisionStates = map[RevisionState]RevisionStateFrontendMetadata{
 		CSSSelector:     "autoscaler_has_errors",
 		Label:           "Autoscaler has errors",
 	},
+	RevisionStateNodeSchedulable: {
+		EnumKeyName:     "RevisionStateNodeSchedulable",
+		BackgroundColor: "#004400",
+		CSSSelector:     "node_schedulable",
+		Label:           "Node is schedulable",
+	},
+	RevisionStateNodeUnschedulable: {
+		EnumKeyName:     "RevisionStateNodeUnschedulable",
+		BackgroundColor: "#EE4400",
+		CSSSelector:     "node_unschedulable",
+		Label:           "Node is cordoned and unschedulable",
+	},
+	RevisionStateNodeTainted: {
+		EnumKeyName:     "RevisionStateNodeTainted",
+		BackgroundColor: "#997700",
+		CSSSelector:     "node_tainted",
+		Label:           "Taint is applied to the node",
+	},
+	RevisionStateVolumePending: {
+		EnumKeyName:     "RevisionStateVolumePending",
+		BackgroundColor: "#997700",
+		CSSSelector:     "volume_pending",
+		Label:           "Volume is pending to be bound",
+	},
+	RevisionStateVolumeAvailable: {
+		EnumKeyName:     "RevisionStateVolumeAvailable",
+		BackgroundColor: "#0077CC",
+		CSSSelector:     "volume_available",
+		Label:           "Volume is available and not bound to any claim",
+	},
+	RevisionStateVolumeBound: {
+		EnumKeyName:     "RevisionStateVolumeBound",
+		BackgroundColor: "#004400",
+		CSSSelector:     "volume_bound",
+		Label:           "Volume is bound",
+	},
+	RevisionStateVolumeReleased: {
+		EnumKeyName:     "RevisionStateVolumeReleased",
+		BackgroundColor: "#555555",
+		CSSSelector:     "volume_released",
+		Label:           "Volume is released from the claim",
+	},
+	RevisionStateVolumeLost: {
+		EnumKeyName:     "RevisionStateVolumeLost",
+		BackgroundColor: "#CC0000",
+		CSSSelector:     "volume_lost",
+		Label:           "Bound volume is lost",
+	},
+	RevisionStateVolumeFailed: {
+		EnumKeyName:     "RevisionStateVolumeFailed",
+		BackgroundColor: "#CC0000",
+		CSSSelector:     "volume_failed",
+		Label:           "Volume failed to be reclaimed",
+	},
+	RevisionStateVolumeAttached: {
+		EnumKeyName:     "RevisionStateVolumeAttached",
+		BackgroundColor: "#004400",
+		CSSSelector:     "volume_attached",
+		Label:           "Volume is attached to the node",
+	},
+	RevisionStateVolumeDetached: {
+		EnumKeyName:     "RevisionStateVolumeDetached",
+		BackgroundColor: "#997700",
+		CSSSelector:     "volume_detached",
+		Label:           "Volume is not attached to the node",
+	},
+	RevisionStateScalingUp: {
+		EnumKeyName:     "RevisionStateScalingUp",
+		BackgroundColor: "#0077CC",
+		CSSSelector:     "scaling_up",
+		Label:           "Desired replicas is more than the current replicas",
+	},
+	RevisionStateScalingDown: {
+		EnumKeyName:     "RevisionStateScalingDown",
+		BackgroundColor: "#EE8800",
+		CSSSelector:     "scaling_down",
+		Label:           "Desired replicas is less than the current replicas",
+	},
+	RevisionStateScalingStable: {
+		EnumKeyName:     "RevisionStateScalingStable",
+		BackgroundColor: "#004400",
+		CSSSelector:     "scaling_stable",
+		Label:           "Desired replicas is same as the current replicas",
+	},
+	RevisionStateJobRunning: {
+		EnumKeyName:     "RevisionStateJobRunning",
+		BackgroundColor: "#0077CC",
+		CSSSelector:     "job_running",
+		Label:           "Job is running",
+	},
+	RevisionStateJobSucceeded: {
+		EnumKeyName:     "RevisionStateJobSucceeded",
+		BackgroundColor: "#004400",
+		CSSSelector:     "job_succeeded",
+		Label:           "Job completed successfully",
+	},
+	RevisionStateJobFailed: {
+		EnumKeyName:     "RevisionStateJobFailed",
+		BackgroundColor: "#CC0000",
+		CSSSelector:     "job_failed",
+		Label:           "Job failed",
+	},
+	RevisionStateJobSuspended: {
+		EnumKeyName:     "RevisionStateJobSuspended",
+		BackgroundColor: "#555555",
+		CSSSelector:     "job_suspended",
+		Label:           "Job or CronJob is suspended",
+	},
+	RevisionStateJobScheduleMissed: {
+		EnumKeyName:     "RevisionStateJobScheduleMissed",
+		BackgroundColor: "#EE8800",
+		CSSSelector:     "job_schedule_missed",
+		Label:           "Scheduled time of the CronJob was missed",
+	},
+	RevisionStateRolloutInProgress: {
+		EnumKeyName:     "RevisionStateRolloutInProgress",
+		BackgroundColor: "#0077CC",
+		CSSSelector:     "rollout_in_progress",
+		Label:           "Rollout is in progress",
+	},
+	RevisionStateRolloutCompleted: {
+		EnumKeyName:     "RevisionStateRolloutCompleted",
+		BackgroundColor: "#004400",
+		CSSSelector:     "rollout_completed",
+		Label:           "Rollout completed",
+	},
+	RevisionStateRolloutStalled: {
+		EnumKeyName:     "RevisionStateRolloutStalled",
+		BackgroundColor: "#CC0000",
+		CSSSelector:     "rollout_stalled",
+		Label:           "Rollout is stalled",
+	},
+	RevisionStateJobResultUnknown: {
+		EnumKeyName:     "RevisionStateJobResultUnknown",
+		BackgroundColor: "#997700",
+		CSSSelector:     "job_result_unknown",
+		Label:           "Job finished but the result is unknown",
+	},
 }
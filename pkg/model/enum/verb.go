@@ -56,6 +56,11 @@ const (
 
 	RevisionVerbTerminating RevisionVerb = 31 // Added since 0.41 for endpoint slice
 
+	RevisionVerbCordon   RevisionVerb = 32
+	RevisionVerbUncordon RevisionVerb = 33
+	RevisionVerbTaint    RevisionVerb = 34
+	RevisionVerbUntaint  RevisionVerb = 35
+
 	revisionVerbUnusedEnd // Adds items above. This value is used for counting items in this enum to test.
 )
 
@@ -262,4 +267,28 @@ var RevisionVerbs = map[RevisionVerb]RevisionVerbFrontendMetadata{
 		LabelBackgroundColor: "#DDDDDD",
 		CSSSelector:          "composer-taskinstance-unimplemented",
 	},
+	RevisionVerbCordon: {
+		EnumKeyName:          "RevisionVerbCordon",
+		Label:                "Cordon",
+		CSSSelector:          "cordon",
+		LabelBackgroundColor: "#EE4400",
+	},
+	RevisionVerbUncordon: {
+		EnumKeyName:          "RevisionVerbUncordon",
+		Label:                "Uncordon",
+		CSSSelector:          "uncordon",
+		LabelBackgroundColor: "#22CC22",
+	},
+	RevisionVerbTaint: {
+		EnumKeyName:          "RevisionVerbTaint",
+		Label:                "Taint",
+		CSSSelector:          "taint",
+		LabelBackgroundColor: "#FFAA00",
+	},
+	RevisionVerbUntaint: {
+		EnumKeyName:          "RevisionVerbUntaint",
+		Label:                "Untaint",
+		CSSSelector:          "untaint",
+		LabelBackgroundColor: "#3fb549",
+	},
 }
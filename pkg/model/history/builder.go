@@ -16,8 +16,6 @@ package history
 
 import (
 	"context"
-	"encoding/binary"
-	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
@@ -54,10 +52,23 @@ type Builder struct {
 }
 
 func NewBuilder(tmpFolder string) *Builder {
+	return newBuilder(tmpFolder, binarychunk.NewFileSystemGzipCompressor(tmpFolder))
+}
+
+// NewBuilderWithCodec returns a Builder compressing the binary chunks with the given codec.
+func NewBuilderWithCodec(tmpFolder string, codec binarychunk.Codec) (*Builder, error) {
+	compressor, err := binarychunk.NewFileSystemCompressor(codec, tmpFolder)
+	if err != nil {
+		return nil, err
+	}
+	return newBuilder(tmpFolder, compressor), nil
+}
+
+func newBuilder(tmpFolder string, compressor binarychunk.Compressor) *Builder {
 	return &Builder{
 		history:                NewHistory(),
 		historyLock:            sync.Mutex{},
-		BinaryBuilder:          binarychunk.NewBuilder(binarychunk.NewFileSystemGzipCompressor(tmpFolder), tmpFolder),
+		BinaryBuilder:          binarychunk.NewBuilder(compressor, tmpFolder),
 		timelinemap:            common.NewShardingMap[*ResourceTimeline](common.NewSuffixShardingProvider(128, 4)),
 		timelineBuilders:       common.NewShardingMap[*TimelineBuilder](common.NewSuffixShardingProvider(128, 4)),
 		timelineIDGenerator:    idgenerator.NewPrefixIDGenerator("t"),
@@ -337,9 +348,8 @@ func (builder *Builder) sortData() error {
 	return nil
 }
 
-// Finalize flushes the binary chunk data and serialized metadata to the given io.Writer. Returns the written data size in bytes and error.
+// Finalize flushes the binary chunk data and serialized metadata to the given io.Writer in the .khi format. Returns the written data size in bytes and error.
 func (builder *Builder) Finalize(ctx context.Context, serializedMetadata map[string]interface{}, writer io.Writer, progress *inspectionmetadata.TaskProgressMetadata) (int, error) {
-	fileSize := 0
 	progress.Update(0, "Sorting log entries")
 	progress.MarkIndeterminate()
 	builder.history.Metadata = serializedMetadata
@@ -347,38 +357,7 @@ func (builder *Builder) Finalize(ctx context.Context, serializedMetadata map[str
 	if err != nil {
 		return 0, err
 	}
-	jsonString, err := json.Marshal(builder.history)
-	if err != nil {
-		return 0, err
-	}
-	jsonBytes := []byte(jsonString)
-
-	if writtenSize, err := writer.Write([]byte("KHI")); err != nil {
-		return 0, err
-	} else {
-		fileSize += writtenSize
-	}
-
-	metaFieldJsonSize := make([]byte, 4)
-	binary.LittleEndian.PutUint32(metaFieldJsonSize, uint32(len(jsonBytes)))
-	if writtenSize, err := writer.Write(metaFieldJsonSize); err != nil {
-		return 0, err
-	} else {
-		fileSize += writtenSize
-	}
-
-	if writtenSize, err := writer.Write(jsonBytes); err != nil {
-		return 0, err
-	} else {
-		fileSize += writtenSize
-	}
-
-	if writtenSize, err := builder.BinaryBuilder.Build(ctx, writer, progress); err != nil {
-		return 0, err
-	} else {
-		fileSize += writtenSize
-	}
-	return fileSize, nil
+	return WriteFile(ctx, writer, builder.history, builder.BinaryBuilder, progress)
 }
 
 // DangerouslyGetRawHistory returns the raw history value written by this builder. This method is only used for testing purpose.
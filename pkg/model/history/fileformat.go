@@ -0,0 +1,187 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+)
+
+// FileMagicBytes is the byte sequence placed at the beginning of every .khi file.
+const FileMagicBytes = "KHI"
+
+// Versions of the .khi container format.
+//
+// Version 1 is `KHI`, the history JSON size in uint32 little endian, the history JSON and the gzip compressed binary chunks prefixed with their sizes in uint32 big endian.
+// Version 2 is `KHI`, FileFormatVersionMarker, the format version in uint32 little endian, the history JSON size in uint64 little endian,
+// the history JSON and the gzip compressed binary chunks prefixed with their sizes in uint64 big endian.
+// Version 3 is same as version 2 except the chunk count in uint32 little endian and the binarychunk.Codec of each chunk in a byte are placed after the history JSON size.
+const (
+	FileFormatVersion64BitSize  = 2
+	FileFormatVersionChunkCodec = 3
+)
+
+// FileFormatVersion is the latest version of the .khi container format.
+// WriteFile writes files in FileFormatVersion64BitSize when all chunks are compressed with gzip to keep them readable by the viewers not supporting newer versions.
+const FileFormatVersion = FileFormatVersionChunkCodec
+
+// FileFormatVersionMarker is written in place of the history JSON size of the version 1 format to mark the file has the format version field.
+// Version 1 files can't have this value as their history JSON size because the JSON can't be larger than this in the format.
+const FileFormatVersionMarker uint32 = math.MaxUint32
+
+// historyWriteBufferSize is the buffer size used to write the history JSON.
+const historyWriteBufferSize = 1024 * 1024
+
+// WriteFile writes the History and the binary chunks in the binaryBuilder to the writer in the .khi format. Returns the written size in bytes.
+// The history JSON is encoded element by element not to hold the entire serialized history in memory.
+func WriteFile(ctx context.Context, writer io.Writer, h *History, binaryBuilder *binarychunk.Builder, progress *inspectionmetadata.TaskProgressMetadata) (int, error) {
+	// The JSON size must be written before the JSON itself. Encode it twice to count the size first instead of buffering the JSON.
+	counter := &countingWriter{}
+	if err := writeHistoryJSON(counter, h); err != nil {
+		return 0, fmt.Errorf("failed to serialize the history section: %w", err)
+	}
+
+	fileSize, err := writer.Write(fileHeader(counter.size, binaryBuilder.ChunkCodecs()))
+	if err != nil {
+		return 0, err
+	}
+
+	bufferedWriter := bufio.NewWriterSize(writer, historyWriteBufferSize)
+	jsonWriter := &countingWriter{writer: bufferedWriter}
+	if err := writeHistoryJSON(jsonWriter, h); err != nil {
+		return 0, fmt.Errorf("failed to serialize the history section: %w", err)
+	}
+	if err := bufferedWriter.Flush(); err != nil {
+		return 0, err
+	}
+	if jsonWriter.size != counter.size {
+		return 0, fmt.Errorf("the history section size changed during serialization: %d != %d", jsonWriter.size, counter.size)
+	}
+	fileSize += int(jsonWriter.size)
+
+	writtenSize, err := binaryBuilder.Build(ctx, writer, progress)
+	if err != nil {
+		return 0, err
+	}
+	return fileSize + writtenSize, nil
+}
+
+// fileHeader returns the header of the .khi file preceding the history JSON.
+func fileHeader(jsonSize int64, chunkCodecs []binarychunk.Codec) []byte {
+	version := FileFormatVersion64BitSize
+	for _, codec := range chunkCodecs {
+		if codec != binarychunk.CodecGzip {
+			version = FileFormatVersionChunkCodec
+			break
+		}
+	}
+	header := make([]byte, len(FileMagicBytes)+4+4+8, len(FileMagicBytes)+4+4+8+4+len(chunkCodecs))
+	copy(header, FileMagicBytes)
+	binary.LittleEndian.PutUint32(header[len(FileMagicBytes):], FileFormatVersionMarker)
+	binary.LittleEndian.PutUint32(header[len(FileMagicBytes)+4:], uint32(version))
+	binary.LittleEndian.PutUint64(header[len(FileMagicBytes)+8:], uint64(jsonSize))
+	if version == FileFormatVersionChunkCodec {
+		header = binary.LittleEndian.AppendUint32(header, uint32(len(chunkCodecs)))
+		for _, codec := range chunkCodecs {
+			header = append(header, byte(codec))
+		}
+	}
+	return header
+}
+
+// writeHistoryJSON writes the History in the same JSON as json.Marshal without marshaling the entire History at once.
+func writeHistoryJSON(writer io.Writer, h *History) error {
+	fields := []struct {
+		name  string
+		write func() error
+	}{
+		{name: "version", write: func() error { return writeJSONValue(writer, h.Version) }},
+		{name: "metadata", write: func() error { return writeJSONValue(writer, h.Metadata) }},
+		{name: "logs", write: func() error { return writeJSONArray(writer, h.Logs) }},
+		{name: "timelines", write: func() error { return writeJSONArray(writer, h.Timelines) }},
+		{name: "resources", write: func() error { return writeJSONArray(writer, h.Resources) }},
+	}
+	for i, field := range fields {
+		separator := ","
+		if i == 0 {
+			separator = "{"
+		}
+		if _, err := fmt.Fprintf(writer, "%s%q:", separator, field.name); err != nil {
+			return err
+		}
+		if err := field.write(); err != nil {
+			return fmt.Errorf("failed to serialize %s: %w", field.name, err)
+		}
+	}
+	_, err := io.WriteString(writer, "}")
+	return err
+}
+
+// writeJSONArray writes the slice as a JSON array marshaling one element at once. A nil slice is written as null like json.Marshal.
+func writeJSONArray[T any](writer io.Writer, values []T) error {
+	if values == nil {
+		_, err := io.WriteString(writer, "null")
+		return err
+	}
+	if _, err := io.WriteString(writer, "["); err != nil {
+		return err
+	}
+	for i, value := range values {
+		if i > 0 {
+			if _, err := io.WriteString(writer, ","); err != nil {
+				return err
+			}
+		}
+		if err := writeJSONValue(writer, value); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(writer, "]")
+	return err
+}
+
+func writeJSONValue(writer io.Writer, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	return err
+}
+
+// countingWriter counts the bytes written to it. It discards the data when the writer is nil.
+type countingWriter struct {
+	writer io.Writer
+	size   int64
+}
+
+// Write implements io.Writer.
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.writer == nil {
+		c.size += int64(len(p))
+		return len(p), nil
+	}
+	n, err := c.writer.Write(p)
+	c.size += int64(n)
+	return n, err
+}
@@ -0,0 +1,137 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteHistoryJSON(t *testing.T) {
+	testCases := []struct {
+		name    string
+		history *History
+	}{
+		{
+			name:    "empty history",
+			history: NewHistory(),
+		},
+		{
+			name:    "nil slices",
+			history: &History{Version: "5"},
+		},
+		{
+			name: "history with elements",
+			history: &History{
+				Version:  "5",
+				Metadata: map[string]any{"header": map[string]any{"title": "<foo & bar>"}, "foo": 1},
+				Logs: []*SerializableLog{
+					{ID: "log-1", Timestamp: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), Body: &binarychunk.BinaryReference{Offset: 1, Length: 2}, Annotations: []any{}},
+					{ID: "log-2", Timestamp: time.Date(2025, time.January, 1, 0, 0, 1, 0, time.UTC), Type: enum.LogTypeAudit},
+				},
+				Timelines: []*ResourceTimeline{
+					{ID: "t1", Revisions: []*ResourceRevision{{Log: "log-1", Verb: enum.RevisionVerbCreate}}, Events: []*ResourceEvent{{Log: "log-2"}}},
+				},
+				Resources: []*Resource{
+					{ResourceName: "core/v1", Children: []*Resource{{ResourceName: "pod", FullResourcePath: "core/v1#pod"}}},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			want, err := json.Marshal(tc.history)
+			if err != nil {
+				t.Fatalf("json.Marshal() returned an unexpected error: %v", err)
+			}
+			var got bytes.Buffer
+			if err := writeHistoryJSON(&got, tc.history); err != nil {
+				t.Fatalf("writeHistoryJSON() returned an unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(string(want), got.String()); diff != "" {
+				t.Errorf("writeHistoryJSON() mismatch with json.Marshal() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	binaryBuilder := binarychunk.NewBuilder(binarychunk.NewFileSystemGzipCompressor(t.TempDir()), t.TempDir())
+	bodyRef, err := binaryBuilder.Write([]byte("foo"))
+	if err != nil {
+		t.Fatalf("Write() returned an unexpected error: %v", err)
+	}
+	h := NewHistory()
+	h.Logs = append(h.Logs, &SerializableLog{ID: "log-1", Body: bodyRef})
+
+	var buf bytes.Buffer
+	size, err := WriteFile(context.Background(), &buf, h, binaryBuilder, inspectionmetadata.NewTaskProgressMetadata("test"))
+	if err != nil {
+		t.Fatalf("WriteFile() returned an unexpected error: %v", err)
+	}
+	if size != buf.Len() {
+		t.Errorf("WriteFile() returned size %d, want %d", size, buf.Len())
+	}
+
+	data := buf.Bytes()
+	if got := string(data[:len(FileMagicBytes)]); got != FileMagicBytes {
+		t.Fatalf("magic bytes = %q, want %q", got, FileMagicBytes)
+	}
+	offset := len(FileMagicBytes)
+	if got := binary.LittleEndian.Uint32(data[offset:]); got != FileFormatVersionMarker {
+		t.Errorf("format version marker = %x, want %x", got, FileFormatVersionMarker)
+	}
+	if got := binary.LittleEndian.Uint32(data[offset+4:]); got != FileFormatVersion64BitSize {
+		t.Errorf("format version = %d, want %d", got, FileFormatVersion64BitSize)
+	}
+	jsonSize := int(binary.LittleEndian.Uint64(data[offset+8:]))
+	offset += 16
+	var gotHistory History
+	if err := json.Unmarshal(data[offset:offset+jsonSize], &gotHistory); err != nil {
+		t.Fatalf("failed to parse the history section: %v", err)
+	}
+	if diff := cmp.Diff(h, &gotHistory); diff != "" {
+		t.Errorf("history section mismatch (-want +got):\n%s", diff)
+	}
+	offset += jsonSize
+
+	chunkSize := int(binary.BigEndian.Uint64(data[offset:]))
+	offset += 8
+	if offset+chunkSize != len(data) {
+		t.Fatalf("chunk ends at %d, want %d", offset+chunkSize, len(data))
+	}
+	gzipReader, err := gzip.NewReader(bytes.NewReader(data[offset:]))
+	if err != nil {
+		t.Fatalf("gzip.NewReader() returned an unexpected error: %v", err)
+	}
+	chunk, err := io.ReadAll(gzipReader)
+	if err != nil {
+		t.Fatalf("failed to decompress the chunk: %v", err)
+	}
+	if got := string(chunk[bodyRef.Offset : bodyRef.Offset+bodyRef.Length]); got != "foo" {
+		t.Errorf("body = %q, want %q", got, "foo")
+	}
+}
@@ -72,6 +72,17 @@ func NodeSerialport(nodeName, port string) ResourcePath {
 	return node
 }
 
+// NodeState returns a ResourcePath for the pseudo timeline under nodes showing a field of the node spec or status.
+func NodeState(nodeName string, field string) ResourcePath {
+	if field == "" {
+		field = nonSpecifiedPlaceholder
+	}
+	node := Node(nodeName)
+	node.ParentRelationship = enum.RelationshipNodeState
+	node.Path = fmt.Sprintf("%s#%s", node.Path, field)
+	return node
+}
+
 // NodeBinding returns a ResourcePath for the pseudo binding timeline under nodes.
 func NodeBinding(nodeName string, podNamespace string, podName string) ResourcePath {
 	if podName == "" {
@@ -114,6 +125,53 @@ func ServiceEndpointSlice(namespace string, endpointSliceName string, serviceNam
 	return service
 }
 
+// PersistentVolumeUnderPersistentVolumeClaim returns a ResourcePath for the pseudo volume timeline under persistentvolumeclaims.
+func PersistentVolumeUnderPersistentVolumeClaim(pvcNamespace string, pvcName string, pvName string) ResourcePath {
+	if pvName == "" {
+		pvName = nonSpecifiedPlaceholder
+	}
+	pvc := PersistentVolumeClaim(pvcNamespace, pvcName)
+	pvc.Path = fmt.Sprintf("%s#%s[persistentvolume]", pvc.Path, pvName)
+	pvc.ParentRelationship = enum.RelationshipVolume
+	return pvc
+}
+
+// PersistentVolumeClaimUnderPersistentVolume returns a ResourcePath for the pseudo volume timeline under persistentvolumes.
+func PersistentVolumeClaimUnderPersistentVolume(pvName string, pvcNamespace string, pvcName string) ResourcePath {
+	if pvcNamespace == "" {
+		pvcNamespace = nonSpecifiedPlaceholder
+	}
+	if pvcName == "" {
+		pvcName = nonSpecifiedPlaceholder
+	}
+	pv := PersistentVolume(pvName)
+	pv.Path = fmt.Sprintf("%s#%s(%s)[persistentvolumeclaim]", pv.Path, pvcName, pvcNamespace)
+	pv.ParentRelationship = enum.RelationshipVolume
+	return pv
+}
+
+// PodUnderPersistentVolumeClaim returns a ResourcePath for the pseudo volume timeline under persistentvolumeclaims showing a pod using the claim.
+func PodUnderPersistentVolumeClaim(namespace string, pvcName string, podName string) ResourcePath {
+	if podName == "" {
+		podName = nonSpecifiedPlaceholder
+	}
+	pvc := PersistentVolumeClaim(namespace, pvcName)
+	pvc.Path = fmt.Sprintf("%s#%s[pod]", pvc.Path, podName)
+	pvc.ParentRelationship = enum.RelationshipVolume
+	return pvc
+}
+
+// VolumeAttachmentUnderResource returns a ResourcePath for the pseudo volume timeline under the given name layer resource showing a volumeattachment.
+func VolumeAttachmentUnderResource(parent ResourcePath, volumeAttachmentName string) ResourcePath {
+	if volumeAttachmentName == "" {
+		volumeAttachmentName = nonSpecifiedPlaceholder
+	}
+	return ResourcePath{
+		Path:               fmt.Sprintf("%s#%s[volumeattachment]", parent.Path, volumeAttachmentName),
+		ParentRelationship: enum.RelationshipVolume,
+	}
+}
+
 // Operation returns a ResourcePath for the pseudo operation timeline under the given name layer resource.
 func Operation(operationOwner ResourcePath, operationMethod string, operationId string) ResourcePath {
 	if operationMethod == "" {
@@ -139,6 +197,49 @@ func Status(statusOwner ResourcePath, statusName string) ResourcePath {
 	}
 }
 
+// Scaling returns a ResourcePath for the pseudo replica scaling timeline under the given name layer resource.
+func Scaling(scalingOwner ResourcePath) ResourcePath {
+	return ResourcePath{
+		Path:               fmt.Sprintf("%s#scaling", scalingOwner.Path),
+		ParentRelationship: enum.RelationshipReplicaScaling,
+	}
+}
+
+// JobExecution returns a ResourcePath for the pseudo execution timeline under the given job.
+func JobExecution(job ResourcePath) ResourcePath {
+	return ResourcePath{
+		Path:               fmt.Sprintf("%s#execution", job.Path),
+		ParentRelationship: enum.RelationshipJobExecution,
+	}
+}
+
+// CronJobSchedule returns a ResourcePath for the pseudo schedule timeline under the given cronjob.
+func CronJobSchedule(cronJob ResourcePath) ResourcePath {
+	return ResourcePath{
+		Path:               fmt.Sprintf("%s#schedule", cronJob.Path),
+		ParentRelationship: enum.RelationshipJobExecution,
+	}
+}
+
+// JobUnderCronJob returns a ResourcePath for the pseudo timeline under the given cronjob showing a job run started from it.
+func JobUnderCronJob(cronJob ResourcePath, jobName string) ResourcePath {
+	if jobName == "" {
+		jobName = nonSpecifiedPlaceholder
+	}
+	return ResourcePath{
+		Path:               fmt.Sprintf("%s#%s[job]", cronJob.Path, jobName),
+		ParentRelationship: enum.RelationshipJobExecution,
+	}
+}
+
+// Rollout returns a ResourcePath for the pseudo rollout timeline under the given workload.
+func Rollout(workload ResourcePath) ResourcePath {
+	return ResourcePath{
+		Path:               fmt.Sprintf("%s#rollout", workload.Path),
+		ParentRelationship: enum.RelationshipRollout,
+	}
+}
+
 // NetworkEndpointGroupUnderResource returns the pseudo neg timeline under the given name layer resource.
 func NetworkEndpointGroupUnderResource(parent ResourcePath, negNamespace string, negName string) ResourcePath {
 	if negNamespace == "" {
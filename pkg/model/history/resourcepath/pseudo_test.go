@@ -178,6 +178,32 @@ func TestNodeSerialport(t *testing.T) {
 	}
 }
 
+func TestNodeState(t *testing.T) {
+	expectedParentRelationship := enum.RelationshipNodeState
+	testCases := []struct {
+		name     string
+		nodeName string
+		field    string
+		expected string
+	}{
+		{"All specified", "my-node", "schedulable", "core/v1#node#cluster-scope#my-node#schedulable"},
+		{"Empty node name", "", "schedulable", "core/v1#node#cluster-scope#unknown#schedulable"},
+		{"Empty field", "my-node", "", "core/v1#node#cluster-scope#my-node#unknown"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := NodeState(tc.nodeName, tc.field)
+			if result.Path != tc.expected {
+				t.Errorf("NodeState(%v,%v).Path = %v, want %v", tc.nodeName, tc.field, result.Path, tc.expected)
+			}
+			if result.ParentRelationship != expectedParentRelationship {
+				t.Errorf("NodeState(%v,%v).ParentRelationship = %v, want %v", tc.nodeName, tc.field, result.ParentRelationship, expectedParentRelationship)
+			}
+		})
+	}
+}
+
 func TestNodeBinding(t *testing.T) {
 	expectedParentRelationship := enum.RelationshipPodBinding
 	testCases := []struct {
@@ -273,6 +299,112 @@ func TestServiceEndpointSlice(t *testing.T) {
 	}
 }
 
+func TestPersistentVolumeUnderPersistentVolumeClaim(t *testing.T) {
+	expectedParentRelationship := enum.RelationshipVolume
+	testCases := []struct {
+		name         string
+		pvcNamespace string
+		pvcName      string
+		pvName       string
+		expected     string
+	}{
+		{"All specified", "my-namespace", "my-pvc", "my-pv", "core/v1#persistentvolumeclaim#my-namespace#my-pvc#my-pv[persistentvolume]"},
+		{"Empty pv name", "my-namespace", "my-pvc", "", "core/v1#persistentvolumeclaim#my-namespace#my-pvc#unknown[persistentvolume]"},
+		{"All empty", "", "", "", "core/v1#persistentvolumeclaim#unknown#unknown#unknown[persistentvolume]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := PersistentVolumeUnderPersistentVolumeClaim(tc.pvcNamespace, tc.pvcName, tc.pvName)
+			if result.Path != tc.expected {
+				t.Errorf("PersistentVolumeUnderPersistentVolumeClaim(%v,%v,%v).Path = %v, want %v", tc.pvcNamespace, tc.pvcName, tc.pvName, result.Path, tc.expected)
+			}
+			if result.ParentRelationship != expectedParentRelationship {
+				t.Errorf("PersistentVolumeUnderPersistentVolumeClaim(%v,%v,%v).ParentRelationship = %v, want %v", tc.pvcNamespace, tc.pvcName, tc.pvName, result.ParentRelationship, expectedParentRelationship)
+			}
+		})
+	}
+}
+
+func TestPersistentVolumeClaimUnderPersistentVolume(t *testing.T) {
+	expectedParentRelationship := enum.RelationshipVolume
+	testCases := []struct {
+		name         string
+		pvName       string
+		pvcNamespace string
+		pvcName      string
+		expected     string
+	}{
+		{"All specified", "my-pv", "my-namespace", "my-pvc", "core/v1#persistentvolume#cluster-scope#my-pv#my-pvc(my-namespace)[persistentvolumeclaim]"},
+		{"Empty pvc namespace", "my-pv", "", "my-pvc", "core/v1#persistentvolume#cluster-scope#my-pv#my-pvc(unknown)[persistentvolumeclaim]"},
+		{"All empty", "", "", "", "core/v1#persistentvolume#cluster-scope#unknown#unknown(unknown)[persistentvolumeclaim]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := PersistentVolumeClaimUnderPersistentVolume(tc.pvName, tc.pvcNamespace, tc.pvcName)
+			if result.Path != tc.expected {
+				t.Errorf("PersistentVolumeClaimUnderPersistentVolume(%v,%v,%v).Path = %v, want %v", tc.pvName, tc.pvcNamespace, tc.pvcName, result.Path, tc.expected)
+			}
+			if result.ParentRelationship != expectedParentRelationship {
+				t.Errorf("PersistentVolumeClaimUnderPersistentVolume(%v,%v,%v).ParentRelationship = %v, want %v", tc.pvName, tc.pvcNamespace, tc.pvcName, result.ParentRelationship, expectedParentRelationship)
+			}
+		})
+	}
+}
+
+func TestPodUnderPersistentVolumeClaim(t *testing.T) {
+	expectedParentRelationship := enum.RelationshipVolume
+	testCases := []struct {
+		name      string
+		namespace string
+		pvcName   string
+		podName   string
+		expected  string
+	}{
+		{"All specified", "my-namespace", "my-pvc", "my-pod", "core/v1#persistentvolumeclaim#my-namespace#my-pvc#my-pod[pod]"},
+		{"Empty pod name", "my-namespace", "my-pvc", "", "core/v1#persistentvolumeclaim#my-namespace#my-pvc#unknown[pod]"},
+		{"All empty", "", "", "", "core/v1#persistentvolumeclaim#unknown#unknown#unknown[pod]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := PodUnderPersistentVolumeClaim(tc.namespace, tc.pvcName, tc.podName)
+			if result.Path != tc.expected {
+				t.Errorf("PodUnderPersistentVolumeClaim(%v,%v,%v).Path = %v, want %v", tc.namespace, tc.pvcName, tc.podName, result.Path, tc.expected)
+			}
+			if result.ParentRelationship != expectedParentRelationship {
+				t.Errorf("PodUnderPersistentVolumeClaim(%v,%v,%v).ParentRelationship = %v, want %v", tc.namespace, tc.pvcName, tc.podName, result.ParentRelationship, expectedParentRelationship)
+			}
+		})
+	}
+}
+
+func TestVolumeAttachmentUnderResource(t *testing.T) {
+	expectedParentRelationship := enum.RelationshipVolume
+	testCases := []struct {
+		name                 string
+		parent               ResourcePath
+		volumeAttachmentName string
+		expected             string
+	}{
+		{"All specified", ResourcePath{Path: "foo"}, "csi-1234", "foo#csi-1234[volumeattachment]"},
+		{"Empty volumeattachment name", ResourcePath{Path: "foo"}, "", "foo#unknown[volumeattachment]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := VolumeAttachmentUnderResource(tc.parent, tc.volumeAttachmentName)
+			if result.Path != tc.expected {
+				t.Errorf("VolumeAttachmentUnderResource(%v,%v).Path = %v, want %v", tc.parent, tc.volumeAttachmentName, result.Path, tc.expected)
+			}
+			if result.ParentRelationship != expectedParentRelationship {
+				t.Errorf("VolumeAttachmentUnderResource(%v,%v).ParentRelationship = %v, want %v", tc.parent, tc.volumeAttachmentName, result.ParentRelationship, expectedParentRelationship)
+			}
+		})
+	}
+}
+
 func TestOperation(t *testing.T) {
 	expectedParentRelationship := enum.RelationshipOperation
 	testCases := []struct {
@@ -326,6 +458,75 @@ func TestStatus(t *testing.T) {
 	}
 }
 
+func TestScaling(t *testing.T) {
+	expectedParentRelationship := enum.RelationshipReplicaScaling
+	result := Scaling(ResourcePath{Path: "apps/v1#deployment#default#web"})
+	if result.Path != "apps/v1#deployment#default#web#scaling" {
+		t.Errorf("Scaling().Path = %v, want %v", result.Path, "apps/v1#deployment#default#web#scaling")
+	}
+	if result.ParentRelationship != expectedParentRelationship {
+		t.Errorf("Scaling().ParentRelationship = %v, want %v", result.ParentRelationship, expectedParentRelationship)
+	}
+}
+
+func TestJobExecution(t *testing.T) {
+	expectedParentRelationship := enum.RelationshipJobExecution
+	result := JobExecution(ResourcePath{Path: "batch/v1#job#default#backup"})
+	if result.Path != "batch/v1#job#default#backup#execution" {
+		t.Errorf("JobExecution().Path = %v, want %v", result.Path, "batch/v1#job#default#backup#execution")
+	}
+	if result.ParentRelationship != expectedParentRelationship {
+		t.Errorf("JobExecution().ParentRelationship = %v, want %v", result.ParentRelationship, expectedParentRelationship)
+	}
+}
+
+func TestCronJobSchedule(t *testing.T) {
+	expectedParentRelationship := enum.RelationshipJobExecution
+	result := CronJobSchedule(ResourcePath{Path: "batch/v1#cronjob#default#backup"})
+	if result.Path != "batch/v1#cronjob#default#backup#schedule" {
+		t.Errorf("CronJobSchedule().Path = %v, want %v", result.Path, "batch/v1#cronjob#default#backup#schedule")
+	}
+	if result.ParentRelationship != expectedParentRelationship {
+		t.Errorf("CronJobSchedule().ParentRelationship = %v, want %v", result.ParentRelationship, expectedParentRelationship)
+	}
+}
+
+func TestJobUnderCronJob(t *testing.T) {
+	expectedParentRelationship := enum.RelationshipJobExecution
+	testCases := []struct {
+		name     string
+		cronJob  ResourcePath
+		jobName  string
+		expected string
+	}{
+		{"All specified", ResourcePath{Path: "foo"}, "backup-28912345", "foo#backup-28912345[job]"},
+		{"Empty job name", ResourcePath{Path: "foo"}, "", "foo#unknown[job]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := JobUnderCronJob(tc.cronJob, tc.jobName)
+			if result.Path != tc.expected {
+				t.Errorf("JobUnderCronJob(%v,%v).Path = %v, want %v", tc.cronJob, tc.jobName, result.Path, tc.expected)
+			}
+			if result.ParentRelationship != expectedParentRelationship {
+				t.Errorf("JobUnderCronJob(%v,%v).ParentRelationship = %v, want %v", tc.cronJob, tc.jobName, result.ParentRelationship, expectedParentRelationship)
+			}
+		})
+	}
+}
+
+func TestRollout(t *testing.T) {
+	expectedParentRelationship := enum.RelationshipRollout
+	result := Rollout(ResourcePath{Path: "apps/v1#deployment#default#web"})
+	if result.Path != "apps/v1#deployment#default#web#rollout" {
+		t.Errorf("Rollout().Path = %v, want %v", result.Path, "apps/v1#deployment#default#web#rollout")
+	}
+	if result.ParentRelationship != expectedParentRelationship {
+		t.Errorf("Rollout().ParentRelationship = %v, want %v", result.ParentRelationship, expectedParentRelationship)
+	}
+}
+
 func TestNetworkEndpointGroupUnderResource(t *testing.T) {
 	expectedParentRelationship := enum.RelationshipNetworkEndpointGroup
 	testCases := []struct {
@@ -61,3 +61,20 @@ func Node(name string) ResourcePath {
 	}
 	return NameLayerGeneralItem("core/v1", "node", "cluster-scope", name)
 }
+
+func PersistentVolumeClaim(namespace string, name string) ResourcePath {
+	if namespace == "" {
+		namespace = nonSpecifiedPlaceholder
+	}
+	if name == "" {
+		name = nonSpecifiedPlaceholder
+	}
+	return NameLayerGeneralItem("core/v1", "persistentvolumeclaim", namespace, name)
+}
+
+func PersistentVolume(name string) ResourcePath {
+	if name == "" {
+		name = nonSpecifiedPlaceholder
+	}
+	return NameLayerGeneralItem("core/v1", "persistentvolume", "cluster-scope", name)
+}
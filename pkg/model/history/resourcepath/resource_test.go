@@ -98,3 +98,51 @@ func TestNode(t *testing.T) {
 		})
 	}
 }
+
+func TestPersistentVolumeClaim(t *testing.T) {
+	testCases := []struct {
+		name      string
+		namespace string
+		pvcName   string
+		expected  string
+	}{
+		{"All specified", "my-namespace", "my-pvc", "core/v1#persistentvolumeclaim#my-namespace#my-pvc"},
+		{"Empty namespace", "", "my-pvc", "core/v1#persistentvolumeclaim#unknown#my-pvc"},
+		{"Empty pvc name", "my-namespace", "", "core/v1#persistentvolumeclaim#my-namespace#unknown"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := PersistentVolumeClaim(tc.namespace, tc.pvcName)
+			if result.Path != tc.expected {
+				t.Errorf("got unexpected path %q, want %q", result.Path, tc.expected)
+			}
+			if result.ParentRelationship != enum.RelationshipChild {
+				t.Errorf("got unexpected relationship %q, want %q", result.ParentRelationship, enum.RelationshipChild)
+			}
+		})
+	}
+}
+
+func TestPersistentVolume(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pvName   string
+		expected string
+	}{
+		{"PV name specified", "my-pv", "core/v1#persistentvolume#cluster-scope#my-pv"},
+		{"Empty PV name", "", "core/v1#persistentvolume#cluster-scope#unknown"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := PersistentVolume(tc.pvName)
+			if result.Path != tc.expected {
+				t.Errorf("got unexpected path %q, want %q", result.Path, tc.expected)
+			}
+			if result.ParentRelationship != enum.RelationshipChild {
+				t.Errorf("got unexpected relationship %q, want %q", result.ParentRelationship, enum.RelationshipChild)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package khifile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/idgenerator"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+)
+
+// MergeSource is a .khi file given to Merge.
+type MergeSource struct {
+	Reader *Reader
+	// ClusterName is prefixed to the first fragment of every resource path in this source when it's not empty.
+	// Use this to keep resources of different clusters with the same name apart.
+	ClusterName string
+}
+
+// ClusterPrefixedResourcePath returns the resource path with the cluster name prefixed to its first fragment.
+// The count of fragments is kept because the frontend regards it as the layer of the timeline.
+func ClusterPrefixedResourcePath(clusterName string, resourcePath string) string {
+	if clusterName == "" {
+		return resourcePath
+	}
+	return clusterName + ":" + resourcePath
+}
+
+// merger accumulates the histories of the sources into a single History.
+type merger struct {
+	result        *history.History
+	binaryBuilder *binarychunk.Builder
+	logIDs        map[mergedLogKey]string
+	resources     map[string]*history.Resource
+	timelines     map[string]*history.ResourceTimeline
+	timelineIDs   idgenerator.IDGenerator
+}
+
+// mergedLogKey identifies a log among the sources. Log IDs are only unique in the process generated the file,
+// thus logs are regarded as identical only when their timestamps and bodies are also the same.
+type mergedLogKey struct {
+	id        string
+	timestamp int64
+	// body is the reference rebased to the result. binarychunk.Builder returns the same reference for the same data.
+	body binarychunk.BinaryReference
+}
+
+// Merge combines the Logs, Timelines and Resources of the sources into a History whose BinaryReferences point the data written in the binaryBuilder.
+// Log IDs are prefixed with the index of the source to keep them unique, and revisions and events are rebound to the new IDs.
+// Logs with the same ID, timestamp and body are regarded as identical and only the first one is kept. Resources at the same path share a timeline containing the revisions and events of all sources.
+// The metadata of the first source is used for the result, with its time range expanded to cover all sources.
+func Merge(sources []*MergeSource, binaryBuilder *binarychunk.Builder) (*history.History, error) {
+	if len(sources) == 0 {
+		return nil, errors.New("no source is given to merge")
+	}
+	m := &merger{
+		result:        history.NewHistory(),
+		binaryBuilder: binaryBuilder,
+		logIDs:        map[mergedLogKey]string{},
+		resources:     map[string]*history.Resource{},
+		timelines:     map[string]*history.ResourceTimeline{},
+		timelineIDs:   idgenerator.NewPrefixIDGenerator("t"),
+	}
+	for i, source := range sources {
+		if err := m.add(i, source); err != nil {
+			return nil, fmt.Errorf("failed to merge source %d: %w", i, err)
+		}
+	}
+	m.mergeMetadata(sources)
+	m.sort()
+	return m.result, nil
+}
+
+// MergeTo merges the sources and writes the result to the writer in the .khi format. Returns the written size in bytes.
+// Binary chunks are compressed with the codec of the first source. Temporary files used for building binary chunks are created in the tmpFolder.
+func MergeTo(ctx context.Context, writer io.Writer, sources []*MergeSource, tmpFolder string) (int, error) {
+	if len(sources) == 0 {
+		return 0, errors.New("no source is given to merge")
+	}
+	binaryBuilder, err := newBinaryBuilder(sources[0].Reader.Codec(), tmpFolder)
+	if err != nil {
+		return 0, err
+	}
+	merged, err := Merge(sources, binaryBuilder)
+	if err != nil {
+		return 0, err
+	}
+	return Write(ctx, writer, merged, binaryBuilder)
+}
+
+func (m *merger) add(sourceIndex int, source *MergeSource) error {
+	rewritePath := func(path string) string {
+		return ClusterPrefixedResourcePath(source.ClusterName, path)
+	}
+	rebaser := newRebaser(source.Reader, m.binaryBuilder, rewritePath)
+	h := source.Reader.History()
+	// logIDs maps the log IDs in the source to the IDs in the result.
+	logIDs := make(map[string]string, len(h.Logs))
+	for _, l := range h.Logs {
+		key := mergedLogKey{id: l.ID, timestamp: l.Timestamp.UnixNano()}
+		body, err := rebaser.reference(DataKindLogBody, l.Body)
+		if err != nil {
+			return fmt.Errorf("failed to copy log %s: %w", l.ID, err)
+		}
+		if body != nil {
+			key.body = *body
+		}
+		if mergedID, found := m.logIDs[key]; found {
+			logIDs[l.ID] = mergedID
+			continue
+		}
+		rebased, err := rebaser.log(l)
+		if err != nil {
+			return fmt.Errorf("failed to copy log %s: %w", l.ID, err)
+		}
+		rebased.ID = fmt.Sprintf("%d-%s", sourceIndex, l.ID)
+		m.logIDs[key] = rebased.ID
+		logIDs[l.ID] = rebased.ID
+		m.result.Logs = append(m.result.Logs, rebased)
+	}
+	rewriteLogID := func(id string) string {
+		if mergedID, found := logIDs[id]; found {
+			return mergedID
+		}
+		return id
+	}
+
+	sourceTimelines := map[string]*history.ResourceTimeline{}
+	for _, timeline := range h.Timelines {
+		sourceTimelines[timeline.ID] = timeline
+	}
+	// mergedTimelines maps the timeline IDs in the source to the timelines in the result.
+	mergedTimelines := map[string]*history.ResourceTimeline{}
+	var addResources func(parentChildren *[]*history.Resource, resources []*history.Resource) error
+	addResources = func(parentChildren *[]*history.Resource, resources []*history.Resource) error {
+		for _, resource := range resources {
+			path := rewritePath(resource.FullResourcePath)
+			merged, found := m.resources[path]
+			if !found {
+				merged = &history.Resource{
+					ResourceName:     resource.ResourceName,
+					Relationship:     resource.Relationship,
+					FullResourcePath: path,
+					Children:         []*history.Resource{},
+				}
+				// The cluster name is prefixed to the first fragment, that is the name of the root resource.
+				if !strings.Contains(resource.FullResourcePath, "#") {
+					merged.ResourceName = path
+				}
+				m.resources[path] = merged
+				*parentChildren = append(*parentChildren, merged)
+			}
+			if resource.Timeline != "" {
+				timeline, err := m.mergeTimeline(rebaser, rewriteLogID, sourceTimelines, mergedTimelines, resource.Timeline, merged.Timeline)
+				if err != nil {
+					return fmt.Errorf("failed to copy the timeline of %s: %w", resource.FullResourcePath, err)
+				}
+				merged.Timeline = timeline.ID
+			}
+			if err := addResources(&merged.Children, resource.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return addResources(&m.result.Resources, h.Resources)
+}
+
+// mergeTimeline copies the timeline of the source into the timeline of the result with destinationID, or into a new timeline when destinationID is empty.
+// A source timeline shared by multiple resources is copied only once. The log IDs of revisions and events are rewritten with rewriteLogID.
+func (m *merger) mergeTimeline(rebaser *rebaser, rewriteLogID func(id string) string, sourceTimelines map[string]*history.ResourceTimeline, mergedTimelines map[string]*history.ResourceTimeline, sourceID string, destinationID string) (*history.ResourceTimeline, error) {
+	if merged, found := mergedTimelines[sourceID]; found {
+		return merged, nil
+	}
+	source, found := sourceTimelines[sourceID]
+	if !found {
+		return nil, fmt.Errorf("timeline %s is not found", sourceID)
+	}
+	destination, found := m.timelines[destinationID]
+	if !found {
+		destination = &history.ResourceTimeline{
+			ID:        m.timelineIDs.Generate(),
+			Revisions: []*history.ResourceRevision{},
+			Events:    []*history.ResourceEvent{},
+		}
+		m.timelines[destination.ID] = destination
+		m.result.Timelines = append(m.result.Timelines, destination)
+	}
+	for _, revision := range source.Revisions {
+		rebased, err := rebaser.revision(revision)
+		if err != nil {
+			return nil, err
+		}
+		rebased.Log = rewriteLogID(revision.Log)
+		destination.Revisions = append(destination.Revisions, rebased)
+	}
+	for _, event := range source.Events {
+		copied := *event
+		copied.Log = rewriteLogID(event.Log)
+		destination.Events = append(destination.Events, &copied)
+	}
+	mergedTimelines[sourceID] = destination
+	return destination, nil
+}
+
+// mergeMetadata uses the metadata of the first source and expands its time range to cover all sources.
+func (m *merger) mergeMetadata(sources []*MergeSource) {
+	m.result.Metadata = maps.Clone(sources[0].Reader.History().Metadata)
+	if m.result.Metadata == nil {
+		m.result.Metadata = map[string]any{}
+	}
+	var start, end int64
+	foundAny := false
+	for _, source := range sources {
+		sourceStart, sourceEnd, found := headerTimeRange(source.Reader.History().Metadata)
+		if !found {
+			continue
+		}
+		if !foundAny || sourceStart < start {
+			start = sourceStart
+		}
+		if !foundAny || sourceEnd > end {
+			end = sourceEnd
+		}
+		foundAny = true
+	}
+	if foundAny {
+		setHeaderTimeRange(m.result.Metadata, start, end)
+	}
+}
+
+// sort sorts logs by their timestamps, and revisions and events in timelines by their time after removing duplicated ones found in multiple sources.
+func (m *merger) sort() {
+	slices.SortStableFunc(m.result.Logs, func(a, b *history.SerializableLog) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	logIndices := make(map[string]int, len(m.result.Logs))
+	for i, l := range m.result.Logs {
+		logIndices[l.ID] = i
+	}
+	for _, timeline := range m.result.Timelines {
+		slices.SortStableFunc(timeline.Revisions, func(a, b *history.ResourceRevision) int {
+			return a.ChangeTime.Compare(b.ChangeTime)
+		})
+		timeline.Revisions = uniqueRevisions(timeline.Revisions)
+		slices.SortStableFunc(timeline.Events, func(a, b *history.ResourceEvent) int {
+			return logIndices[a.Log] - logIndices[b.Log]
+		})
+		timeline.Events = slices.CompactFunc(timeline.Events, func(a, b *history.ResourceEvent) bool {
+			return a.Log == b.Log
+		})
+	}
+}
+
+// revisionKey is the comparable representation of a ResourceRevision to find duplicated revisions.
+type revisionKey struct {
+	log        string
+	verb       enum.RevisionVerb
+	state      enum.RevisionState
+	changeTime int64
+	body       binarychunk.BinaryReference
+	requestor  binarychunk.BinaryReference
+}
+
+// uniqueRevisions removes the revisions identical to another revision preceding in the list.
+// Rebased references of identical data are identical because binarychunk.Builder deduplicates written data.
+func uniqueRevisions(revisions []*history.ResourceRevision) []*history.ResourceRevision {
+	seen := map[revisionKey]struct{}{}
+	return slices.DeleteFunc(revisions, func(revision *history.ResourceRevision) bool {
+		key := revisionKey{
+			log:        revision.Log,
+			verb:       revision.Verb,
+			state:      revision.State,
+			changeTime: revision.ChangeTime.UnixNano(),
+		}
+		if revision.Body != nil {
+			key.body = *revision.Body
+		}
+		if revision.Requestor != nil {
+			key.requestor = *revision.Requestor
+		}
+		if _, found := seen[key]; found {
+			return true
+		}
+		seen[key] = struct{}{}
+		return false
+	})
+}
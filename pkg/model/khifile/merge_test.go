@@ -0,0 +1,452 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package khifile
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structured"
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	"github.com/google/go-cmp/cmp"
+)
+
+// testLog is a log in a test .khi file. The body is also used as the ID.
+type testLog struct {
+	body          string
+	second        int
+	referencePath string
+}
+
+// testRevision is a revision in a test .khi file. The body is also used as the log ID.
+type testRevision struct {
+	body   string
+	second int
+}
+
+// testTimeline is a timeline of a resource in a test .khi file. The events are the bodies of the logs associated with them.
+type testTimeline struct {
+	path      string
+	revisions []testRevision
+	events    []string
+}
+
+// testKHIFile is the simplified content of a .khi file used to build and verify files in merge tests.
+type testKHIFile struct {
+	startTime int64
+	endTime   int64
+	logs      []testLog
+	timelines []testTimeline
+}
+
+// testTime returns the time used in test files from the seconds.
+func testTime(second int) time.Time {
+	return time.Date(2025, time.January, 1, 0, 0, second, 0, time.UTC)
+}
+
+func buildTestKHIFileFromContent(t *testing.T, content *testKHIFile) *Reader {
+	t.Helper()
+	return buildTestKHIFileFromContentWithCodec(t, content, binarychunk.CodecGzip)
+}
+
+func buildTestKHIFileFromContentWithCodec(t *testing.T, content *testKHIFile, codec binarychunk.Codec) *Reader {
+	t.Helper()
+	builder, err := history.NewBuilderWithCodec(t.TempDir(), codec)
+	if err != nil {
+		t.Fatalf("failed to create the builder: %v", err)
+	}
+	for _, l := range content.logs {
+		bodyRef, err := builder.BinaryBuilder.Write([]byte(l.body))
+		if err != nil {
+			t.Fatalf("failed to write body: %v", err)
+		}
+		annotations := []any{}
+		if l.referencePath != "" {
+			annotation, err := history.NewResourceReferenceAnnotation(l.referencePath).Serialize(builder.BinaryBuilder)
+			if err != nil {
+				t.Fatalf("failed to serialize the annotation: %v", err)
+			}
+			annotations = append(annotations, annotation)
+		}
+		builder.DangerouslyGetRawHistory().Logs = append(builder.DangerouslyGetRawHistory().Logs, &history.SerializableLog{
+			ID:          l.body,
+			Timestamp:   testTime(l.second),
+			Body:        bodyRef,
+			Type:        enum.LogTypeAudit,
+			Severity:    enum.SeverityInfo,
+			Annotations: annotations,
+		})
+	}
+	for _, timeline := range content.timelines {
+		builder.GetTimelineBuilder(timeline.path)
+		raw := builder.DangerouslyGetRawHistory()
+		// GetTimelineBuilder appends the new timeline at the end.
+		rt := raw.Timelines[len(raw.Timelines)-1]
+		for _, revision := range timeline.revisions {
+			bodyRef, err := builder.BinaryBuilder.Write([]byte(revision.body))
+			if err != nil {
+				t.Fatalf("failed to write revision: %v", err)
+			}
+			rt.Revisions = append(rt.Revisions, &history.ResourceRevision{Log: revision.body, Body: bodyRef, ChangeTime: testTime(revision.second), Verb: enum.RevisionVerbUpdate, State: enum.RevisionStateExisting})
+		}
+		for _, event := range timeline.events {
+			rt.Events = append(rt.Events, &history.ResourceEvent{Log: event})
+		}
+	}
+	var buf bytes.Buffer
+	metadata := map[string]any{
+		"header": map[string]any{
+			"inspectionType":       "test",
+			"startTimeUnixSeconds": content.startTime,
+			"endTimeUnixSeconds":   content.endTime,
+		},
+	}
+	if _, err := builder.Finalize(context.Background(), metadata, &buf, inspectionmetadata.NewTaskProgressMetadata("test")); err != nil {
+		t.Fatalf("failed to finalize the history: %v", err)
+	}
+	reader, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("NewReader() returned an unexpected error: %v", err)
+	}
+	return reader
+}
+
+// readTestKHIFileContent resolves the references in the .khi file and returns its simplified content.
+func readTestKHIFileContent(t *testing.T, reader *Reader) *testKHIFile {
+	t.Helper()
+	h := reader.History()
+	result := &testKHIFile{}
+	result.startTime, result.endTime, _ = headerTimeRange(h.Metadata)
+	logBodies := map[string]string{}
+	for _, l := range h.Logs {
+		body, err := reader.ReadString(l.Body)
+		if err != nil {
+			t.Fatalf("failed to read the log body: %v", err)
+		}
+		logBodies[l.ID] = body
+		log := testLog{body: body, second: l.Timestamp.Second()}
+		for _, annotation := range l.Annotations {
+			ref, _ := asBinaryReference(annotation.(map[string]any)["path"])
+			if log.referencePath, err = reader.ReadString(ref); err != nil {
+				t.Fatalf("failed to read the annotation path: %v", err)
+			}
+		}
+		result.logs = append(result.logs, log)
+	}
+	timelines := map[string]*history.ResourceTimeline{}
+	for _, timeline := range h.Timelines {
+		timelines[timeline.ID] = timeline
+	}
+	var walk func(resources []*history.Resource)
+	walk = func(resources []*history.Resource) {
+		for _, resource := range resources {
+			if timeline, found := timelines[resource.Timeline]; found {
+				content := testTimeline{path: resource.FullResourcePath}
+				for _, revision := range timeline.Revisions {
+					body, err := reader.ReadString(revision.Body)
+					if err != nil {
+						t.Fatalf("failed to read the revision body: %v", err)
+					}
+					content.revisions = append(content.revisions, testRevision{body: body, second: revision.ChangeTime.Second()})
+				}
+				for _, event := range timeline.Events {
+					body, found := logBodies[event.Log]
+					if !found {
+						t.Fatalf("log %s associated with an event of %s is not found", event.Log, resource.FullResourcePath)
+					}
+					content.events = append(content.events, body)
+				}
+				result.timelines = append(result.timelines, content)
+			}
+			walk(resource.Children)
+		}
+	}
+	walk(h.Resources)
+	return result
+}
+
+func TestMergeTo(t *testing.T) {
+	fileA := &testKHIFile{
+		startTime: 100,
+		endTime:   200,
+		logs: []testLog{
+			{body: "log-a1", second: 1},
+			{body: "log-shared", second: 2},
+		},
+		timelines: []testTimeline{
+			{path: "core/v1#pod#default#nginx", revisions: []testRevision{{body: "rev-a", second: 5}}, events: []string{"log-shared"}},
+		},
+	}
+	fileB := &testKHIFile{
+		startTime: 50,
+		endTime:   150,
+		logs: []testLog{
+			{body: "log-shared", second: 2},
+			{body: "log-b1", second: 0, referencePath: "core/v1#pod#default#other"},
+		},
+		timelines: []testTimeline{
+			{path: "core/v1#pod#default#nginx", revisions: []testRevision{{body: "rev-a", second: 5}, {body: "rev-b", second: 8}}, events: []string{"log-b1", "log-shared"}},
+			{path: "core/v1#pod#default#other", events: []string{"log-b1"}},
+		},
+	}
+	testCases := []struct {
+		name         string
+		clusterNames []string
+		want         *testKHIFile
+	}{
+		{
+			name:         "without cluster names",
+			clusterNames: []string{"", ""},
+			want: &testKHIFile{
+				startTime: 50,
+				endTime:   200,
+				logs: []testLog{
+					{body: "log-b1", second: 0, referencePath: "core/v1#pod#default#other"},
+					{body: "log-a1", second: 1},
+					{body: "log-shared", second: 2},
+				},
+				timelines: []testTimeline{
+					{path: "core/v1#pod#default#nginx", revisions: []testRevision{{body: "rev-a", second: 5}, {body: "rev-b", second: 8}}, events: []string{"log-b1", "log-shared"}},
+					{path: "core/v1#pod#default#other", events: []string{"log-b1"}},
+				},
+			},
+		},
+		{
+			name:         "with cluster names",
+			clusterNames: []string{"cluster-a", "cluster-b"},
+			want: &testKHIFile{
+				startTime: 50,
+				endTime:   200,
+				logs: []testLog{
+					{body: "log-b1", second: 0, referencePath: "cluster-b:core/v1#pod#default#other"},
+					{body: "log-a1", second: 1},
+					{body: "log-shared", second: 2},
+				},
+				timelines: []testTimeline{
+					{path: "cluster-a:core/v1#pod#default#nginx", revisions: []testRevision{{body: "rev-a", second: 5}}, events: []string{"log-shared"}},
+					{path: "cluster-b:core/v1#pod#default#nginx", revisions: []testRevision{{body: "rev-a", second: 5}, {body: "rev-b", second: 8}}, events: []string{"log-b1", "log-shared"}},
+					{path: "cluster-b:core/v1#pod#default#other", events: []string{"log-b1"}},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sources := []*MergeSource{
+				{Reader: buildTestKHIFileFromContent(t, fileA), ClusterName: tc.clusterNames[0]},
+				{Reader: buildTestKHIFileFromContent(t, fileB), ClusterName: tc.clusterNames[1]},
+			}
+			var buf bytes.Buffer
+			size, err := MergeTo(context.Background(), &buf, sources, t.TempDir())
+			if err != nil {
+				t.Fatalf("MergeTo() returned an unexpected error: %v", err)
+			}
+			if size != buf.Len() {
+				t.Errorf("MergeTo() returned size %d, want %d", size, buf.Len())
+			}
+			merged, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			if err != nil {
+				t.Fatalf("NewReader() returned an unexpected error for the merged file: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, readTestKHIFileContent(t, merged), cmp.AllowUnexported(testKHIFile{}, testLog{}, testTimeline{}, testRevision{})); diff != "" {
+				t.Errorf("merged file mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMergeTo_KeepsCodecOfFirstSource(t *testing.T) {
+	content := &testKHIFile{
+		logs:      []testLog{{body: "log-1", second: 1}},
+		timelines: []testTimeline{{path: "core/v1#pod#default#nginx", revisions: []testRevision{{body: "log-1", second: 1}}}},
+	}
+	sources := []*MergeSource{
+		{Reader: buildTestKHIFileFromContentWithCodec(t, content, binarychunk.CodecZstd)},
+		{Reader: buildTestKHIFileFromContent(t, content)},
+	}
+	var buf bytes.Buffer
+	if _, err := MergeTo(context.Background(), &buf, sources, t.TempDir()); err != nil {
+		t.Fatalf("MergeTo() returned an unexpected error: %v", err)
+	}
+	merged, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("NewReader() returned an unexpected error for the merged file: %v", err)
+	}
+	if got := merged.Codec(); got != binarychunk.CodecZstd {
+		t.Errorf("Codec() of the merged file = %s, want %s", got, binarychunk.CodecZstd)
+	}
+	if diff := cmp.Diff(content.logs, readTestKHIFileContent(t, merged).logs, cmp.AllowUnexported(testLog{})); diff != "" {
+		t.Errorf("logs in the merged file mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMerge_NoSource(t *testing.T) {
+	if _, err := Merge(nil, nil); err == nil {
+		t.Errorf("Merge() returned nil error, want an error")
+	}
+}
+
+type testCommonFieldSetReader struct{}
+
+// FieldSetKind implements log.FieldSetReader.
+func (t *testCommonFieldSetReader) FieldSetKind() string {
+	return (&log.CommonFieldSet{}).Kind()
+}
+
+// Read implements log.FieldSetReader.
+func (t *testCommonFieldSetReader) Read(reader *structured.NodeReader) (log.FieldSet, error) {
+	timestamp, err := reader.ReadTimestamp("timestamp")
+	if err != nil {
+		return nil, err
+	}
+	return &log.CommonFieldSet{
+		DisplayID: reader.ReadStringOrDefault("insertId", "unknown"),
+		Timestamp: timestamp,
+		Severity:  enum.SeverityInfo,
+	}, nil
+}
+
+// buildTestKHIFileFromLogs builds a .khi file through the same path as inspections. Each log records a revision and an event on the pod named with its insertId.
+// Log IDs are reassigned from 1 to emulate a file generated by another process.
+func buildTestKHIFileFromLogs(t *testing.T, logYAMLs []string) *Reader {
+	t.Helper()
+	ctx := context.Background()
+	builder := history.NewBuilder(t.TempDir())
+	logs := []*log.Log{}
+	for i, logYAML := range logYAMLs {
+		l, err := log.NewLogFromYAMLString(logYAML)
+		if err != nil {
+			t.Fatalf("failed to parse the log: %v", err)
+		}
+		if err := l.SetFieldSetReader(&testCommonFieldSetReader{}); err != nil {
+			t.Fatalf("failed to read the common fields: %v", err)
+		}
+		l.ID = strconv.Itoa(i + 1)
+		logs = append(logs, l)
+	}
+	if err := builder.SerializeLogs(ctx, logs, func() {}); err != nil {
+		t.Fatalf("SerializeLogs() returned an unexpected error: %v", err)
+	}
+	err := builder.ParseLogsByGroups(ctx, logs, func(logIndex int, l *log.Log) *history.ChangeSet {
+		commonFieldSet := log.MustGetFieldSet(l, &log.CommonFieldSet{})
+		podPath := resourcepath.NameLayerGeneralItem("core/v1", "pod", "default", commonFieldSet.DisplayID)
+		cs := history.NewChangeSet(l)
+		cs.AddRevision(podPath, &history.StagingResourceRevision{
+			Verb:       enum.RevisionVerbUpdate,
+			State:      enum.RevisionStateExisting,
+			Body:       commonFieldSet.DisplayID,
+			ChangeTime: commonFieldSet.Timestamp,
+		})
+		cs.AddEvent(podPath)
+		return cs
+	})
+	if err != nil {
+		t.Fatalf("ParseLogsByGroups() returned an unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := builder.Finalize(ctx, map[string]any{}, &buf, inspectionmetadata.NewTaskProgressMetadata("test")); err != nil {
+		t.Fatalf("failed to finalize the history: %v", err)
+	}
+	reader, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("NewReader() returned an unexpected error: %v", err)
+	}
+	return reader
+}
+
+func TestMergeTo_LogIDsCollidingAcrossFiles(t *testing.T) {
+	sources := []*MergeSource{
+		{Reader: buildTestKHIFileFromLogs(t, []string{
+			"insertId: a1\ntimestamp: 2025-01-01T00:00:01Z\n",
+			"insertId: a2\ntimestamp: 2025-01-01T00:00:02Z\n",
+		})},
+		{Reader: buildTestKHIFileFromLogs(t, []string{
+			// The same log as the first log of the first file.
+			"insertId: a1\ntimestamp: 2025-01-01T00:00:01Z\n",
+			// A different log having the same ID as the second log of the first file.
+			"insertId: b2\ntimestamp: 2025-01-01T00:00:03Z\n",
+		})},
+	}
+	var buf bytes.Buffer
+	if _, err := MergeTo(context.Background(), &buf, sources, t.TempDir()); err != nil {
+		t.Fatalf("MergeTo() returned an unexpected error: %v", err)
+	}
+	merged, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("NewReader() returned an unexpected error for the merged file: %v", err)
+	}
+	h := merged.History()
+
+	logDisplayIDs := map[string]string{}
+	gotLogs := []string{}
+	for _, l := range h.Logs {
+		if _, found := logDisplayIDs[l.ID]; found {
+			t.Errorf("log ID %s is duplicated in the merged file", l.ID)
+		}
+		logDisplayIDs[l.ID] = l.DisplayId
+		gotLogs = append(gotLogs, l.DisplayId)
+	}
+	if diff := cmp.Diff([]string{"a1", "a2", "b2"}, gotLogs); diff != "" {
+		t.Errorf("logs in the merged file mismatch (-want +got):\n%s", diff)
+	}
+
+	timelines := map[string]*history.ResourceTimeline{}
+	for _, timeline := range h.Timelines {
+		timelines[timeline.ID] = timeline
+	}
+	gotPods := []string{}
+	var walk func(resources []*history.Resource)
+	walk = func(resources []*history.Resource) {
+		for _, resource := range resources {
+			walk(resource.Children)
+			timeline, found := timelines[resource.Timeline]
+			if !found {
+				continue
+			}
+			gotPods = append(gotPods, resource.ResourceName)
+			if len(timeline.Revisions) != 1 || len(timeline.Events) != 1 {
+				t.Errorf("timeline of %s has %d revisions and %d events, want 1 revision and 1 event", resource.FullResourcePath, len(timeline.Revisions), len(timeline.Events))
+			}
+			for _, revision := range timeline.Revisions {
+				body, err := merged.ReadString(revision.Body)
+				if err != nil {
+					t.Fatalf("failed to read the revision body: %v", err)
+				}
+				if got := logDisplayIDs[revision.Log]; got != body {
+					t.Errorf("revision %q of %s is bound to log %q", body, resource.FullResourcePath, got)
+				}
+			}
+			for _, event := range timeline.Events {
+				if got := logDisplayIDs[event.Log]; got != resource.ResourceName {
+					t.Errorf("event of %s is bound to log %q", resource.FullResourcePath, got)
+				}
+			}
+		}
+	}
+	walk(h.Resources)
+	slices.Sort(gotPods)
+	if diff := cmp.Diff([]string{"a1", "a2", "b2"}, gotPods); diff != "" {
+		t.Errorf("pods in the merged file mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package khifile provides a reader for the .khi inspection result files written by history.Builder.
+package khifile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+)
+
+// MagicBytes is the byte sequence placed at the beginning of every .khi file.
+const MagicBytes = history.FileMagicBytes
+
+// DefaultMaxCachedChunks is the default number of decompressed binary chunks kept in memory by a Reader.
+// Each chunk can be up to binarychunk.MAXIMUM_CHUNK_SIZE bytes after decompression.
+const DefaultMaxCachedChunks = 4
+
+// ErrInvalidMagicBytes is returned when the given source doesn't start with MagicBytes.
+var ErrInvalidMagicBytes = errors.New("the given data is not a KHI file")
+
+// chunkLocation is the location and the compression codec of a compressed binary chunk in the source.
+type chunkLocation struct {
+	offset int64
+	size   int64
+	codec  binarychunk.Codec
+}
+
+// Reader reads a .khi file.
+// The History section is parsed eagerly, while binary chunks are decompressed lazily when a BinaryReference pointing them is resolved.
+type Reader struct {
+	source  io.ReaderAt
+	closer  io.Closer
+	history *history.History
+	chunks  []chunkLocation
+
+	maxCachedChunks int
+	// cachedChunks holds the decompressed chunks. cacheOrder is the list of chunk indices ordered from least recently used.
+	cachedChunks map[int][]byte
+	cacheOrder   []int
+	cacheLock    sync.Mutex
+}
+
+// Open opens the .khi file at the given path. The returned Reader must be closed by the caller.
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	reader, err := NewReader(file, stat.Size())
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	reader.closer = file
+	return reader, nil
+}
+
+// NewReader parses the header and the History section of the .khi data given as the source with its size.
+// Both of the legacy format without the format version and the versioned format written by history.WriteFile are supported.
+func NewReader(source io.ReaderAt, size int64) (*Reader, error) {
+	header := make([]byte, len(MagicBytes)+4)
+	if _, err := source.ReadAt(header, 0); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrInvalidMagicBytes
+		}
+		return nil, fmt.Errorf("failed to read the file header: %w", err)
+	}
+	if string(header[:len(MagicBytes)]) != MagicBytes {
+		return nil, ErrInvalidMagicBytes
+	}
+	// The JSON size is written in little endian but the chunk sizes are written in big endian.
+	jsonSize := int64(binary.LittleEndian.Uint32(header[len(MagicBytes):]))
+	jsonOffset := int64(len(header))
+	chunkSizeLength := 4
+	var chunkCodecs []binarychunk.Codec
+	if uint32(jsonSize) == history.FileFormatVersionMarker {
+		versionedHeader := make([]byte, 4+8)
+		if _, err := source.ReadAt(versionedHeader, jsonOffset); err != nil {
+			return nil, fmt.Errorf("failed to read the versioned file header: %w", err)
+		}
+		version := binary.LittleEndian.Uint32(versionedHeader)
+		if version != history.FileFormatVersion64BitSize && version != history.FileFormatVersionChunkCodec {
+			return nil, fmt.Errorf("unsupported file format version %d", version)
+		}
+		jsonSize = int64(binary.LittleEndian.Uint64(versionedHeader[4:]))
+		jsonOffset += int64(len(versionedHeader))
+		chunkSizeLength = 8
+		if version == history.FileFormatVersionChunkCodec {
+			codecs, codecTableSize, err := readChunkCodecs(source, jsonOffset, size)
+			if err != nil {
+				return nil, err
+			}
+			chunkCodecs = codecs
+			jsonOffset += codecTableSize
+		}
+	}
+	if jsonSize < 0 || jsonOffset+jsonSize > size {
+		return nil, fmt.Errorf("history section size %d exceeds the file size %d", jsonSize, size)
+	}
+
+	var h history.History
+	if err := json.NewDecoder(io.NewSectionReader(source, jsonOffset, jsonSize)).Decode(&h); err != nil {
+		return nil, fmt.Errorf("failed to decode the history section: %w", err)
+	}
+
+	chunks, err := readChunkLocations(source, jsonOffset+jsonSize, size, chunkSizeLength)
+	if err != nil {
+		return nil, err
+	}
+	if chunkCodecs != nil {
+		if len(chunkCodecs) != len(chunks) {
+			return nil, fmt.Errorf("the file header has codecs of %d chunks but the file has %d chunks", len(chunkCodecs), len(chunks))
+		}
+		for i := range chunks {
+			chunks[i].codec = chunkCodecs[i]
+		}
+	}
+
+	return &Reader{
+		source:          source,
+		history:         &h,
+		chunks:          chunks,
+		maxCachedChunks: DefaultMaxCachedChunks,
+		cachedChunks:    map[int][]byte{},
+		cacheOrder:      []int{},
+	}, nil
+}
+
+// readChunkCodecs reads the chunk count and the codec of each chunk placed at the offset. Returns the codecs and the size of the read bytes.
+func readChunkCodecs(source io.ReaderAt, offset int64, size int64) ([]binarychunk.Codec, int64, error) {
+	countBuffer := make([]byte, 4)
+	if _, err := source.ReadAt(countBuffer, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to read the chunk count: %w", err)
+	}
+	count := int64(binary.LittleEndian.Uint32(countBuffer))
+	if offset+int64(len(countBuffer))+count > size {
+		return nil, 0, fmt.Errorf("chunk codecs of %d chunks exceed the file size %d", count, size)
+	}
+	codecBuffer := make([]byte, count)
+	if _, err := source.ReadAt(codecBuffer, offset+int64(len(countBuffer))); err != nil {
+		return nil, 0, fmt.Errorf("failed to read the chunk codecs: %w", err)
+	}
+	codecs := make([]binarychunk.Codec, count)
+	for i, codec := range codecBuffer {
+		codecs[i] = binarychunk.Codec(codec)
+	}
+	return codecs, int64(len(countBuffer)) + count, nil
+}
+
+// readChunkLocations walks the sequence of size prefixed binary chunks from the offset to the end of the source.
+// The sizes are 4 bytes in the legacy format and 8 bytes in the versioned format.
+func readChunkLocations(source io.ReaderAt, offset int64, size int64, sizeLength int) ([]chunkLocation, error) {
+	result := []chunkLocation{}
+	sizeBuffer := make([]byte, sizeLength)
+	for offset < size {
+		if _, err := source.ReadAt(sizeBuffer, offset); err != nil {
+			return nil, fmt.Errorf("failed to read the size of binary chunk %d at %d: %w", len(result), offset, err)
+		}
+		var chunkSize int64
+		if sizeLength == 8 {
+			chunkSize = int64(binary.BigEndian.Uint64(sizeBuffer))
+		} else {
+			chunkSize = int64(binary.BigEndian.Uint32(sizeBuffer))
+		}
+		offset += int64(len(sizeBuffer))
+		if chunkSize < 0 || offset+chunkSize > size {
+			return nil, fmt.Errorf("binary chunk %d at %d with size %d exceeds the file size %d", len(result), offset, chunkSize, size)
+		}
+		result = append(result, chunkLocation{offset: offset, size: chunkSize})
+		offset += chunkSize
+	}
+	return result, nil
+}
+
+// History returns the History parsed from the file.
+func (r *Reader) History() *history.History {
+	return r.history
+}
+
+// ChunkCount returns the count of binary chunks in the file.
+func (r *Reader) ChunkCount() int {
+	return len(r.chunks)
+}
+
+// Codec returns the compression codec of the binary chunks in the file.
+// Files written before codecs were recorded and files without chunks are regarded as gzip compressed.
+func (r *Reader) Codec() binarychunk.Codec {
+	if len(r.chunks) == 0 {
+		return binarychunk.CodecGzip
+	}
+	return r.chunks[0].codec
+}
+
+// SetMaxCachedChunks changes the maximum count of decompressed chunks kept in memory. Values less than 1 are treated as 1.
+func (r *Reader) SetMaxCachedChunks(count int) {
+	r.cacheLock.Lock()
+	defer r.cacheLock.Unlock()
+	r.maxCachedChunks = max(count, 1)
+	r.evictChunks()
+}
+
+// ReadChunk returns the decompressed binary chunk at the given index.
+// The returned slice is shared with the cache and must not be modified.
+func (r *Reader) ReadChunk(index int) ([]byte, error) {
+	if index < 0 || index >= len(r.chunks) {
+		return nil, fmt.Errorf("buffer index %d is out of the range", index)
+	}
+	r.cacheLock.Lock()
+	defer r.cacheLock.Unlock()
+	if chunk, found := r.cachedChunks[index]; found {
+		r.touchChunk(index)
+		return chunk, nil
+	}
+	location := r.chunks[index]
+	decompressor, err := binarychunk.NewDecompressor(location.codec, io.NewSectionReader(r.source, location.offset, location.size))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read binary chunk %d: %w", index, err)
+	}
+	defer decompressor.Close()
+	var chunk bytes.Buffer
+	if _, err := io.Copy(&chunk, decompressor); err != nil {
+		return nil, fmt.Errorf("failed to decompress binary chunk %d: %w", index, err)
+	}
+	r.cachedChunks[index] = chunk.Bytes()
+	r.touchChunk(index)
+	r.evictChunks()
+	return chunk.Bytes(), nil
+}
+
+// Read resolves the given BinaryReference and returns the referenced bytes.
+func (r *Reader) Read(ref *binarychunk.BinaryReference) ([]byte, error) {
+	if ref == nil {
+		return nil, fmt.Errorf("binary reference is nil")
+	}
+	chunk, err := r.ReadChunk(ref.Buffer)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Offset < 0 || ref.Length < 0 || ref.Offset+ref.Length > len(chunk) {
+		return nil, fmt.Errorf("binary reference (offset:%d,len:%d) is out of the range of buffer %d (size:%d)", ref.Offset, ref.Length, ref.Buffer, len(chunk))
+	}
+	result := make([]byte, ref.Length)
+	copy(result, chunk[ref.Offset:ref.Offset+ref.Length])
+	return result, nil
+}
+
+// ReadString resolves the given BinaryReference as a string. It returns an empty string for a nil reference
+// because optional fields like SerializableLog.Summary can be nil.
+func (r *Reader) ReadString(ref *binarychunk.BinaryReference) (string, error) {
+	if ref == nil {
+		return "", nil
+	}
+	result, err := r.Read(ref)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+// Close releases the underlying file when the Reader was created by Open.
+func (r *Reader) Close() error {
+	r.cacheLock.Lock()
+	r.cachedChunks = map[int][]byte{}
+	r.cacheOrder = []int{}
+	r.cacheLock.Unlock()
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+	return nil
+}
+
+// touchChunk marks the chunk as the most recently used one. cacheLock must be held by the caller.
+func (r *Reader) touchChunk(index int) {
+	r.cacheOrder = slices.DeleteFunc(r.cacheOrder, func(i int) bool { return i == index })
+	r.cacheOrder = append(r.cacheOrder, index)
+}
+
+// evictChunks removes the least recently used chunks exceeding maxCachedChunks. cacheLock must be held by the caller.
+func (r *Reader) evictChunks() {
+	for len(r.cacheOrder) > r.maxCachedChunks {
+		delete(r.cachedChunks, r.cacheOrder[0])
+		r.cacheOrder = r.cacheOrder[1:]
+	}
+}
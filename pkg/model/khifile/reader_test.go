@@ -0,0 +1,283 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package khifile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/google/go-cmp/cmp"
+)
+
+// buildTestKHIFile writes a .khi file containing a log per given body and returns the serialized bytes.
+func buildTestKHIFile(t *testing.T, bodies []string) []byte {
+	t.Helper()
+	return buildTestKHIFileWithCodec(t, bodies, binarychunk.CodecGzip)
+}
+
+// buildTestKHIFileWithCodec is same as buildTestKHIFile except the binary chunks are compressed with the given codec.
+func buildTestKHIFileWithCodec(t *testing.T, bodies []string, codec binarychunk.Codec) []byte {
+	t.Helper()
+	builder, err := history.NewBuilderWithCodec(t.TempDir(), codec)
+	if err != nil {
+		t.Fatalf("failed to create the builder: %v", err)
+	}
+	raw := builder.DangerouslyGetRawHistory()
+	for i, body := range bodies {
+		bodyRef, err := builder.BinaryBuilder.Write([]byte(body))
+		if err != nil {
+			t.Fatalf("failed to write body: %v", err)
+		}
+		summaryRef, err := builder.BinaryBuilder.Write([]byte("summary-" + body))
+		if err != nil {
+			t.Fatalf("failed to write summary: %v", err)
+		}
+		raw.Logs = append(raw.Logs, &history.SerializableLog{
+			ID:          body,
+			DisplayId:   body,
+			Timestamp:   time.Date(2025, time.January, 1, 0, 0, i, 0, time.UTC),
+			Body:        bodyRef,
+			Summary:     summaryRef,
+			Type:        enum.LogTypeAudit,
+			Severity:    enum.SeverityInfo,
+			Annotations: []any{},
+		})
+	}
+	var buf bytes.Buffer
+	_, err = builder.Finalize(context.Background(), map[string]any{"foo": "bar"}, &buf, inspectionmetadata.NewTaskProgressMetadata("test"))
+	if err != nil {
+		t.Fatalf("failed to finalize the history: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestReader(t *testing.T) {
+	bodies := []string{"log-1", "log-2", "log-3"}
+	data := buildTestKHIFile(t, bodies)
+	reader, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewReader() returned an unexpected error: %v", err)
+	}
+	defer reader.Close()
+
+	h := reader.History()
+	if diff := cmp.Diff(map[string]any{"foo": "bar"}, h.Metadata); diff != "" {
+		t.Errorf("History().Metadata mismatch (-want +got):\n%s", diff)
+	}
+	if reader.ChunkCount() != 1 {
+		t.Errorf("ChunkCount() = %d, want 1", reader.ChunkCount())
+	}
+	gotBodies := []string{}
+	gotSummaries := []string{}
+	for _, l := range h.Logs {
+		body, err := reader.ReadString(l.Body)
+		if err != nil {
+			t.Fatalf("ReadString(body) returned an unexpected error: %v", err)
+		}
+		summary, err := reader.ReadString(l.Summary)
+		if err != nil {
+			t.Fatalf("ReadString(summary) returned an unexpected error: %v", err)
+		}
+		gotBodies = append(gotBodies, body)
+		gotSummaries = append(gotSummaries, summary)
+	}
+	if diff := cmp.Diff(bodies, gotBodies); diff != "" {
+		t.Errorf("log bodies mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"summary-log-1", "summary-log-2", "summary-log-3"}, gotSummaries); diff != "" {
+		t.Errorf("log summaries mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReader_Read(t *testing.T) {
+	data := buildTestKHIFile(t, []string{"foo"})
+	reader, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewReader() returned an unexpected error: %v", err)
+	}
+	testCases := []struct {
+		name    string
+		ref     *binarychunk.BinaryReference
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "partial range of a buffer",
+			ref:  &binarychunk.BinaryReference{Buffer: 0, Offset: 1, Length: 2},
+			want: "oo",
+		},
+		{
+			name:    "nil reference",
+			ref:     nil,
+			wantErr: true,
+		},
+		{
+			name:    "buffer index out of range",
+			ref:     &binarychunk.BinaryReference{Buffer: 1, Offset: 0, Length: 1},
+			wantErr: true,
+		},
+		{
+			name:    "length out of range",
+			ref:     &binarychunk.BinaryReference{Buffer: 0, Offset: 0, Length: 1000},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := reader.Read(tc.ref)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Read() returned no error, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Read() returned an unexpected error: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("Read() = %q, want %q", string(got), tc.want)
+			}
+		})
+	}
+}
+
+func TestReader_Codec(t *testing.T) {
+	for _, codec := range []binarychunk.Codec{binarychunk.CodecGzip, binarychunk.CodecZstd} {
+		t.Run(codec.String(), func(t *testing.T) {
+			data := buildTestKHIFileWithCodec(t, []string{"foo", "bar"}, codec)
+			reader, err := NewReader(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatalf("NewReader() returned an unexpected error: %v", err)
+			}
+			defer reader.Close()
+			if diff := cmp.Diff(codec, reader.Codec()); diff != "" {
+				t.Errorf("Codec() mismatch (-want +got):\n%s", diff)
+			}
+			got, err := reader.ReadString(reader.History().Logs[1].Body)
+			if err != nil {
+				t.Fatalf("ReadString() returned an unexpected error: %v", err)
+			}
+			if got != "bar" {
+				t.Errorf("ReadString() = %q, want %q", got, "bar")
+			}
+		})
+	}
+}
+
+func TestReader_ChunkCache(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString(MagicBytes)
+	buf.Write([]byte{2, 0, 0, 0})
+	buf.WriteString("{}")
+	for _, chunk := range []string{"a", "b", "c"} {
+		var compressed bytes.Buffer
+		gzipWriter := gzip.NewWriter(&compressed)
+		gzipWriter.Write([]byte(chunk))
+		gzipWriter.Close()
+		size := make([]byte, 4)
+		binary.BigEndian.PutUint32(size, uint32(compressed.Len()))
+		buf.Write(size)
+		buf.Write(compressed.Bytes())
+	}
+
+	reader, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("NewReader() returned an unexpected error: %v", err)
+	}
+	reader.SetMaxCachedChunks(2)
+	for _, index := range []int{0, 1, 0, 2} {
+		if _, err := reader.ReadChunk(index); err != nil {
+			t.Fatalf("ReadChunk(%d) returned an unexpected error: %v", index, err)
+		}
+	}
+	if diff := cmp.Diff([]int{0, 2}, reader.cacheOrder); diff != "" {
+		t.Errorf("cached chunks mismatch (-want +got):\n%s", diff)
+	}
+	if len(reader.cachedChunks) != 2 {
+		t.Errorf("len(cachedChunks) = %d, want 2", len(reader.cachedChunks))
+	}
+	got, err := reader.ReadString(&binarychunk.BinaryReference{Buffer: 1, Offset: 0, Length: 1})
+	if err != nil {
+		t.Fatalf("ReadString() returned an unexpected error: %v", err)
+	}
+	if got != "b" {
+		t.Errorf("ReadString() = %q, want %q", got, "b")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	t.Run("opens a valid .khi file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "test.khi")
+		if err := os.WriteFile(path, buildTestKHIFile(t, []string{"foo"}), 0644); err != nil {
+			t.Fatalf("failed to write the test file: %v", err)
+		}
+		reader, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open() returned an unexpected error: %v", err)
+		}
+		defer reader.Close()
+		got, err := reader.ReadString(reader.History().Logs[0].Body)
+		if err != nil {
+			t.Fatalf("ReadString() returned an unexpected error: %v", err)
+		}
+		if got != "foo" {
+			t.Errorf("ReadString() = %q, want %q", got, "foo")
+		}
+	})
+	t.Run("returns ErrInvalidMagicBytes for non KHI file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "test.txt")
+		if err := os.WriteFile(path, []byte("this is not a khi file"), 0644); err != nil {
+			t.Fatalf("failed to write the test file: %v", err)
+		}
+		_, err := Open(path)
+		if !errors.Is(err, ErrInvalidMagicBytes) {
+			t.Errorf("Open() returned %v, want %v", err, ErrInvalidMagicBytes)
+		}
+	})
+	t.Run("returns an error for unsupported format version", func(t *testing.T) {
+		data := buildTestKHIFile(t, []string{"foo"})
+		binary.LittleEndian.PutUint32(data[len(MagicBytes)+4:], history.FileFormatVersion+1)
+		path := filepath.Join(t.TempDir(), "test.khi")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("failed to write the test file: %v", err)
+		}
+		_, err := Open(path)
+		if err == nil {
+			t.Errorf("Open() returned no error, want an error")
+		}
+	})
+	t.Run("returns an error for truncated file", func(t *testing.T) {
+		data := buildTestKHIFile(t, []string{"foo"})
+		path := filepath.Join(t.TempDir(), "test.khi")
+		if err := os.WriteFile(path, data[:len(data)-10], 0644); err != nil {
+			t.Fatalf("failed to write the test file: %v", err)
+		}
+		_, err := Open(path)
+		if err == nil {
+			t.Errorf("Open() returned no error, want an error")
+		}
+	})
+}
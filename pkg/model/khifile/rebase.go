@@ -0,0 +1,166 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package khifile
+
+import (
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+)
+
+// resourceReferenceAnnotationType is the type of the serialized history.ResourceReferenceAnnotation.
+const resourceReferenceAnnotationType = "resource_ref"
+
+// rebaserCacheKey is the key of the cache of rebased references. The same data can be rewritten differently depending on its kind.
+type rebaserCacheKey struct {
+	kind DataKind
+	ref  binarychunk.BinaryReference
+}
+
+// rebaser copies the data referenced from a History read by a Reader into another binarychunk.Builder
+// and returns the BinaryReferences pointing the copied data.
+type rebaser struct {
+	source      *Reader
+	destination *binarychunk.Builder
+	cache       map[rebaserCacheKey]*binarychunk.BinaryReference
+	// rewriteResourcePath rewrites the resource paths contained in the resource reference annotations of logs. It can be nil.
+	rewriteResourcePath func(path string) string
+	// transformer rewrites the copied data. The data is copied as is when it's nil.
+	transformer Transformer
+}
+
+func newRebaser(source *Reader, destination *binarychunk.Builder, rewriteResourcePath func(path string) string) *rebaser {
+	return &rebaser{
+		source:              source,
+		destination:         destination,
+		cache:               map[rebaserCacheKey]*binarychunk.BinaryReference{},
+		rewriteResourcePath: rewriteResourcePath,
+	}
+}
+
+// reference returns the BinaryReference in the destination pointing the same data as the given reference in the source after the transformation.
+func (r *rebaser) reference(kind DataKind, ref *binarychunk.BinaryReference) (*binarychunk.BinaryReference, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	key := rebaserCacheKey{kind: kind, ref: *ref}
+	if rebased, found := r.cache[key]; found {
+		return rebased, nil
+	}
+	data, err := r.source.Read(ref)
+	if err != nil {
+		return nil, err
+	}
+	if r.transformer != nil {
+		if data, err = r.transformer.Transform(kind, data); err != nil {
+			return nil, err
+		}
+	}
+	rebased, err := r.destination.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	r.cache[key] = rebased
+	return rebased, nil
+}
+
+// log returns a copy of the log with the references rebased.
+func (r *rebaser) log(l *history.SerializableLog) (*history.SerializableLog, error) {
+	result := *l
+	var err error
+	if result.Body, err = r.reference(DataKindLogBody, l.Body); err != nil {
+		return nil, err
+	}
+	if result.Summary, err = r.reference(DataKindLogSummary, l.Summary); err != nil {
+		return nil, err
+	}
+	result.Annotations = make([]any, 0, len(l.Annotations))
+	for _, annotation := range l.Annotations {
+		rebased, err := r.annotation(annotation)
+		if err != nil {
+			return nil, err
+		}
+		result.Annotations = append(result.Annotations, rebased)
+	}
+	return &result, nil
+}
+
+// annotation returns a copy of the annotation decoded from JSON with the references rebased.
+// Annotations are decoded as maps, so any map having the same fields as BinaryReference is regarded as a reference.
+func (r *rebaser) annotation(annotation any) (any, error) {
+	fields, ok := annotation.(map[string]any)
+	if !ok {
+		return annotation, nil
+	}
+	if ref, ok := asBinaryReference(fields); ok {
+		return r.reference(DataKindAnnotation, ref)
+	}
+	rewritePath := fields["type"] == resourceReferenceAnnotationType && r.rewriteResourcePath != nil
+	result := make(map[string]any, len(fields))
+	for key, value := range fields {
+		var rebased any
+		var err error
+		if ref, ok := asBinaryReference(value); ok && rewritePath && key == "path" {
+			rebased, err = r.resourcePath(ref)
+		} else {
+			rebased, err = r.annotation(value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[key] = rebased
+	}
+	return result, nil
+}
+
+// resourcePath writes the resource path referenced in the source after rewriting it with rewriteResourcePath.
+func (r *rebaser) resourcePath(ref *binarychunk.BinaryReference) (*binarychunk.BinaryReference, error) {
+	path, err := r.source.ReadString(ref)
+	if err != nil {
+		return nil, err
+	}
+	return r.destination.Write([]byte(r.rewriteResourcePath(path)))
+}
+
+// revision returns a copy of the revision with the references rebased.
+func (r *rebaser) revision(revision *history.ResourceRevision) (*history.ResourceRevision, error) {
+	result := *revision
+	var err error
+	if result.Requestor, err = r.reference(DataKindRevisionRequestor, revision.Requestor); err != nil {
+		return nil, err
+	}
+	if result.Body, err = r.reference(DataKindRevisionBody, revision.Body); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// asBinaryReference converts a BinaryReference decoded as a map back to the BinaryReference.
+func asBinaryReference(value any) (*binarychunk.BinaryReference, bool) {
+	fields, ok := value.(map[string]any)
+	if !ok || len(fields) != 3 {
+		return nil, false
+	}
+	offset, offsetOk := fields["offset"].(float64)
+	length, lengthOk := fields["len"].(float64)
+	buffer, bufferOk := fields["buffer"].(float64)
+	if !offsetOk || !lengthOk || !bufferOk {
+		return nil, false
+	}
+	return &binarychunk.BinaryReference{
+		Offset: int(offset),
+		Length: int(length),
+		Buffer: int(buffer),
+	}, true
+}
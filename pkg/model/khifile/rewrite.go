@@ -0,0 +1,124 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package khifile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+)
+
+// DataKind is the kind of the data referenced by a BinaryReference in a .khi file.
+type DataKind int
+
+const (
+	// DataKindLogBody is the body of a log. It's a YAML serialized structured data.
+	DataKindLogBody DataKind = iota
+	// DataKindLogSummary is the summary text of a log.
+	DataKindLogSummary
+	// DataKindAnnotation is the data referenced from an annotation of a log. (e.g. the resource path of a resource reference annotation)
+	DataKindAnnotation
+	// DataKindRevisionBody is the manifest of a resource revision. It's a YAML serialized structured data.
+	DataKindRevisionBody
+	// DataKindRevisionRequestor is the requestor of a resource revision.
+	DataKindRevisionRequestor
+	// DataKindMetadata is the metadata of the inspection stored in the History section. It's serialized in JSON and the transformer must return JSON.
+	DataKindMetadata
+)
+
+// Transformer rewrites the data referenced from a .khi file.
+type Transformer interface {
+	// Transform returns the rewritten data of the given kind.
+	Transform(kind DataKind, data []byte) ([]byte, error)
+}
+
+// Rewrite returns a History with the same logs, timelines and resources as the .khi file, but with the referenced data rewritten by the transformer
+// and written in the binaryBuilder. The original data is not included in the binaryBuilder unless the transformer returns it as is.
+func Rewrite(reader *Reader, transformer Transformer, binaryBuilder *binarychunk.Builder) (*history.History, error) {
+	source := reader.History()
+	rebaser := newRebaser(reader, binaryBuilder, nil)
+	rebaser.transformer = transformer
+
+	result := history.NewHistory()
+	result.Version = source.Version
+	metadata, err := transformMetadata(transformer, source.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rewrite the metadata: %w", err)
+	}
+	result.Metadata = metadata
+	result.Resources = source.Resources
+	for _, l := range source.Logs {
+		rebased, err := rebaser.log(l)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewrite log %s: %w", l.ID, err)
+		}
+		result.Logs = append(result.Logs, rebased)
+	}
+	for _, timeline := range source.Timelines {
+		rewritten := &history.ResourceTimeline{
+			ID:        timeline.ID,
+			Revisions: make([]*history.ResourceRevision, 0, len(timeline.Revisions)),
+			Events:    timeline.Events,
+		}
+		for _, revision := range timeline.Revisions {
+			rebased, err := rebaser.revision(revision)
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewrite a revision in timeline %s: %w", timeline.ID, err)
+			}
+			rewritten.Revisions = append(rewritten.Revisions, rebased)
+		}
+		result.Timelines = append(result.Timelines, rewritten)
+	}
+	return result, nil
+}
+
+// transformMetadata returns the metadata rewritten by the transformer. The metadata is cloned as is when the transformer is nil.
+func transformMetadata(transformer Transformer, metadata map[string]any) (map[string]any, error) {
+	if transformer == nil || metadata == nil {
+		return maps.Clone(metadata), nil
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	data, err = transformer.Transform(DataKindMetadata, data)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]any{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("the transformer returned an invalid JSON for the metadata: %w", err)
+	}
+	return result, nil
+}
+
+// RewriteTo rewrites the .khi file with the transformer and writes the result to the writer in the .khi format. Returns the written size in bytes.
+// Binary chunks are compressed with the codec of the source. Temporary files used for building binary chunks are created in the tmpFolder.
+func RewriteTo(ctx context.Context, writer io.Writer, reader *Reader, transformer Transformer, tmpFolder string) (int, error) {
+	binaryBuilder, err := newBinaryBuilder(reader.Codec(), tmpFolder)
+	if err != nil {
+		return 0, err
+	}
+	rewritten, err := Rewrite(reader, transformer, binaryBuilder)
+	if err != nil {
+		return 0, err
+	}
+	return Write(ctx, writer, rewritten, binaryBuilder)
+}
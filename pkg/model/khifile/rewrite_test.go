@@ -0,0 +1,114 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package khifile
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+	"github.com/google/go-cmp/cmp"
+)
+
+// prefixTransformer prefixes the kind to the log bodies and the revision bodies, and the inspection type in the metadata.
+type prefixTransformer struct{}
+
+func (p *prefixTransformer) Transform(kind DataKind, data []byte) ([]byte, error) {
+	switch kind {
+	case DataKindLogBody, DataKindRevisionBody:
+		return []byte(fmt.Sprintf("%d-%s", kind, data)), nil
+	case DataKindMetadata:
+		return bytes.ReplaceAll(data, []byte(`"inspectionType":"test"`), []byte(fmt.Sprintf(`"inspectionType":"%d-test"`, kind))), nil
+	default:
+		return data, nil
+	}
+}
+
+func TestRewriteTo(t *testing.T) {
+	source := &testKHIFile{
+		startTime: testTime(0).Unix(),
+		endTime:   testTime(10).Unix(),
+		logs: []testLog{
+			{body: "log-1", second: 1, referencePath: "core/v1#pod#default#p1"},
+			{body: "log-2", second: 2},
+		},
+		timelines: []testTimeline{
+			{path: "core/v1#pod#default#p1", revisions: []testRevision{{body: "log-1", second: 1}}, events: []string{"log-2"}},
+		},
+	}
+	want := &testKHIFile{
+		startTime: testTime(0).Unix(),
+		endTime:   testTime(10).Unix(),
+		logs: []testLog{
+			{body: fmt.Sprintf("%d-log-1", DataKindLogBody), second: 1, referencePath: "core/v1#pod#default#p1"},
+			{body: fmt.Sprintf("%d-log-2", DataKindLogBody), second: 2},
+		},
+		timelines: []testTimeline{
+			{path: "core/v1#pod#default#p1", revisions: []testRevision{{body: fmt.Sprintf("%d-log-1", DataKindRevisionBody), second: 1}}, events: []string{fmt.Sprintf("%d-log-2", DataKindLogBody)}},
+		},
+	}
+
+	var buf bytes.Buffer
+	_, err := RewriteTo(context.Background(), &buf, buildTestKHIFileFromContent(t, source), &prefixTransformer{}, t.TempDir())
+	if err != nil {
+		t.Fatalf("RewriteTo() returned an unexpected error: %v", err)
+	}
+	rewritten, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("NewReader() returned an unexpected error for the rewritten file: %v", err)
+	}
+	if diff := cmp.Diff(want, readTestKHIFileContent(t, rewritten), cmp.AllowUnexported(testKHIFile{}, testLog{}, testTimeline{}, testRevision{})); diff != "" {
+		t.Errorf("rewritten file mismatch (-want +got):\n%s", diff)
+	}
+	wantInspectionType := fmt.Sprintf("%d-test", DataKindMetadata)
+	if got := rewritten.History().Metadata[headerMetadataKey].(map[string]any)["inspectionType"]; got != wantInspectionType {
+		t.Errorf("inspectionType in the rewritten metadata = %v, want %s", got, wantInspectionType)
+	}
+	// The original bodies must not remain in the binary chunks.
+	for i := 0; i < rewritten.ChunkCount(); i++ {
+		chunk, err := rewritten.ReadChunk(i)
+		if err != nil {
+			t.Fatalf("ReadChunk(%d) returned an unexpected error: %v", i, err)
+		}
+		if strings.Contains(strings.ReplaceAll(string(chunk), "-log-", ""), "log-") {
+			t.Errorf("chunk %d contains the original data: %q", i, chunk)
+		}
+	}
+}
+
+func TestRewriteTo_KeepsCodec(t *testing.T) {
+	source := &testKHIFile{
+		logs: []testLog{{body: "log-1", second: 1}},
+	}
+	var buf bytes.Buffer
+	_, err := RewriteTo(context.Background(), &buf, buildTestKHIFileFromContentWithCodec(t, source, binarychunk.CodecZstd), &prefixTransformer{}, t.TempDir())
+	if err != nil {
+		t.Fatalf("RewriteTo() returned an unexpected error: %v", err)
+	}
+	rewritten, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("NewReader() returned an unexpected error for the rewritten file: %v", err)
+	}
+	if got := rewritten.Codec(); got != binarychunk.CodecZstd {
+		t.Errorf("Codec() of the rewritten file = %s, want %s", got, binarychunk.CodecZstd)
+	}
+	want := []testLog{{body: fmt.Sprintf("%d-log-1", DataKindLogBody), second: 1}}
+	if diff := cmp.Diff(want, readTestKHIFileContent(t, rewritten).logs, cmp.AllowUnexported(testLog{})); diff != "" {
+		t.Errorf("logs in the rewritten file mismatch (-want +got):\n%s", diff)
+	}
+}
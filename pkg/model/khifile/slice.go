@@ -0,0 +1,262 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package khifile
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+)
+
+// SliceOptions specifies the part of a .khi file kept by Slice. Zero values mean no limit.
+type SliceOptions struct {
+	// StartTime is the inclusive beginning of the time range to keep.
+	StartTime time.Time
+	// EndTime is the inclusive end of the time range to keep.
+	EndTime time.Time
+	// ResourcePathPrefixes is the list of resource paths to keep with their descendants. (e.g. `core/v1#pod#my-namespace`)
+	ResourcePathPrefixes []string
+	// Namespaces is the list of namespaces to keep the resources in them regardless of their kinds.
+	Namespaces []string
+}
+
+// hasResourceFilter returns true when the options select a part of resources.
+func (o *SliceOptions) hasResourceFilter() bool {
+	return len(o.ResourcePathPrefixes) > 0 || len(o.Namespaces) > 0
+}
+
+// matchesResourcePath returns true when the resource at the path is selected. Descendants of a selected resource are also selected.
+func (o *SliceOptions) matchesResourcePath(path string) bool {
+	if !o.hasResourceFilter() {
+		return true
+	}
+	for _, prefix := range o.ResourcePathPrefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"#") {
+			return true
+		}
+	}
+	// The namespace is the third fragment of a resource path in `<apiVersion>#<kind>#<namespace>#<name>` format.
+	fragments := strings.Split(path, "#")
+	return len(fragments) >= 3 && slices.Contains(o.Namespaces, fragments[2])
+}
+
+// includesTime returns true when the time is in the time range.
+func (o *SliceOptions) includesTime(t time.Time) bool {
+	return (o.StartTime.IsZero() || !t.Before(o.StartTime)) && (o.EndTime.IsZero() || !t.After(o.EndTime))
+}
+
+// slicer holds the state of Slice.
+type slicer struct {
+	source         *history.History
+	options        *SliceOptions
+	rebaser        *rebaser
+	logTimes       map[string]time.Time
+	timelines      map[string]*history.ResourceTimeline
+	slicedTimeline map[string]*history.ResourceTimeline
+	referencedLogs map[string]struct{}
+}
+
+// Slice returns a History containing only the part of the .khi file selected by the options. BinaryReferences in the result point the data written in the binaryBuilder,
+// thus the data not referenced from the selected part is not included.
+// The latest revision before the start time is kept for each timeline to show the state at the beginning of the range, and logs referenced from the selected revisions and events are kept even if they are out of the range.
+// When resources are filtered, logs not referenced from the selected resources are dropped. Resources without any revision or event in the result are removed.
+func Slice(reader *Reader, options *SliceOptions, binaryBuilder *binarychunk.Builder) (*history.History, error) {
+	if !options.StartTime.IsZero() && !options.EndTime.IsZero() && options.EndTime.Before(options.StartTime) {
+		return nil, fmt.Errorf("end time %s is before the start time %s", options.EndTime, options.StartTime)
+	}
+	source := reader.History()
+	s := &slicer{
+		source:         source,
+		options:        options,
+		rebaser:        newRebaser(reader, binaryBuilder, nil),
+		logTimes:       make(map[string]time.Time, len(source.Logs)),
+		timelines:      make(map[string]*history.ResourceTimeline, len(source.Timelines)),
+		slicedTimeline: map[string]*history.ResourceTimeline{},
+		referencedLogs: map[string]struct{}{},
+	}
+	for _, l := range source.Logs {
+		s.logTimes[l.ID] = l.Timestamp
+	}
+	for _, timeline := range source.Timelines {
+		s.timelines[timeline.ID] = timeline
+	}
+
+	result := history.NewHistory()
+	result.Version = source.Version
+	var err error
+	if result.Resources, err = s.sliceResources(source.Resources, false); err != nil {
+		return nil, err
+	}
+	for _, timeline := range source.Timelines {
+		if sliced, found := s.slicedTimeline[timeline.ID]; found && !isEmptyTimeline(sliced) {
+			result.Timelines = append(result.Timelines, sliced)
+		}
+	}
+	for _, l := range source.Logs {
+		_, referenced := s.referencedLogs[l.ID]
+		if !referenced && (options.hasResourceFilter() || !options.includesTime(l.Timestamp)) {
+			continue
+		}
+		rebased, err := s.rebaser.log(l)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy log %s: %w", l.ID, err)
+		}
+		result.Logs = append(result.Logs, rebased)
+	}
+	result.Metadata = s.metadata()
+	return result, nil
+}
+
+// SliceTo slices the .khi file and writes the result to the writer in the .khi format. Returns the written size in bytes.
+// Binary chunks are compressed with the codec of the source. Temporary files used for building binary chunks are created in the tmpFolder.
+func SliceTo(ctx context.Context, writer io.Writer, reader *Reader, options *SliceOptions, tmpFolder string) (int, error) {
+	binaryBuilder, err := newBinaryBuilder(reader.Codec(), tmpFolder)
+	if err != nil {
+		return 0, err
+	}
+	sliced, err := Slice(reader, options, binaryBuilder)
+	if err != nil {
+		return 0, err
+	}
+	return Write(ctx, writer, sliced, binaryBuilder)
+}
+
+// sliceResources returns the copies of the resources having any revision or event after slicing, or having such descendants.
+func (s *slicer) sliceResources(resources []*history.Resource, parentSelected bool) ([]*history.Resource, error) {
+	result := []*history.Resource{}
+	for _, resource := range resources {
+		selected := parentSelected || s.options.matchesResourcePath(resource.FullResourcePath)
+		children, err := s.sliceResources(resource.Children, selected)
+		if err != nil {
+			return nil, err
+		}
+		timelineID := ""
+		if selected && resource.Timeline != "" {
+			timeline, err := s.sliceTimeline(resource.Timeline)
+			if err != nil {
+				return nil, fmt.Errorf("failed to slice the timeline of %s: %w", resource.FullResourcePath, err)
+			}
+			if !isEmptyTimeline(timeline) {
+				timelineID = timeline.ID
+			}
+		}
+		if timelineID == "" && len(children) == 0 {
+			continue
+		}
+		result = append(result, &history.Resource{
+			ResourceName:     resource.ResourceName,
+			Timeline:         timelineID,
+			Relationship:     resource.Relationship,
+			Children:         children,
+			FullResourcePath: resource.FullResourcePath,
+		})
+	}
+	return result, nil
+}
+
+// sliceTimeline returns the copy of the timeline only with the revisions and events in the time range. A timeline shared by multiple resources is sliced only once.
+func (s *slicer) sliceTimeline(id string) (*history.ResourceTimeline, error) {
+	if sliced, found := s.slicedTimeline[id]; found {
+		return sliced, nil
+	}
+	source, found := s.timelines[id]
+	if !found {
+		return nil, fmt.Errorf("timeline %s is not found", id)
+	}
+	sliced := &history.ResourceTimeline{
+		ID:        id,
+		Revisions: []*history.ResourceRevision{},
+		Events:    []*history.ResourceEvent{},
+	}
+	// Revisions are sorted by the change time. Keep the last one before the start time to show the state at the beginning of the range.
+	var lastRevisionBeforeStart *history.ResourceRevision
+	for _, revision := range source.Revisions {
+		if !s.options.StartTime.IsZero() && revision.ChangeTime.Before(s.options.StartTime) {
+			lastRevisionBeforeStart = revision
+			continue
+		}
+		if !s.options.includesTime(revision.ChangeTime) {
+			continue
+		}
+		if lastRevisionBeforeStart != nil {
+			if err := s.addRevision(sliced, lastRevisionBeforeStart); err != nil {
+				return nil, err
+			}
+			lastRevisionBeforeStart = nil
+		}
+		if err := s.addRevision(sliced, revision); err != nil {
+			return nil, err
+		}
+	}
+	if lastRevisionBeforeStart != nil {
+		if err := s.addRevision(sliced, lastRevisionBeforeStart); err != nil {
+			return nil, err
+		}
+	}
+	for _, event := range source.Events {
+		logTime, found := s.logTimes[event.Log]
+		if !found || !s.options.includesTime(logTime) {
+			continue
+		}
+		copied := *event
+		sliced.Events = append(sliced.Events, &copied)
+		s.referencedLogs[event.Log] = struct{}{}
+	}
+	s.slicedTimeline[id] = sliced
+	return sliced, nil
+}
+
+func (s *slicer) addRevision(timeline *history.ResourceTimeline, revision *history.ResourceRevision) error {
+	rebased, err := s.rebaser.revision(revision)
+	if err != nil {
+		return err
+	}
+	timeline.Revisions = append(timeline.Revisions, rebased)
+	if revision.Log != "" {
+		s.referencedLogs[revision.Log] = struct{}{}
+	}
+	return nil
+}
+
+// metadata returns the metadata of the source with the time range in the header narrowed to the sliced range.
+func (s *slicer) metadata() map[string]any {
+	result := maps.Clone(s.source.Metadata)
+	if result == nil {
+		return map[string]any{}
+	}
+	start, end, found := headerTimeRange(result)
+	if !found {
+		return result
+	}
+	if !s.options.StartTime.IsZero() && s.options.StartTime.Unix() > start {
+		start = s.options.StartTime.Unix()
+	}
+	if !s.options.EndTime.IsZero() && s.options.EndTime.Unix() < end {
+		end = s.options.EndTime.Unix()
+	}
+	setHeaderTimeRange(result, start, end)
+	return result
+}
+
+func isEmptyTimeline(timeline *history.ResourceTimeline) bool {
+	return len(timeline.Revisions) == 0 && len(timeline.Events) == 0
+}
@@ -0,0 +1,187 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package khifile
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSliceTo(t *testing.T) {
+	source := &testKHIFile{
+		startTime: testTime(0).Unix(),
+		endTime:   testTime(10).Unix(),
+		logs: []testLog{
+			{body: "log-1", second: 1},
+			{body: "log-0", second: 2},
+			{body: "log-2", second: 3},
+			{body: "log-other", second: 4},
+			{body: "log-3", second: 5},
+			{body: "log-4", second: 7},
+		},
+		timelines: []testTimeline{
+			{path: "core/v1#pod#ns-a#p1", revisions: []testRevision{{body: "log-1", second: 1}, {body: "log-2", second: 3}, {body: "log-4", second: 7}}, events: []string{"log-3"}},
+			{path: "core/v1#pod#ns-b#p2", revisions: []testRevision{{body: "log-0", second: 2}}, events: []string{"log-other"}},
+			{path: "apps/v1#deployment#ns-a#d1", events: []string{"log-1"}},
+		},
+	}
+	testCases := []struct {
+		name    string
+		options *SliceOptions
+		want    *testKHIFile
+		// wantExcludedData is the list of data that must not be contained in the binary chunks of the result.
+		wantExcludedData []string
+		wantErr          bool
+	}{
+		{
+			name:    "time range",
+			options: &SliceOptions{StartTime: testTime(4), EndTime: testTime(6)},
+			want: &testKHIFile{
+				startTime: testTime(4).Unix(),
+				endTime:   testTime(6).Unix(),
+				logs: []testLog{
+					{body: "log-0", second: 2},
+					{body: "log-2", second: 3},
+					{body: "log-other", second: 4},
+					{body: "log-3", second: 5},
+				},
+				timelines: []testTimeline{
+					{path: "core/v1#pod#ns-a#p1", revisions: []testRevision{{body: "log-2", second: 3}}, events: []string{"log-3"}},
+					{path: "core/v1#pod#ns-b#p2", revisions: []testRevision{{body: "log-0", second: 2}}, events: []string{"log-other"}},
+				},
+			},
+			wantExcludedData: []string{"log-1", "log-4"},
+		},
+		{
+			name:    "namespace",
+			options: &SliceOptions{Namespaces: []string{"ns-a"}},
+			want: &testKHIFile{
+				startTime: testTime(0).Unix(),
+				endTime:   testTime(10).Unix(),
+				logs: []testLog{
+					{body: "log-1", second: 1},
+					{body: "log-2", second: 3},
+					{body: "log-3", second: 5},
+					{body: "log-4", second: 7},
+				},
+				timelines: []testTimeline{
+					{path: "core/v1#pod#ns-a#p1", revisions: []testRevision{{body: "log-1", second: 1}, {body: "log-2", second: 3}, {body: "log-4", second: 7}}, events: []string{"log-3"}},
+					{path: "apps/v1#deployment#ns-a#d1", events: []string{"log-1"}},
+				},
+			},
+			wantExcludedData: []string{"log-0", "log-other"},
+		},
+		{
+			name:    "resource path prefix",
+			options: &SliceOptions{ResourcePathPrefixes: []string{"core/v1#pod#ns-b"}},
+			want: &testKHIFile{
+				startTime: testTime(0).Unix(),
+				endTime:   testTime(10).Unix(),
+				logs: []testLog{
+					{body: "log-0", second: 2},
+					{body: "log-other", second: 4},
+				},
+				timelines: []testTimeline{
+					{path: "core/v1#pod#ns-b#p2", revisions: []testRevision{{body: "log-0", second: 2}}, events: []string{"log-other"}},
+				},
+			},
+			wantExcludedData: []string{"log-1", "log-2", "log-3", "log-4"},
+		},
+		{
+			name:    "resource path prefix and end time",
+			options: &SliceOptions{ResourcePathPrefixes: []string{"core/v1#pod"}, EndTime: testTime(2)},
+			want: &testKHIFile{
+				startTime: testTime(0).Unix(),
+				endTime:   testTime(2).Unix(),
+				logs: []testLog{
+					{body: "log-1", second: 1},
+					{body: "log-0", second: 2},
+				},
+				timelines: []testTimeline{
+					{path: "core/v1#pod#ns-a#p1", revisions: []testRevision{{body: "log-1", second: 1}}},
+					{path: "core/v1#pod#ns-b#p2", revisions: []testRevision{{body: "log-0", second: 2}}},
+				},
+			},
+		},
+		{
+			name:    "end time before start time",
+			options: &SliceOptions{StartTime: testTime(5), EndTime: testTime(4)},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reader := buildTestKHIFileFromContent(t, source)
+			var buf bytes.Buffer
+			_, err := SliceTo(context.Background(), &buf, reader, tc.options, t.TempDir())
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("SliceTo() returned nil error, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SliceTo() returned an unexpected error: %v", err)
+			}
+			sliced, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			if err != nil {
+				t.Fatalf("NewReader() returned an unexpected error for the sliced file: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, readTestKHIFileContent(t, sliced), cmp.AllowUnexported(testKHIFile{}, testLog{}, testTimeline{}, testRevision{})); diff != "" {
+				t.Errorf("sliced file mismatch (-want +got):\n%s", diff)
+			}
+			for i := 0; i < sliced.ChunkCount(); i++ {
+				chunk, err := sliced.ReadChunk(i)
+				if err != nil {
+					t.Fatalf("ReadChunk(%d) returned an unexpected error: %v", i, err)
+				}
+				for _, excluded := range tc.wantExcludedData {
+					if bytes.Contains(chunk, []byte(excluded)) {
+						t.Errorf("chunk %d contains %q not referenced from the sliced file", i, excluded)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSliceTo_KeepsCodec(t *testing.T) {
+	source := &testKHIFile{
+		logs: []testLog{
+			{body: "log-1", second: 1},
+			{body: "log-2", second: 8},
+		},
+	}
+	reader := buildTestKHIFileFromContentWithCodec(t, source, binarychunk.CodecZstd)
+	var buf bytes.Buffer
+	if _, err := SliceTo(context.Background(), &buf, reader, &SliceOptions{EndTime: testTime(5)}, t.TempDir()); err != nil {
+		t.Fatalf("SliceTo() returned an unexpected error: %v", err)
+	}
+	sliced, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("NewReader() returned an unexpected error for the sliced file: %v", err)
+	}
+	if got := sliced.Codec(); got != binarychunk.CodecZstd {
+		t.Errorf("Codec() of the sliced file = %s, want %s", got, binarychunk.CodecZstd)
+	}
+	if diff := cmp.Diff([]testLog{{body: "log-1", second: 1}}, readTestKHIFileContent(t, sliced).logs, cmp.AllowUnexported(testLog{})); diff != "" {
+		t.Errorf("logs in the sliced file mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package khifile
+
+import (
+	"context"
+	"io"
+	"maps"
+
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+)
+
+// Write writes the History and the binary chunks in the binaryBuilder to the writer in the .khi format. Returns the written size in bytes.
+// The BinaryReferences in the History must point the data written in the binaryBuilder.
+func Write(ctx context.Context, writer io.Writer, h *history.History, binaryBuilder *binarychunk.Builder) (int, error) {
+	return history.WriteFile(ctx, writer, h, binaryBuilder, inspectionmetadata.NewTaskProgressMetadata("khifile-write"))
+}
+
+// newBinaryBuilder returns a binarychunk.Builder compressing binary chunks with the codec. Temporary files are created in the tmpFolder.
+func newBinaryBuilder(codec binarychunk.Codec, tmpFolder string) (*binarychunk.Builder, error) {
+	compressor, err := binarychunk.NewFileSystemCompressor(codec, tmpFolder)
+	if err != nil {
+		return nil, err
+	}
+	return binarychunk.NewBuilder(compressor, tmpFolder), nil
+}
+
+// headerMetadataKey is the key of inspectionmetadata.HeaderMetadata in History.Metadata.
+const headerMetadataKey = "header"
+
+// headerTimeRange returns the start and end time in unix seconds recorded in the header metadata.
+func headerTimeRange(metadata map[string]any) (start int64, end int64, found bool) {
+	header, ok := metadata[headerMetadataKey].(map[string]any)
+	if !ok {
+		return 0, 0, false
+	}
+	startValue, startOk := header["startTimeUnixSeconds"].(float64)
+	endValue, endOk := header["endTimeUnixSeconds"].(float64)
+	if !startOk || !endOk {
+		return 0, 0, false
+	}
+	return int64(startValue), int64(endValue), true
+}
+
+// setHeaderTimeRange overwrites the start and end time in the header metadata. The file size is removed because it's not valid for the rewritten file.
+// It does nothing when the metadata has no header.
+func setHeaderTimeRange(metadata map[string]any, start int64, end int64) {
+	header, ok := metadata[headerMetadataKey].(map[string]any)
+	if !ok {
+		return
+	}
+	// Clone the header not to modify the metadata of the source History.
+	header = maps.Clone(header)
+	metadata[headerMetadataKey] = header
+	header["startTimeUnixSeconds"] = float64(start)
+	header["endTimeUnixSeconds"] = float64(end)
+	delete(header, "fileSize")
+}
@@ -0,0 +1,159 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redaction removes sensitive data like secrets, tokens, emails and IP addresses from inspection results.
+package redaction
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Action is the way to replace the sensitive data matched with a rule.
+type Action string
+
+const (
+	// ActionMask replaces the sensitive data with a fixed text containing the rule name.
+	ActionMask Action = "mask"
+	// ActionPseudonymize replaces the sensitive data with a pseudonym consistent for the same value.
+	// This keeps the relationship between logs, e.g. requests from the same user, without revealing the value.
+	ActionPseudonymize Action = "pseudonymize"
+)
+
+// builtinPatterns is the list of regular expressions available with Rule.Builtin.
+var builtinPatterns = map[string]string{
+	"email":        `[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`,
+	"ipv4":         `\b(?:(?:25[0-5]|2[0-4][0-9]|1[0-9]{2}|[1-9]?[0-9])\.){3}(?:25[0-5]|2[0-4][0-9]|1[0-9]{2}|[1-9]?[0-9])\b`,
+	"bearer-token": `(?i)bearer\s+[a-zA-Z0-9\-._~+/]+=*`,
+	"jwt":          `eyJ[a-zA-Z0-9_\-]+\.eyJ[a-zA-Z0-9_\-]+\.[a-zA-Z0-9_\-]+`,
+}
+
+// Config is the configuration of the redaction written in YAML.
+type Config struct {
+	// Rules is the list of rules applied in the order.
+	Rules []*Rule `yaml:"rules"`
+	// RedactSecretData masks the values in `data` and `stringData` of Secret resources and their last applied configuration annotations.
+	RedactSecretData bool `yaml:"redactSecretData"`
+	// PseudonymizationKey is the secret key used to generate pseudonyms. The same key generates the same pseudonyms for the same values across files.
+	// A random key is generated when this is empty, thus pseudonyms are consistent only within a Redactor.
+	PseudonymizationKey string `yaml:"pseudonymizationKey"`
+}
+
+// Rule specifies the sensitive data to redact. Exactly one of Builtin, Pattern, Values or FieldPaths must be set.
+type Rule struct {
+	// Name is the name of the rule used in the masked text and the pseudonyms.
+	Name string `yaml:"name"`
+	// Builtin is the name of a builtin pattern. One of `email`, `ipv4`, `bearer-token` or `jwt`.
+	Builtin string `yaml:"builtin"`
+	// Pattern is the regular expression matching the sensitive text in any string value.
+	Pattern string `yaml:"pattern"`
+	// Values is the list of sensitive literal texts like project IDs.
+	Values []string `yaml:"values"`
+	// FieldPaths is the list of field paths in structured data whose values are redacted entirely.
+	// It's in the same format as the field paths of structured.NodeReader (e.g. `protoPayload.request.spec.token`). `*` matches any key or index.
+	// Field paths are resolved from the root of the log bodies and the resource manifests.
+	FieldPaths []string `yaml:"fieldPaths"`
+	// Action is the way to replace the matched data. The default is `mask`.
+	Action Action `yaml:"action"`
+}
+
+// LoadConfig reads the redaction config from the YAML file at the path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the redaction config %s: %w", path, err)
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig parses the redaction config written in YAML and validates it.
+func ParseConfig(data []byte) (*Config, error) {
+	var config Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse the redaction config: %w", err)
+	}
+	for i, rule := range config.Rules {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("rule %d is invalid: %w", i, err)
+		}
+	}
+	return &config, nil
+}
+
+func (r *Rule) validate() error {
+	if r.Name == "" {
+		r.Name = r.Builtin
+	}
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.Action == "" {
+		r.Action = ActionMask
+	}
+	if r.Action != ActionMask && r.Action != ActionPseudonymize {
+		return fmt.Errorf("unknown action %q in rule %s", r.Action, r.Name)
+	}
+	specified := 0
+	for _, set := range []bool{r.Builtin != "", r.Pattern != "", len(r.Values) > 0, len(r.FieldPaths) > 0} {
+		if set {
+			specified++
+		}
+	}
+	if specified != 1 {
+		return fmt.Errorf("exactly one of builtin, pattern, values or fieldPaths must be set in rule %s", r.Name)
+	}
+	if r.Builtin != "" {
+		if _, found := builtinPatterns[r.Builtin]; !found {
+			return fmt.Errorf("unknown builtin pattern %q in rule %s", r.Builtin, r.Name)
+		}
+	}
+	if slices.Contains(r.Values, "") {
+		return fmt.Errorf("values must not contain an empty string in rule %s", r.Name)
+	}
+	if r.Pattern != "" {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("failed to compile the pattern of rule %s: %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
+// textPattern returns the regular expression matching the sensitive texts of the rule. It returns nil for field path rules.
+func (r *Rule) textPattern() *regexp.Regexp {
+	switch {
+	case r.Builtin != "":
+		return regexp.MustCompile(builtinPatterns[r.Builtin])
+	case r.Pattern != "":
+		return regexp.MustCompile(r.Pattern)
+	case len(r.Values) > 0:
+		// Match longer values first not to leave a part of a value containing another value.
+		values := slices.Clone(r.Values)
+		slices.SortFunc(values, func(a, b string) int { return len(b) - len(a) })
+		quoted := make([]string, 0, len(values))
+		for _, value := range values {
+			quoted = append(quoted, regexp.QuoteMeta(value))
+		}
+		return regexp.MustCompile(strings.Join(quoted, "|"))
+	default:
+		return nil
+	}
+}
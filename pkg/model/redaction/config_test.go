@@ -0,0 +1,103 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redaction
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseConfig(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		want    *Config
+		wantErr bool
+	}{
+		{
+			name: "valid rules with defaults",
+			input: `rules:
+- builtin: email
+  action: pseudonymize
+- name: project
+  values: ["my-project"]
+- name: token
+  fieldPaths: ["spec.token"]
+redactSecretData: true
+`,
+			want: &Config{
+				Rules: []*Rule{
+					{Name: "email", Builtin: "email", Action: ActionPseudonymize},
+					{Name: "project", Values: []string{"my-project"}, Action: ActionMask},
+					{Name: "token", FieldPaths: []string{"spec.token"}, Action: ActionMask},
+				},
+				RedactSecretData: true,
+			},
+		},
+		{
+			name:    "unknown field",
+			input:   "rules: []\nunknown: true\n",
+			wantErr: true,
+		},
+		{
+			name:    "rule without name",
+			input:   "rules:\n- pattern: foo\n",
+			wantErr: true,
+		},
+		{
+			name:    "multiple matchers in a rule",
+			input:   "rules:\n- name: foo\n  pattern: foo\n  values: [bar]\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown builtin",
+			input:   "rules:\n- builtin: phone\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid pattern",
+			input:   "rules:\n- name: foo\n  pattern: \"(\"\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty value",
+			input:   "rules:\n- name: foo\n  values: [\"\"]\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown action",
+			input:   "rules:\n- builtin: email\n  action: drop\n",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseConfig([]byte(tc.input))
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("ParseConfig() returned no error, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseConfig() returned an unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ParseConfig() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redaction
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structured"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/khifile"
+)
+
+// secretDataRuleName is the rule name used for the values redacted by Config.RedactSecretData.
+const secretDataRuleName = "secret-data"
+
+// lastAppliedConfigurationAnnotation is the annotation containing the entire manifest including the Secret data.
+const lastAppliedConfigurationAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// pseudonymHashLength is the count of hex characters of the hash used in a pseudonym.
+const pseudonymHashLength = 12
+
+type textRule struct {
+	rule    *Rule
+	pattern *regexp.Regexp
+}
+
+type fieldRule struct {
+	rule  *Rule
+	paths [][]string
+}
+
+// Redactor replaces the sensitive data in texts and structured data according to the Config.
+type Redactor struct {
+	textRules        []*textRule
+	fieldRules       []*fieldRule
+	redactSecretData bool
+	key              []byte
+
+	pseudonymsLock sync.Mutex
+	// pseudonyms maps the generated pseudonyms to the original values. It's nil unless RecordPseudonyms is called.
+	pseudonyms map[string]string
+}
+
+var _ khifile.Transformer = (*Redactor)(nil)
+
+// NewRedactor returns a Redactor applying the rules in the config. The config must be validated by ParseConfig.
+func NewRedactor(config *Config) (*Redactor, error) {
+	redactor := &Redactor{
+		redactSecretData: config.RedactSecretData,
+		key:              []byte(config.PseudonymizationKey),
+	}
+	if len(redactor.key) == 0 {
+		redactor.key = make([]byte, 32)
+		if _, err := rand.Read(redactor.key); err != nil {
+			return nil, fmt.Errorf("failed to generate a pseudonymization key: %w", err)
+		}
+	}
+	for _, rule := range config.Rules {
+		if len(rule.FieldPaths) > 0 {
+			paths := make([][]string, 0, len(rule.FieldPaths))
+			for _, fieldPath := range rule.FieldPaths {
+				paths = append(paths, structured.ParseFieldPath(fieldPath))
+			}
+			redactor.fieldRules = append(redactor.fieldRules, &fieldRule{rule: rule, paths: paths})
+			continue
+		}
+		redactor.textRules = append(redactor.textRules, &textRule{rule: rule, pattern: rule.textPattern()})
+	}
+	return redactor, nil
+}
+
+// Transform implements khifile.Transformer. Log bodies, revision bodies and the metadata are redacted as structured data, and the others are redacted as texts.
+func (r *Redactor) Transform(kind khifile.DataKind, data []byte) ([]byte, error) {
+	switch kind {
+	case khifile.DataKindLogBody, khifile.DataKindRevisionBody:
+		return r.RedactStructured(data)
+	case khifile.DataKindMetadata:
+		return r.redactStructured(data, &structured.JSONNodeSerializer{})
+	case khifile.DataKindLogSummary, khifile.DataKindRevisionRequestor:
+		return []byte(r.RedactText(string(data))), nil
+	default:
+		// Annotations contain resource paths. Resource names are kept to keep the resource tree same.
+		return data, nil
+	}
+}
+
+// RedactText replaces the texts matched with the pattern rules.
+func (r *Redactor) RedactText(text string) string {
+	for _, textRule := range r.textRules {
+		text = textRule.pattern.ReplaceAllStringFunc(text, func(matched string) string {
+			return r.replace(textRule.rule.Name, textRule.rule.Action, matched)
+		})
+	}
+	return text
+}
+
+// RedactStructured redacts the YAML or JSON data. Field path rules and the Secret data rule are applied in addition to the pattern rules.
+// The result is serialized in YAML. The data is redacted as a text when it's not a YAML map or sequence.
+func (r *Redactor) RedactStructured(data []byte) ([]byte, error) {
+	return r.redactStructured(data, &structured.YAMLNodeSerializer{})
+}
+
+// redactStructured is same as RedactStructured except the result is serialized with the given serializer.
+func (r *Redactor) redactStructured(data []byte, serializer structured.NodeSerializer) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+	node, err := structured.FromYAML(string(data))
+	if err != nil || node.Type() == structured.ScalarNodeType {
+		return []byte(r.RedactText(string(data))), nil
+	}
+	redacted, err := r.redactNode([]string{}, node)
+	if err != nil {
+		return nil, err
+	}
+	return serializer.Serialize(redacted)
+}
+
+// RecordPseudonyms makes the Redactor record the generated pseudonyms returned from Pseudonyms.
+// Pseudonyms are not recorded by default because the mapping grows with every redacted value, e.g. when the Redactor is shared by the inspections in the server.
+func (r *Redactor) RecordPseudonyms() {
+	r.pseudonymsLock.Lock()
+	defer r.pseudonymsLock.Unlock()
+	if r.pseudonyms == nil {
+		r.pseudonyms = map[string]string{}
+	}
+}
+
+// Pseudonyms returns the map from the pseudonyms generated after calling RecordPseudonyms to the original values.
+// Keep this mapping private to find the original values from the pseudonyms in the redacted data.
+func (r *Redactor) Pseudonyms() map[string]string {
+	r.pseudonymsLock.Lock()
+	defer r.pseudonymsLock.Unlock()
+	if r.pseudonyms == nil {
+		return map[string]string{}
+	}
+	return maps.Clone(r.pseudonyms)
+}
+
+func (r *Redactor) redactNode(path []string, node structured.Node) (structured.Node, error) {
+	for _, fieldRule := range r.fieldRules {
+		for _, rulePath := range fieldRule.paths {
+			if matchFieldPath(rulePath, path) {
+				return r.replaceNode(fieldRule.rule.Name, fieldRule.rule.Action, node)
+			}
+		}
+	}
+	switch node.Type() {
+	case structured.ScalarNodeType:
+		value, err := node.NodeScalarValue()
+		if err != nil {
+			return nil, err
+		}
+		if text, ok := value.(string); ok {
+			return structured.NewStandardScalarNode(r.RedactText(text)), nil
+		}
+		return node, nil
+	case structured.SequenceNodeType:
+		values := make([]structured.Node, 0, node.Len())
+		for key, child := range node.Children() {
+			redacted, err := r.redactNode(childPath(path, strconv.Itoa(key.Index)), child)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, redacted)
+		}
+		return structured.NewStandardSequence(values), nil
+	case structured.MapNodeType:
+		isSecret := r.redactSecretData && isSecretNode(node)
+		keys := make([]string, 0, node.Len())
+		values := make([]structured.Node, 0, node.Len())
+		for key, child := range node.Children() {
+			var redacted structured.Node
+			var err error
+			if isSecret && (key.Key == "data" || key.Key == "stringData") {
+				redacted, err = r.maskChildren(child)
+			} else if isSecret && key.Key == "metadata" {
+				redacted, err = r.redactSecretMetadata(childPath(path, key.Key), child)
+			} else {
+				redacted, err = r.redactNode(childPath(path, key.Key), child)
+			}
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key.Key)
+			values = append(values, redacted)
+		}
+		return structured.NewStandardMap(keys, values), nil
+	default:
+		return nil, fmt.Errorf("unknown node type: %v", node.Type())
+	}
+}
+
+// redactSecretMetadata redacts the metadata of a Secret. The last applied configuration annotation is masked because it contains the Secret data.
+func (r *Redactor) redactSecretMetadata(path []string, metadata structured.Node) (structured.Node, error) {
+	redacted, err := r.redactNode(path, metadata)
+	if err != nil {
+		return nil, err
+	}
+	reader := structured.NewNodeReader(redacted)
+	annotationPath := "annotations." + strings.ReplaceAll(lastAppliedConfigurationAnnotation, ".", `\.`)
+	if !reader.Has(annotationPath) {
+		return redacted, nil
+	}
+	return structured.WithScalarField(redacted, []string{"annotations", lastAppliedConfigurationAnnotation}, maskText(secretDataRuleName))
+}
+
+// maskChildren masks every value in the map or sequence. Keys are kept to show which keys are contained.
+func (r *Redactor) maskChildren(node structured.Node) (structured.Node, error) {
+	switch node.Type() {
+	case structured.MapNodeType:
+		keys := make([]string, 0, node.Len())
+		values := make([]structured.Node, 0, node.Len())
+		for key := range node.Children() {
+			keys = append(keys, key.Key)
+			values = append(values, structured.NewStandardScalarNode(maskText(secretDataRuleName)))
+		}
+		return structured.NewStandardMap(keys, values), nil
+	default:
+		return structured.NewStandardScalarNode(maskText(secretDataRuleName)), nil
+	}
+}
+
+// replaceNode replaces the entire node matched with a field path rule. Maps and sequences are always masked because they can't have pseudonyms.
+func (r *Redactor) replaceNode(ruleName string, action Action, node structured.Node) (structured.Node, error) {
+	if node.Type() != structured.ScalarNodeType {
+		return structured.NewStandardScalarNode(maskText(ruleName)), nil
+	}
+	value, err := node.NodeScalarValue()
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return node, nil
+	}
+	return structured.NewStandardScalarNode(r.replace(ruleName, action, fmt.Sprint(value))), nil
+}
+
+// replace returns the text replacing the sensitive value.
+func (r *Redactor) replace(ruleName string, action Action, value string) string {
+	if action != ActionPseudonymize {
+		return maskText(ruleName)
+	}
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(ruleName))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	pseudonym := fmt.Sprintf("%s-%s", ruleName, hex.EncodeToString(mac.Sum(nil))[:pseudonymHashLength])
+	r.pseudonymsLock.Lock()
+	if r.pseudonyms != nil {
+		r.pseudonyms[pseudonym] = value
+	}
+	r.pseudonymsLock.Unlock()
+	return pseudonym
+}
+
+func maskText(ruleName string) string {
+	return fmt.Sprintf("[REDACTED:%s]", ruleName)
+}
+
+// isSecretNode returns true when the map node is a Kubernetes Secret.
+func isSecretNode(node structured.Node) bool {
+	kind, err := structured.NewNodeReader(node).ReadString("kind")
+	return err == nil && kind == "Secret"
+}
+
+// matchFieldPath returns true when the path matches the rule path. `*` in the rule path matches any segment.
+func matchFieldPath(rulePath []string, path []string) bool {
+	if len(rulePath) != len(path) {
+		return false
+	}
+	for i, segment := range rulePath {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// childPath returns a new path slice with the segment appended, not to share the backing array between siblings.
+func childPath(path []string, segment string) []string {
+	result := make([]string, len(path)+1)
+	copy(result, path)
+	result[len(path)] = segment
+	return result
+}
@@ -0,0 +1,255 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redaction
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/khifile"
+	"github.com/google/go-cmp/cmp"
+)
+
+func newTestRedactor(t *testing.T, config string) *Redactor {
+	t.Helper()
+	parsed, err := ParseConfig([]byte(config))
+	if err != nil {
+		t.Fatalf("ParseConfig() returned an unexpected error: %v", err)
+	}
+	redactor, err := NewRedactor(parsed)
+	if err != nil {
+		t.Fatalf("NewRedactor() returned an unexpected error: %v", err)
+	}
+	return redactor
+}
+
+func TestRedactText(t *testing.T) {
+	redactor := newTestRedactor(t, `rules:
+- builtin: email
+- builtin: ipv4
+- builtin: bearer-token
+- name: project
+  values: ["my-project", "my-project-staging"]
+`)
+	testCases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "email",
+			input: "requested by foo@example.com",
+			want:  "requested by [REDACTED:email]",
+		},
+		{
+			name:  "ipv4",
+			input: "connection from 10.0.0.1:443",
+			want:  "connection from [REDACTED:ipv4]:443",
+		},
+		{
+			name:  "bearer token",
+			input: "Authorization: Bearer abc.def-ghi",
+			want:  "Authorization: [REDACTED:bearer-token]",
+		},
+		{
+			name:  "longer literal value first",
+			input: "projects/my-project-staging/logs",
+			want:  "projects/[REDACTED:project]/logs",
+		},
+		{
+			name:  "no sensitive data",
+			input: "pod was scheduled",
+			want:  "pod was scheduled",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := redactor.RedactText(tc.input)
+			if got != tc.want {
+				t.Errorf("RedactText() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRedactText_Pseudonymize(t *testing.T) {
+	config := `rules:
+- builtin: email
+  action: pseudonymize
+pseudonymizationKey: test-key
+`
+	redactor := newTestRedactor(t, config)
+	redactor.RecordPseudonyms()
+	first := redactor.RedactText("foo@example.com")
+	second := redactor.RedactText("user foo@example.com")
+	other := redactor.RedactText("bar@example.com")
+
+	if !strings.HasPrefix(first, "email-") || len(first) != len("email-")+pseudonymHashLength {
+		t.Errorf("RedactText() = %q, want a pseudonym with the rule name", first)
+	}
+	if second != "user "+first {
+		t.Errorf("RedactText() = %q, want the same pseudonym %q", second, first)
+	}
+	if other == first {
+		t.Errorf("RedactText() returned the same pseudonym %q for a different value", other)
+	}
+	wantPseudonyms := map[string]string{
+		first: "foo@example.com",
+		other: "bar@example.com",
+	}
+	if diff := cmp.Diff(wantPseudonyms, redactor.Pseudonyms()); diff != "" {
+		t.Errorf("Pseudonyms() mismatch (-want +got):\n%s", diff)
+	}
+
+	// The same key must generate the same pseudonym in another redactor.
+	notRecording := newTestRedactor(t, config)
+	if got := notRecording.RedactText("foo@example.com"); got != first {
+		t.Errorf("RedactText() with the same key = %q, want %q", got, first)
+	}
+	if got := notRecording.Pseudonyms(); len(got) != 0 {
+		t.Errorf("Pseudonyms() = %v, want no pseudonyms recorded without RecordPseudonyms()", got)
+	}
+}
+
+func TestRedactStructured(t *testing.T) {
+	redactor := newTestRedactor(t, `rules:
+- builtin: email
+- name: token
+  fieldPaths: ["spec.token", "spec.containers.*.env"]
+redactSecretData: true
+`)
+	testCases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name: "field paths and patterns",
+			input: `kind: Pod
+metadata:
+  annotations:
+    owner: foo@example.com
+spec:
+  token: secret-token
+  containers:
+  - name: app
+    env:
+    - name: PASSWORD
+      value: bar
+`,
+			want: `kind: Pod
+metadata:
+    annotations:
+        owner: '[REDACTED:email]'
+spec:
+    token: '[REDACTED:token]'
+    containers:
+        - name: app
+          env: '[REDACTED:token]'
+`,
+		},
+		{
+			name: "secret data",
+			input: `kind: Secret
+metadata:
+  name: foo
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: '{"data":{"password":"YmFy"}}'
+data:
+  password: YmFy
+stringData:
+  username: foo
+`,
+			want: `kind: Secret
+metadata:
+    name: foo
+    annotations:
+        kubectl.kubernetes.io/last-applied-configuration: '[REDACTED:secret-data]'
+data:
+    password: '[REDACTED:secret-data]'
+stringData:
+    username: '[REDACTED:secret-data]'
+`,
+		},
+		{
+			name:  "non structured text",
+			input: "mail from foo@example.com",
+			want:  "mail from [REDACTED:email]",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := redactor.RedactStructured([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("RedactStructured() returned an unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("RedactStructured() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTransform(t *testing.T) {
+	redactor := newTestRedactor(t, "rules:\n- builtin: email\n")
+	testCases := []struct {
+		name  string
+		kind  khifile.DataKind
+		input string
+		want  string
+	}{
+		{
+			name:  "log summary",
+			kind:  khifile.DataKindLogSummary,
+			input: "foo@example.com",
+			want:  "[REDACTED:email]",
+		},
+		{
+			name:  "revision requestor",
+			kind:  khifile.DataKindRevisionRequestor,
+			input: "foo@example.com",
+			want:  "[REDACTED:email]",
+		},
+		{
+			name:  "log body",
+			kind:  khifile.DataKindLogBody,
+			input: "user: foo@example.com\n",
+			want:  "user: '[REDACTED:email]'\n",
+		},
+		{
+			name:  "metadata",
+			kind:  khifile.DataKindMetadata,
+			input: `{"header":{"inspectionType":"gke","requestor":"foo@example.com"}}`,
+			want:  `{"header":{"inspectionType":"gke","requestor":"[REDACTED:email]"}}`,
+		},
+		{
+			name:  "annotation is kept",
+			kind:  khifile.DataKindAnnotation,
+			input: "foo@example.com",
+			want:  "foo@example.com",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := redactor.Transform(tc.kind, []byte(tc.input))
+			if err != nil {
+				t.Fatalf("Transform() returned an unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("Transform() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -32,6 +32,8 @@ type CommonParameters struct {
 	TemporaryFolder *string
 	// UploadFileStoreFolder is the folder path to store the uploaded log files.
 	UploadFileStoreFolder *string
+	// CompressionCodec is the name of the compression codec of the binary chunks in the khi files. One of `gzip` or `zstd`.
+	CompressionCodec *string
 	// Version is the flag to show the version name and exit.
 	Version *bool
 }
@@ -42,6 +44,9 @@ func (c *CommonParameters) PostProcess() error {
 		slog.Info(fmt.Sprintf("Kubernetes History Inspector (version: %s)", constants.VERSION))
 		os.Exit(0)
 	}
+	if *c.CompressionCodec != "gzip" && *c.CompressionCodec != "zstd" {
+		return fmt.Errorf("--compression-codec must be `gzip` or `zstd` but %q was given", *c.CompressionCodec)
+	}
 	if *c.UploadFileStoreFolder == "" {
 		*c.UploadFileStoreFolder = *c.DataDestinationFolder + "/upload"
 	}
@@ -53,6 +58,7 @@ func (c *CommonParameters) Prepare() error {
 	c.DataDestinationFolder = flag.String("data-destination-folder", "./data", "The folder path where the final khi file to be stored for serving.", "")
 	c.TemporaryFolder = flag.String("temporary-folder", "/tmp", "The folder path where be used as a working directory to generate the final khi file.", "")
 	c.UploadFileStoreFolder = flag.String("upload-file-store-folder", "", "The folder path to store the uploaded log files. Use the concatinated path of `--data-destination-folder` and `/upload` when this value is not specified.", "")
+	c.CompressionCodec = flag.String("compression-codec", "gzip", "The compression codec of the binary chunks in the khi files. One of `gzip` or `zstd`. `zstd` is faster and generates smaller files, but the files can't be opened in the viewers of older versions or viewers without zstd support.", "KHI_COMPRESSION_CODEC")
 	c.Version = flag.Bool("version", false, "Show the version.", "")
 	return nil
 }
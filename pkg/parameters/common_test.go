@@ -34,6 +34,7 @@ func TestCommonParameters(t *testing.T) {
 			want: &CommonParameters{
 				DataDestinationFolder: testutil.P("./data"),
 				TemporaryFolder:       testutil.P("/tmp"),
+				CompressionCodec:      testutil.P("gzip"),
 				Version:               testutil.P(false),
 				UploadFileStoreFolder: testutil.P("./data/upload"),
 			},
@@ -42,6 +43,20 @@ func TestCommonParameters(t *testing.T) {
 				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 			},
 		},
+		{
+			name: "zstd compression codec",
+			want: &CommonParameters{
+				DataDestinationFolder: testutil.P("./data"),
+				TemporaryFolder:       testutil.P("/tmp"),
+				CompressionCodec:      testutil.P("zstd"),
+				Version:               testutil.P(false),
+				UploadFileStoreFolder: testutil.P("./data/upload"),
+			},
+			before: func() {
+				os.Args = []string{os.Args[0], "--compression-codec", "zstd"}
+				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -73,12 +88,14 @@ func TestCommonParametersPostProcess(t *testing.T) {
 			before: CommonParameters{
 				DataDestinationFolder: testutil.P("./data"),
 				TemporaryFolder:       testutil.P("/tmp"),
+				CompressionCodec:      testutil.P("gzip"),
 				Version:               testutil.P(false),
 				UploadFileStoreFolder: testutil.P(""),
 			},
 			want: CommonParameters{
 				DataDestinationFolder: testutil.P("./data"),
 				TemporaryFolder:       testutil.P("/tmp"),
+				CompressionCodec:      testutil.P("gzip"),
 				Version:               testutil.P(false),
 				UploadFileStoreFolder: testutil.P("./data/upload"),
 			},
@@ -88,12 +105,14 @@ func TestCommonParametersPostProcess(t *testing.T) {
 			before: CommonParameters{
 				DataDestinationFolder: testutil.P("./data"),
 				TemporaryFolder:       testutil.P("/tmp"),
+				CompressionCodec:      testutil.P("gzip"),
 				Version:               testutil.P(false),
 				UploadFileStoreFolder: testutil.P("/foo/bar"),
 			},
 			want: CommonParameters{
 				DataDestinationFolder: testutil.P("./data"),
 				TemporaryFolder:       testutil.P("/tmp"),
+				CompressionCodec:      testutil.P("gzip"),
 				Version:               testutil.P(false),
 				UploadFileStoreFolder: testutil.P("/foo/bar"),
 			},
@@ -113,3 +132,17 @@ func TestCommonParametersPostProcess(t *testing.T) {
 		})
 	}
 }
+
+func TestCommonParametersPostProcess_InvalidCompressionCodec(t *testing.T) {
+	prepareFlagParsingTest(t)
+	parameters := CommonParameters{
+		DataDestinationFolder: testutil.P("./data"),
+		TemporaryFolder:       testutil.P("/tmp"),
+		CompressionCodec:      testutil.P("brotli"),
+		Version:               testutil.P(false),
+		UploadFileStoreFolder: testutil.P(""),
+	}
+	if err := parameters.PostProcess(); err == nil {
+		t.Errorf("PostProcess() returned no error, want an error")
+	}
+}
@@ -38,6 +38,11 @@ type DebugParameters struct {
 	// NoColor
 	// If this flag is set, KHI prints logs without color.
 	NoColor *bool
+
+	// APIFixtureRecordPath is the path of the fixture file to record the responses of Google Cloud APIs.
+	APIFixtureRecordPath *string
+	// APIFixtureReplayPath is the path of the fixture file to serve the responses of Google Cloud APIs instead of calling them.
+	APIFixtureReplayPath *string
 }
 
 // PostProcess implements ParameterStore.
@@ -45,6 +50,9 @@ func (d *DebugParameters) PostProcess() error {
 	if *d.Profiler && (d.ProfilerProject == nil || *d.ProfilerProject == "") {
 		return errors.New("--profiler-project is required when --profiler is set")
 	}
+	if *d.APIFixtureRecordPath != "" && *d.APIFixtureReplayPath != "" {
+		return errors.New("--api-fixture-record and --api-fixture-replay can't be set at the same time")
+	}
 	return nil
 }
 
@@ -55,6 +63,8 @@ func (d *DebugParameters) Prepare() error {
 	d.ProfilerService = flag.String("profiler-service", "khi", "The service name given to CloudProfiler.", "")
 	d.Verbose = flag.Bool("verbose", false, "If this flag is set, KHI prints verbose logs.", "")
 	d.NoColor = flag.Bool("no-color", false, "If this flag is set, KHI prints logs without color.", "")
+	d.APIFixtureRecordPath = flag.String("api-fixture-record", "", "The path of the fixture file to record every response of Google Cloud APIs including Cloud Logging. The fixture can be replayed with --api-fixture-replay.", "")
+	d.APIFixtureReplayPath = flag.String("api-fixture-replay", "", "The path of the fixture file recorded with --api-fixture-record. KHI serves the recorded responses instead of calling Google Cloud APIs.", "")
 	return nil
 }
 
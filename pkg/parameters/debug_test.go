@@ -36,11 +36,13 @@ func TestDebugParameters(t *testing.T) {
 			},
 			name: "default",
 			want: &DebugParameters{
-				Profiler:        testutil.P(false),
-				ProfilerService: testutil.P("khi"),
-				ProfilerProject: testutil.P(""),
-				Verbose:         testutil.P(false),
-				NoColor:         testutil.P(false),
+				Profiler:             testutil.P(false),
+				ProfilerService:      testutil.P("khi"),
+				ProfilerProject:      testutil.P(""),
+				Verbose:              testutil.P(false),
+				NoColor:              testutil.P(false),
+				APIFixtureRecordPath: testutil.P(""),
+				APIFixtureReplayPath: testutil.P(""),
 			},
 		},
 	}
@@ -63,3 +65,14 @@ func TestDebugParameters(t *testing.T) {
 	}
 
 }
+
+func TestDebugParametersPostProcess_FixtureRecordAndReplay(t *testing.T) {
+	parameters := &DebugParameters{
+		Profiler:             testutil.P(false),
+		APIFixtureRecordPath: testutil.P("record.jsonl"),
+		APIFixtureReplayPath: testutil.P("replay.jsonl"),
+	}
+	if err := parameters.PostProcess(); err == nil {
+		t.Errorf("PostProcess() returned no error, want an error")
+	}
+}
@@ -25,9 +25,10 @@ import (
 
 func TestJobParameters(t *testing.T) {
 	testCases := []struct {
-		name   string
-		want   *JobParameters
-		before func()
+		name    string
+		want    *JobParameters
+		wantErr bool
+		before  func()
 	}{
 		{
 			name: "default",
@@ -37,12 +38,44 @@ func TestJobParameters(t *testing.T) {
 				InspectionFeatures: testutil.P(""),
 				InspectionValues:   testutil.P(""),
 				ExportDestination:  testutil.P(""),
+				JobSpec:            testutil.P(""),
 			},
 			before: func() {
 				os.Args = []string{os.Args[0]}
 				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 			},
 		},
+		{
+			name: "job spec",
+			want: &JobParameters{
+				JobMode:            testutil.P(true),
+				InspectionType:     testutil.P(""),
+				InspectionFeatures: testutil.P(""),
+				InspectionValues:   testutil.P(""),
+				ExportDestination:  testutil.P(""),
+				JobSpec:            testutil.P("/tmp/spec.yaml"),
+			},
+			before: func() {
+				os.Args = []string{os.Args[0], "--job-mode", "--job-spec=/tmp/spec.yaml"}
+				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			},
+		},
+		{
+			name:    "job mode without any job",
+			wantErr: true,
+			before: func() {
+				os.Args = []string{os.Args[0], "--job-mode"}
+				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			},
+		},
+		{
+			name:    "job spec with a single job flag",
+			wantErr: true,
+			before: func() {
+				os.Args = []string{os.Args[0], "--job-mode", "--job-spec=/tmp/spec.yaml", "--job-inspection-type=foo"}
+				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -53,6 +86,12 @@ func TestJobParameters(t *testing.T) {
 			ResetStore()
 			AddStore(store)
 			err := Parse()
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error but got nil")
+				}
+				return
+			}
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -0,0 +1,40 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parameters
+
+import (
+	"github.com/GoogleCloudPlatform/khi/pkg/common/flag"
+)
+
+var Redaction = &RedactionParameters{}
+
+// RedactionParameters is the ParameterStore for the redaction of the inspection results.
+type RedactionParameters struct {
+	// ConfigPath is the path to the redaction config YAML. Inspection results are redacted before being written when this is set.
+	ConfigPath *string
+}
+
+// PostProcess implements ParameterStore.
+func (r *RedactionParameters) PostProcess() error {
+	return nil
+}
+
+// Prepare implements ParameterStore.
+func (r *RedactionParameters) Prepare() error {
+	r.ConfigPath = flag.String("redaction-config", "", "The path to the redaction config YAML. Sensitive data in inspection results are redacted with the rules before being written when this is set.", "KHI_REDACTION_CONFIG")
+	return nil
+}
+
+var _ ParameterStore = (*RedactionParameters)(nil)
@@ -0,0 +1,63 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parameters
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/flag"
+)
+
+var Retention = &RetentionParameters{}
+
+// RetentionParameters is the ParameterStore for the retention policy of the inspection results and the uploaded files.
+type RetentionParameters struct {
+	// MaxAgeInHours is the maximum age of the inspections and the uploaded files. 0 means no limit.
+	MaxAgeInHours *int
+	// MaxInspectionCount is the maximum count of finished inspections kept in the server. 0 means no limit.
+	MaxInspectionCount *int
+	// MaxTotalSizeInBytes is the maximum total size of the inspection results in the data destination folder and the uploaded files in the upload store folder. 0 means no limit.
+	MaxTotalSizeInBytes *int
+	// CheckIntervalInSeconds is the interval of the retention policy checks and the orphaned temporary file cleanups.
+	CheckIntervalInSeconds *int
+}
+
+// PostProcess implements ParameterStore.
+func (r *RetentionParameters) PostProcess() error {
+	if *r.MaxAgeInHours < 0 {
+		return fmt.Errorf("--retention-max-age-hours must not be negative")
+	}
+	if *r.MaxInspectionCount < 0 {
+		return fmt.Errorf("--retention-max-inspection-count must not be negative")
+	}
+	if *r.MaxTotalSizeInBytes < 0 {
+		return fmt.Errorf("--retention-max-total-size-in-bytes must not be negative")
+	}
+	if *r.CheckIntervalInSeconds <= 0 {
+		return fmt.Errorf("--retention-check-interval-seconds must be positive")
+	}
+	return nil
+}
+
+// Prepare implements ParameterStore.
+func (r *RetentionParameters) Prepare() error {
+	r.MaxAgeInHours = flag.Int("retention-max-age-hours", 0, "The maximum age of the inspections and the uploaded files. Older ones are deleted. 0 means no limit.", "KHI_RETENTION_MAX_AGE_HOURS")
+	r.MaxInspectionCount = flag.Int("retention-max-inspection-count", 0, "The maximum count of finished inspections kept in the server. Older ones are deleted. 0 means no limit.", "KHI_RETENTION_MAX_INSPECTION_COUNT")
+	r.MaxTotalSizeInBytes = flag.Int("retention-max-total-size-in-bytes", 0, "The maximum total size of the inspection results and the uploaded files. Older ones are deleted. 0 means no limit.", "KHI_RETENTION_MAX_TOTAL_SIZE_IN_BYTES")
+	r.CheckIntervalInSeconds = flag.Int("retention-check-interval-seconds", 600, "The interval of the retention policy checks and the orphaned temporary file cleanups.", "KHI_RETENTION_CHECK_INTERVAL_SECONDS")
+	return nil
+}
+
+var _ ParameterStore = (*RetentionParameters)(nil)
@@ -0,0 +1,88 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parameters
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRetentionParameters(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    []string
+		want    *RetentionParameters
+		wantErr bool
+	}{
+		{
+			name: "default",
+			args: []string{},
+			want: &RetentionParameters{
+				MaxAgeInHours:          testutil.P(0),
+				MaxInspectionCount:     testutil.P(0),
+				MaxTotalSizeInBytes:    testutil.P(0),
+				CheckIntervalInSeconds: testutil.P(600),
+			},
+		},
+		{
+			name: "with limits",
+			args: []string{"--retention-max-age-hours", "24", "--retention-max-inspection-count", "10", "--retention-max-total-size-in-bytes", "1000"},
+			want: &RetentionParameters{
+				MaxAgeInHours:          testutil.P(24),
+				MaxInspectionCount:     testutil.P(10),
+				MaxTotalSizeInBytes:    testutil.P(1000),
+				CheckIntervalInSeconds: testutil.P(600),
+			},
+		},
+		{
+			name:    "negative limit",
+			args:    []string{"--retention-max-inspection-count", "-1"},
+			wantErr: true,
+		},
+		{
+			name:    "zero interval",
+			args:    []string{"--retention-check-interval-seconds", "0"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prepareFlagParsingTest(t)
+			os.Args = append([]string{os.Args[0]}, tc.args...)
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			store := &RetentionParameters{}
+			ResetStore()
+			AddStore(store)
+			err := Parse()
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Parse() returned no error, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, store); diff != "" {
+				t.Errorf("unexpected result (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/filter"
+	"github.com/GoogleCloudPlatform/khi/pkg/common/typedmap"
+	coreinspection "github.com/GoogleCloudPlatform/khi/pkg/core/inspection"
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	"github.com/gin-gonic/gin"
+)
+
+// eventStreamMinInterval is the minimum interval between events sent to a client of an event stream.
+// Changes happening within the interval are merged into one event to avoid flooding the clients with frequent progress updates.
+var eventStreamMinInterval = 500 * time.Millisecond
+
+// Names of the server-sent events.
+const (
+	// EventNameInspections is the event containing the GetInspectionsResponse. This is sent when any inspection in the list is changed.
+	EventNameInspections = "inspections"
+	// EventNameProgress is the event containing the inspectionmetadata.Progress of an inspection.
+	EventNameProgress = "progress"
+	// EventNameErrorMessages is the event containing the inspectionmetadata.ErrorMessageSetMetadata of an inspection.
+	// This is not named `error` because it is reserved for connection errors by EventSource in browsers.
+	EventNameErrorMessages = "errorMessages"
+	// EventNameLog is the event containing the logs of tasks appended after the previous log event as []inspectionmetadata.SerializableLogItem.
+	EventNameLog = "log"
+	// EventNameEnd is the last event sent after the inspection is finished.
+	EventNameEnd = "end"
+)
+
+// newGetInspectionsResponse returns the list of started inspections on the inspection server.
+func newGetInspectionsResponse(inspectionServer *coreinspection.InspectionTaskServer, serverConfig *ServerConfig) (*GetInspectionsResponse, error) {
+	responseInspections := map[string]SerializedMetadata{}
+	for _, inspection := range inspectionServer.GetAllRunners() {
+		if inspection.Started() {
+			md, err := inspection.GetCurrentMetadata()
+			if err != nil {
+				return nil, err
+			}
+
+			m, err := inspectionmetadata.GetSerializableSubsetMapFromMetadataSet(md, filter.NewEnabledFilter(inspectionmetadata.LabelKeyIncludedInTaskListFlag, false))
+			if err != nil {
+				return nil, err
+			}
+			responseInspections[inspection.ID] = m
+		}
+	}
+	return &GetInspectionsResponse{
+		Inspections: responseInspections,
+		ServerStat: &ServerStat{
+			TotalMemoryAvailable: serverConfig.ResourceMonitor.GetUsedMemory(),
+		},
+	}, nil
+}
+
+// prepareEventStream writes the headers of server-sent events.
+func prepareEventStream(ctx *gin.Context) {
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	// Disable response buffering on reverse proxies like nginx.
+	ctx.Header("X-Accel-Buffering", "no")
+}
+
+// waitNextChange waits for the given channel to be closed and the minimum interval from the previous event.
+// It returns false when the client is disconnected.
+func waitNextChange(ctx *gin.Context, changed <-chan struct{}, lastSentTime time.Time) bool {
+	select {
+	case <-ctx.Request.Context().Done():
+		return false
+	case <-changed:
+	}
+	select {
+	case <-ctx.Request.Context().Done():
+		return false
+	case <-time.After(time.Until(lastSentTime.Add(eventStreamMinInterval))):
+		return true
+	}
+}
+
+// streamInspectionListEvents sends EventNameInspections events until the client is disconnected.
+func streamInspectionListEvents(ctx *gin.Context, inspectionServer *coreinspection.InspectionTaskServer, serverConfig *ServerConfig) {
+	prepareEventStream(ctx)
+	var lastInspections []byte
+	var lastSentTime time.Time
+	for {
+		// Get the channel before reading the current state not to miss changes made during sending the event.
+		changed := inspectionServer.Notifier().Changed()
+		// Failures can be transient while an inspection is being started. Skip sending the list until the next change.
+		response, err := newGetInspectionsResponse(inspectionServer, serverConfig)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to generate the inspection list for the event stream", "error", err)
+		} else if inspections, err := json.Marshal(response.Inspections); err != nil {
+			slog.WarnContext(ctx, "failed to serialize the inspection list for the event stream", "error", err)
+		} else if !bytes.Equal(lastInspections, inspections) {
+			ctx.SSEvent(EventNameInspections, response)
+			ctx.Writer.Flush()
+			lastInspections = inspections
+			lastSentTime = time.Now()
+		}
+		if !waitNextChange(ctx, changed, lastSentTime) {
+			return
+		}
+	}
+}
+
+// inspectionEventStreamState holds the last sent state of an inspection to send only the changed metadata.
+type inspectionEventStreamState struct {
+	lastProgress   []byte
+	lastError      []byte
+	readLogLengths map[string]int
+}
+
+// streamInspectionEvents sends the events of the given inspection until the inspection is finished or the client is disconnected.
+func streamInspectionEvents(ctx *gin.Context, inspection *coreinspection.InspectionTaskRunner) {
+	prepareEventStream(ctx)
+	state := &inspectionEventStreamState{
+		readLogLengths: map[string]int{},
+	}
+	var lastSentTime time.Time
+	for {
+		changed := inspection.Notifier().Changed()
+		// Read the finished flag before sending the metadata to send the final state of the inspection before the end event.
+		finished := inspection.Finished()
+		if inspection.Started() {
+			md, err := inspection.GetCurrentMetadata()
+			if err != nil {
+				slog.ErrorContext(ctx, fmt.Sprintf("failed to get the metadata of inspection %s for the event stream", inspection.ID), "error", err)
+				return
+			}
+			sent, err := sendInspectionMetadataEvents(ctx, md, state)
+			if err != nil {
+				slog.WarnContext(ctx, fmt.Sprintf("failed to send the metadata of inspection %s to the event stream", inspection.ID), "error", err)
+			}
+			if sent {
+				lastSentTime = time.Now()
+			}
+		}
+		if finished {
+			ctx.SSEvent(EventNameEnd, inspection.ID)
+			ctx.Writer.Flush()
+			return
+		}
+		if !waitNextChange(ctx, changed, lastSentTime) {
+			return
+		}
+	}
+}
+
+// sendInspectionMetadataEvents sends the metadata changed from the last sent state. It returns true when any event was sent.
+func sendInspectionMetadataEvents(ctx *gin.Context, md *typedmap.ReadonlyTypedMap, state *inspectionEventStreamState) (bool, error) {
+	sent := false
+	if progress, found := typedmap.Get(md, inspectionmetadata.ProgressMetadataKey); found {
+		serialized, err := json.Marshal(progress.ToSerializable())
+		if err != nil {
+			return false, err
+		}
+		if !bytes.Equal(state.lastProgress, serialized) {
+			ctx.SSEvent(EventNameProgress, string(serialized))
+			state.lastProgress = serialized
+			sent = true
+		}
+	}
+	if errorSet, found := typedmap.Get(md, inspectionmetadata.ErrorMessageSetMetadataKey); found {
+		serialized, err := json.Marshal(errorSet.ToSerializable())
+		if err != nil {
+			return false, err
+		}
+		if !bytes.Equal(state.lastError, serialized) {
+			ctx.SSEvent(EventNameErrorMessages, string(serialized))
+			state.lastError = serialized
+			sent = true
+		}
+	}
+	if logMetadata, found := typedmap.Get(md, inspectionmetadata.LogMetadataKey); found {
+		if logs := logMetadata.LogsSince(state.readLogLengths); len(logs) > 0 {
+			ctx.SSEvent(EventNameLog, logs)
+			sent = true
+		}
+	}
+	if sent {
+		ctx.Writer.Flush()
+	}
+	return sent, nil
+}
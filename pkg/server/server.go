@@ -17,16 +17,16 @@ package server
 import (
 	"errors"
 	"fmt"
+	"io"
 	"math"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"path"
 	"strconv"
 	"strings"
 
-	"github.com/GoogleCloudPlatform/khi/pkg/common/filter"
 	coreinspection "github.com/GoogleCloudPlatform/khi/pkg/core/inspection"
-	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
 	"github.com/GoogleCloudPlatform/khi/pkg/parameters"
 	"github.com/GoogleCloudPlatform/khi/pkg/server/config"
 	"github.com/GoogleCloudPlatform/khi/pkg/server/popup"
@@ -97,31 +97,30 @@ func CreateKHIServer(engine *gin.Engine, inspectionServer *coreinspection.Inspec
 		// GET /api/v3/inspection
 		// Returns the all started inspections on the inspection server.
 		router.GET("/api/v3/inspection", func(ctx *gin.Context) {
-			inspections := inspectionServer.GetAllRunners()
-			responseInspections := map[string]SerializedMetadata{}
-			for _, inspection := range inspections {
-				if inspection.Started() {
-					md, err := inspection.GetCurrentMetadata()
-					if err != nil {
-						ctx.String(http.StatusInternalServerError, err.Error())
-						return
-					}
-
-					m, err := inspectionmetadata.GetSerializableSubsetMapFromMetadataSet(md, filter.NewEnabledFilter(inspectionmetadata.LabelKeyIncludedInTaskListFlag, false))
-					if err != nil {
-						ctx.String(http.StatusInternalServerError, err.Error())
-						return
-					}
-					responseInspections[inspection.ID] = m
-				}
+			response, err := newGetInspectionsResponse(inspectionServer, serverConfig)
+			if err != nil {
+				ctx.String(http.StatusInternalServerError, err.Error())
+				return
 			}
+			ctx.JSON(http.StatusOK, response)
+		})
 
-			ctx.JSON(http.StatusOK, &GetInspectionsResponse{
-				Inspections: responseInspections,
-				ServerStat: &ServerStat{
-					TotalMemoryAvailable: serverConfig.ResourceMonitor.GetUsedMemory(),
-				},
-			})
+		// GET /api/v3/inspection/events
+		// Streams the list of started inspections as server-sent events whenever any of them is changed.
+		router.GET("/api/v3/inspection/events", func(ctx *gin.Context) {
+			streamInspectionListEvents(ctx, inspectionServer, serverConfig)
+		})
+
+		// GET /api/v3/inspection/:inspectionID/events
+		// Streams the progress, error messages and logs of the inspection as server-sent events until the inspection finishes.
+		router.GET("/api/v3/inspection/:inspectionID/events", func(ctx *gin.Context) {
+			inspectionID := ctx.Param("inspectionID")
+			currentTask := inspectionServer.GetInspection(inspectionID)
+			if currentTask == nil {
+				ctx.String(http.StatusNotFound, fmt.Sprintf("inspecton %s was not found", inspectionID))
+				return
+			}
+			streamInspectionEvents(ctx, currentTask)
 		})
 
 		// POST /api/v3/inspection/tasks
@@ -252,6 +251,43 @@ func CreateKHIServer(engine *gin.Engine, inspectionServer *coreinspection.Inspec
 			ctx.String(http.StatusOK, "ok")
 		})
 
+		router.POST("/api/v3/inspection/:inspectionID/resume", func(ctx *gin.Context) {
+			inspectionID := ctx.Param("inspectionID")
+			currentTask := inspectionServer.GetInspection(inspectionID)
+			if currentTask == nil {
+				ctx.String(http.StatusNotFound, fmt.Sprintf("inspecton %s was not found", inspectionID))
+				return
+			}
+			err := currentTask.Resume(ctx)
+			if errors.Is(err, coreinspection.ErrInspectionNotResumable) {
+				ctx.String(http.StatusBadRequest, err.Error())
+				return
+			}
+			if err != nil {
+				ctx.String(http.StatusInternalServerError, err.Error())
+				return
+			}
+			ctx.String(http.StatusAccepted, "ok")
+		})
+
+		router.DELETE("/api/v3/inspection/:inspectionID", func(ctx *gin.Context) {
+			inspectionID := ctx.Param("inspectionID")
+			err := inspectionServer.DeleteInspection(ctx, inspectionID)
+			if errors.Is(err, coreinspection.ErrInspectionNotFound) {
+				ctx.String(http.StatusNotFound, fmt.Sprintf("inspecton %s was not found", inspectionID))
+				return
+			}
+			if errors.Is(err, coreinspection.ErrInspectionRunning) {
+				ctx.String(http.StatusConflict, err.Error())
+				return
+			}
+			if err != nil {
+				ctx.String(http.StatusInternalServerError, err.Error())
+				return
+			}
+			ctx.String(http.StatusOK, "ok")
+		})
+
 		router.GET("/api/v3/inspection/:inspectionID/metadata", func(ctx *gin.Context) {
 			inspectionID := ctx.Param("inspectionID")
 			currentTask := inspectionServer.GetInspection(inspectionID)
@@ -375,11 +411,16 @@ func CreateKHIServer(engine *gin.Engine, inspectionServer *coreinspection.Inspec
 				ctx.String(http.StatusBadRequest, "invalid operation. Current UploadFileStore.StoreProvider is not supporting to be written directly")
 				return
 			}
-			file, err := ctx.FormFile("file")
+			form, err := ctx.MultipartForm()
 			if err != nil {
 				ctx.String(http.StatusBadRequest, err.Error())
 				return
 			}
+			files := form.File["file"]
+			if len(files) == 0 {
+				ctx.String(http.StatusBadRequest, "missing file")
+				return
+			}
 
 			id := ctx.Request.FormValue("upload-token-id")
 			if id == "" {
@@ -388,7 +429,11 @@ func CreateKHIServer(engine *gin.Engine, inspectionServer *coreinspection.Inspec
 			}
 
 			token := &upload.DirectUploadToken{ID: id}
-			if parameters.Server.MaxUploadFileSizeInBytes != nil && *parameters.Server.MaxUploadFileSizeInBytes < int(file.Size) {
+			var totalSize int64
+			for _, file := range files {
+				totalSize += file.Size
+			}
+			if parameters.Server.MaxUploadFileSizeInBytes != nil && int64(*parameters.Server.MaxUploadFileSizeInBytes) < totalSize {
 				ctx.String(http.StatusBadRequest, fmt.Sprintf("file size exceeds the limit (%d bytes)", *parameters.Server.MaxUploadFileSizeInBytes))
 				return
 			}
@@ -398,15 +443,16 @@ func CreateKHIServer(engine *gin.Engine, inspectionServer *coreinspection.Inspec
 				ctx.String(http.StatusInternalServerError, err.Error())
 				return
 			}
-
-			multipart, err := file.Open()
-			if err != nil {
-				ctx.String(http.StatusBadRequest, err.Error())
-				return
+			// Keep the name of a single file for the readers deciding the log source from the file name. Multiple files keep their names in the tar archive.
+			if len(files) == 1 {
+				err = serverConfig.UploadFileStore.SetUploadedFileName(token, path.Base(files[0].Filename))
+				if err != nil {
+					ctx.String(http.StatusInternalServerError, err.Error())
+					return
+				}
 			}
-			defer multipart.Close()
 
-			err = localUploadFileStoreProvider.Write(token, multipart)
+			err = writeUploadedFiles(localUploadFileStoreProvider, token, files)
 			if err != nil {
 				serverConfig.UploadFileStore.SetResultOnCompletedUpload(token, err)
 				ctx.String(http.StatusInternalServerError, err.Error())
@@ -419,3 +465,23 @@ func CreateKHIServer(engine *gin.Engine, inspectionServer *coreinspection.Inspec
 	}
 	return engine
 }
+
+// writeUploadedFiles stores the uploaded files with the token. Multiple files uploaded at once are stored as a tar archive.
+func writeUploadedFiles(storeProvider *upload.LocalUploadFileStoreProvider, token upload.UploadToken, files []*multipart.FileHeader) error {
+	if len(files) == 1 {
+		file, err := files[0].Open()
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return storeProvider.Write(token, file)
+	}
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(upload.WriteTarArchive(writer, files))
+	}()
+	err := storeProvider.Write(token, reader)
+	// Unblock the writer goroutine when the store failed before reading the whole archive.
+	reader.CloseWithError(err)
+	return err
+}
@@ -684,6 +684,68 @@ func TestApiResponses(t *testing.T) {
 				ViewerMode: true,
 			}),
 		},
+		{
+			// 041
+			// The stream of a finished inspection ends after sending its final state.
+			ExpectedCode:  200,
+			RequestMethod: "GET",
+			RequestPath:   "/foo/api/v3/inspection/<task-3>/events",
+			BodyValidator: func(t *testing.T, body string, stat map[string]string) {
+				for _, want := range []string{"event:progress\ndata:{\"phase\":\"ERROR\"", "event:errorMessages\n", "event:end\n"} {
+					if !strings.Contains(body, want) {
+						t.Errorf("event stream doesn't contain %q\n%s", want, body)
+					}
+				}
+			},
+		},
+		{
+			// 042
+			ExpectedCode:  200,
+			RequestMethod: "DELETE",
+			RequestPath:   "/foo/api/v3/inspection/<task-3>",
+			BodyValidator: bodyCompareWithStringExpectedValue("ok"),
+		},
+		{
+			// 043
+			ExpectedCode:  404,
+			RequestMethod: "GET",
+			RequestPath:   "/foo/api/v3/inspection/<task-3>/metadata",
+		},
+		{
+			// 044
+			ExpectedCode:  404,
+			RequestMethod: "DELETE",
+			RequestPath:   "/foo/api/v3/inspection/<task-3>",
+		},
+		{
+			// 045
+			// Attempting to resume a successfully finished task
+			ExpectedCode:  400,
+			RequestMethod: "POST",
+			RequestPath:   "/foo/api/v3/inspection/<task-1>/resume",
+		},
+		{
+			// 046
+			ExpectedCode:  202,
+			RequestMethod: "POST",
+			RequestPath:   "/foo/api/v3/inspection/<task-2>/resume",
+			BodyValidator: bodyCompareWithStringExpectedValue("ok"),
+			WaitAfter:     time.Second,
+		},
+		{
+			// 047
+			// The resumed task is running again
+			ExpectedCode:  200,
+			RequestMethod: "POST",
+			RequestPath:   "/foo/api/v3/inspection/<task-2>/cancel",
+			WaitAfter:     time.Second,
+		},
+		{
+			// 048
+			ExpectedCode:  404,
+			RequestMethod: "POST",
+			RequestPath:   "/foo/api/v3/inspection/<task-3>/resume",
+		},
 	}
 
 	stat := map[string]string{}
@@ -951,3 +1013,168 @@ func TestKHIDirectFileUpload(t *testing.T) {
 		})
 	}
 }
+
+func TestKHIDirectFileUpload_MultipleFiles(t *testing.T) {
+	logger.InitGlobalKHILogger()
+	provider := upload.NewLocalUploadFileStoreProvider(t.TempDir())
+	store := upload.NewUploadFileStore(provider)
+	token := store.GetUploadToken("test-token", &upload.NopWaitUploadFileVerifier{})
+	serverConfig := ServerConfig{
+		StaticFolderPath: "../../dist",
+		ResourceMonitor:  &ResourceMonitorMock{UsedMemory: 1000},
+		ServerBasePath:   "/foo",
+		UploadFileStore:  store,
+	}
+	inspectionServer, err := createTestInspectionServer()
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	engine := CreateKHIServer(gin.New(), inspectionServer, &serverConfig)
+	parameters.Server.MaxUploadFileSizeInBytes = testutil.P(1024)
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	writer.WriteField("upload-token-id", "test-token")
+	for _, name := range []string{"a.log", "b.log"} {
+		fileWriter, err := writer.CreateFormFile("file", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fileWriter.Write([]byte("content of " + name))
+	}
+	writer.Close()
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/foo/api/v3/upload", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	engine.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("got response code %d(%s), want %d", recorder.Code, recorder.Body.String(), http.StatusOK)
+	}
+
+	reader, err := provider.Read(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	got := map[string]string{}
+	err = upload.WalkUploadedFiles(reader, func(name string, reader io.Reader) error {
+		content, err := io.ReadAll(reader)
+		got[name] = string(content)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to read the stored archive: %v", err)
+	}
+	want := map[string]string{"a.log": "content of a.log", "b.log": "content of b.log"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("stored files mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestKHIDirectFileUpload_SingleFileName(t *testing.T) {
+	logger.InitGlobalKHILogger()
+	provider := upload.NewLocalUploadFileStoreProvider(t.TempDir())
+	store := upload.NewUploadFileStore(provider)
+	token := store.GetUploadToken("test-token", &upload.NopWaitUploadFileVerifier{})
+	serverConfig := ServerConfig{
+		StaticFolderPath: "../../dist",
+		ResourceMonitor:  &ResourceMonitorMock{UsedMemory: 1000},
+		ServerBasePath:   "/foo",
+		UploadFileStore:  store,
+	}
+	inspectionServer, err := createTestInspectionServer()
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	engine := CreateKHIServer(gin.New(), inspectionServer, &serverConfig)
+	parameters.Server.MaxUploadFileSizeInBytes = testutil.P(1024)
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	writer.WriteField("upload-token-id", "test-token")
+	fileWriter, err := writer.CreateFormFile("file", "kube-scheduler.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileWriter.Write([]byte("content of kube-scheduler.log"))
+	writer.Close()
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/foo/api/v3/upload", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	engine.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("got response code %d(%s), want %d", recorder.Code, recorder.Body.String(), http.StatusOK)
+	}
+
+	result, err := store.GetResult(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.FileName != "kube-scheduler.log" {
+		t.Errorf("got file name %q, want %q", result.FileName, "kube-scheduler.log")
+	}
+	reader, err := provider.Read(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "content of kube-scheduler.log" {
+		t.Errorf("got stored content %q, want the uploaded file as it is", string(content))
+	}
+}
+
+func TestKHIServer_InspectionListEvents(t *testing.T) {
+	inspectionServer, err := createTestInspectionServer()
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	serverConfig := ServerConfig{
+		StaticFolderPath: "../../dist",
+		ResourceMonitor:  &ResourceMonitorMock{UsedMemory: 1000},
+		ServerBasePath:   "/",
+	}
+	engine := CreateKHIServer(gin.New(), inspectionServer, &serverConfig)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(ctx, "GET", "/api/v3/inspection/events", nil)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		engine.ServeHTTP(recorder, req)
+	}()
+
+	// Wait the first event before changing the list.
+	<-time.After(100 * time.Millisecond)
+	inspectionID, err := inspectionServer.CreateInspection("foo")
+	if err != nil {
+		t.Fatalf("CreateInspection() returned an unexpected error: %v", err)
+	}
+	if err := inspectionServer.GetInspection(inspectionID).SetFeatureList([]string{"feature-foo1#default"}); err != nil {
+		t.Fatalf("SetFeatureList() returned an unexpected error: %v", err)
+	}
+	if err := inspectionServer.GetInspection(inspectionID).Run(context.Background(), &inspectioncore_contract.InspectionRequest{Values: map[string]any{"foo-input": "foo"}}); err != nil {
+		t.Fatalf("Run() returned an unexpected error: %v", err)
+	}
+	<-inspectionServer.GetInspection(inspectionID).Wait()
+	<-time.After(eventStreamMinInterval + 100*time.Millisecond)
+	cancel()
+	<-done
+
+	body := recorder.Body.String()
+	if got := strings.Count(body, "event:inspections\n"); got < 2 {
+		t.Errorf("event stream contains %d inspections events, want at least 2\n%s", got, body)
+	}
+	if !strings.HasPrefix(body, "event:inspections\ndata:{\"inspections\":{},") {
+		t.Errorf("the first event is not the empty inspection list\n%s", body)
+	}
+	if !strings.Contains(body, inspectionID) {
+		t.Errorf("event stream doesn't contain the started inspection %s\n%s", inspectionID, body)
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upload
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path"
+)
+
+// maxArchiveNestingDepth is the maximum depth of archives contained in other archives.
+// This prevents infinite recursion on crafted archives.
+const maxArchiveNestingDepth = 4
+
+// defaultLineScannerBufferSize is the initial size of the buffer used by the scanner returned from NewLineScanner.
+const defaultLineScannerBufferSize = 64 * 1024
+
+// fileSniffSize is the byte size read ahead to detect the format of a file. This is the size of a tar header block.
+const fileSniffSize = 512
+
+var (
+	gzipMagicBytes = []byte{0x1f, 0x8b}
+	zipMagicBytes  = []byte("PK\x03\x04")
+	// tarMagicBytes is the magic field of POSIX and GNU tar headers located at tarMagicOffset.
+	tarMagicBytes  = []byte("ustar")
+	tarMagicOffset = 257
+)
+
+// UploadedFileWalkFunc is the type of the function called for each file found in an uploaded file.
+// name is the path of the file in the archive, or an empty string when the uploaded file is not an archive.
+type UploadedFileWalkFunc = func(name string, reader io.Reader) error
+
+// WalkUploadedFiles calls walkFunc with every regular file contained in the given uploaded file.
+// gzip compressed files are decompressed transparently, and tar and zip archives are expanded into the files in them.
+// Files are passed in the order they appear in the archive.
+func WalkUploadedFiles(reader io.Reader, walkFunc UploadedFileWalkFunc) error {
+	return walkFile("", reader, 0, walkFunc)
+}
+
+// WalkUploadedFilesWithName is WalkUploadedFiles for the uploaded file with the known name (e.g. UploadResult.FileName).
+// walkFunc receives the name when the uploaded file is not an archive, and the files in an archive are named under it.
+func WalkUploadedFilesWithName(name string, reader io.Reader, walkFunc UploadedFileWalkFunc) error {
+	return walkFile(name, reader, 0, walkFunc)
+}
+
+// NewLineScanner returns a bufio.Scanner reading lines from the reader with a buffer growing up to maxLineSizeInBytes.
+// The buffer is allocated lazily, so a large maxLineSizeInBytes doesn't consume memory until a long line is found.
+func NewLineScanner(reader io.Reader, maxLineSizeInBytes int) *bufio.Scanner {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, min(defaultLineScannerBufferSize, maxLineSizeInBytes)), maxLineSizeInBytes)
+	return scanner
+}
+
+// WriteTarArchive writes the files uploaded at once as a tar archive. This allows storing multiple files with an UploadToken.
+func WriteTarArchive(writer io.Writer, files []*multipart.FileHeader) error {
+	tarWriter := tar.NewWriter(writer)
+	for _, file := range files {
+		if err := writeTarEntry(tarWriter, file); err != nil {
+			return err
+		}
+	}
+	return tarWriter.Close()
+}
+
+func writeTarEntry(tarWriter *tar.Writer, file *multipart.FileHeader) error {
+	reader, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open the uploaded file %s: %w", file.Filename, err)
+	}
+	defer reader.Close()
+	err = tarWriter.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     path.Base(file.Filename),
+		Size:     file.Size,
+		Mode:     0600,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write the tar header of %s: %w", file.Filename, err)
+	}
+	if _, err := io.Copy(tarWriter, reader); err != nil {
+		return fmt.Errorf("failed to write %s to the tar archive: %w", file.Filename, err)
+	}
+	return nil
+}
+
+func walkFile(name string, reader io.Reader, depth int, walkFunc UploadedFileWalkFunc) error {
+	if depth > maxArchiveNestingDepth {
+		return fmt.Errorf("%s: archives are nested too deeply", displayName(name))
+	}
+	bufferedReader := bufio.NewReaderSize(reader, fileSniffSize)
+	head, err := bufferedReader.Peek(fileSniffSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read %s: %w", displayName(name), err)
+	}
+	switch {
+	case bytes.HasPrefix(head, gzipMagicBytes):
+		gzipReader, err := gzip.NewReader(bufferedReader)
+		if err != nil {
+			return fmt.Errorf("failed to decompress %s: %w", displayName(name), err)
+		}
+		defer gzipReader.Close()
+		return walkFile(trimCompressionExtension(name), gzipReader, depth+1, walkFunc)
+	case bytes.HasPrefix(head, zipMagicBytes):
+		return walkZipArchive(name, bufferedReader, reader, depth, walkFunc)
+	case len(head) >= tarMagicOffset+len(tarMagicBytes) && bytes.Equal(head[tarMagicOffset:tarMagicOffset+len(tarMagicBytes)], tarMagicBytes):
+		return walkTarArchive(name, bufferedReader, depth, walkFunc)
+	default:
+		return walkFunc(name, bufferedReader)
+	}
+}
+
+func walkTarArchive(name string, reader io.Reader, depth int, walkFunc UploadedFileWalkFunc) error {
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the tar archive %s: %w", displayName(name), err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := walkFile(path.Join(name, header.Name), tarReader, depth+1, walkFunc); err != nil {
+			return err
+		}
+	}
+}
+
+// walkZipArchive expands a zip archive. zip requires random access to the file, so the archive is copied to a temporary file
+// unless the original reader is a file.
+func walkZipArchive(name string, bufferedReader *bufio.Reader, original io.Reader, depth int, walkFunc UploadedFileWalkFunc) error {
+	// zip.Reader reads the archive with absolute offsets. It can use the original reader directly only when it's the uploaded file itself.
+	file, isFile := original.(*os.File)
+	if !isFile {
+		temporaryFile, err := os.CreateTemp(TemporaryFolder, "khi-upload-*.zip")
+		if err != nil {
+			return fmt.Errorf("failed to create a temporary file to expand %s: %w", displayName(name), err)
+		}
+		defer os.Remove(temporaryFile.Name())
+		defer temporaryFile.Close()
+		if _, err := io.Copy(temporaryFile, bufferedReader); err != nil {
+			return fmt.Errorf("failed to copy %s to a temporary file: %w", displayName(name), err)
+		}
+		file = temporaryFile
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to get the size of %s: %w", displayName(name), err)
+	}
+	zipReader, err := zip.NewReader(file, stat.Size())
+	if err != nil {
+		return fmt.Errorf("failed to read the zip archive %s: %w", displayName(name), err)
+	}
+	for _, entry := range zipReader.File {
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+		if err := walkZipEntry(path.Join(name, entry.Name), entry, depth, walkFunc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkZipEntry(name string, entry *zip.File, depth int, walkFunc UploadedFileWalkFunc) error {
+	reader, err := entry.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer reader.Close()
+	return walkFile(name, reader, depth+1, walkFunc)
+}
+
+// trimCompressionExtension removes the .gz extension from the name of a gzip compressed file.
+// .tgz is converted to .tar to keep the name of the archive meaningful.
+func trimCompressionExtension(name string) string {
+	switch path.Ext(name) {
+	case ".gz":
+		return name[:len(name)-len(".gz")]
+	case ".tgz":
+		return name[:len(name)-len(".tgz")] + ".tar"
+	}
+	return name
+}
+
+func displayName(name string) string {
+	if name == "" {
+		return "the uploaded file"
+	}
+	return name
+}
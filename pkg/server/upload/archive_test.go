@@ -0,0 +1,349 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upload
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type testArchiveEntry struct {
+	name    string
+	content []byte
+}
+
+func gzipBytes(t *testing.T, content []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tarBytes(t *testing.T, entries []testArchiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	if err := writer.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "logs/", Mode: 0700}); err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if err := writer.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: entry.name, Size: int64(len(entry.content)), Mode: 0600}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write(entry.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zipBytes(t *testing.T, entries []testArchiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	if _, err := writer.Create("logs/"); err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		fileWriter, err := writer.Create(entry.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fileWriter.Write(entry.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func walkAll(reader io.Reader) ([]string, error) {
+	result := []string{}
+	err := WalkUploadedFiles(reader, func(name string, reader io.Reader) error {
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		result = append(result, name+"="+string(content))
+		return nil
+	})
+	return result, err
+}
+
+func TestWalkUploadedFiles(t *testing.T) {
+	entries := []testArchiveEntry{
+		{name: "logs/a.log", content: []byte("a1\na2\n")},
+		{name: "logs/b.log.gz", content: gzipBytes(t, []byte("b1\n"))},
+	}
+	testCases := []struct {
+		name    string
+		content func(t *testing.T) []byte
+		want    []string
+	}{
+		{
+			name: "plain file",
+			content: func(t *testing.T) []byte {
+				return []byte("line1\nline2\n")
+			},
+			want: []string{"=line1\nline2\n"},
+		},
+		{
+			name: "empty file",
+			content: func(t *testing.T) []byte {
+				return []byte{}
+			},
+			want: []string{"="},
+		},
+		{
+			name: "gzip compressed file",
+			content: func(t *testing.T) []byte {
+				return gzipBytes(t, []byte("line1\n"))
+			},
+			want: []string{"=line1\n"},
+		},
+		{
+			name: "tar archive",
+			content: func(t *testing.T) []byte {
+				return tarBytes(t, entries)
+			},
+			want: []string{"logs/a.log=a1\na2\n", "logs/b.log=b1\n"},
+		},
+		{
+			name: "gzip compressed tar archive",
+			content: func(t *testing.T) []byte {
+				return gzipBytes(t, tarBytes(t, entries))
+			},
+			want: []string{"logs/a.log=a1\na2\n", "logs/b.log=b1\n"},
+		},
+		{
+			name: "zip archive",
+			content: func(t *testing.T) []byte {
+				return zipBytes(t, entries)
+			},
+			want: []string{"logs/a.log=a1\na2\n", "logs/b.log=b1\n"},
+		},
+		{
+			name: "zip archive in tar archive",
+			content: func(t *testing.T) []byte {
+				return tarBytes(t, []testArchiveEntry{{name: "inner.zip", content: zipBytes(t, entries)}})
+			},
+			want: []string{"inner.zip/logs/a.log=a1\na2\n", "inner.zip/logs/b.log=b1\n"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := walkAll(bytes.NewReader(tc.content(t)))
+			if err != nil {
+				t.Fatalf("WalkUploadedFiles() returned an unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("WalkUploadedFiles() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWalkUploadedFilesWithName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fileName string
+		content  []byte
+		want     []string
+	}{
+		{
+			name:     "plain file",
+			fileName: "kube-scheduler.log",
+			content:  []byte("line1\n"),
+			want:     []string{"kube-scheduler.log=line1\n"},
+		},
+		{
+			name:     "gzip compressed file",
+			fileName: "kube-scheduler.log.gz",
+			content:  gzipBytes(t, []byte("line1\n")),
+			want:     []string{"kube-scheduler.log=line1\n"},
+		},
+		{
+			name:     "tar archive",
+			fileName: "logs.tar",
+			content:  tarBytes(t, []testArchiveEntry{{name: "a.log", content: []byte("a")}}),
+			want:     []string{"logs.tar/a.log=a"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := []string{}
+			err := WalkUploadedFilesWithName(tc.fileName, bytes.NewReader(tc.content), func(name string, reader io.Reader) error {
+				content, err := io.ReadAll(reader)
+				if err != nil {
+					return err
+				}
+				got = append(got, name+"="+string(content))
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("WalkUploadedFilesWithName() returned an unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("WalkUploadedFilesWithName() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWalkUploadedFiles_ZipFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "upload")
+	if err := os.WriteFile(filePath, zipBytes(t, []testArchiveEntry{{name: "a.log", content: []byte("a")}}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	got, err := walkAll(file)
+	if err != nil {
+		t.Fatalf("WalkUploadedFiles() returned an unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"a.log=a"}, got); diff != "" {
+		t.Errorf("WalkUploadedFiles() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWalkUploadedFiles_ZipTemporaryFile(t *testing.T) {
+	temporaryFolder := t.TempDir()
+	originalTemporaryFolder := TemporaryFolder
+	TemporaryFolder = temporaryFolder
+	defer func() { TemporaryFolder = originalTemporaryFolder }()
+
+	// A zip archive in a gzip compressed file is copied to a temporary file to be read randomly.
+	reader := bytes.NewReader(gzipBytes(t, zipBytes(t, []testArchiveEntry{{name: "a.log", content: []byte("a")}})))
+	var temporaryFiles []string
+	err := WalkUploadedFiles(reader, func(name string, reader io.Reader) error {
+		entries, err := os.ReadDir(temporaryFolder)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			temporaryFiles = append(temporaryFiles, entry.Name())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkUploadedFiles() returned an unexpected error: %v", err)
+	}
+	if len(temporaryFiles) != 1 || !strings.HasPrefix(temporaryFiles[0], "khi-upload-") {
+		t.Errorf("temporary files while walking = %v, want a khi-upload-* file in the temporary folder", temporaryFiles)
+	}
+	entries, err := os.ReadDir(temporaryFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("%d files remain in the temporary folder after walking", len(entries))
+	}
+}
+
+func TestWalkUploadedFiles_TooDeeplyNested(t *testing.T) {
+	content := []byte("line")
+	for i := 0; i <= maxArchiveNestingDepth+1; i++ {
+		content = gzipBytes(t, content)
+	}
+	_, err := walkAll(bytes.NewReader(content))
+	if err == nil || !strings.Contains(err.Error(), "nested too deeply") {
+		t.Errorf("WalkUploadedFiles() returned %v, want an error about the nesting depth", err)
+	}
+}
+
+func TestWalkUploadedFiles_WalkFuncError(t *testing.T) {
+	wantErr := errors.New("test error")
+	err := WalkUploadedFiles(bytes.NewReader(tarBytes(t, []testArchiveEntry{{name: "a.log", content: []byte("a")}})), func(name string, reader io.Reader) error {
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Errorf("WalkUploadedFiles() returned %v, want %v", err, wantErr)
+	}
+}
+
+func TestNewLineScanner(t *testing.T) {
+	scanner := NewLineScanner(strings.NewReader("short\n"+strings.Repeat("a", 100)+"\n"), 50)
+	got := []string{}
+	for scanner.Scan() {
+		got = append(got, scanner.Text())
+	}
+	if diff := cmp.Diff([]string{"short"}, got); diff != "" {
+		t.Errorf("scanned lines mismatch (-want +got):\n%s", diff)
+	}
+	if !errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		t.Errorf("scanner.Err() = %v, want %v", scanner.Err(), bufio.ErrTooLong)
+	}
+}
+
+func TestWriteTarArchive(t *testing.T) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, entry := range []testArchiveEntry{{name: "a.log", content: []byte("a")}, {name: "b.log.gz", content: gzipBytes(t, []byte("b"))}} {
+		fileWriter, err := writer.CreateFormFile("file", entry.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fileWriter.Write(entry.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1024 * 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer form.RemoveAll()
+
+	var archive bytes.Buffer
+	if err := WriteTarArchive(&archive, form.File["file"]); err != nil {
+		t.Fatalf("WriteTarArchive() returned an unexpected error: %v", err)
+	}
+	got, err := walkAll(&archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"a.log=a", "b.log=b"}, got); diff != "" {
+		t.Errorf("files in the archive mismatch (-want +got):\n%s", diff)
+	}
+}
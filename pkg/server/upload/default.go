@@ -15,3 +15,6 @@
 package upload
 
 var DefaultUploadFileStore *UploadFileStore = nil
+
+// TemporaryFolder is the folder to create temporary files while reading uploaded files. The default temporary directory of the OS is used when it's empty.
+var TemporaryFolder = ""
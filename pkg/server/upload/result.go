@@ -34,6 +34,8 @@ type UploadResult struct {
 	VerificationError error
 	// VerificationCount is the attempt count of the verification logic. This value is preventing the race condition in verification steps.
 	VerificationCount int
+	// FileName is the name of the uploaded file when a single file is uploaded. It's empty when multiple files are uploaded at once.
+	FileName string
 }
 
 // GetReader returns an io.ReadCloser for reading the uploaded file.
@@ -105,6 +105,23 @@ func (s *UploadFileStore) SetResultOnStartingUpload(token UploadToken) error {
 	return nil
 }
 
+// SetUploadedFileName records the name of the uploaded file on the upload started with SetResultOnStartingUpload.
+func (s *UploadFileStore) SetUploadedFileName(token UploadToken, fileName string) error {
+	err := s.ensureIssuedToken(token)
+	if err != nil {
+		return err
+	}
+	s.resultLock.Lock()
+	defer s.resultLock.Unlock()
+	result, ok := s.results[token.GetID()]
+	if !ok {
+		return fmt.Errorf("upload result not found for token %s", token.GetID())
+	}
+	result.FileName = fileName
+	s.results[token.GetID()] = result
+	return nil
+}
+
 // SetResultOnCompletedUpload notify the file upload is completed and start verifier.
 func (s *UploadFileStore) SetResultOnCompletedUpload(token UploadToken, uploadError error) error {
 	err := s.ensureIssuedToken(token)
@@ -125,6 +142,7 @@ func (s *UploadFileStore) SetResultOnCompletedUpload(token UploadToken, uploadEr
 			Status:            UploadStatusVerifying,
 			UploadError:       uploadError,
 			VerificationCount: nextVerificationIndex,
+			FileName:          prev.FileName,
 		}
 	} else {
 		s.results[token.GetID()] = UploadResult{
@@ -133,6 +151,7 @@ func (s *UploadFileStore) SetResultOnCompletedUpload(token UploadToken, uploadEr
 			Status:            UploadStatusWaiting,
 			UploadError:       uploadError,
 			VerificationCount: prev.VerificationCount,
+			FileName:          prev.FileName,
 		}
 	}
 	if uploadError == nil {
@@ -156,6 +175,7 @@ func (s *UploadFileStore) SetResultOnCompletedUpload(token UploadToken, uploadEr
 				UploadError:       current.UploadError,
 				VerificationError: err,
 				VerificationCount: nextVerificationIndex,
+				FileName:          current.FileName,
 			}
 		}()
 	}
@@ -102,6 +102,55 @@ func TestUploadFileStore(t *testing.T) {
 		}
 	})
 
+	t.Run("SetUploadedFileName_KeptUntilVerified", func(t *testing.T) {
+		store := NewUploadFileStore(provider)
+		verified := make(chan struct{})
+		verifier := &MockUploadFileVerifier{
+			VerifyFunc: func(storeProvider UploadFileStoreProvider, token UploadToken) error {
+				close(verified)
+				return nil
+			},
+		}
+		token := store.GetUploadToken("file-name-id", verifier)
+
+		if err := store.SetResultOnStartingUpload(token); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := store.SetUploadedFileName(token, "kube-scheduler.log"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := store.SetResultOnCompletedUpload(token, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		<-verified
+		for {
+			result, err := store.GetResult(token)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Status != UploadStatusCompleted {
+				<-time.After(100 * time.Microsecond)
+				continue
+			}
+			if result.FileName != "kube-scheduler.log" {
+				t.Errorf("Expected file name 'kube-scheduler.log', got '%v'", result.FileName)
+			}
+			break
+		}
+
+		// The name is cleared on uploading the file again.
+		if err := store.SetResultOnStartingUpload(token); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		result, err := store.GetResult(token)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.FileName != "" {
+			t.Errorf("Expected no file name, got '%v'", result.FileName)
+		}
+	})
+
 	t.Run("SetResultOnStartingUpload_NotFound", func(t *testing.T) {
 		store := NewUploadFileStore(provider)
 
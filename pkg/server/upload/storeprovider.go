@@ -35,6 +35,10 @@ type DirectWritableUploadFileStoreProvider interface {
 	Write(token UploadToken, reader io.Reader) error
 }
 
+// LocalUploadFileNamePrefix is the prefix of the names of the files written by LocalUploadFileStoreProvider.
+// The folder can be shared with other files, and only the files with this prefix are regarded as uploaded files.
+const LocalUploadFileNamePrefix = "khi-upload-"
+
 // LocalUploadFileStoreProvider is an implementation of UploadFileStore that stores files
 // in the local file system.
 type LocalUploadFileStoreProvider struct {
@@ -57,8 +61,7 @@ func (l *LocalUploadFileStoreProvider) Read(token UploadToken) (io.ReadCloser, e
 	if err != nil {
 		return nil, err
 	}
-	filePath := filepath.Join(l.directoryPath, token.GetID())
-	file, err := os.Open(filePath)
+	file, err := os.Open(l.filePath(token))
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, os.ErrNotExist
@@ -77,7 +80,7 @@ func (l *LocalUploadFileStoreProvider) Write(token UploadToken, reader io.Reader
 	if err != nil {
 		return err
 	}
-	filePath := filepath.Join(l.directoryPath, token.GetID())
+	filePath := l.filePath(token)
 	file, err := os.Create(filePath)
 	if err != nil {
 		return err
@@ -93,6 +96,11 @@ func (l *LocalUploadFileStoreProvider) Write(token UploadToken, reader io.Reader
 	return nil
 }
 
+// filePath returns the path of the file storing the uploaded file of the given token.
+func (l *LocalUploadFileStoreProvider) filePath(token UploadToken) string {
+	return filepath.Join(l.directoryPath, LocalUploadFileNamePrefix+token.GetID())
+}
+
 func (l *LocalUploadFileStoreProvider) ensureFolderExists() error {
 	// Create the directory (and any parent directories) if it doesn't exist.
 	// os.MkdirAll will not return an error if the directory already exists.
@@ -17,6 +17,7 @@ package upload
 import (
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -75,6 +76,16 @@ func TestLocalUploadFileStoreProvider_Essential(t *testing.T) {
 		}
 	})
 
+	t.Run("Write_FileNameWithPrefix", func(t *testing.T) {
+		token := store.GetUploadToken("prefixed-token")
+		if err := store.Write(token, strings.NewReader("foo")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(tempDir, "khi-upload-prefixed-token")); err != nil {
+			t.Errorf("Expected the uploaded file to be written with the prefix, got: %v", err)
+		}
+	})
+
 	t.Run("Read_NonExistentFile", func(t *testing.T) {
 		token := store.GetUploadToken("not-uploaded")
 		_, err := store.Read(token)
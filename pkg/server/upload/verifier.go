@@ -15,10 +15,10 @@
 package upload
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"time"
 )
 
@@ -44,7 +44,10 @@ func (n *NopWaitUploadFileVerifier) Verify(storeProvider UploadFileStoreProvider
 
 var _ UploadFileVerifier = &NopWaitUploadFileVerifier{}
 
+// JSONLineUploadFileVerifier verifies every line of the uploaded file is a valid JSON.
+// Archives and gzip compressed files are expanded with WalkUploadedFiles and every file in them is verified.
 type JSONLineUploadFileVerifier struct {
+	// MaxLineSizeInBytes is the maximum size of a line. The buffer to read lines grows up to this size.
 	MaxLineSizeInBytes int
 }
 
@@ -56,8 +59,17 @@ func (j *JSONLineUploadFileVerifier) Verify(storeProvider UploadFileStoreProvide
 	}
 	defer reader.Close()
 
-	scanner := bufio.NewScanner(reader)
-	scanner.Buffer(make([]byte, j.MaxLineSizeInBytes), j.MaxLineSizeInBytes)
+	return WalkUploadedFiles(reader, func(name string, reader io.Reader) error {
+		err := j.verifyFile(reader)
+		if err != nil && name != "" {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return err
+	})
+}
+
+func (j *JSONLineUploadFileVerifier) verifyFile(reader io.Reader) error {
+	scanner := NewLineScanner(reader, j.MaxLineSizeInBytes)
 	lineNumber := 0
 	for scanner.Scan() {
 		lineNumber++
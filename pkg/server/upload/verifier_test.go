@@ -78,3 +78,18 @@ func TestJSONLineUploadFileVerifier(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONLineUploadFileVerifier_Archive(t *testing.T) {
+	archive := gzipBytes(t, tarBytes(t, []testArchiveEntry{
+		{name: "logs/valid.log", content: []byte(`{"name": "Alice"}`)},
+		{name: "logs/invalid.log.gz", content: gzipBytes(t, []byte("{\"name\": \"Bob\"}\n{invalid json}"))},
+	}))
+	verifier := &JSONLineUploadFileVerifier{MaxLineSizeInBytes: 1024 * 1024}
+	provider := &MockLocalUploadFileStoreProvider{Data: string(archive)}
+	err := verifier.Verify(provider, &DirectUploadToken{ID: "test"})
+
+	wantErr := "logs/invalid.log: invalid JSON on line 2"
+	if err == nil || !strings.Contains(err.Error(), wantErr) {
+		t.Errorf("Verify() returned %v, want an error containing %q", err, wantErr)
+	}
+}
@@ -55,6 +55,9 @@ type AuditLogParserInput struct {
 	// RequestTarget is the address of target resource modified by this request.
 	RequestTarget                          string
 	GeneratedFromDeleteCollectionOperation bool
+
+	// StageTiming is the timing of the audit stages of this request. This is nil when the log backend only records completed requests.
+	StageTiming *AuditLogStageTiming
 }
 
 type TimelineGrouperResult struct {
@@ -0,0 +1,76 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commonlogk8saudit_contract
+
+import "time"
+
+// AuditLogStage is the stage of a request when a Kubernetes audit event is generated.
+type AuditLogStage string
+
+const (
+	// AuditLogStageRequestReceived is the stage for events generated as soon as the audit handler receives the request.
+	AuditLogStageRequestReceived AuditLogStage = "RequestReceived"
+	// AuditLogStageResponseStarted is the stage for events generated when the response headers are sent but before the response body is sent. This is only generated for long-running requests like watch or exec.
+	AuditLogStageResponseStarted AuditLogStage = "ResponseStarted"
+	// AuditLogStageResponseComplete is the stage for events generated when the response body is completed.
+	AuditLogStageResponseComplete AuditLogStage = "ResponseComplete"
+	// AuditLogStagePanic is the stage for events generated when a panic occurred while handling the request.
+	AuditLogStagePanic AuditLogStage = "Panic"
+)
+
+// AuditLogStageTiming is the timing of the stages of a request, correlated from the audit events with the same auditID.
+type AuditLogStageTiming struct {
+	// Stage is the last stage recorded for the request.
+	Stage AuditLogStage
+	// RequestReceivedTime is the time of the RequestReceived stage. This is zero when the stage is not recorded.
+	RequestReceivedTime time.Time
+	// ResponseStartedTime is the time of the ResponseStarted stage. This is zero when the stage is not recorded.
+	ResponseStartedTime time.Time
+	// FinishedTime is the time of the ResponseComplete or Panic stage. This is zero when the request didn't finish within the logs.
+	FinishedTime time.Time
+}
+
+// Completed returns true when the request finished with the ResponseComplete stage.
+// A nil AuditLogStageTiming is regarded as completed because log backends without the stage information only record completed requests.
+func (t *AuditLogStageTiming) Completed() bool {
+	return t == nil || t.Stage == AuditLogStageResponseComplete
+}
+
+// InFlight returns true when the request didn't finish within the logs.
+func (t *AuditLogStageTiming) InFlight() bool {
+	return t != nil && (t.Stage == AuditLogStageRequestReceived || t.Stage == AuditLogStageResponseStarted)
+}
+
+// Panicked returns true when a panic occurred while handling the request.
+func (t *AuditLogStageTiming) Panicked() bool {
+	return t != nil && t.Stage == AuditLogStagePanic
+}
+
+// Latency returns the duration from receiving the request to finishing it. This returns 0 when either of the stages is not recorded.
+func (t *AuditLogStageTiming) Latency() time.Duration {
+	if t == nil || t.RequestReceivedTime.IsZero() || t.FinishedTime.IsZero() {
+		return 0
+	}
+	return t.FinishedTime.Sub(t.RequestReceivedTime)
+}
+
+// TimeToResponseStarted returns the duration from receiving the request to starting the response of a long-running request.
+// This returns 0 when either of the stages is not recorded.
+func (t *AuditLogStageTiming) TimeToResponseStarted() time.Duration {
+	if t == nil || t.RequestReceivedTime.IsZero() || t.ResponseStartedTime.IsZero() {
+		return 0
+	}
+	return t.ResponseStartedTime.Sub(t.RequestReceivedTime)
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commonlogk8saudit_contract
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAuditLogStageTiming(t *testing.T) {
+	received := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name                      string
+		timing                    *AuditLogStageTiming
+		wantCompleted             bool
+		wantInFlight              bool
+		wantPanicked              bool
+		wantLatency               time.Duration
+		wantTimeToResponseStarted time.Duration
+	}{
+		{
+			name:          "nil",
+			timing:        nil,
+			wantCompleted: true,
+		},
+		{
+			name: "completed",
+			timing: &AuditLogStageTiming{
+				Stage:               AuditLogStageResponseComplete,
+				RequestReceivedTime: received,
+				FinishedTime:        received.Add(time.Second),
+			},
+			wantCompleted: true,
+			wantLatency:   time.Second,
+		},
+		{
+			name: "completed long-running request",
+			timing: &AuditLogStageTiming{
+				Stage:               AuditLogStageResponseComplete,
+				RequestReceivedTime: received,
+				ResponseStartedTime: received.Add(time.Second),
+				FinishedTime:        received.Add(time.Minute),
+			},
+			wantCompleted:             true,
+			wantLatency:               time.Minute,
+			wantTimeToResponseStarted: time.Second,
+		},
+		{
+			name: "completed without RequestReceived stage",
+			timing: &AuditLogStageTiming{
+				Stage:        AuditLogStageResponseComplete,
+				FinishedTime: received,
+			},
+			wantCompleted: true,
+		},
+		{
+			name: "in-flight",
+			timing: &AuditLogStageTiming{
+				Stage:               AuditLogStageResponseStarted,
+				RequestReceivedTime: received,
+				ResponseStartedTime: received.Add(time.Second),
+			},
+			wantInFlight:              true,
+			wantTimeToResponseStarted: time.Second,
+		},
+		{
+			name: "panicked",
+			timing: &AuditLogStageTiming{
+				Stage:               AuditLogStagePanic,
+				RequestReceivedTime: received,
+				FinishedTime:        received.Add(time.Millisecond),
+			},
+			wantPanicked: true,
+			wantLatency:  time.Millisecond,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := []any{tc.timing.Completed(), tc.timing.InFlight(), tc.timing.Panicked(), tc.timing.Latency(), tc.timing.TimeToResponseStarted()}
+			want := []any{tc.wantCompleted, tc.wantInFlight, tc.wantPanicked, tc.wantLatency, tc.wantTimeToResponseStarted}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("[Completed(), InFlight(), Panicked(), Latency(), TimeToResponseStarted()] mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -71,7 +71,8 @@ var ManifestGenerateTask = inspectiontaskbase.NewProgressReportableInspectionTas
 			prevRevisionReader := structured.NewNodeReader(structured.NewEmptyMapNode())
 			for _, log := range currentGroup.PreParsedLogs {
 				var currentRevisionBodyType commonlogk8saudit_contract.RequestResponseType
-				if log.IsErrorResponse || log.GeneratedFromDeleteCollectionOperation {
+				// Requests not completed may not change the resource. Keep the previous body for them as well as errors.
+				if log.IsErrorResponse || log.GeneratedFromDeleteCollectionOperation || !log.StageTiming.Completed() {
 					log.ResourceBodyYaml = prevRevisionBody
 					log.ResourceBodyReader = prevRevisionReader
 					log.ResourceUID = prevResourceUID
@@ -23,6 +23,7 @@ import (
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	commonlogk8saudit_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/contract"
 	commonlogk8saudit_impl "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl"
 	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder"
 	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder/recorderutil"
@@ -57,6 +58,11 @@ func recordChangeSetForLog(ctx context.Context, req *recorder.RecorderRequest) (
 		prevState = req.PreviousState.(*commonRecorderStatus)
 	}
 
+	if timing := req.LogParseResult.StageTiming; timing.InFlight() || timing.Panicked() {
+		recordUnfinishedRequest(req, resourcePath, timing)
+		return prevState, nil
+	}
+
 	if req.LogParseResult.IsErrorResponse {
 		req.ChangeSet.AddEvent(resourcePath)
 		req.ChangeSet.SetLogSeverity(enum.SeverityError)
@@ -112,3 +118,22 @@ func recordChangeSetForLog(ctx context.Context, req *recorder.RecorderRequest) (
 
 	return prevState, nil
 }
+
+// recordUnfinishedRequest records the request not completed within the logs or panicked as an event on the timeline.
+// These requests don't change the resource, so no revision is recorded.
+func recordUnfinishedRequest(req *recorder.RecorderRequest, resourcePath resourcepath.ResourcePath, timing *commonlogk8saudit_contract.AuditLogStageTiming) {
+	operation := req.LogParseResult.Operation
+	target := fmt.Sprintf("%s %s.%s.%s(%s in %s)", enum.RevisionVerbs[operation.Verb].Label, operation.Namespace, operation.Name, operation.SubResourceName, operation.PluralKind, operation.APIVersion)
+	req.ChangeSet.AddEvent(resourcePath)
+	if timing.Panicked() {
+		req.ChangeSet.SetLogSeverity(enum.SeverityError)
+		message := req.LogParseResult.ResponseErrorMessage
+		if message == "" {
+			message = "panic"
+		}
+		req.ChangeSet.SetLogSummary(fmt.Sprintf("【Panic】%s: %s", message, target))
+		return
+	}
+	req.ChangeSet.SetLogSeverity(enum.SeverityWarning)
+	req.ChangeSet.SetLogSummary(fmt.Sprintf("【Not completed(last stage: %s)】%s", timing.Stage, target))
+}
@@ -57,9 +57,10 @@ func AnyLogFilter() LogFilterFunc {
 }
 
 // OnlySucceedLogs returns a LogFilterFunc that only matches audit logs with non zero response code.
+// Logs of requests not completed within the logs or panicked are also excluded.
 func OnlySucceedLogs() LogFilterFunc {
 	return func(ctx context.Context, l *commonlogk8saudit_contract.AuditLogParserInput) bool {
-		return !l.IsErrorResponse
+		return !l.IsErrorResponse && l.StageTiming.Completed()
 	}
 }
 
@@ -0,0 +1,190 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hparecorder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structured"
+	"github.com/GoogleCloudPlatform/khi/pkg/core/task/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
+	commonlogk8saudit_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/contract"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder/recorderutil"
+	"gopkg.in/yaml.v2"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+)
+
+func Register(manager *recorder.RecorderTaskManager) error {
+	manager.AddRecorder("horizontalpodautoscaler-scaling", []taskid.UntypedTaskReference{}, func(ctx context.Context, req *recorder.RecorderRequest) (any, error) {
+		var prevStatus *scalingStatus
+		if req.PreviousState != nil {
+			prevStatus = req.PreviousState.(*scalingStatus)
+		}
+		return recordHorizontalPodAutoscaler(ctx, req.LogParseResult, prevStatus, req.ChangeSet)
+	}, recorder.ResourceKindLogGroupFilter("horizontalpodautoscaler"), recorder.AndLogFilter(recorder.OnlySucceedLogs(), recorder.OnlyWithResourceBody()))
+	manager.AddRecorder("scale-subresource-scaling", []taskid.UntypedTaskReference{}, func(ctx context.Context, req *recorder.RecorderRequest) (any, error) {
+		var prevScale *autoscalingv1.Scale
+		if req.PreviousState != nil {
+			prevScale = req.PreviousState.(*autoscalingv1.Scale)
+		}
+		return recordScaleSubresource(ctx, req.LogParseResult, prevScale, req.ChangeSet)
+	}, recorder.SubresourceLogGroupFilter("scale"), recorder.AndLogFilter(recorder.OnlySucceedLogs(), recorder.OnlyWithResourceBody()))
+	return nil
+}
+
+// scalingStatus is the scaling decision read from a HorizontalPodAutoscaler.
+type scalingStatus struct {
+	ScaleTarget     string   `yaml:"scaleTarget"`
+	CurrentReplicas int32    `yaml:"currentReplicas"`
+	DesiredReplicas int32    `yaml:"desiredReplicas"`
+	CurrentMetrics  []string `yaml:"currentMetrics,omitempty"`
+}
+
+// recordHorizontalPodAutoscaler records the scaling decision of the HPA on its scaling timeline and adds the log as an event on the scale target when the desired replicas is changed.
+func recordHorizontalPodAutoscaler(ctx context.Context, l *commonlogk8saudit_contract.AuditLogParserInput, prevStatus *scalingStatus, cs *history.ChangeSet) (*scalingStatus, error) {
+	var hpa autoscalingv2.HorizontalPodAutoscaler
+	err := structured.ReadReflectK8sRuntimeObject(l.ResourceBodyReader, "", &hpa)
+	if err != nil {
+		return nil, err
+	}
+	status := &scalingStatus{
+		ScaleTarget:     fmt.Sprintf("%s/%s", hpa.Spec.ScaleTargetRef.Kind, hpa.Spec.ScaleTargetRef.Name),
+		CurrentReplicas: hpa.Status.CurrentReplicas,
+		DesiredReplicas: hpa.Status.DesiredReplicas,
+	}
+	for _, metric := range hpa.Status.CurrentMetrics {
+		status.CurrentMetrics = append(status.CurrentMetrics, formatMetricStatus(metric))
+	}
+	body, err := yaml.Marshal(status)
+	if err != nil {
+		return nil, err
+	}
+	if prevStatus != nil {
+		prevBody, err := yaml.Marshal(prevStatus)
+		if err != nil {
+			return nil, err
+		}
+		if string(prevBody) == string(body) {
+			return status, nil
+		}
+	}
+
+	hpaPath := resourcepath.NameLayerGeneralItem(l.Operation.APIVersion, l.Operation.GetSingularKindName(), l.Operation.Namespace, l.Operation.Name)
+	recorderutil.AddRevision(cs, l, resourcepath.Scaling(hpaPath), l.Operation.Verb, scalingRevisionState(status.DesiredReplicas, status.CurrentReplicas), string(body))
+	if prevStatus != nil && prevStatus.DesiredReplicas != status.DesiredReplicas && hpa.Spec.ScaleTargetRef.Name != "" {
+		if targetPath, found := scaleTargetPath(l.Operation.Namespace, hpa.Spec.ScaleTargetRef); found {
+			cs.AddEvent(targetPath)
+		}
+	}
+	return status, nil
+}
+
+// recordScaleSubresource records the replicas written to the scale subresource on the scaling timeline of its parent and adds the log as an event on the parent.
+func recordScaleSubresource(ctx context.Context, l *commonlogk8saudit_contract.AuditLogParserInput, prevScale *autoscalingv1.Scale, cs *history.ChangeSet) (*autoscalingv1.Scale, error) {
+	var scale autoscalingv1.Scale
+	err := structured.ReadReflectK8sRuntimeObject(l.ResourceBodyReader, "", &scale)
+	if err != nil {
+		return nil, err
+	}
+	if prevScale != nil && prevScale.Spec.Replicas == scale.Spec.Replicas {
+		return &scale, nil
+	}
+	body, err := yaml.Marshal(map[string]int32{
+		"replicas":        scale.Spec.Replicas,
+		"currentReplicas": scale.Status.Replicas,
+	})
+	if err != nil {
+		return nil, err
+	}
+	targetPath := resourcepath.NameLayerGeneralItem(l.Operation.APIVersion, l.Operation.GetSingularKindName(), l.Operation.Namespace, l.Operation.Name)
+	recorderutil.AddRevision(cs, l, resourcepath.Scaling(targetPath), l.Operation.Verb, scalingRevisionState(scale.Spec.Replicas, scale.Status.Replicas), string(body))
+	cs.AddEvent(targetPath)
+	return &scale, nil
+}
+
+func scalingRevisionState(desiredReplicas int32, currentReplicas int32) enum.RevisionState {
+	switch {
+	case desiredReplicas > currentReplicas:
+		return enum.RevisionStateScalingUp
+	case desiredReplicas < currentReplicas:
+		return enum.RevisionStateScalingDown
+	default:
+		return enum.RevisionStateScalingStable
+	}
+}
+
+// defaultScaleTargetAPIVersions is the apiVersion used for the scaleTargetRef without apiVersion for the kinds supporting the scale subresource in Kubernetes.
+var defaultScaleTargetAPIVersions = map[string]string{
+	"deployment":            "apps/v1",
+	"statefulset":           "apps/v1",
+	"replicaset":            "apps/v1",
+	"replicationcontroller": "core/v1",
+}
+
+// scaleTargetPath returns the resource path of the resource referenced from the scaleTargetRef.
+// It returns false when the apiVersion is omitted and the kind is not a built-in kind, because the path of such resource can't be determined.
+func scaleTargetPath(namespace string, ref autoscalingv2.CrossVersionObjectReference) (resourcepath.ResourcePath, bool) {
+	kind := strings.ToLower(ref.Kind)
+	apiVersion := ref.APIVersion
+	if apiVersion == "" {
+		defaultAPIVersion, found := defaultScaleTargetAPIVersions[kind]
+		if !found {
+			return resourcepath.ResourcePath{}, false
+		}
+		apiVersion = defaultAPIVersion
+	}
+	if !strings.Contains(apiVersion, "/") {
+		apiVersion = "core/" + apiVersion
+	}
+	return resourcepath.NameLayerGeneralItem(apiVersion, kind, namespace, ref.Name), true
+}
+
+// formatMetricStatus returns a single line summary of the given metric status.
+func formatMetricStatus(metric autoscalingv2.MetricStatus) string {
+	switch {
+	case metric.Resource != nil:
+		return fmt.Sprintf("resource %s: %s", metric.Resource.Name, formatMetricValue(metric.Resource.Current))
+	case metric.ContainerResource != nil:
+		return fmt.Sprintf("container resource %s/%s: %s", metric.ContainerResource.Container, metric.ContainerResource.Name, formatMetricValue(metric.ContainerResource.Current))
+	case metric.Pods != nil:
+		return fmt.Sprintf("pods %s: %s", metric.Pods.Metric.Name, formatMetricValue(metric.Pods.Current))
+	case metric.Object != nil:
+		return fmt.Sprintf("object %s/%s %s: %s", metric.Object.DescribedObject.Kind, metric.Object.DescribedObject.Name, metric.Object.Metric.Name, formatMetricValue(metric.Object.Current))
+	case metric.External != nil:
+		return fmt.Sprintf("external %s: %s", metric.External.Metric.Name, formatMetricValue(metric.External.Current))
+	default:
+		return string(metric.Type)
+	}
+}
+
+func formatMetricValue(value autoscalingv2.MetricValueStatus) string {
+	values := []string{}
+	if value.AverageUtilization != nil {
+		values = append(values, fmt.Sprintf("averageUtilization=%d%%", *value.AverageUtilization))
+	}
+	if value.AverageValue != nil {
+		values = append(values, fmt.Sprintf("averageValue=%s", value.AverageValue.String()))
+	}
+	if value.Value != nil {
+		values = append(values, fmt.Sprintf("value=%s", value.Value.String()))
+	}
+	return strings.Join(values, ", ")
+}
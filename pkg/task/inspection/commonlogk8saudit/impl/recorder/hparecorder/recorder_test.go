@@ -0,0 +1,272 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hparecorder
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil/testchangeset"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil/testk8saudit"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+)
+
+const hpaManifestTemplate = `apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: web
+  namespace: default
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: web
+  minReplicas: 1
+  maxReplicas: 10
+status:
+  currentReplicas: %d
+  desiredReplicas: %d
+  currentMetrics:
+  - type: Resource
+    resource:
+      name: cpu
+      current:
+        averageUtilization: %d
+        averageValue: 850m
+`
+
+func TestRecordHorizontalPodAutoscaler(t *testing.T) {
+	testCases := []struct {
+		name  string
+		steps []testk8saudit.Step
+	}{
+		{
+			name: "hpa scaling up a deployment and becoming stable",
+			steps: []testk8saudit.Step{
+				{
+					Manifest: fmt.Sprintf(hpaManifestTemplate, 2, 2, 50),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								"autoscaling/v2#horizontalpodautoscaler#default#web#scaling",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "autoscaling/v2#horizontalpodautoscaler#default#web#scaling",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateScalingStable,
+								Requestor:  "system:serviceaccount:kube-system:horizontal-pod-autoscaler",
+								ChangeTime: testk8saudit.BaseTime,
+								Body: `scaleTarget: Deployment/web
+currentReplicas: 2
+desiredReplicas: 2
+currentMetrics:
+- 'resource cpu: averageUtilization=50%, averageValue=850m'
+`,
+							},
+						},
+					},
+				},
+				{
+					Manifest: fmt.Sprintf(hpaManifestTemplate, 2, 4, 95),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								"autoscaling/v2#horizontalpodautoscaler#default#web#scaling",
+								"apps/v1#deployment#default#web",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "autoscaling/v2#horizontalpodautoscaler#default#web#scaling",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateScalingUp,
+								Requestor:  "system:serviceaccount:kube-system:horizontal-pod-autoscaler",
+								ChangeTime: testk8saudit.BaseTime.Add(time.Minute),
+								Body: `scaleTarget: Deployment/web
+currentReplicas: 2
+desiredReplicas: 4
+currentMetrics:
+- 'resource cpu: averageUtilization=95%, averageValue=850m'
+`,
+							},
+						},
+						&testchangeset.HasEvent{
+							ResourcePath: "apps/v1#deployment#default#web",
+						},
+					},
+				},
+				{
+					Manifest: fmt.Sprintf(hpaManifestTemplate, 2, 4, 95),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{},
+						},
+					},
+				},
+				{
+					Manifest: fmt.Sprintf(hpaManifestTemplate, 4, 4, 60),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								"autoscaling/v2#horizontalpodautoscaler#default#web#scaling",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testk8saudit.RunSteps(t, tc.steps, "system:serviceaccount:kube-system:horizontal-pod-autoscaler", model.KubernetesObjectOperation{
+				APIVersion: "autoscaling/v2",
+				PluralKind: "horizontalpodautoscalers",
+				Namespace:  "default",
+				Name:       "web",
+			}, recordHorizontalPodAutoscaler)
+		})
+	}
+}
+
+func TestRecordScaleSubresource(t *testing.T) {
+	scaleManifestTemplate := `apiVersion: autoscaling/v1
+kind: Scale
+metadata:
+  name: web
+  namespace: default
+spec:
+  replicas: %d
+status:
+  replicas: %d
+`
+	testCases := []struct {
+		name  string
+		steps []testk8saudit.Step
+	}{
+		{
+			name: "deployment scaled up and down through the scale subresource",
+			steps: []testk8saudit.Step{
+				{
+					Manifest: fmt.Sprintf(scaleManifestTemplate, 4, 2),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								"apps/v1#deployment#default#web#scaling",
+								"apps/v1#deployment#default#web",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "apps/v1#deployment#default#web#scaling",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateScalingUp,
+								Requestor:  "system:serviceaccount:kube-system:horizontal-pod-autoscaler",
+								ChangeTime: testk8saudit.BaseTime,
+								Body:       "currentReplicas: 2\nreplicas: 4\n",
+							},
+						},
+						&testchangeset.HasEvent{
+							ResourcePath: "apps/v1#deployment#default#web",
+						},
+					},
+				},
+				{
+					Manifest: fmt.Sprintf(scaleManifestTemplate, 4, 4),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{},
+						},
+					},
+				},
+				{
+					Manifest: fmt.Sprintf(scaleManifestTemplate, 1, 4),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.HasRevision{
+							ResourcePath: "apps/v1#deployment#default#web#scaling",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateScalingDown,
+								Requestor:  "system:serviceaccount:kube-system:horizontal-pod-autoscaler",
+								ChangeTime: testk8saudit.BaseTime.Add(2 * time.Minute),
+								Body:       "currentReplicas: 4\nreplicas: 1\n",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testk8saudit.RunSteps(t, tc.steps, "system:serviceaccount:kube-system:horizontal-pod-autoscaler", model.KubernetesObjectOperation{
+				APIVersion:      "apps/v1",
+				PluralKind:      "deployments",
+				Namespace:       "default",
+				Name:            "web",
+				SubResourceName: "scale",
+			}, recordScaleSubresource)
+		})
+	}
+}
+
+func TestScaleTargetPath(t *testing.T) {
+	testCases := []struct {
+		name      string
+		ref       autoscalingv2.CrossVersionObjectReference
+		wantPath  string
+		wantFound bool
+	}{
+		{
+			name:      "apiVersion with group",
+			ref:       autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+			wantPath:  "apps/v1#deployment#default#web",
+			wantFound: true,
+		},
+		{
+			name:      "apiVersion of the core group",
+			ref:       autoscalingv2.CrossVersionObjectReference{APIVersion: "v1", Kind: "ReplicationController", Name: "web"},
+			wantPath:  "core/v1#replicationcontroller#default#web",
+			wantFound: true,
+		},
+		{
+			name:      "omitted apiVersion of a built-in kind",
+			ref:       autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+			wantPath:  "apps/v1#deployment#default#web",
+			wantFound: true,
+		},
+		{
+			name:      "omitted apiVersion of a custom kind",
+			ref:       autoscalingv2.CrossVersionObjectReference{Kind: "Rollout", Name: "web"},
+			wantFound: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, found := scaleTargetPath("default", tc.ref)
+			if found != tc.wantFound {
+				t.Fatalf("scaleTargetPath() returned found=%v, want %v", found, tc.wantFound)
+			}
+			if got.Path != tc.wantPath {
+				t.Errorf("scaleTargetPath() = %q, want %q", got.Path, tc.wantPath)
+			}
+		})
+	}
+}
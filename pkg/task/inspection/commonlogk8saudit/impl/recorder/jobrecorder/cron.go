@@ -0,0 +1,201 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobrecorder
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCronScheduleLookup is the maximum duration to look up the next schedule time.
+const maxCronScheduleLookup = 5 * 365 * 24 * time.Hour
+
+var cronScheduleMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronMonthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var cronDayOfWeekNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// cronSchedule is a parsed standard 5 fields cron schedule used in the `.spec.schedule` field of CronJobs.
+type cronSchedule struct {
+	minutes     map[int]bool
+	hours       map[int]bool
+	daysOfMonth map[int]bool
+	months      map[int]bool
+	daysOfWeek  map[int]bool
+	// anyDayOfMonth and anyDayOfWeek are true when the field is `*` or `?`.
+	// When both of the day fields are restricted, a time matches when either of them matches.
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+	location      *time.Location
+}
+
+// parseCronSchedule parses the schedule of a CronJob in the given location.
+// The schedule can override the location with `TZ=` or `CRON_TZ=` prefix.
+func parseCronSchedule(schedule string, location *time.Location) (*cronSchedule, error) {
+	schedule = strings.TrimSpace(schedule)
+	if strings.HasPrefix(schedule, "TZ=") || strings.HasPrefix(schedule, "CRON_TZ=") {
+		timezone, rest, found := strings.Cut(schedule, " ")
+		if !found {
+			return nil, fmt.Errorf("schedule %q has no fields after the timezone", schedule)
+		}
+		_, timezoneName, _ := strings.Cut(timezone, "=")
+		loc, err := time.LoadLocation(timezoneName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the timezone %q: %w", timezoneName, err)
+		}
+		location = loc
+		schedule = strings.TrimSpace(rest)
+	}
+	if macro, found := cronScheduleMacros[strings.ToLower(schedule)]; found {
+		schedule = macro
+	}
+	fields := strings.Fields(schedule)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("schedule %q must have 5 fields but it has %d fields", schedule, len(fields))
+	}
+	result := &cronSchedule{
+		anyDayOfMonth: fields[2] == "*" || fields[2] == "?",
+		anyDayOfWeek:  fields[4] == "*" || fields[4] == "?",
+		location:      location,
+	}
+	var err error
+	if result.minutes, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, err
+	}
+	if result.hours, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, err
+	}
+	if result.daysOfMonth, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, err
+	}
+	if result.months, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, err
+	}
+	if result.daysOfWeek, err = parseCronField(fields[4], 0, 7, cronDayOfWeekNames); err != nil {
+		return nil, err
+	}
+	if result.daysOfWeek[7] {
+		result.daysOfWeek[0] = true
+	}
+	return result, nil
+}
+
+// parseCronField parses a comma separated field of cron schedule. Each element can be a value, a range or `*` with an optional step.
+func parseCronField(field string, min int, max int, names map[string]int) (map[int]bool, error) {
+	result := map[int]bool{}
+	for _, element := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(element, "/")
+		step := 1
+		if hasStep {
+			parsedStep, err := strconv.Atoi(stepPart)
+			if err != nil || parsedStep <= 0 {
+				return nil, fmt.Errorf("invalid step %q in cron field %q", stepPart, field)
+			}
+			step = parsedStep
+		}
+		start, end := min, max
+		if rangePart != "*" && rangePart != "?" {
+			startPart, endPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = parseCronValue(startPart, min, max, names); err != nil {
+				return nil, err
+			}
+			end = start
+			if isRange {
+				if end, err = parseCronValue(endPart, min, max, names); err != nil {
+					return nil, err
+				}
+			} else if hasStep {
+				end = max
+			}
+			if start > end {
+				return nil, fmt.Errorf("invalid range %q in cron field %q", rangePart, field)
+			}
+		}
+		for value := start; value <= end; value += step {
+			result[value] = true
+		}
+	}
+	return result, nil
+}
+
+func parseCronValue(value string, min int, max int, names map[string]int) (int, error) {
+	if named, found := names[strings.ToLower(value)]; found {
+		return named, nil
+	}
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in cron schedule", value)
+	}
+	if result < min || result > max {
+		return 0, fmt.Errorf("value %d is out of range [%d,%d] in cron schedule", result, min, max)
+	}
+	return result, nil
+}
+
+// Next returns the first schedule time strictly after the given time. It returns zero time when no schedule is found in the lookup range.
+func (c *cronSchedule) Next(after time.Time) time.Time {
+	t := after.In(c.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxCronScheduleLookup)
+	for t.Before(limit) {
+		switch {
+		case !c.months[int(t.Month())]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.location)
+		case !c.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.location)
+		case !c.hours[t.Hour()]:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, c.location)
+		case !c.minutes[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c *cronSchedule) matchDay(t time.Time) bool {
+	dayOfMonth := c.daysOfMonth[t.Day()]
+	dayOfWeek := c.daysOfWeek[int(t.Weekday())]
+	if c.anyDayOfMonth || c.anyDayOfWeek {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
+
+// Between returns the schedule times strictly in between the given start and end, up to the given limit.
+func (c *cronSchedule) Between(start time.Time, end time.Time, limit int) []time.Time {
+	result := []time.Time{}
+	for t := c.Next(start); !t.IsZero() && t.Before(end) && len(result) < limit; t = c.Next(t) {
+		result = append(result, t)
+	}
+	return result
+}
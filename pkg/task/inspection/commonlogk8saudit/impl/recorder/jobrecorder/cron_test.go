@@ -0,0 +1,148 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobrecorder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+	testCases := []struct {
+		name     string
+		schedule string
+		location *time.Location
+		after    time.Time
+		want     time.Time
+	}{
+		{
+			name:     "every 15 minutes",
+			schedule: "*/15 * * * *",
+			location: time.UTC,
+			after:    time.Date(2025, 1, 1, 10, 7, 30, 0, time.UTC),
+			want:     time.Date(2025, 1, 1, 10, 15, 0, 0, time.UTC),
+		},
+		{
+			name:     "exactly at a schedule time returns the next one",
+			schedule: "0 * * * *",
+			location: time.UTC,
+			after:    time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
+			want:     time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "list and range",
+			schedule: "30 9-17/4 * * MON-FRI",
+			location: time.UTC,
+			after:    time.Date(2025, 1, 3, 18, 0, 0, 0, time.UTC), // Friday
+			want:     time.Date(2025, 1, 6, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "macro",
+			schedule: "@monthly",
+			location: time.UTC,
+			after:    time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+			want:     time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "day of month or day of week when both are restricted",
+			schedule: "0 0 13 * 5",
+			location: time.UTC,
+			after:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:     time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "sunday as 7",
+			schedule: "0 0 * * 7",
+			location: time.UTC,
+			after:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:     time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "location given from spec",
+			schedule: "0 9 * * *",
+			location: tokyo,
+			after:    time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC),
+			want:     time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "location given from schedule",
+			schedule: "CRON_TZ=Asia/Tokyo 0 9 * * *",
+			location: time.UTC,
+			after:    time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC),
+			want:     time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule, err := parseCronSchedule(tc.schedule, tc.location)
+			if err != nil {
+				t.Fatalf("parseCronSchedule(%q) returned an unexpected error: %v", tc.schedule, err)
+			}
+			got := schedule.Next(tc.after)
+			if !got.Equal(tc.want) {
+				t.Errorf("Next(%v) = %v, want %v", tc.after, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCronScheduleBetween(t *testing.T) {
+	schedule, err := parseCronSchedule("*/10 * * * *", time.UTC)
+	if err != nil {
+		t.Fatalf("parseCronSchedule() returned an unexpected error: %v", err)
+	}
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 1, 1, 10, 40, 0, 0, time.UTC)
+
+	got := schedule.Between(start, end, 100)
+	want := []time.Time{
+		time.Date(2025, 1, 1, 10, 10, 0, 0, time.UTC),
+		time.Date(2025, 1, 1, 10, 20, 0, 0, time.UTC),
+		time.Date(2025, 1, 1, 10, 30, 0, 0, time.UTC),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Between() mismatch (-want +got):\n%s", diff)
+	}
+
+	got = schedule.Between(start, end, 2)
+	if len(got) != 2 {
+		t.Errorf("Between() with limit 2 returned %d schedules, want 2", len(got))
+	}
+}
+
+func TestParseCronSchedule_Errors(t *testing.T) {
+	testCases := []string{
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * FOO *",
+		"TZ=Invalid/Zone * * * * *",
+	}
+	for _, schedule := range testCases {
+		t.Run(schedule, func(t *testing.T) {
+			if _, err := parseCronSchedule(schedule, time.UTC); err == nil {
+				t.Errorf("parseCronSchedule(%q) returned no error, want an error", schedule)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobrecorder
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structured"
+	"github.com/GoogleCloudPlatform/khi/pkg/core/task/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	commonlogk8saudit_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/contract"
+	commonlogk8saudit_impl "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder/recorderutil"
+	"gopkg.in/yaml.v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxMissedSchedules is the maximum count of missed schedules recorded for a log.
+// The CronJob controller also stops counting missed schedules at 100.
+const maxMissedSchedules = 100
+
+func Register(manager *recorder.RecorderTaskManager) error {
+	manager.AddRecorder("job-execution", []taskid.UntypedTaskReference{}, func(ctx context.Context, req *recorder.RecorderRequest) (any, error) {
+		var prevState *jobExecutionState
+		if req.PreviousState != nil {
+			prevState = req.PreviousState.(*jobExecutionState)
+		}
+		return recordJob(ctx, req.LogParseResult, prevState, req.ChangeSet)
+	}, recorder.ResourceKindLogGroupFilter("job"), recorder.AndLogFilter(recorder.OnlySucceedLogs(), recorder.OnlyWithResourceBody()))
+	manager.AddRecorder("cronjob-execution", []taskid.UntypedTaskReference{}, func(ctx context.Context, req *recorder.RecorderRequest) (any, error) {
+		var prevState *cronJobState
+		if req.PreviousState != nil {
+			prevState = req.PreviousState.(*cronJobState)
+		}
+		return recordCronJob(ctx, req.LogParseResult, prevState, req.ChangeSet)
+	}, recorder.ResourceKindLogGroupFilter("cronjob"), recorder.AndLogFilter(recorder.OnlySucceedLogs(), recorder.OnlyWithResourceBody()))
+	return nil
+}
+
+// jobExecutionState is the state of the job recorder kept between logs.
+type jobExecutionState struct {
+	body           string
+	lastChangeTime time.Time
+}
+
+// cronJobState is the state of the cronjob recorder kept between logs.
+type cronJobState struct {
+	cronJob *batchv1.CronJob
+	// logTime is the timestamp of the log the cronjob was read from.
+	logTime time.Time
+}
+
+// jobExecutionStatus is the body of revisions on the job execution timeline.
+type jobExecutionStatus struct {
+	Active         int32  `yaml:"active"`
+	Succeeded      int32  `yaml:"succeeded"`
+	Failed         int32  `yaml:"failed"`
+	StartTime      string `yaml:"startTime,omitempty"`
+	CompletionTime string `yaml:"completionTime,omitempty"`
+	Reason         string `yaml:"reason,omitempty"`
+	Message        string `yaml:"message,omitempty"`
+}
+
+// recordJob records the execution state of the job. A new revision is recorded when the state or the count of pods are changed, thus every backoff failure is visible on the timeline.
+func recordJob(ctx context.Context, l *commonlogk8saudit_contract.AuditLogParserInput, prevState *jobExecutionState, cs *history.ChangeSet) (*jobExecutionState, error) {
+	commonFieldSet := log.MustGetFieldSet(l.Log, &log.CommonFieldSet{})
+	var job batchv1.Job
+	err := structured.ReadReflectK8sRuntimeObject(l.ResourceBodyReader, "", &job)
+	if err != nil {
+		return nil, err
+	}
+	executionPath := resourcepath.JobExecution(resourcepath.NameLayerGeneralItem(l.Operation.APIVersion, l.Operation.GetSingularKindName(), l.Operation.Namespace, l.Operation.Name))
+	if isDeleted(ctx, l) {
+		recorderutil.AddRevisionAt(cs, l, executionPath, enum.RevisionVerbDelete, enum.RevisionStateDeleted, "", commonFieldSet.Timestamp)
+		return prevState, nil
+	}
+
+	state, condition, transitionTime := readJobExecutionState(&job)
+	status := jobExecutionStatus{
+		Active:         job.Status.Active,
+		Succeeded:      job.Status.Succeeded,
+		Failed:         job.Status.Failed,
+		StartTime:      formatTime(job.Status.StartTime),
+		CompletionTime: formatTime(job.Status.CompletionTime),
+	}
+	if condition != nil {
+		status.Reason = condition.Reason
+		status.Message = condition.Message
+	}
+	body, err := yaml.Marshal(status)
+	if err != nil {
+		return nil, err
+	}
+	if prevState != nil && prevState.body == string(body) {
+		return prevState, nil
+	}
+
+	// Audit logs can be written later than the actual transition. Use the time in the status when it's in between the last revision and this log.
+	changeTime := commonFieldSet.Timestamp
+	if transitionTime != nil && transitionTime.Before(changeTime) && (prevState == nil || transitionTime.After(prevState.lastChangeTime)) {
+		changeTime = *transitionTime
+	}
+	recorderutil.AddRevisionAt(cs, l, executionPath, l.Operation.Verb, state, string(body), changeTime)
+	return &jobExecutionState{
+		body:           string(body),
+		lastChangeTime: changeTime,
+	}, nil
+}
+
+// readJobExecutionState returns the execution state of the job with the condition and the time that determined the state.
+func readJobExecutionState(job *batchv1.Job) (enum.RevisionState, *batchv1.JobCondition, *time.Time) {
+	for i := range job.Status.Conditions {
+		condition := &job.Status.Conditions[i]
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			if job.Status.CompletionTime != nil {
+				return enum.RevisionStateJobSucceeded, condition, &job.Status.CompletionTime.Time
+			}
+			return enum.RevisionStateJobSucceeded, condition, &condition.LastTransitionTime.Time
+		case batchv1.JobFailed:
+			return enum.RevisionStateJobFailed, condition, &condition.LastTransitionTime.Time
+		}
+	}
+	if job.Spec.Suspend != nil && *job.Spec.Suspend {
+		for i := range job.Status.Conditions {
+			condition := &job.Status.Conditions[i]
+			if condition.Type == batchv1.JobSuspended && condition.Status == corev1.ConditionTrue {
+				return enum.RevisionStateJobSuspended, condition, &condition.LastTransitionTime.Time
+			}
+		}
+		return enum.RevisionStateJobSuspended, nil, nil
+	}
+	if job.Status.StartTime != nil {
+		return enum.RevisionStateJobRunning, nil, &job.Status.StartTime.Time
+	}
+	return enum.RevisionStateJobRunning, nil, nil
+}
+
+// recordCronJob records the schedule of the cronjob with missed schedules and the job runs listed in `.status.active`.
+func recordCronJob(ctx context.Context, l *commonlogk8saudit_contract.AuditLogParserInput, prevState *cronJobState, cs *history.ChangeSet) (*cronJobState, error) {
+	commonFieldSet := log.MustGetFieldSet(l.Log, &log.CommonFieldSet{})
+	var cronJob batchv1.CronJob
+	err := structured.ReadReflectK8sRuntimeObject(l.ResourceBodyReader, "", &cronJob)
+	if err != nil {
+		return nil, err
+	}
+	state := &cronJobState{
+		cronJob: &cronJob,
+		logTime: commonFieldSet.Timestamp,
+	}
+	var prevCronJob *batchv1.CronJob
+	if prevState != nil {
+		prevCronJob = prevState.cronJob
+	}
+	cronJobPath := resourcepath.NameLayerGeneralItem(l.Operation.APIVersion, l.Operation.GetSingularKindName(), l.Operation.Namespace, l.Operation.Name)
+	schedulePath := resourcepath.CronJobSchedule(cronJobPath)
+	if isDeleted(ctx, l) {
+		recorderutil.AddRevisionAt(cs, l, schedulePath, enum.RevisionVerbDelete, enum.RevisionStateDeleted, "", commonFieldSet.Timestamp)
+		for _, active := range cronJob.Status.Active {
+			recorderutil.AddRevisionAt(cs, l, resourcepath.JobUnderCronJob(cronJobPath, active.Name), enum.RevisionVerbDelete, enum.RevisionStateDeleted, "", commonFieldSet.Timestamp)
+		}
+		return state, nil
+	}
+
+	scheduleBody, err := cronJobScheduleBody(&cronJob)
+	if err != nil {
+		return nil, err
+	}
+	scheduleState := enum.RevisionStateExisting
+	if isCronJobSuspended(&cronJob) {
+		scheduleState = enum.RevisionStateJobSuspended
+	}
+	prevScheduleBody := ""
+	if prevCronJob != nil {
+		prevScheduleBody, err = cronJobScheduleBody(prevCronJob)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if prevScheduleBody != scheduleBody {
+		recorderutil.AddRevisionAt(cs, l, schedulePath, l.Operation.Verb, scheduleState, scheduleBody, commonFieldSet.Timestamp)
+	}
+
+	// A schedule is missed only when the previous log was written after the schedule time with an older last schedule time, and this log moved on to a later schedule.
+	// Schedules after the previous log are not counted because the logs of the jobs started at them may be just missing in the fetched logs.
+	if prevCronJob != nil && !isCronJobSuspended(prevCronJob) && prevCronJob.Status.LastScheduleTime != nil && cronJob.Status.LastScheduleTime != nil && cronJob.Status.LastScheduleTime.After(prevCronJob.Status.LastScheduleTime.Time) {
+		end := cronJob.Status.LastScheduleTime.Time
+		if prevState.logTime.Before(end) {
+			end = prevState.logTime
+		}
+		missedSchedules, err := readMissedSchedules(prevCronJob, prevCronJob.Status.LastScheduleTime.Time, end)
+		if err != nil {
+			slog.WarnContext(ctx, fmt.Sprintf("failed to read missed schedules of the cronjob %s/%s: %v", l.Operation.Namespace, l.Operation.Name, err))
+		}
+		for _, missedSchedule := range missedSchedules {
+			recorderutil.AddRevisionAt(cs, l, schedulePath, l.Operation.Verb, enum.RevisionStateJobScheduleMissed, fmt.Sprintf("missedScheduleTime: %s\n", missedSchedule.UTC().Format(time.RFC3339)), missedSchedule)
+		}
+		if len(missedSchedules) > 0 {
+			recorderutil.AddRevisionAt(cs, l, schedulePath, l.Operation.Verb, scheduleState, scheduleBody, cronJob.Status.LastScheduleTime.Time)
+		}
+	}
+
+	prevActiveJobs := map[string]struct{}{}
+	if prevCronJob != nil {
+		for _, active := range prevCronJob.Status.Active {
+			prevActiveJobs[active.Name] = struct{}{}
+		}
+	}
+	for _, active := range cronJob.Status.Active {
+		if _, found := prevActiveJobs[active.Name]; found {
+			delete(prevActiveJobs, active.Name)
+			continue
+		}
+		body := fmt.Sprintf("job: %s\nlastScheduleTime: %s\n", active.Name, formatTime(cronJob.Status.LastScheduleTime))
+		recorderutil.AddRevisionAt(cs, l, resourcepath.JobUnderCronJob(cronJobPath, active.Name), l.Operation.Verb, enum.RevisionStateJobRunning, body, commonFieldSet.Timestamp)
+	}
+	// Jobs removed from `.status.active` are finished. CronJob only tells if the last job succeeded with `.status.lastSuccessfulTime`.
+	// The result is unknown otherwise, because the job may also have been deleted or replaced. The execution timeline of the job shows the result confirmed by its own status.
+	for jobName := range prevActiveJobs {
+		succeeded := cronJob.Status.LastSuccessfulTime != nil && (prevCronJob.Status.LastSuccessfulTime == nil || cronJob.Status.LastSuccessfulTime.After(prevCronJob.Status.LastSuccessfulTime.Time))
+		runState := enum.RevisionStateJobResultUnknown
+		comment := "# The result is unknown because the CronJob didn't update `.status.lastSuccessfulTime`. See the execution timeline of the Job for its result"
+		if succeeded {
+			runState = enum.RevisionStateJobSucceeded
+			comment = "# The result was inferred from `.status.lastSuccessfulTime` of the CronJob"
+		}
+		body := fmt.Sprintf("%s\njob: %s\nlastSuccessfulTime: %s\n", comment, jobName, formatTime(cronJob.Status.LastSuccessfulTime))
+		recorderutil.AddRevisionAt(cs, l, resourcepath.JobUnderCronJob(cronJobPath, jobName), l.Operation.Verb, runState, body, commonFieldSet.Timestamp)
+	}
+	return state, nil
+}
+
+// readMissedSchedules returns the schedule times of the cronjob strictly in between the given times.
+func readMissedSchedules(cronJob *batchv1.CronJob, start time.Time, end time.Time) ([]time.Time, error) {
+	location := time.UTC
+	if cronJob.Spec.TimeZone != nil {
+		loc, err := time.LoadLocation(*cronJob.Spec.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		location = loc
+	}
+	schedule, err := parseCronSchedule(cronJob.Spec.Schedule, location)
+	if err != nil {
+		return nil, err
+	}
+	return schedule.Between(start, end, maxMissedSchedules), nil
+}
+
+func cronJobScheduleBody(cronJob *batchv1.CronJob) (string, error) {
+	timeZone := ""
+	if cronJob.Spec.TimeZone != nil {
+		timeZone = *cronJob.Spec.TimeZone
+	}
+	body, err := yaml.Marshal(map[string]any{
+		"schedule":          cronJob.Spec.Schedule,
+		"timeZone":          timeZone,
+		"suspend":           isCronJobSuspended(cronJob),
+		"concurrencyPolicy": string(cronJob.Spec.ConcurrencyPolicy),
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+func isCronJobSuspended(cronJob *batchv1.CronJob) bool {
+	return cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend
+}
+
+func formatTime(t *metav1.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func isDeleted(ctx context.Context, l *commonlogk8saudit_contract.AuditLogParserInput) bool {
+	return commonlogk8saudit_impl.ParseDeletionStatus(ctx, l.ResourceBodyReader, l.Operation) == commonlogk8saudit_impl.DeletionStatusDeleted
+}
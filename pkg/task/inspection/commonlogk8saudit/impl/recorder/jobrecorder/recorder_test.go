@@ -0,0 +1,452 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobrecorder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structured"
+	"github.com/GoogleCloudPlatform/khi/pkg/model"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil/testchangeset"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil/testk8saudit"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+// baseTime is the first schedule time of the CronJob fixtures.
+var baseTime = testk8saudit.BaseTime.Add(10 * time.Hour)
+
+func TestRecordJob(t *testing.T) {
+	testCases := []struct {
+		name  string
+		steps []testk8saudit.Step
+	}{
+		{
+			name: "job failing once and then succeeding",
+			steps: []testk8saudit.Step{
+				{
+					Manifest: `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: backup
+  namespace: default
+status:
+  active: 1
+  startTime: "2025-01-01T09:59:30Z"
+`,
+					Timestamp: baseTime,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.HasRevision{
+							ResourcePath: "batch/v1#job#default#backup#execution",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateJobRunning,
+								Requestor:  "system:serviceaccount:kube-system:job-controller",
+								ChangeTime: time.Date(2025, 1, 1, 9, 59, 30, 0, time.UTC),
+								Body:       "active: 1\nsucceeded: 0\nfailed: 0\nstartTime: \"2025-01-01T09:59:30Z\"\n",
+							},
+						},
+					},
+				},
+				{
+					Manifest: `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: backup
+  namespace: default
+status:
+  active: 1
+  failed: 1
+  startTime: "2025-01-01T09:59:30Z"
+`,
+					Timestamp: baseTime.Add(time.Minute),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.HasRevision{
+							ResourcePath: "batch/v1#job#default#backup#execution",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateJobRunning,
+								Requestor:  "system:serviceaccount:kube-system:job-controller",
+								ChangeTime: baseTime.Add(time.Minute),
+								Body:       "active: 1\nsucceeded: 0\nfailed: 1\nstartTime: \"2025-01-01T09:59:30Z\"\n",
+							},
+						},
+					},
+				},
+				{
+					Manifest: `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: backup
+  namespace: default
+status:
+  active: 1
+  failed: 1
+  startTime: "2025-01-01T09:59:30Z"
+`,
+					Timestamp: baseTime.Add(2 * time.Minute),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{},
+						},
+					},
+				},
+				{
+					Manifest: `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: backup
+  namespace: default
+status:
+  succeeded: 1
+  failed: 1
+  startTime: "2025-01-01T09:59:30Z"
+  completionTime: "2025-01-01T10:02:30Z"
+  conditions:
+  - type: Complete
+    status: "True"
+    lastTransitionTime: "2025-01-01T10:02:30Z"
+`,
+					Timestamp: baseTime.Add(3 * time.Minute),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.HasRevision{
+							ResourcePath: "batch/v1#job#default#backup#execution",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateJobSucceeded,
+								Requestor:  "system:serviceaccount:kube-system:job-controller",
+								ChangeTime: time.Date(2025, 1, 1, 10, 2, 30, 0, time.UTC),
+								Body:       "active: 0\nsucceeded: 1\nfailed: 1\nstartTime: \"2025-01-01T09:59:30Z\"\ncompletionTime: \"2025-01-01T10:02:30Z\"\n",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testk8saudit.RunSteps(t, tc.steps, "system:serviceaccount:kube-system:job-controller", model.KubernetesObjectOperation{
+				APIVersion: "batch/v1",
+				PluralKind: "jobs",
+				Namespace:  "default",
+				Name:       "backup",
+			}, recordJob)
+		})
+	}
+}
+
+func TestReadJobExecutionState(t *testing.T) {
+	testCases := []struct {
+		name      string
+		manifest  string
+		wantState enum.RevisionState
+	}{
+		{
+			name: "failed with backoff limit",
+			manifest: `apiVersion: batch/v1
+kind: Job
+status:
+  failed: 6
+  conditions:
+  - type: Failed
+    status: "True"
+    reason: BackoffLimitExceeded
+`,
+			wantState: enum.RevisionStateJobFailed,
+		},
+		{
+			name: "suspended",
+			manifest: `apiVersion: batch/v1
+kind: Job
+spec:
+  suspend: true
+status:
+  conditions:
+  - type: Suspended
+    status: "True"
+`,
+			wantState: enum.RevisionStateJobSuspended,
+		},
+		{
+			name: "resumed after suspension",
+			manifest: `apiVersion: batch/v1
+kind: Job
+spec:
+  suspend: false
+status:
+  active: 1
+  conditions:
+  - type: Suspended
+    status: "False"
+`,
+			wantState: enum.RevisionStateJobRunning,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			node, err := structured.FromYAML(tc.manifest)
+			if err != nil {
+				t.Fatalf("failed to parse the manifest: %v", err)
+			}
+			var job batchv1.Job
+			if err := structured.ReadReflectK8sRuntimeObject(structured.NewNodeReader(node), "", &job); err != nil {
+				t.Fatalf("failed to read the job: %v", err)
+			}
+			gotState, _, _ := readJobExecutionState(&job)
+			if gotState != tc.wantState {
+				t.Errorf("readJobExecutionState() = %v, want %v", gotState, tc.wantState)
+			}
+		})
+	}
+}
+
+func TestRecordCronJob(t *testing.T) {
+	testCases := []struct {
+		name  string
+		steps []testk8saudit.Step
+	}{
+		{
+			name: "cronjob running, missing schedules and suspended",
+			steps: []testk8saudit.Step{
+				{
+					Manifest: `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+  namespace: default
+spec:
+  schedule: "*/10 * * * *"
+  concurrencyPolicy: Forbid
+status:
+  lastScheduleTime: "2025-01-01T10:00:00Z"
+  active:
+  - name: backup-28930200
+`,
+					Timestamp: baseTime,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								"batch/v1#cronjob#default#backup#schedule",
+								"batch/v1#cronjob#default#backup#backup-28930200[job]",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "batch/v1#cronjob#default#backup#schedule",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateExisting,
+								Requestor:  "system:serviceaccount:kube-system:cronjob-controller",
+								ChangeTime: baseTime,
+								Body:       "concurrencyPolicy: Forbid\nschedule: '*/10 * * * *'\nsuspend: false\ntimeZone: \"\"\n",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "batch/v1#cronjob#default#backup#backup-28930200[job]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateJobRunning,
+								Requestor:  "system:serviceaccount:kube-system:cronjob-controller",
+								ChangeTime: baseTime,
+								Body:       "job: backup-28930200\nlastScheduleTime: 2025-01-01T10:00:00Z\n",
+							},
+						},
+					},
+				},
+				{
+					Manifest: `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+  namespace: default
+spec:
+  schedule: "*/10 * * * *"
+  concurrencyPolicy: Forbid
+status:
+  lastScheduleTime: "2025-01-01T10:00:00Z"
+  lastSuccessfulTime: "2025-01-01T10:25:00Z"
+`,
+					Timestamp: baseTime.Add(25 * time.Minute),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								"batch/v1#cronjob#default#backup#backup-28930200[job]",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "batch/v1#cronjob#default#backup#backup-28930200[job]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateJobSucceeded,
+								Requestor:  "system:serviceaccount:kube-system:cronjob-controller",
+								ChangeTime: baseTime.Add(25 * time.Minute),
+								Body:       "# The result was inferred from `.status.lastSuccessfulTime` of the CronJob\njob: backup-28930200\nlastSuccessfulTime: 2025-01-01T10:25:00Z\n",
+							},
+						},
+					},
+				},
+				{
+					Manifest: `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+  namespace: default
+spec:
+  schedule: "*/10 * * * *"
+  concurrencyPolicy: Forbid
+status:
+  lastScheduleTime: "2025-01-01T10:30:00Z"
+  lastSuccessfulTime: "2025-01-01T10:25:00Z"
+  active:
+  - name: backup-28930230
+`,
+					Timestamp: baseTime.Add(30 * time.Minute),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								"batch/v1#cronjob#default#backup#schedule",
+								"batch/v1#cronjob#default#backup#backup-28930230[job]",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "batch/v1#cronjob#default#backup#schedule",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateJobScheduleMissed,
+								Requestor:  "system:serviceaccount:kube-system:cronjob-controller",
+								ChangeTime: baseTime.Add(10 * time.Minute),
+								Body:       "missedScheduleTime: 2025-01-01T10:10:00Z\n",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "batch/v1#cronjob#default#backup#schedule",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateJobScheduleMissed,
+								Requestor:  "system:serviceaccount:kube-system:cronjob-controller",
+								ChangeTime: baseTime.Add(20 * time.Minute),
+								Body:       "missedScheduleTime: 2025-01-01T10:20:00Z\n",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "batch/v1#cronjob#default#backup#schedule",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateExisting,
+								Requestor:  "system:serviceaccount:kube-system:cronjob-controller",
+								ChangeTime: baseTime.Add(30 * time.Minute),
+								Body:       "concurrencyPolicy: Forbid\nschedule: '*/10 * * * *'\nsuspend: false\ntimeZone: \"\"\n",
+							},
+						},
+					},
+				},
+				{
+					Manifest: `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+  namespace: default
+spec:
+  schedule: "*/10 * * * *"
+  concurrencyPolicy: Forbid
+  suspend: true
+status:
+  lastScheduleTime: "2025-01-01T10:30:00Z"
+  lastSuccessfulTime: "2025-01-01T10:25:00Z"
+`,
+					Timestamp: baseTime.Add(35 * time.Minute),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.HasRevision{
+							ResourcePath: "batch/v1#cronjob#default#backup#schedule",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateJobSuspended,
+								Requestor:  "system:serviceaccount:kube-system:cronjob-controller",
+								ChangeTime: baseTime.Add(35 * time.Minute),
+								Body:       "concurrencyPolicy: Forbid\nschedule: '*/10 * * * *'\nsuspend: true\ntimeZone: \"\"\n",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "batch/v1#cronjob#default#backup#backup-28930230[job]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateJobResultUnknown,
+								Requestor:  "system:serviceaccount:kube-system:cronjob-controller",
+								ChangeTime: baseTime.Add(35 * time.Minute),
+								Body:       "# The result is unknown because the CronJob didn't update `.status.lastSuccessfulTime`. See the execution timeline of the Job for its result\njob: backup-28930230\nlastSuccessfulTime: 2025-01-01T10:25:00Z\n",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "schedules after the previous log are not missed",
+			steps: []testk8saudit.Step{
+				{
+					Manifest: `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+  namespace: default
+spec:
+  schedule: "*/10 * * * *"
+status:
+  lastScheduleTime: "2025-01-01T10:00:00Z"
+`,
+					Timestamp: baseTime.Add(time.Minute),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								"batch/v1#cronjob#default#backup#schedule",
+							},
+						},
+					},
+				},
+				{
+					// The logs of the schedules at 10:10 and 10:20 are not fetched, but the jobs may have been started.
+					Manifest: `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+  namespace: default
+spec:
+  schedule: "*/10 * * * *"
+status:
+  lastScheduleTime: "2025-01-01T10:30:00Z"
+`,
+					Timestamp: baseTime.Add(31 * time.Minute),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testk8saudit.RunSteps(t, tc.steps, "system:serviceaccount:kube-system:cronjob-controller", model.KubernetesObjectOperation{
+				APIVersion: "batch/v1",
+				PluralKind: "cronjobs",
+				Namespace:  "default",
+				Name:       "backup",
+			}, recordCronJob)
+		})
+	}
+}
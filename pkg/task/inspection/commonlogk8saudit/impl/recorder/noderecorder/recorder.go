@@ -16,16 +16,222 @@ package noderecorder
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structured"
 	"github.com/GoogleCloudPlatform/khi/pkg/core/task/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourceinfo/resourcelease"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	commonlogk8saudit_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/contract"
+	commonlogk8saudit_impl "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl"
 	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder/recorderutil"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	schedulableTimelineName = "schedulable"
+	resourcesTimelineName   = "resources"
+	versionTimelineName     = "version"
+	addressesTimelineName   = "addresses"
 )
 
 func Register(manager *recorder.RecorderTaskManager) error {
 	manager.AddRecorder("node-fields", []taskid.UntypedTaskReference{}, func(ctx context.Context, req *recorder.RecorderRequest) (any, error) {
 		// record node name for querying compute engine api later.
 		req.Builder.ClusterResource.AddNode(req.LogParseResult.Operation.Name)
-		return nil, nil
+		var prevNode *corev1.Node
+		if req.PreviousState != nil {
+			prevNode = req.PreviousState.(*corev1.Node)
+		}
+		return recordChangeSetForLog(ctx, req.LogParseResult, prevNode, req.ChangeSet, req.Builder)
 	}, recorder.ResourceKindLogGroupFilter("node"), recorder.AndLogFilter(recorder.OnlySucceedLogs(), recorder.OnlyWithResourceBody()))
 	return nil
 }
+
+// recordChangeSetForLog records the schedulability, taints, resources, versions and addresses of the node as its subresources when they are changed from the previous node.
+func recordChangeSetForLog(ctx context.Context, l *commonlogk8saudit_contract.AuditLogParserInput, prevNode *corev1.Node, cs *history.ChangeSet, builder *history.Builder) (*corev1.Node, error) {
+	commonFieldSet := log.MustGetFieldSet(l.Log, &log.CommonFieldSet{})
+	var node corev1.Node
+	err := structured.ReadReflectK8sRuntimeObject(l.ResourceBodyReader, "", &node)
+	if err != nil {
+		return nil, err
+	}
+	nodeName := l.Operation.Name
+
+	// Node addresses are recorded always to resolve the node from IPs seen in the other logs.
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP || address.Type == corev1.NodeExternalIP {
+			builder.ClusterResource.IPs.TouchResourceLease(address.Address, commonFieldSet.Timestamp, resourcelease.NewK8sResourceLeaseHolder("node", "", nodeName))
+		}
+	}
+
+	deletionStatus := commonlogk8saudit_impl.ParseDeletionStatus(ctx, l.ResourceBodyReader, l.Operation)
+	if deletionStatus == commonlogk8saudit_impl.DeletionStatusDeleted {
+		timelineNames := []string{schedulableTimelineName}
+		for _, taint := range node.Spec.Taints {
+			timelineNames = append(timelineNames, taintTimelineName(taint))
+		}
+		timelineNames = append(timelineNames, resourcesTimelineName, versionTimelineName, addressesTimelineName)
+		for _, timelineName := range timelineNames {
+			recorderutil.AddRevision(cs, l, resourcepath.NodeState(nodeName, timelineName), enum.RevisionVerbDelete, enum.RevisionStateDeleted, "")
+		}
+		return &node, nil
+	}
+
+	if prevNode == nil || prevNode.Spec.Unschedulable != node.Spec.Unschedulable {
+		verb := l.Operation.Verb
+		state := enum.RevisionStateNodeSchedulable
+		if node.Spec.Unschedulable {
+			state = enum.RevisionStateNodeUnschedulable
+		}
+		if prevNode != nil {
+			verb = enum.RevisionVerbUncordon
+			if node.Spec.Unschedulable {
+				verb = enum.RevisionVerbCordon
+			}
+		}
+		recorderutil.AddRevision(cs, l, resourcepath.NodeState(nodeName, schedulableTimelineName), verb, state, fmt.Sprintf("unschedulable: %t\n", node.Spec.Unschedulable))
+	}
+
+	prevTaints := map[string]corev1.Taint{}
+	if prevNode != nil {
+		for _, taint := range prevNode.Spec.Taints {
+			prevTaints[taintTimelineName(taint)] = taint
+		}
+	}
+	for _, taint := range node.Spec.Taints {
+		timelineName := taintTimelineName(taint)
+		prevTaint, found := prevTaints[timelineName]
+		delete(prevTaints, timelineName)
+		if prevNode != nil && found && prevTaint.Value == taint.Value {
+			continue
+		}
+		verb := enum.RevisionVerbTaint
+		if prevNode == nil {
+			verb = l.Operation.Verb
+		}
+		body, err := taintBody(taint)
+		if err != nil {
+			return nil, err
+		}
+		recorderutil.AddRevision(cs, l, resourcepath.NodeState(nodeName, timelineName), verb, enum.RevisionStateNodeTainted, body)
+	}
+	for timelineName := range prevTaints {
+		recorderutil.AddRevision(cs, l, resourcepath.NodeState(nodeName, timelineName), enum.RevisionVerbUntaint, enum.RevisionStateDeleted, "")
+	}
+
+	var prevStatus *corev1.NodeStatus
+	if prevNode != nil {
+		prevStatus = &prevNode.Status
+	}
+	statusFields := []struct {
+		timelineName string
+		bodyFunc     func(status *corev1.NodeStatus) (string, error)
+	}{
+		{resourcesTimelineName, resourcesBody},
+		{versionTimelineName, versionBody},
+		{addressesTimelineName, addressesBody},
+	}
+	for _, field := range statusFields {
+		body, err := field.bodyFunc(&node.Status)
+		if err != nil {
+			return nil, err
+		}
+		if body == "" {
+			continue
+		}
+		if prevStatus != nil {
+			prevBody, err := field.bodyFunc(prevStatus)
+			if err != nil {
+				return nil, err
+			}
+			if prevBody == body {
+				continue
+			}
+		}
+		recorderutil.AddRevision(cs, l, resourcepath.NodeState(nodeName, field.timelineName), l.Operation.Verb, enum.RevisionStateExisting, body)
+	}
+	return &node, nil
+}
+
+// taintTimelineName returns the name of the subresource timeline for the given taint.
+// Taints are identified by the pair of its key and effect.
+func taintTimelineName(taint corev1.Taint) string {
+	return fmt.Sprintf("taint[%s:%s]", taint.Key, taint.Effect)
+}
+
+func taintBody(taint corev1.Taint) (string, error) {
+	body, err := yaml.Marshal(map[string]string{
+		"key":    taint.Key,
+		"value":  taint.Value,
+		"effect": string(taint.Effect),
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// resourcesBody returns the capacity and allocatable of the node. It returns an empty string when both are missing.
+func resourcesBody(status *corev1.NodeStatus) (string, error) {
+	if len(status.Capacity) == 0 && len(status.Allocatable) == 0 {
+		return "", nil
+	}
+	body, err := yaml.Marshal(map[string]map[string]string{
+		"capacity":    resourceListToMap(status.Capacity),
+		"allocatable": resourceListToMap(status.Allocatable),
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// versionBody returns the versions of the components running on the node. It returns an empty string when the kubelet version is missing.
+func versionBody(status *corev1.NodeStatus) (string, error) {
+	if status.NodeInfo.KubeletVersion == "" {
+		return "", nil
+	}
+	body, err := yaml.Marshal(map[string]string{
+		"kubeletVersion":          status.NodeInfo.KubeletVersion,
+		"containerRuntimeVersion": status.NodeInfo.ContainerRuntimeVersion,
+		"osImage":                 status.NodeInfo.OSImage,
+		"kernelVersion":           status.NodeInfo.KernelVersion,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// addressesBody returns the list of addresses of the node. It returns an empty string when the node has no address.
+func addressesBody(status *corev1.NodeStatus) (string, error) {
+	if len(status.Addresses) == 0 {
+		return "", nil
+	}
+	addresses := make([]map[string]string, 0, len(status.Addresses))
+	for _, address := range status.Addresses {
+		addresses = append(addresses, map[string]string{
+			"type":    string(address.Type),
+			"address": address.Address,
+		})
+	}
+	body, err := yaml.Marshal(addresses)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+func resourceListToMap(resources corev1.ResourceList) map[string]string {
+	result := map[string]string{}
+	for name, quantity := range resources {
+		result[string(name)] = quantity.String()
+	}
+	return result
+}
@@ -0,0 +1,363 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package noderecorder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourceinfo/resourcelease"
+	commonlogk8saudit_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/contract"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil/testchangeset"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil/testk8saudit"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const baseNodeManifest = `apiVersion: v1
+kind: Node
+metadata:
+  name: node-1
+spec:
+  taints:
+  - key: dedicated
+    value: gpu
+    effect: NoSchedule
+status:
+  capacity:
+    cpu: "4"
+    memory: 16Gi
+  allocatable:
+    cpu: 3920m
+    memory: 13Gi
+  nodeInfo:
+    kubeletVersion: v1.30.1
+    containerRuntimeVersion: containerd://1.7.0
+    osImage: Container-Optimized OS
+    kernelVersion: 6.1.0
+  addresses:
+  - type: InternalIP
+    address: 10.0.0.1
+  - type: Hostname
+    address: node-1
+`
+
+const cordonedNodeManifest = `apiVersion: v1
+kind: Node
+metadata:
+  name: node-1
+spec:
+  unschedulable: true
+  taints:
+  - key: dedicated
+    value: gpu
+    effect: NoSchedule
+  - key: node.kubernetes.io/unschedulable
+    effect: NoSchedule
+status:
+  capacity:
+    cpu: "4"
+    memory: 16Gi
+  allocatable:
+    cpu: 3920m
+    memory: 13Gi
+  nodeInfo:
+    kubeletVersion: v1.30.1
+    containerRuntimeVersion: containerd://1.7.0
+    osImage: Container-Optimized OS
+    kernelVersion: 6.1.0
+  addresses:
+  - type: InternalIP
+    address: 10.0.0.1
+  - type: Hostname
+    address: node-1
+`
+
+const upgradedNodeManifest = `apiVersion: v1
+kind: Node
+metadata:
+  name: node-1
+spec:
+  taints:
+  - key: dedicated
+    value: gpu
+    effect: NoSchedule
+status:
+  capacity:
+    cpu: "4"
+    memory: 16Gi
+  allocatable:
+    cpu: 3920m
+    memory: 13Gi
+  nodeInfo:
+    kubeletVersion: v1.31.0
+    containerRuntimeVersion: containerd://1.7.0
+    osImage: Container-Optimized OS
+    kernelVersion: 6.1.0
+  addresses:
+  - type: InternalIP
+    address: 10.0.0.1
+  - type: Hostname
+    address: node-1
+`
+
+var nodeOperation = model.KubernetesObjectOperation{
+	APIVersion: "core/v1",
+	PluralKind: "nodes",
+	Name:       "node-1",
+	Verb:       enum.RevisionVerbUpdate,
+}
+
+func TestRecordChangeSetForLog(t *testing.T) {
+	testCases := []struct {
+		name  string
+		steps []testk8saudit.Step
+	}{
+		{
+			name: "first log records every field of the node",
+			steps: []testk8saudit.Step{
+				{
+					Manifest: baseNodeManifest,
+					Verb:     enum.RevisionVerbCreate,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								"core/v1#node#cluster-scope#node-1#schedulable",
+								"core/v1#node#cluster-scope#node-1#taint[dedicated:NoSchedule]",
+								"core/v1#node#cluster-scope#node-1#resources",
+								"core/v1#node#cluster-scope#node-1#version",
+								"core/v1#node#cluster-scope#node-1#addresses",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#node#cluster-scope#node-1#schedulable",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbCreate,
+								State:      enum.RevisionStateNodeSchedulable,
+								Requestor:  "system:node:node-1",
+								ChangeTime: testk8saudit.BaseTime,
+								Body:       "unschedulable: false\n",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#node#cluster-scope#node-1#taint[dedicated:NoSchedule]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbCreate,
+								State:      enum.RevisionStateNodeTainted,
+								Requestor:  "system:node:node-1",
+								ChangeTime: testk8saudit.BaseTime,
+								Body:       "effect: NoSchedule\nkey: dedicated\nvalue: gpu\n",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#node#cluster-scope#node-1#resources",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbCreate,
+								State:      enum.RevisionStateExisting,
+								Requestor:  "system:node:node-1",
+								ChangeTime: testk8saudit.BaseTime,
+								Body: `allocatable:
+  cpu: 3920m
+  memory: 13Gi
+capacity:
+  cpu: "4"
+  memory: 16Gi
+`,
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#node#cluster-scope#node-1#addresses",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbCreate,
+								State:      enum.RevisionStateExisting,
+								Requestor:  "system:node:node-1",
+								ChangeTime: testk8saudit.BaseTime,
+								Body: `- address: 10.0.0.1
+  type: InternalIP
+- address: node-1
+  type: Hostname
+`,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "cordon, uncordon and kubelet upgrade",
+			steps: []testk8saudit.Step{
+				{
+					Manifest: baseNodeManifest,
+					Verb:     enum.RevisionVerbUpdate,
+				},
+				{
+					Manifest: cordonedNodeManifest,
+					Verb:     enum.RevisionVerbPatch,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								"core/v1#node#cluster-scope#node-1#schedulable",
+								"core/v1#node#cluster-scope#node-1#taint[node.kubernetes.io/unschedulable:NoSchedule]",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#node#cluster-scope#node-1#schedulable",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbCordon,
+								State:      enum.RevisionStateNodeUnschedulable,
+								Requestor:  "system:node:node-1",
+								ChangeTime: testk8saudit.BaseTime.Add(time.Minute),
+								Body:       "unschedulable: true\n",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#node#cluster-scope#node-1#taint[node.kubernetes.io/unschedulable:NoSchedule]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbTaint,
+								State:      enum.RevisionStateNodeTainted,
+								Requestor:  "system:node:node-1",
+								ChangeTime: testk8saudit.BaseTime.Add(time.Minute),
+								Body:       "effect: NoSchedule\nkey: node.kubernetes.io/unschedulable\nvalue: \"\"\n",
+							},
+						},
+					},
+				},
+				{
+					Manifest: upgradedNodeManifest,
+					Verb:     enum.RevisionVerbPatch,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								"core/v1#node#cluster-scope#node-1#schedulable",
+								"core/v1#node#cluster-scope#node-1#taint[node.kubernetes.io/unschedulable:NoSchedule]",
+								"core/v1#node#cluster-scope#node-1#version",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#node#cluster-scope#node-1#schedulable",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUncordon,
+								State:      enum.RevisionStateNodeSchedulable,
+								Requestor:  "system:node:node-1",
+								ChangeTime: testk8saudit.BaseTime.Add(2 * time.Minute),
+								Body:       "unschedulable: false\n",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#node#cluster-scope#node-1#taint[node.kubernetes.io/unschedulable:NoSchedule]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUntaint,
+								State:      enum.RevisionStateDeleted,
+								Requestor:  "system:node:node-1",
+								ChangeTime: testk8saudit.BaseTime.Add(2 * time.Minute),
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#node#cluster-scope#node-1#version",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbPatch,
+								State:      enum.RevisionStateExisting,
+								Requestor:  "system:node:node-1",
+								ChangeTime: testk8saudit.BaseTime.Add(2 * time.Minute),
+								Body: `containerRuntimeVersion: containerd://1.7.0
+kernelVersion: 6.1.0
+kubeletVersion: v1.31.0
+osImage: Container-Optimized OS
+`,
+							},
+						},
+					},
+				},
+				{
+					Manifest: upgradedNodeManifest,
+					Verb:     enum.RevisionVerbPatch,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "node deletion",
+			steps: []testk8saudit.Step{
+				{
+					Manifest: baseNodeManifest,
+					Verb:     enum.RevisionVerbUpdate,
+				},
+				{
+					Manifest: baseNodeManifest,
+					Verb:     enum.RevisionVerbDelete,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								"core/v1#node#cluster-scope#node-1#schedulable",
+								"core/v1#node#cluster-scope#node-1#taint[dedicated:NoSchedule]",
+								"core/v1#node#cluster-scope#node-1#resources",
+								"core/v1#node#cluster-scope#node-1#version",
+								"core/v1#node#cluster-scope#node-1#addresses",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#node#cluster-scope#node-1#taint[dedicated:NoSchedule]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbDelete,
+								State:      enum.RevisionStateDeleted,
+								Requestor:  "system:node:node-1",
+								ChangeTime: testk8saudit.BaseTime.Add(time.Minute),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := history.NewBuilder(t.TempDir())
+			testk8saudit.RunSteps(t, tc.steps, "system:node:node-1", nodeOperation, func(ctx context.Context, l *commonlogk8saudit_contract.AuditLogParserInput, prevNode *corev1.Node, cs *history.ChangeSet) (*corev1.Node, error) {
+				return recordChangeSetForLog(ctx, l, prevNode, cs, builder)
+			})
+		})
+	}
+}
+
+func TestRecordChangeSetForLog_TouchesNodeIPLeases(t *testing.T) {
+	timestamp := testk8saudit.BaseTime
+	builder := history.NewBuilder(t.TempDir())
+	operation := nodeOperation
+	l := testk8saudit.MustAuditLog(t, timestamp, "system:node:node-1", &operation, baseNodeManifest)
+
+	_, err := recordChangeSetForLog(context.Background(), l, nil, history.NewChangeSet(l.Log), builder)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lease, err := builder.ClusterResource.IPs.GetResourceLeaseHolderAt("10.0.0.1", timestamp)
+	if err != nil {
+		t.Fatalf("GetResourceLeaseHolderAt(10.0.0.1) returned an unexpected error: %v", err)
+	}
+	want := resourcelease.NewK8sResourceLeaseHolder("node", "", "node-1")
+	if !lease.Holder.Equals(want) {
+		t.Errorf("lease holder = %+v, want %+v", lease.Holder, want)
+	}
+	if _, err := builder.ClusterResource.IPs.GetResourceLeaseHolderAt("node-1", timestamp); err == nil {
+		t.Errorf("GetResourceLeaseHolderAt(node-1) returned no error, want an error for the hostname address")
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recorderutil
+
+import (
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	commonlogk8saudit_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/contract"
+)
+
+// AddRevision adds a revision requested by the requestor of the given audit log at the timestamp of the log.
+func AddRevision(cs *history.ChangeSet, l *commonlogk8saudit_contract.AuditLogParserInput, path resourcepath.ResourcePath, verb enum.RevisionVerb, state enum.RevisionState, body string) {
+	commonFieldSet := log.MustGetFieldSet(l.Log, &log.CommonFieldSet{})
+	cs.AddRevision(path, &history.StagingResourceRevision{
+		Verb:       verb,
+		Body:       body,
+		Requestor:  l.Requestor,
+		ChangeTime: commonFieldSet.Timestamp,
+		State:      state,
+	})
+}
+
+// AddRevisionAt adds a revision requested by the requestor of the given audit log at the given time.
+// The time is converted to UTC because times read from manifests or schedules can be in the other locations.
+func AddRevisionAt(cs *history.ChangeSet, l *commonlogk8saudit_contract.AuditLogParserInput, path resourcepath.ResourcePath, verb enum.RevisionVerb, state enum.RevisionState, body string, changeTime time.Time) {
+	cs.AddRevision(path, &history.StagingResourceRevision{
+		Verb:       verb,
+		Body:       body,
+		Requestor:  l.Requestor,
+		ChangeTime: changeTime.UTC(),
+		State:      state,
+	})
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recorderutil_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	commonlogk8saudit_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/contract"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder/recorderutil"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil/testchangeset"
+)
+
+func TestAddRevision(t *testing.T) {
+	timestamp := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name  string
+		verb  enum.RevisionVerb
+		state enum.RevisionState
+		body  string
+	}{
+		{
+			name:  "existing revision with body",
+			verb:  enum.RevisionVerbUpdate,
+			state: enum.RevisionStateExisting,
+			body:  "foo: bar\n",
+		},
+		{
+			name:  "deleted revision without body",
+			verb:  enum.RevisionVerbDelete,
+			state: enum.RevisionStateDeleted,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := &commonlogk8saudit_contract.AuditLogParserInput{
+				Log:       log.NewLogWithFieldSetsForTest(&log.CommonFieldSet{Timestamp: timestamp}),
+				Requestor: "user@example.com",
+			}
+			cs := history.NewChangeSet(l.Log)
+
+			recorderutil.AddRevision(cs, l, resourcepath.ResourcePath{Path: "core/v1#pod#default#foo"}, tc.verb, tc.state, tc.body)
+
+			asserter := &testchangeset.HasRevision{
+				ResourcePath: "core/v1#pod#default#foo",
+				WantRevision: history.StagingResourceRevision{
+					Verb:       tc.verb,
+					State:      tc.state,
+					Body:       tc.body,
+					Requestor:  "user@example.com",
+					ChangeTime: timestamp,
+				},
+			}
+			asserter.Assert(t, cs)
+		})
+	}
+}
+
+func TestAddRevisionAt(t *testing.T) {
+	timestamp := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name           string
+		changeTime     time.Time
+		wantChangeTime time.Time
+	}{
+		{
+			name:           "time before the log",
+			changeTime:     timestamp.Add(-time.Minute),
+			wantChangeTime: timestamp.Add(-time.Minute),
+		},
+		{
+			name:           "time in the other location is converted to UTC",
+			changeTime:     time.Date(2025, 1, 1, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60)),
+			wantChangeTime: timestamp,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := &commonlogk8saudit_contract.AuditLogParserInput{
+				Log:       log.NewLogWithFieldSetsForTest(&log.CommonFieldSet{Timestamp: timestamp}),
+				Requestor: "user@example.com",
+			}
+			cs := history.NewChangeSet(l.Log)
+
+			recorderutil.AddRevisionAt(cs, l, resourcepath.ResourcePath{Path: "core/v1#pod#default#foo"}, enum.RevisionVerbUpdate, enum.RevisionStateExisting, "", tc.changeTime)
+
+			asserter := &testchangeset.HasRevision{
+				ResourcePath: "core/v1#pod#default#foo",
+				WantRevision: history.StagingResourceRevision{
+					Verb:       enum.RevisionVerbUpdate,
+					State:      enum.RevisionStateExisting,
+					Requestor:  "user@example.com",
+					ChangeTime: tc.wantChangeTime,
+				},
+			}
+			asserter.Assert(t, cs)
+		})
+	}
+}
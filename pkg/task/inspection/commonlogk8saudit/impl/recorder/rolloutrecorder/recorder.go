@@ -0,0 +1,269 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rolloutrecorder
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structured"
+	"github.com/GoogleCloudPlatform/khi/pkg/core/task/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
+	commonlogk8saudit_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/contract"
+	commonlogk8saudit_impl "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder/recorderutil"
+	"gopkg.in/yaml.v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// deploymentRevisionAnnotation is the annotation set by the deployment controller to count revisions of the pod template.
+const deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
+// progressDeadlineExceededReason is the reason of the Progressing condition when a deployment failed to progress in its deadline.
+const progressDeadlineExceededReason = "ProgressDeadlineExceeded"
+
+// replicaSetInConditionMessage matches the ReplicaSet name in the message of the Progressing condition of Deployments.
+// e.g. `ReplicaSet "nginx-deployment-5d9c8b7f6" is progressing.`
+var replicaSetInConditionMessage = regexp.MustCompile(`ReplicaSet "([^"]+)"`)
+
+// rolloutStatus is the body of revisions on the rollout timeline.
+type rolloutStatus struct {
+	Revision          string `yaml:"revision,omitempty"`
+	ReplicaSet        string `yaml:"replicaSet,omitempty"`
+	CurrentRevision   string `yaml:"currentRevision,omitempty"`
+	UpdateRevision    string `yaml:"updateRevision,omitempty"`
+	DesiredReplicas   int32  `yaml:"desiredReplicas"`
+	UpdatedReplicas   int32  `yaml:"updatedReplicas"`
+	AvailableReplicas int32  `yaml:"availableReplicas"`
+	Reason            string `yaml:"reason,omitempty"`
+	Message           string `yaml:"message,omitempty"`
+}
+
+// workloadRollout is the rollout information read from a workload manifest.
+type workloadRollout struct {
+	// template is the serialized pod template used to detect template changes.
+	template  string
+	status    rolloutStatus
+	completed bool
+	stalled   bool
+	// linkedResources are the resources created for the rollout like ReplicaSets or ControllerRevisions.
+	linkedResources []resourcepath.ResourcePath
+}
+
+// rolloutRecorderState is the state of the rollout recorder kept between logs.
+type rolloutRecorderState struct {
+	rollout    *workloadRollout
+	body       string
+	state      enum.RevisionState
+	inProgress bool
+}
+
+type workloadRolloutReader = func(l *commonlogk8saudit_contract.AuditLogParserInput) (*workloadRollout, error)
+
+func Register(manager *recorder.RecorderTaskManager) error {
+	registerRolloutRecorder(manager, "deployment", readDeploymentRollout)
+	registerRolloutRecorder(manager, "statefulset", readStatefulSetRollout)
+	registerRolloutRecorder(manager, "daemonset", readDaemonSetRollout)
+	return nil
+}
+
+func registerRolloutRecorder(manager *recorder.RecorderTaskManager, kind string, reader workloadRolloutReader) {
+	manager.AddRecorder(kind+"-rollout", []taskid.UntypedTaskReference{}, func(ctx context.Context, req *recorder.RecorderRequest) (any, error) {
+		var prevState *rolloutRecorderState
+		if req.PreviousState != nil {
+			prevState = req.PreviousState.(*rolloutRecorderState)
+		}
+		return recordRollout(ctx, req.LogParseResult, prevState, req.ChangeSet, reader)
+	}, recorder.ResourceKindLogGroupFilter(kind), recorder.AndLogFilter(recorder.OnlySucceedLogs(), recorder.OnlyWithResourceBody()))
+}
+
+// recordRollout records the progress of rollouts. A rollout starts when the pod template or its revision is changed and it finishes when all the replicas are updated and available.
+// Changes of the replica counts out of rollouts are not recorded.
+func recordRollout(ctx context.Context, l *commonlogk8saudit_contract.AuditLogParserInput, prevState *rolloutRecorderState, cs *history.ChangeSet, reader workloadRolloutReader) (*rolloutRecorderState, error) {
+	rollout, err := reader(l)
+	if err != nil {
+		return nil, err
+	}
+	workloadPath := resourcepath.NameLayerGeneralItem(l.Operation.APIVersion, l.Operation.GetSingularKindName(), l.Operation.Namespace, l.Operation.Name)
+	rolloutPath := resourcepath.Rollout(workloadPath)
+
+	if commonlogk8saudit_impl.ParseDeletionStatus(ctx, l.ResourceBodyReader, l.Operation) == commonlogk8saudit_impl.DeletionStatusDeleted {
+		if prevState != nil && prevState.inProgress {
+			recorderutil.AddRevision(cs, l, rolloutPath, enum.RevisionVerbDelete, enum.RevisionStateDeleted, "")
+		}
+		return &rolloutRecorderState{rollout: rollout}, nil
+	}
+
+	started := prevState != nil && isNewRollout(prevState.rollout, rollout)
+	inProgress := started || (prevState != nil && prevState.inProgress) || (prevState == nil && !rollout.completed)
+	if !inProgress {
+		return &rolloutRecorderState{rollout: rollout}, nil
+	}
+
+	state := enum.RevisionStateRolloutInProgress
+	switch {
+	case started:
+		// The status in the log changing the template can be still showing the previous completed rollout.
+	case rollout.stalled:
+		state = enum.RevisionStateRolloutStalled
+	case rollout.completed:
+		state = enum.RevisionStateRolloutCompleted
+	}
+	body, err := yaml.Marshal(rollout.status)
+	if err != nil {
+		return nil, err
+	}
+	nextState := &rolloutRecorderState{
+		rollout:    rollout,
+		body:       string(body),
+		state:      state,
+		inProgress: state != enum.RevisionStateRolloutCompleted,
+	}
+	if !started && prevState != nil && prevState.body == nextState.body && prevState.state == nextState.state {
+		return nextState, nil
+	}
+	recorderutil.AddRevision(cs, l, rolloutPath, l.Operation.Verb, state, string(body))
+	for _, linkedResource := range rollout.linkedResources {
+		cs.AddEvent(linkedResource)
+	}
+	return nextState, nil
+}
+
+// isNewRollout returns true when the current workload has a different pod template or revision from the previous one.
+func isNewRollout(prev *workloadRollout, current *workloadRollout) bool {
+	if prev == nil {
+		return false
+	}
+	if prev.template != current.template {
+		return true
+	}
+	revisionChanged := prev.status.Revision != "" && current.status.Revision != "" && prev.status.Revision != current.status.Revision
+	updateRevisionChanged := prev.status.UpdateRevision != "" && current.status.UpdateRevision != "" && prev.status.UpdateRevision != current.status.UpdateRevision
+	return revisionChanged || updateRevisionChanged
+}
+
+func readDeploymentRollout(l *commonlogk8saudit_contract.AuditLogParserInput) (*workloadRollout, error) {
+	var deployment appsv1.Deployment
+	err := structured.ReadReflectK8sRuntimeObject(l.ResourceBodyReader, "", &deployment)
+	if err != nil {
+		return nil, err
+	}
+	template, err := json.Marshal(deployment.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+	desiredReplicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desiredReplicas = *deployment.Spec.Replicas
+	}
+	result := &workloadRollout{
+		template: string(template),
+		status: rolloutStatus{
+			Revision:          deployment.Annotations[deploymentRevisionAnnotation],
+			DesiredReplicas:   desiredReplicas,
+			UpdatedReplicas:   deployment.Status.UpdatedReplicas,
+			AvailableReplicas: deployment.Status.AvailableReplicas,
+		},
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type != appsv1.DeploymentProgressing {
+			continue
+		}
+		if matches := replicaSetInConditionMessage.FindStringSubmatch(condition.Message); len(matches) == 2 {
+			result.status.ReplicaSet = matches[1]
+			result.linkedResources = append(result.linkedResources, resourcepath.NameLayerGeneralItem("apps/v1", "replicaset", l.Operation.Namespace, matches[1]))
+		}
+		if condition.Status == corev1.ConditionFalse && condition.Reason == progressDeadlineExceededReason {
+			result.stalled = true
+			result.status.Reason = condition.Reason
+			result.status.Message = condition.Message
+		}
+	}
+	// Same as the condition used in `kubectl rollout status`.
+	result.completed = deployment.Status.ObservedGeneration >= deployment.Generation &&
+		deployment.Status.UpdatedReplicas == desiredReplicas &&
+		deployment.Status.Replicas == deployment.Status.UpdatedReplicas &&
+		deployment.Status.AvailableReplicas == deployment.Status.UpdatedReplicas
+	return result, nil
+}
+
+func readStatefulSetRollout(l *commonlogk8saudit_contract.AuditLogParserInput) (*workloadRollout, error) {
+	var statefulSet appsv1.StatefulSet
+	err := structured.ReadReflectK8sRuntimeObject(l.ResourceBodyReader, "", &statefulSet)
+	if err != nil {
+		return nil, err
+	}
+	template, err := json.Marshal(statefulSet.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+	desiredReplicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		desiredReplicas = *statefulSet.Spec.Replicas
+	}
+	result := &workloadRollout{
+		template: string(template),
+		status: rolloutStatus{
+			CurrentRevision:   statefulSet.Status.CurrentRevision,
+			UpdateRevision:    statefulSet.Status.UpdateRevision,
+			DesiredReplicas:   desiredReplicas,
+			UpdatedReplicas:   statefulSet.Status.UpdatedReplicas,
+			AvailableReplicas: statefulSet.Status.AvailableReplicas,
+		},
+	}
+	for _, revision := range []string{statefulSet.Status.UpdateRevision, statefulSet.Status.CurrentRevision} {
+		if revision == "" {
+			continue
+		}
+		revisionPath := resourcepath.NameLayerGeneralItem("apps/v1", "controllerrevision", l.Operation.Namespace, revision)
+		if len(result.linkedResources) == 0 || result.linkedResources[0] != revisionPath {
+			result.linkedResources = append(result.linkedResources, revisionPath)
+		}
+	}
+	result.completed = statefulSet.Status.ObservedGeneration >= statefulSet.Generation &&
+		statefulSet.Status.UpdatedReplicas == desiredReplicas &&
+		statefulSet.Status.AvailableReplicas == desiredReplicas &&
+		statefulSet.Status.CurrentRevision == statefulSet.Status.UpdateRevision
+	return result, nil
+}
+
+func readDaemonSetRollout(l *commonlogk8saudit_contract.AuditLogParserInput) (*workloadRollout, error) {
+	var daemonSet appsv1.DaemonSet
+	err := structured.ReadReflectK8sRuntimeObject(l.ResourceBodyReader, "", &daemonSet)
+	if err != nil {
+		return nil, err
+	}
+	template, err := json.Marshal(daemonSet.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+	result := &workloadRollout{
+		template: string(template),
+		status: rolloutStatus{
+			DesiredReplicas:   daemonSet.Status.DesiredNumberScheduled,
+			UpdatedReplicas:   daemonSet.Status.UpdatedNumberScheduled,
+			AvailableReplicas: daemonSet.Status.NumberAvailable,
+		},
+	}
+	result.completed = daemonSet.Status.ObservedGeneration >= daemonSet.Generation &&
+		daemonSet.Status.UpdatedNumberScheduled == daemonSet.Status.DesiredNumberScheduled &&
+		daemonSet.Status.NumberAvailable == daemonSet.Status.DesiredNumberScheduled
+	return result, nil
+}
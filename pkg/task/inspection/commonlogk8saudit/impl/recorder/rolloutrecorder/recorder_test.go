@@ -0,0 +1,379 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rolloutrecorder
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	commonlogk8saudit_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/contract"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil/testchangeset"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil/testk8saudit"
+)
+
+const deploymentManifestTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  generation: %d
+  annotations:
+    deployment.kubernetes.io/revision: "%d"
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:v%d
+status:
+  observedGeneration: %d
+  replicas: %d
+  updatedReplicas: %d
+  availableReplicas: %d
+  conditions:
+  - type: Progressing
+    status: "%s"
+    reason: %s
+    message: '%s'
+`
+
+const statefulSetManifestTemplate = `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+  namespace: default
+  generation: %d
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: db
+        image: db:v%d
+status:
+  observedGeneration: %d
+  currentRevision: %s
+  updateRevision: %s
+  updatedReplicas: %d
+  availableReplicas: %d
+`
+
+const daemonSetManifestTemplate = `apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: agent
+  namespace: kube-system
+  generation: %d
+spec:
+  template:
+    spec:
+      containers:
+      - name: agent
+        image: agent:v%d
+status:
+  observedGeneration: %d
+  desiredNumberScheduled: 3
+  updatedNumberScheduled: %d
+  numberAvailable: %d
+`
+
+func TestRecordRollout_Deployment(t *testing.T) {
+	rolloutPath := "apps/v1#deployment#default#web#rollout"
+	testCases := []struct {
+		name  string
+		steps []testk8saudit.Step
+	}{
+		{
+			name: "deployment rolling out a new revision",
+			steps: []testk8saudit.Step{
+				{
+					Manifest: fmt.Sprintf(deploymentManifestTemplate, 1, 1, 1, 1, 3, 3, 3, "True", "NewReplicaSetAvailable", `ReplicaSet "web-1" has successfully progressed.`),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{WantResourcePaths: []string{}},
+					},
+				},
+				{
+					Manifest: fmt.Sprintf(deploymentManifestTemplate, 2, 2, 2, 1, 3, 3, 3, "True", "NewReplicaSetAvailable", `ReplicaSet "web-1" has successfully progressed.`),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								rolloutPath,
+								"apps/v1#replicaset#default#web-1",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: rolloutPath,
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateRolloutInProgress,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime.Add(time.Minute),
+								Body: `revision: "2"
+replicaSet: web-1
+desiredReplicas: 3
+updatedReplicas: 3
+availableReplicas: 3
+`,
+							},
+						},
+					},
+				},
+				{
+					Manifest: fmt.Sprintf(deploymentManifestTemplate, 2, 2, 2, 2, 4, 1, 3, "True", "ReplicaSetUpdated", `ReplicaSet "web-2" is progressing.`),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								rolloutPath,
+								"apps/v1#replicaset#default#web-2",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: rolloutPath,
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateRolloutInProgress,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime.Add(2 * time.Minute),
+								Body: `revision: "2"
+replicaSet: web-2
+desiredReplicas: 3
+updatedReplicas: 1
+availableReplicas: 3
+`,
+							},
+						},
+					},
+				},
+				{
+					Manifest: fmt.Sprintf(deploymentManifestTemplate, 2, 2, 2, 2, 4, 1, 3, "False", "ProgressDeadlineExceeded", `ReplicaSet "web-2" has timed out progressing.`),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.HasRevision{
+							ResourcePath: rolloutPath,
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateRolloutStalled,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime.Add(3 * time.Minute),
+								Body: `revision: "2"
+replicaSet: web-2
+desiredReplicas: 3
+updatedReplicas: 1
+availableReplicas: 3
+reason: ProgressDeadlineExceeded
+message: ReplicaSet "web-2" has timed out progressing.
+`,
+							},
+						},
+					},
+				},
+				{
+					Manifest: fmt.Sprintf(deploymentManifestTemplate, 2, 2, 2, 2, 3, 3, 3, "True", "NewReplicaSetAvailable", `ReplicaSet "web-2" has successfully progressed.`),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.HasRevision{
+							ResourcePath: rolloutPath,
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateRolloutCompleted,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime.Add(4 * time.Minute),
+								Body: `revision: "2"
+replicaSet: web-2
+desiredReplicas: 3
+updatedReplicas: 3
+availableReplicas: 3
+`,
+							},
+						},
+					},
+				},
+				{
+					Manifest: fmt.Sprintf(deploymentManifestTemplate, 2, 2, 2, 2, 3, 3, 3, "True", "NewReplicaSetAvailable", `ReplicaSet "web-2" has successfully progressed.`),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{WantResourcePaths: []string{}},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testk8saudit.RunSteps(t, tc.steps, "user@example.com", model.KubernetesObjectOperation{
+				APIVersion: "apps/v1",
+				PluralKind: "deployments",
+				Namespace:  "default",
+				Name:       "web",
+			}, recordRolloutWith(readDeploymentRollout))
+		})
+	}
+}
+
+func TestRecordRollout_StatefulSet(t *testing.T) {
+	rolloutPath := "apps/v1#statefulset#default#db#rollout"
+	testCases := []struct {
+		name  string
+		steps []testk8saudit.Step
+	}{
+		{
+			name: "statefulset rolling out a new revision",
+			steps: []testk8saudit.Step{
+				{
+					Manifest: fmt.Sprintf(statefulSetManifestTemplate, 1, 1, 1, "db-1", "db-1", 2, 2),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{WantResourcePaths: []string{}},
+					},
+				},
+				{
+					Manifest: fmt.Sprintf(statefulSetManifestTemplate, 2, 2, 2, "db-1", "db-2", 1, 2),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								rolloutPath,
+								"apps/v1#controllerrevision#default#db-2",
+								"apps/v1#controllerrevision#default#db-1",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: rolloutPath,
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateRolloutInProgress,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime.Add(time.Minute),
+								Body: `currentRevision: db-1
+updateRevision: db-2
+desiredReplicas: 2
+updatedReplicas: 1
+availableReplicas: 2
+`,
+							},
+						},
+					},
+				},
+				{
+					Manifest: fmt.Sprintf(statefulSetManifestTemplate, 2, 2, 2, "db-2", "db-2", 2, 2),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								rolloutPath,
+								"apps/v1#controllerrevision#default#db-2",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: rolloutPath,
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateRolloutCompleted,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime.Add(2 * time.Minute),
+								Body: `currentRevision: db-2
+updateRevision: db-2
+desiredReplicas: 2
+updatedReplicas: 2
+availableReplicas: 2
+`,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testk8saudit.RunSteps(t, tc.steps, "user@example.com", model.KubernetesObjectOperation{
+				APIVersion: "apps/v1",
+				PluralKind: "statefulsets",
+				Namespace:  "default",
+				Name:       "db",
+			}, recordRolloutWith(readStatefulSetRollout))
+		})
+	}
+}
+
+func TestRecordRollout_DaemonSetDeletedDuringRollout(t *testing.T) {
+	rolloutPath := "apps/v1#daemonset#kube-system#agent#rollout"
+	testCases := []struct {
+		name  string
+		steps []testk8saudit.Step
+	}{
+		{
+			name: "daemonset deleted during a rollout",
+			steps: []testk8saudit.Step{
+				{
+					Manifest: fmt.Sprintf(daemonSetManifestTemplate, 1, 1, 1, 3, 3),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{WantResourcePaths: []string{}},
+					},
+				},
+				{
+					Manifest: fmt.Sprintf(daemonSetManifestTemplate, 2, 2, 2, 0, 3),
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.HasRevision{
+							ResourcePath: rolloutPath,
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateRolloutInProgress,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime.Add(time.Minute),
+								Body: `desiredReplicas: 3
+updatedReplicas: 0
+availableReplicas: 3
+`,
+							},
+						},
+					},
+				},
+				{
+					Manifest: fmt.Sprintf(daemonSetManifestTemplate, 2, 2, 2, 1, 2),
+					Verb:     enum.RevisionVerbDelete,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.HasRevision{
+							ResourcePath: rolloutPath,
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbDelete,
+								State:      enum.RevisionStateDeleted,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime.Add(2 * time.Minute),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testk8saudit.RunSteps(t, tc.steps, "user@example.com", model.KubernetesObjectOperation{
+				APIVersion: "apps/v1",
+				PluralKind: "daemonsets",
+				Namespace:  "kube-system",
+				Name:       "agent",
+			}, recordRolloutWith(readDaemonSetRollout))
+		})
+	}
+}
+
+// recordRolloutWith returns a RecordFunc calling recordRollout with the given reader.
+func recordRolloutWith(reader workloadRolloutReader) testk8saudit.RecordFunc[*rolloutRecorderState] {
+	return func(ctx context.Context, l *commonlogk8saudit_contract.AuditLogParserInput, prevState *rolloutRecorderState, cs *history.ChangeSet) (*rolloutRecorderState, error) {
+		return recordRollout(ctx, l, prevState, cs, reader)
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package volumerecorder
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structured"
+	"github.com/GoogleCloudPlatform/khi/pkg/core/task/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
+	commonlogk8saudit_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/contract"
+	commonlogk8saudit_impl "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder/recorderutil"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+func Register(manager *recorder.RecorderTaskManager) error {
+	succeedLogsWithBody := recorder.AndLogFilter(recorder.OnlySucceedLogs(), recorder.OnlyWithResourceBody())
+	manager.AddRecorder("persistentvolumeclaim-volume", []taskid.UntypedTaskReference{}, func(ctx context.Context, req *recorder.RecorderRequest) (any, error) {
+		var prevClaim *corev1.PersistentVolumeClaim
+		if req.PreviousState != nil {
+			prevClaim = req.PreviousState.(*corev1.PersistentVolumeClaim)
+		}
+		return recordPersistentVolumeClaim(ctx, req.LogParseResult, prevClaim, req.ChangeSet)
+	}, recorder.ResourceKindLogGroupFilter("persistentvolumeclaim"), succeedLogsWithBody)
+	manager.AddRecorder("persistentvolume-claim", []taskid.UntypedTaskReference{}, func(ctx context.Context, req *recorder.RecorderRequest) (any, error) {
+		var prevVolume *corev1.PersistentVolume
+		if req.PreviousState != nil {
+			prevVolume = req.PreviousState.(*corev1.PersistentVolume)
+		}
+		return recordPersistentVolume(ctx, req.LogParseResult, prevVolume, req.ChangeSet)
+	}, recorder.ResourceKindLogGroupFilter("persistentvolume"), succeedLogsWithBody)
+	manager.AddRecorder("pod-persistentvolumeclaim", []taskid.UntypedTaskReference{}, func(ctx context.Context, req *recorder.RecorderRequest) (any, error) {
+		var prevClaims map[string]string
+		if req.PreviousState != nil {
+			prevClaims = req.PreviousState.(map[string]string)
+		}
+		return recordPodVolumes(ctx, req.LogParseResult, prevClaims, req.ChangeSet)
+	}, recorder.ResourceKindLogGroupFilter("pod"), succeedLogsWithBody)
+	manager.AddRecorder("volumeattachment", []taskid.UntypedTaskReference{}, func(ctx context.Context, req *recorder.RecorderRequest) (any, error) {
+		var prevAttachment *storagev1.VolumeAttachment
+		if req.PreviousState != nil {
+			prevAttachment = req.PreviousState.(*storagev1.VolumeAttachment)
+		}
+		return recordVolumeAttachment(ctx, req.LogParseResult, prevAttachment, req.ChangeSet)
+	}, recorder.ResourceKindLogGroupFilter("volumeattachment"), succeedLogsWithBody)
+	return nil
+}
+
+// recordPersistentVolumeClaim records the phase of the claim on the timeline of the bound volume under the claim.
+func recordPersistentVolumeClaim(ctx context.Context, l *commonlogk8saudit_contract.AuditLogParserInput, prevClaim *corev1.PersistentVolumeClaim, cs *history.ChangeSet) (*corev1.PersistentVolumeClaim, error) {
+	var claim corev1.PersistentVolumeClaim
+	err := structured.ReadReflectK8sRuntimeObject(l.ResourceBodyReader, "", &claim)
+	if err != nil {
+		return nil, err
+	}
+	volumePath := resourcepath.PersistentVolumeUnderPersistentVolumeClaim(l.Operation.Namespace, l.Operation.Name, claim.Spec.VolumeName)
+	if isDeleted(ctx, l) {
+		recorderutil.AddRevision(cs, l, volumePath, enum.RevisionVerbDelete, enum.RevisionStateDeleted, "")
+		return &claim, nil
+	}
+	if prevClaim != nil && prevClaim.Spec.VolumeName == claim.Spec.VolumeName && prevClaim.Status.Phase == claim.Status.Phase {
+		return &claim, nil
+	}
+	if prevClaim != nil && prevClaim.Spec.VolumeName != claim.Spec.VolumeName {
+		// The claim was pending without a volume, or bound to another volume before.
+		prevVolumePath := resourcepath.PersistentVolumeUnderPersistentVolumeClaim(l.Operation.Namespace, l.Operation.Name, prevClaim.Spec.VolumeName)
+		recorderutil.AddRevision(cs, l, prevVolumePath, l.Operation.Verb, enum.RevisionStateDeleted, "")
+	}
+	body, err := yaml.Marshal(map[string]string{
+		"volumeName": claim.Spec.VolumeName,
+		"phase":      string(claim.Status.Phase),
+	})
+	if err != nil {
+		return nil, err
+	}
+	recorderutil.AddRevision(cs, l, volumePath, l.Operation.Verb, claimPhaseToRevisionState(claim.Status.Phase), string(body))
+	return &claim, nil
+}
+
+// recordPersistentVolume records the phase of the volume on the timeline of the claim under the volume.
+func recordPersistentVolume(ctx context.Context, l *commonlogk8saudit_contract.AuditLogParserInput, prevVolume *corev1.PersistentVolume, cs *history.ChangeSet) (*corev1.PersistentVolume, error) {
+	var volume corev1.PersistentVolume
+	err := structured.ReadReflectK8sRuntimeObject(l.ResourceBodyReader, "", &volume)
+	if err != nil {
+		return nil, err
+	}
+	claimNamespace, claimName := claimRefNamespaceAndName(&volume)
+	claimPath := resourcepath.PersistentVolumeClaimUnderPersistentVolume(l.Operation.Name, claimNamespace, claimName)
+	if isDeleted(ctx, l) {
+		recorderutil.AddRevision(cs, l, claimPath, enum.RevisionVerbDelete, enum.RevisionStateDeleted, "")
+		return &volume, nil
+	}
+	if prevVolume != nil {
+		prevClaimNamespace, prevClaimName := claimRefNamespaceAndName(prevVolume)
+		claimChanged := prevClaimNamespace != claimNamespace || prevClaimName != claimName
+		if !claimChanged && prevVolume.Status.Phase == volume.Status.Phase {
+			return &volume, nil
+		}
+		if claimChanged {
+			prevClaimPath := resourcepath.PersistentVolumeClaimUnderPersistentVolume(l.Operation.Name, prevClaimNamespace, prevClaimName)
+			recorderutil.AddRevision(cs, l, prevClaimPath, l.Operation.Verb, enum.RevisionStateDeleted, "")
+		}
+	}
+	claimRef := ""
+	if claimName != "" {
+		claimRef = fmt.Sprintf("%s/%s", claimNamespace, claimName)
+	}
+	body, err := yaml.Marshal(map[string]string{
+		"claimRef":      claimRef,
+		"phase":         string(volume.Status.Phase),
+		"reclaimPolicy": string(volume.Spec.PersistentVolumeReclaimPolicy),
+		"message":       volume.Status.Message,
+	})
+	if err != nil {
+		return nil, err
+	}
+	recorderutil.AddRevision(cs, l, claimPath, l.Operation.Verb, volumePhaseToRevisionState(volume.Status.Phase), string(body))
+	return &volume, nil
+}
+
+// recordPodVolumes records the pod on the timelines under the claims used in its volumes.
+// It returns the map of claim names to the volume names in the pod.
+func recordPodVolumes(ctx context.Context, l *commonlogk8saudit_contract.AuditLogParserInput, prevClaims map[string]string, cs *history.ChangeSet) (map[string]string, error) {
+	var pod corev1.Pod
+	err := structured.ReadReflectK8sRuntimeObject(l.ResourceBodyReader, "", &pod)
+	if err != nil {
+		return nil, err
+	}
+	claims := map[string]string{}
+	for _, volume := range pod.Spec.Volumes {
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			claims[volume.PersistentVolumeClaim.ClaimName] = volume.Name
+		case volume.Ephemeral != nil:
+			// PVCs for generic ephemeral volumes are named as `<pod name>-<volume name>`.
+			claims[fmt.Sprintf("%s-%s", l.Operation.Name, volume.Name)] = volume.Name
+		}
+	}
+	deleted := isDeleted(ctx, l)
+	for claimName, volumeName := range claims {
+		podPath := resourcepath.PodUnderPersistentVolumeClaim(l.Operation.Namespace, claimName, l.Operation.Name)
+		if deleted {
+			recorderutil.AddRevision(cs, l, podPath, enum.RevisionVerbDelete, enum.RevisionStateDeleted, "")
+			continue
+		}
+		if _, found := prevClaims[claimName]; found {
+			continue
+		}
+		body, err := yaml.Marshal(map[string]string{
+			"pod":    l.Operation.Name,
+			"volume": volumeName,
+		})
+		if err != nil {
+			return nil, err
+		}
+		recorderutil.AddRevision(cs, l, podPath, l.Operation.Verb, enum.RevisionStateExisting, string(body))
+	}
+	return claims, nil
+}
+
+// recordVolumeAttachment records the attachment status on the timelines under the node and the persistent volume.
+func recordVolumeAttachment(ctx context.Context, l *commonlogk8saudit_contract.AuditLogParserInput, prevAttachment *storagev1.VolumeAttachment, cs *history.ChangeSet) (*storagev1.VolumeAttachment, error) {
+	var attachment storagev1.VolumeAttachment
+	err := structured.ReadReflectK8sRuntimeObject(l.ResourceBodyReader, "", &attachment)
+	if err != nil {
+		return nil, err
+	}
+	attachmentPaths := []resourcepath.ResourcePath{
+		resourcepath.VolumeAttachmentUnderResource(resourcepath.Node(attachment.Spec.NodeName), l.Operation.Name),
+	}
+	if attachment.Spec.Source.PersistentVolumeName != nil {
+		attachmentPaths = append(attachmentPaths, resourcepath.VolumeAttachmentUnderResource(resourcepath.PersistentVolume(*attachment.Spec.Source.PersistentVolumeName), l.Operation.Name))
+	}
+	if isDeleted(ctx, l) {
+		for _, path := range attachmentPaths {
+			recorderutil.AddRevision(cs, l, path, enum.RevisionVerbDelete, enum.RevisionStateDeleted, "")
+		}
+		return &attachment, nil
+	}
+	statusBody := volumeAttachmentStatusBody(&attachment)
+	if prevAttachment != nil && statusBody == volumeAttachmentStatusBody(prevAttachment) {
+		return &attachment, nil
+	}
+	body, err := yaml.Marshal(statusBody)
+	if err != nil {
+		return nil, err
+	}
+	state := enum.RevisionStateVolumeDetached
+	if attachment.Status.Attached {
+		state = enum.RevisionStateVolumeAttached
+	}
+	for _, path := range attachmentPaths {
+		recorderutil.AddRevision(cs, l, path, l.Operation.Verb, state, string(body))
+	}
+	return &attachment, nil
+}
+
+type volumeAttachmentStatus struct {
+	Attacher    string `yaml:"attacher"`
+	Attached    bool   `yaml:"attached"`
+	AttachError string `yaml:"attachError,omitempty"`
+	DetachError string `yaml:"detachError,omitempty"`
+}
+
+func volumeAttachmentStatusBody(attachment *storagev1.VolumeAttachment) volumeAttachmentStatus {
+	status := volumeAttachmentStatus{
+		Attacher: attachment.Spec.Attacher,
+		Attached: attachment.Status.Attached,
+	}
+	if attachment.Status.AttachError != nil {
+		status.AttachError = attachment.Status.AttachError.Message
+	}
+	if attachment.Status.DetachError != nil {
+		status.DetachError = attachment.Status.DetachError.Message
+	}
+	return status
+}
+
+func claimRefNamespaceAndName(volume *corev1.PersistentVolume) (string, string) {
+	if volume.Spec.ClaimRef == nil {
+		return "", ""
+	}
+	return volume.Spec.ClaimRef.Namespace, volume.Spec.ClaimRef.Name
+}
+
+func claimPhaseToRevisionState(phase corev1.PersistentVolumeClaimPhase) enum.RevisionState {
+	switch phase {
+	case corev1.ClaimBound:
+		return enum.RevisionStateVolumeBound
+	case corev1.ClaimLost:
+		return enum.RevisionStateVolumeLost
+	default:
+		return enum.RevisionStateVolumePending
+	}
+}
+
+func volumePhaseToRevisionState(phase corev1.PersistentVolumePhase) enum.RevisionState {
+	switch phase {
+	case corev1.VolumeAvailable:
+		return enum.RevisionStateVolumeAvailable
+	case corev1.VolumeBound:
+		return enum.RevisionStateVolumeBound
+	case corev1.VolumeReleased:
+		return enum.RevisionStateVolumeReleased
+	case corev1.VolumeFailed:
+		return enum.RevisionStateVolumeFailed
+	default:
+		return enum.RevisionStateVolumePending
+	}
+}
+
+func isDeleted(ctx context.Context, l *commonlogk8saudit_contract.AuditLogParserInput) bool {
+	return commonlogk8saudit_impl.ParseDeletionStatus(ctx, l.ResourceBodyReader, l.Operation) == commonlogk8saudit_impl.DeletionStatusDeleted
+}
@@ -0,0 +1,453 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package volumerecorder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil/testchangeset"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil/testk8saudit"
+)
+
+func TestRecordPersistentVolumeClaim(t *testing.T) {
+	testCases := []struct {
+		name  string
+		steps []testk8saudit.Step
+	}{
+		{
+			name: "claim bound to a volume and deleted",
+			steps: []testk8saudit.Step{
+				{
+					Manifest: `apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data
+  namespace: default
+status:
+  phase: Pending
+`,
+					Verb: enum.RevisionVerbCreate,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#persistentvolumeclaim#default#data#unknown[persistentvolume]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbCreate,
+								State:      enum.RevisionStateVolumePending,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime,
+								Body:       "phase: Pending\nvolumeName: \"\"\n",
+							},
+						},
+					},
+				},
+				{
+					Manifest: `apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data
+  namespace: default
+spec:
+  volumeName: pv-1
+status:
+  phase: Bound
+`,
+					Verb: enum.RevisionVerbUpdate,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								"core/v1#persistentvolumeclaim#default#data#unknown[persistentvolume]",
+								"core/v1#persistentvolumeclaim#default#data#pv-1[persistentvolume]",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#persistentvolumeclaim#default#data#unknown[persistentvolume]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateDeleted,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime.Add(time.Minute),
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#persistentvolumeclaim#default#data#pv-1[persistentvolume]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateVolumeBound,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime.Add(time.Minute),
+								Body:       "phase: Bound\nvolumeName: pv-1\n",
+							},
+						},
+					},
+				},
+				{
+					Manifest: `apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data
+  namespace: default
+  labels:
+    foo: bar
+spec:
+  volumeName: pv-1
+status:
+  phase: Bound
+`,
+					Verb: enum.RevisionVerbPatch,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{},
+						},
+					},
+				},
+				{
+					Manifest: `apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data
+  namespace: default
+spec:
+  volumeName: pv-1
+status:
+  phase: Bound
+`,
+					Verb: enum.RevisionVerbDelete,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#persistentvolumeclaim#default#data#pv-1[persistentvolume]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbDelete,
+								State:      enum.RevisionStateDeleted,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime.Add(3 * time.Minute),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testk8saudit.RunSteps(t, tc.steps, "user@example.com", model.KubernetesObjectOperation{Namespace: "default", Name: "data"}, recordPersistentVolumeClaim)
+		})
+	}
+}
+
+func TestRecordPersistentVolume(t *testing.T) {
+	testCases := []struct {
+		name  string
+		steps []testk8saudit.Step
+	}{
+		{
+			name: "volume bound to a claim and released",
+			steps: []testk8saudit.Step{
+				{
+					Manifest: `apiVersion: v1
+kind: PersistentVolume
+metadata:
+  name: pv-1
+spec:
+  persistentVolumeReclaimPolicy: Retain
+status:
+  phase: Available
+`,
+					Verb: enum.RevisionVerbCreate,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#persistentvolume#cluster-scope#pv-1#unknown(unknown)[persistentvolumeclaim]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbCreate,
+								State:      enum.RevisionStateVolumeAvailable,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime,
+								Body:       "claimRef: \"\"\nmessage: \"\"\nphase: Available\nreclaimPolicy: Retain\n",
+							},
+						},
+					},
+				},
+				{
+					Manifest: `apiVersion: v1
+kind: PersistentVolume
+metadata:
+  name: pv-1
+spec:
+  persistentVolumeReclaimPolicy: Retain
+  claimRef:
+    namespace: default
+    name: data
+status:
+  phase: Bound
+`,
+					Verb: enum.RevisionVerbUpdate,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								"core/v1#persistentvolume#cluster-scope#pv-1#unknown(unknown)[persistentvolumeclaim]",
+								"core/v1#persistentvolume#cluster-scope#pv-1#data(default)[persistentvolumeclaim]",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#persistentvolume#cluster-scope#pv-1#data(default)[persistentvolumeclaim]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateVolumeBound,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime.Add(time.Minute),
+								Body:       "claimRef: default/data\nmessage: \"\"\nphase: Bound\nreclaimPolicy: Retain\n",
+							},
+						},
+					},
+				},
+				{
+					Manifest: `apiVersion: v1
+kind: PersistentVolume
+metadata:
+  name: pv-1
+spec:
+  persistentVolumeReclaimPolicy: Retain
+  claimRef:
+    namespace: default
+    name: data
+status:
+  phase: Released
+`,
+					Verb: enum.RevisionVerbUpdate,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								"core/v1#persistentvolume#cluster-scope#pv-1#data(default)[persistentvolumeclaim]",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#persistentvolume#cluster-scope#pv-1#data(default)[persistentvolumeclaim]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateVolumeReleased,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime.Add(2 * time.Minute),
+								Body:       "claimRef: default/data\nmessage: \"\"\nphase: Released\nreclaimPolicy: Retain\n",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testk8saudit.RunSteps(t, tc.steps, "user@example.com", model.KubernetesObjectOperation{Name: "pv-1"}, recordPersistentVolume)
+		})
+	}
+}
+
+func TestRecordPodVolumes(t *testing.T) {
+	podManifest := `apiVersion: v1
+kind: Pod
+metadata:
+  name: web-0
+  namespace: default
+spec:
+  volumes:
+  - name: data
+    persistentVolumeClaim:
+      claimName: data-web-0
+  - name: scratch
+    ephemeral:
+      volumeClaimTemplate:
+        spec:
+          accessModes: ["ReadWriteOnce"]
+  - name: config
+    configMap:
+      name: web-config
+`
+	testCases := []struct {
+		name  string
+		steps []testk8saudit.Step
+	}{
+		{
+			name: "pod using a persistent volume claim and an ephemeral volume",
+			steps: []testk8saudit.Step{
+				{
+					Manifest: podManifest,
+					Verb:     enum.RevisionVerbCreate,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								"core/v1#persistentvolumeclaim#default#data-web-0#web-0[pod]",
+								"core/v1#persistentvolumeclaim#default#web-0-scratch#web-0[pod]",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#persistentvolumeclaim#default#data-web-0#web-0[pod]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbCreate,
+								State:      enum.RevisionStateExisting,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime,
+								Body:       "pod: web-0\nvolume: data\n",
+							},
+						},
+					},
+				},
+				{
+					Manifest: podManifest,
+					Verb:     enum.RevisionVerbPatch,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{},
+						},
+					},
+				},
+				{
+					Manifest: podManifest,
+					Verb:     enum.RevisionVerbDelete,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								"core/v1#persistentvolumeclaim#default#data-web-0#web-0[pod]",
+								"core/v1#persistentvolumeclaim#default#web-0-scratch#web-0[pod]",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#persistentvolumeclaim#default#web-0-scratch#web-0[pod]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbDelete,
+								State:      enum.RevisionStateDeleted,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime.Add(2 * time.Minute),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testk8saudit.RunSteps(t, tc.steps, "user@example.com", model.KubernetesObjectOperation{Namespace: "default", Name: "web-0"}, recordPodVolumes)
+		})
+	}
+}
+
+func TestRecordVolumeAttachment(t *testing.T) {
+	testCases := []struct {
+		name  string
+		steps []testk8saudit.Step
+	}{
+		{
+			name: "attachment failing and then attached",
+			steps: []testk8saudit.Step{
+				{
+					Manifest: `apiVersion: storage.k8s.io/v1
+kind: VolumeAttachment
+metadata:
+  name: csi-1234
+spec:
+  attacher: pd.csi.storage.gke.io
+  nodeName: node-1
+  source:
+    persistentVolumeName: pv-1
+status:
+  attached: false
+  attachError:
+    message: "rpc error: code = DeadlineExceeded"
+`,
+					Verb: enum.RevisionVerbUpdate,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.MatchResourcePathSet{
+							WantResourcePaths: []string{
+								"core/v1#node#cluster-scope#node-1#csi-1234[volumeattachment]",
+								"core/v1#persistentvolume#cluster-scope#pv-1#csi-1234[volumeattachment]",
+							},
+						},
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#node#cluster-scope#node-1#csi-1234[volumeattachment]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbUpdate,
+								State:      enum.RevisionStateVolumeDetached,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime,
+								Body:       "attacher: pd.csi.storage.gke.io\nattached: false\nattachError: 'rpc error: code = DeadlineExceeded'\n",
+							},
+						},
+					},
+				},
+				{
+					Manifest: `apiVersion: storage.k8s.io/v1
+kind: VolumeAttachment
+metadata:
+  name: csi-1234
+spec:
+  attacher: pd.csi.storage.gke.io
+  nodeName: node-1
+  source:
+    persistentVolumeName: pv-1
+status:
+  attached: true
+`,
+					Verb: enum.RevisionVerbPatch,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#persistentvolume#cluster-scope#pv-1#csi-1234[volumeattachment]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbPatch,
+								State:      enum.RevisionStateVolumeAttached,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime.Add(time.Minute),
+								Body:       "attacher: pd.csi.storage.gke.io\nattached: true\n",
+							},
+						},
+					},
+				},
+				{
+					Manifest: `apiVersion: storage.k8s.io/v1
+kind: VolumeAttachment
+metadata:
+  name: csi-1234
+spec:
+  attacher: pd.csi.storage.gke.io
+  nodeName: node-1
+  source:
+    persistentVolumeName: pv-1
+status:
+  attached: true
+`,
+					Verb: enum.RevisionVerbDelete,
+					Asserters: []testchangeset.ChangeSetAsserter{
+						&testchangeset.HasRevision{
+							ResourcePath: "core/v1#node#cluster-scope#node-1#csi-1234[volumeattachment]",
+							WantRevision: history.StagingResourceRevision{
+								Verb:       enum.RevisionVerbDelete,
+								State:      enum.RevisionStateDeleted,
+								Requestor:  "user@example.com",
+								ChangeTime: testk8saudit.BaseTime.Add(2 * time.Minute),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testk8saudit.RunSteps(t, tc.steps, "user@example.com", model.KubernetesObjectOperation{Name: "csi-1234"}, recordVolumeAttachment)
+		})
+	}
+}
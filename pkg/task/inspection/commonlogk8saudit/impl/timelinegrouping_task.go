@@ -246,6 +246,7 @@ func createDeletionRequestsByDeleteColection(groups []*commonlogk8saudit_contrac
 								Response:                               nil,
 								ResponseType:                           commonlogk8saudit_contract.RTypeUnknown,
 								GeneratedFromDeleteCollectionOperation: true,
+								StageTiming:                            l.StageTiming,
 							})
 							requireSortTimelinePaths[childGroup.TimelineResourcePath] = struct{}{}
 						}
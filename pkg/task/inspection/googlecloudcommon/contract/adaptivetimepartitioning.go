@@ -0,0 +1,456 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlecloudcommon_contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud"
+	"github.com/GoogleCloudPlatform/khi/pkg/core/inspection/gcpqueryutil"
+	"golang.org/x/sync/errgroup"
+)
+
+// adaptivePartition is a time range fetched by a query in the adaptive time partitioning.
+type adaptivePartition struct {
+	begin time.Time
+	// end is moved back when the rest of the range is split off to another partition.
+	end time.Time
+	// latestLogTime is the timestamp of the last received log. LogFetcher returns logs in ascending order of the timestamp.
+	latestLogTime time.Time
+	logCount      int
+	startTime     time.Time
+	done          bool
+}
+
+// adaptivePartitioningState holds the partitions shared among the goroutines fetching them.
+type adaptivePartitioningState struct {
+	lock      sync.Mutex
+	beginTime time.Time
+	endTime   time.Time
+	// started is the list of the partitions started fetching.
+	started []*adaptivePartition
+	// queue is the list of the partitions not started yet in ascending order of the time.
+	queue []*adaptivePartition
+}
+
+func newAdaptivePartitioningState(beginTime, endTime time.Time, partitionCount int) *adaptivePartitioningState {
+	times := divideTimeSegments(beginTime, endTime, partitionCount)
+	queue := make([]*adaptivePartition, 0, partitionCount)
+	for i := 0; i < partitionCount; i++ {
+		queue = append(queue, &adaptivePartition{begin: times[i], end: times[i+1]})
+	}
+	return &adaptivePartitioningState{
+		beginTime: beginTime,
+		endTime:   endTime,
+		queue:     queue,
+	}
+}
+
+// skip removes the time range from the partitions waiting to start and records it as a finished partition with the given log count.
+// It's used for the time range read from a checkpoint of the previous run.
+func (s *adaptivePartitioningState) skip(begin, end time.Time, logCount int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	queue := make([]*adaptivePartition, 0, len(s.queue)+1)
+	for _, partition := range s.queue {
+		if !partition.end.After(begin) || !partition.begin.Before(end) {
+			queue = append(queue, partition)
+			continue
+		}
+		if partition.begin.Before(begin) {
+			queue = append(queue, &adaptivePartition{begin: partition.begin, end: begin})
+		}
+		if partition.end.After(end) {
+			queue = append(queue, &adaptivePartition{begin: end, end: partition.end})
+		}
+	}
+	s.queue = queue
+	s.started = append(s.started, &adaptivePartition{
+		begin:         begin,
+		end:           end,
+		latestLogTime: end,
+		logCount:      logCount,
+		done:          true,
+	})
+}
+
+// progress returns the progress computed from the ratio of the time range already fetched.
+func (s *adaptivePartitioningState) progress() LogFetchProgress {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	result := LogFetchProgress{}
+	var fetched time.Duration
+	for _, partition := range s.started {
+		result.LogCount += partition.logCount
+		if partition.done {
+			fetched += partition.end.Sub(partition.begin)
+		} else {
+			fetched += partition.latestLogTime.Sub(partition.begin)
+		}
+	}
+	if total := s.endTime.Sub(s.beginTime); total > 0 {
+		result.Progress = float32(fetched.Seconds() / total.Seconds())
+	}
+	return result
+}
+
+// logDensity returns the count of logs per second observed in the finished partitions.
+// It returns false when no partition is finished yet.
+func (s *adaptivePartitioningState) logDensity() (float64, bool) {
+	logCount := 0
+	var duration time.Duration
+	for _, partition := range s.started {
+		if partition.done {
+			logCount += partition.logCount
+			duration += partition.end.Sub(partition.begin)
+		}
+	}
+	if duration <= 0 {
+		return 0, false
+	}
+	return float64(logCount) / duration.Seconds(), true
+}
+
+// next returns the next partition to start fetching or nil when no partition is left.
+// The following adjacent partitions are merged into it while the estimated log count is within targetLogCount.
+func (s *adaptivePartitioningState) next(targetLogCount int) *adaptivePartition {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.queue) == 0 {
+		return nil
+	}
+	partition := s.queue[0]
+	s.queue = s.queue[1:]
+	if density, found := s.logDensity(); found {
+		for len(s.queue) > 0 && s.queue[0].begin.Equal(partition.end) && density*s.queue[0].end.Sub(partition.begin).Seconds() <= float64(targetLogCount) {
+			partition.end = s.queue[0].end
+			s.queue = s.queue[1:]
+		}
+	}
+	partition.startTime = time.Now()
+	partition.latestLogTime = partition.begin
+	s.started = append(s.started, partition)
+	return partition
+}
+
+// record records a received log in the partition. It returns false when the log is out of the partition because the range was split.
+func (s *adaptivePartitioningState) record(partition *adaptivePartition, logTime time.Time) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !logTime.Before(partition.end) {
+		return false
+	}
+	if logTime.After(partition.latestLogTime) {
+		partition.latestLogTime = logTime
+	}
+	partition.logCount++
+	return true
+}
+
+// finish marks the partition as fetched.
+func (s *adaptivePartitioningState) finish(partition *adaptivePartition) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	partition.done = true
+}
+
+// split splits off the rest of the partition from the splitTime and returns the new partition.
+// It returns nil without splitting when any partition is waiting to start, the partition is not busy enough or acquire returns false.
+func (s *adaptivePartitioningState) split(partition *adaptivePartition, checkInterval time.Duration, minDuration time.Duration, acquire func() bool) *adaptivePartition {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	// Partitions waiting to start have priority over the split partitions.
+	if len(s.queue) > 0 || partition.done {
+		return nil
+	}
+	now := time.Now()
+	fetched := partition.latestLogTime.Sub(partition.begin)
+	remaining := partition.end.Sub(partition.latestLogTime)
+	// A partition is busy when the rest of the range is expected to take longer than the next check.
+	if fetched > 0 {
+		expectedRemainingTime := time.Duration(float64(now.Sub(partition.startTime)) * remaining.Seconds() / fetched.Seconds())
+		if expectedRemainingTime < checkInterval {
+			return nil
+		}
+	}
+	// Time range filters are in seconds precision.
+	splitTime := partition.latestLogTime.Add(remaining / 2).Truncate(time.Second)
+	if splitTime.Sub(partition.latestLogTime) < minDuration || partition.end.Sub(splitTime) < minDuration {
+		return nil
+	}
+	if !acquire() {
+		return nil
+	}
+	splitPartition := &adaptivePartition{
+		begin:         splitTime,
+		end:           partition.end,
+		latestLogTime: splitTime,
+		startTime:     now,
+	}
+	partition.end = splitTime
+	s.started = append(s.started, splitPartition)
+	return splitPartition
+}
+
+// adaptiveFetch is the state of a FetchLogsWithProgress call of TimePartitioningProgressReportableLogFetcher in the adaptive mode.
+type adaptiveFetch struct {
+	fetcher *TimePartitioningProgressReportableLogFetcher
+	// logFetcher is the LogFetcher fetching partitions. It's the LogFetcher underlying the checkpointLogFetcher when checkpoints is set.
+	logFetcher LogFetcher
+	// checkpoints stores the logs of the fetched partitions when a checkpointLogFetcher is given. It's nil otherwise.
+	checkpoints            *timeRangeCheckpoints
+	state                  *adaptivePartitioningState
+	group                  *errgroup.Group
+	localSlots             chan struct{}
+	logChan                chan<- *loggingpb.LogEntry
+	filterWithoutTimeRange string
+	container              googlecloud.ResourceContainer
+	resourceContainers     []string
+}
+
+// fetchLogsAdaptively fetches logs with partitions adjusted on the observed log density.
+// The time range is divided into partitionCount partitions initially and the partitions are started within the LogFetchConcurrencyBudget and maxParallelism.
+// After some partitions are finished, the following partitions are merged when the log density is low enough to fetch them in a query.
+// When no partition is waiting to start, a busy partition is split at the middle of the rest of its range and the latter half is fetched in parallel.
+// When the LogFetcher is a checkpointLogFetcher, the logs are stored for the time ranges actually fetched and the time ranges stored in the previous run are read from the checkpoints instead of fetching them again.
+func (t *TimePartitioningProgressReportableLogFetcher) fetchLogsAdaptively(logChan chan<- *loggingpb.LogEntry, progressChan chan<- LogFetchProgress, ctx context.Context, beginTime time.Time, endTime time.Time, filterWithoutTimeRange string, container googlecloud.ResourceContainer, resourceContainers []string) error {
+	defer close(logChan)
+	defer close(progressChan)
+
+	select {
+	case progressChan <- LogFetchProgress{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	state := newAdaptivePartitioningState(beginTime, endTime, max(t.partitionCount, 1))
+	logFetcher := t.fetcher
+	var checkpoints *timeRangeCheckpoints
+	// The partitions change from run to run, so the checkpoints keyed on the filter including the time range of a partition are not reusable.
+	if checkpointFetcher, ok := t.fetcher.(*checkpointLogFetcher); ok {
+		logFetcher = checkpointFetcher.fetcher
+		checkpoints = checkpointFetcher.timeRangeCheckpoints(filterWithoutTimeRange, container, resourceContainers)
+		if err := readTimeRangeCheckpoints(ctx, logChan, state, checkpoints, beginTime, endTime); err != nil {
+			return err
+		}
+	}
+	cancellableCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	reporterDone := make(chan struct{})
+	go func() {
+		defer close(reporterDone)
+		ticker := time.NewTicker(t.reportInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-cancellableCtx.Done():
+				return
+			case <-ticker.C:
+				select {
+				case progressChan <- state.progress():
+				case <-cancellableCtx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	group, groupCtx := errgroup.WithContext(cancellableCtx)
+	fetch := &adaptiveFetch{
+		fetcher:                t,
+		logFetcher:             logFetcher,
+		checkpoints:            checkpoints,
+		state:                  state,
+		group:                  group,
+		localSlots:             make(chan struct{}, max(t.maxParallelism, 1)),
+		logChan:                logChan,
+		filterWithoutTimeRange: filterWithoutTimeRange,
+		container:              container,
+		resourceContainers:     resourceContainers,
+	}
+	for {
+		select {
+		case fetch.localSlots <- struct{}{}:
+		case <-groupCtx.Done():
+		}
+		if groupCtx.Err() != nil {
+			break
+		}
+		if err := t.budget.Acquire(groupCtx); err != nil {
+			<-fetch.localSlots
+			break
+		}
+		// Get the next partition after acquiring the budget to merge partitions with the latest log density.
+		partition := state.next(t.targetLogsPerPartition)
+		if partition == nil {
+			fetch.release()
+			break
+		}
+		fetch.start(groupCtx, partition)
+	}
+
+	err := group.Wait()
+	cancel()
+	<-reporterDone
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case progressChan <- LogFetchProgress{LogCount: state.progress().LogCount, Progress: 1}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// readTimeRangeCheckpoints sends the logs of the time ranges stored in the checkpoints to logChan and removes the time ranges from the partitions to fetch.
+func readTimeRangeCheckpoints(ctx context.Context, logChan chan<- *loggingpb.LogEntry, state *adaptivePartitioningState, checkpoints *timeRangeCheckpoints, beginTime, endTime time.Time) error {
+	timeRanges, err := checkpoints.list(beginTime, endTime)
+	if err != nil {
+		return err
+	}
+	totalCount := 0
+	for _, timeRange := range timeRanges {
+		count, err := checkpoints.read(logChan, ctx, timeRange)
+		if err != nil {
+			return err
+		}
+		state.skip(timeRange.begin, timeRange.end, count)
+		totalCount += count
+	}
+	if len(timeRanges) > 0 {
+		slog.InfoContext(ctx, fmt.Sprintf("%d logs in %d time ranges were read from the checkpoint of the previous run", totalCount, len(timeRanges)))
+	}
+	return nil
+}
+
+// start starts fetching the partition. The caller must acquire both of the local slot and the budget.
+func (a *adaptiveFetch) start(ctx context.Context, partition *adaptivePartition) {
+	a.group.Go(func() error {
+		defer a.release()
+		return a.fetchPartition(ctx, partition)
+	})
+}
+
+// tryAcquire acquires a local slot and the budget without blocking.
+func (a *adaptiveFetch) tryAcquire() bool {
+	select {
+	case a.localSlots <- struct{}{}:
+	default:
+		return false
+	}
+	if !a.fetcher.budget.TryAcquire() {
+		<-a.localSlots
+		return false
+	}
+	return true
+}
+
+// release releases a local slot and the budget.
+func (a *adaptiveFetch) release() {
+	a.fetcher.budget.Release()
+	<-a.localSlots
+}
+
+// fetchPartition fetches logs in the partition. The query is stopped when it reaches the range split off to another partition.
+// The logs are stored as the checkpoint of the range from the beginning to the end of the partition after the split when checkpoints is set.
+func (a *adaptiveFetch) fetchPartition(ctx context.Context, partition *adaptivePartition) error {
+	partitionCtx, cancelPartition := context.WithCancel(ctx)
+	defer cancelPartition()
+
+	var checkpointWriter *timeRangeCheckpointWriter
+	if a.checkpoints != nil {
+		writer, err := a.checkpoints.newWriter()
+		if err != nil {
+			// Failing to write the checkpoint only makes the next run to fetch the logs again.
+			slog.WarnContext(ctx, "failed to write the checkpoint of fetched logs", "error", err)
+		} else {
+			checkpointWriter = writer
+			defer checkpointWriter.discard()
+		}
+	}
+
+	// partition.end is only modified in this goroutine.
+	filter := fmt.Sprintf("%s\n%s", a.filterWithoutTimeRange, gcpqueryutil.TimeRangeQuerySection(partition.begin, partition.end, false))
+	entries := make(chan *loggingpb.LogEntry)
+	fetchErr := make(chan error, 1)
+	go func() {
+		fetchErr <- a.logFetcher.FetchLogs(entries, partitionCtx, filter, a.container, a.resourceContainers)
+	}()
+
+	splitTicker := time.NewTicker(a.fetcher.splitCheckInterval)
+	defer splitTicker.Stop()
+	reachedSplitTime := false
+	var err error
+	// All logs sent to entries are received before FetchLogs returns because entries is unbuffered.
+	for fetching := true; fetching; {
+		select {
+		case err = <-fetchErr:
+			fetching = false
+		case entry, ok := <-entries:
+			if !ok {
+				entries = nil
+				continue
+			}
+			// Consume the rest of logs until the fetcher returns.
+			if reachedSplitTime || ctx.Err() != nil {
+				continue
+			}
+			if !a.state.record(partition, entry.GetTimestamp().AsTime()) {
+				// The rest of logs are fetched by the partition split off from this partition.
+				reachedSplitTime = true
+				cancelPartition()
+				continue
+			}
+			if checkpointWriter != nil {
+				checkpointWriter.write(entry)
+			}
+			select {
+			case a.logChan <- entry:
+			case <-ctx.Done():
+			}
+		case <-splitTicker.C:
+			if reachedSplitTime {
+				continue
+			}
+			if splitPartition := a.state.split(partition, a.fetcher.splitCheckInterval, a.fetcher.minPartitionDuration, a.tryAcquire); splitPartition != nil {
+				a.start(ctx, splitPartition)
+			}
+		}
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if err != nil && !(reachedSplitTime && errors.Is(err, context.Canceled)) {
+		return err
+	}
+	if checkpointWriter != nil {
+		if err := checkpointWriter.commit(partition.begin, partition.end); err != nil {
+			slog.WarnContext(ctx, "failed to write the checkpoint of fetched logs", "error", err)
+		}
+	}
+	a.state.finish(partition)
+	return nil
+}
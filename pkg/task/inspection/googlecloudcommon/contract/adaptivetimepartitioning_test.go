@@ -0,0 +1,280 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlecloudcommon_contract
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud"
+	gcp_test "github.com/GoogleCloudPlatform/khi/pkg/testutil/gcp"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// slowLogFetcher is a LogFetcher delaying every log to simulate queries returning many pages.
+// It also records the maximum count of FetchLogs calls running concurrently and the count of logs sent.
+type slowLogFetcher struct {
+	fetcher       LogFetcher
+	delay         time.Duration
+	running       atomic.Int32
+	maxConcurrent atomic.Int32
+	sentCount     atomic.Int32
+}
+
+func (s *slowLogFetcher) FetchLogs(dest chan<- *loggingpb.LogEntry, ctx context.Context, filter string, container googlecloud.ResourceContainer, resourceContainers []string) error {
+	defer close(dest)
+	running := s.running.Add(1)
+	defer s.running.Add(-1)
+	for {
+		current := s.maxConcurrent.Load()
+		if running <= current || s.maxConcurrent.CompareAndSwap(current, running) {
+			break
+		}
+	}
+	entries := make(chan *loggingpb.LogEntry)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- s.fetcher.FetchLogs(entries, ctx, filter, container, resourceContainers)
+	}()
+	for entry := range entries {
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+		}
+		select {
+		case dest <- entry:
+			s.sentCount.Add(1)
+		case <-ctx.Done():
+		}
+	}
+	return <-errChan
+}
+
+var _ LogFetcher = (*slowLogFetcher)(nil)
+
+func newFakeServerLogsAtEveryMinute(t *testing.T, beginTime time.Time, count int) (*gcp_test.FakeLoggingServer, []string) {
+	t.Helper()
+	server := gcp_test.NewFakeLoggingServer(t)
+	var insertIDs []string
+	for i := 0; i < count; i++ {
+		insertID := fmt.Sprintf("log-%03d", i)
+		server.AddEntries(t, &loggingpb.LogEntry{
+			InsertId:  insertID,
+			LogName:   "projects/test-project/logs/events",
+			Timestamp: timestamppb.New(beginTime.Add(time.Duration(i) * time.Minute)),
+		})
+		insertIDs = append(insertIDs, insertID)
+	}
+	return server, insertIDs
+}
+
+func fetchLogsWithProgressForTest(t *testing.T, fetcher ProgressReportableLogFetcher, beginTime, endTime time.Time) ([]string, []LogFetchProgress, error) {
+	t.Helper()
+	wg := sync.WaitGroup{}
+	var logs []*loggingpb.LogEntry
+	var progresses []LogFetchProgress
+	logReceiveChan := channelToArrayParallel(t.Context(), &wg, &logs)
+	progressReceiveChan := channelToArrayParallel(t.Context(), &wg, &progresses)
+	err := fetcher.FetchLogsWithProgress(logReceiveChan, progressReceiveChan, t.Context(), beginTime, endTime, `LOG_ID("events")`, googlecloud.Project("test-project"), []string{"projects/test-project"})
+	wg.Wait()
+	var insertIDs []string
+	for _, l := range logs {
+		insertIDs = append(insertIDs, l.InsertId)
+	}
+	slices.Sort(insertIDs)
+	return insertIDs, progresses, err
+}
+
+func distinctFilterCount(server *gcp_test.FakeLoggingServer) int {
+	filters := map[string]struct{}{}
+	for _, req := range server.Requests() {
+		filters[req.Filter] = struct{}{}
+	}
+	return len(filters)
+}
+
+func TestTimePartitioningProgressReportableLogFetcher_SplitsBusyPartitions(t *testing.T) {
+	beginTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endTime := beginTime.Add(time.Hour)
+	server, wantInsertIDs := newFakeServerLogsAtEveryMinute(t, beginTime, 60)
+	cf, err := googlecloud.NewClientFactory(server.ClientFactoryOption())
+	if err != nil {
+		t.Fatalf("failed to instanciate client factory: %v", err)
+	}
+	slowFetcher := &slowLogFetcher{fetcher: NewLogFetcher(cf, googlecloud.NewCallOptionInjector(), 5), delay: 20 * time.Millisecond}
+	fetcher := NewTimePartitioningProgressReportableLogFetcher(slowFetcher, 10*time.Millisecond, 1, 4, NewLogFetchConcurrencyBudget(4))
+	fetcher.splitCheckInterval = 100 * time.Millisecond
+
+	gotInsertIDs, progresses, err := fetchLogsWithProgressForTest(t, fetcher, beginTime, endTime)
+	if err != nil {
+		t.Fatalf("FetchLogsWithProgress() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(wantInsertIDs, gotInsertIDs); diff != "" {
+		t.Errorf("FetchLogsWithProgress() returned unexpected logs (-want +got):\n%s", diff)
+	}
+	if got := distinctFilterCount(server); got < 2 {
+		t.Errorf("got %d distinct filters, want the busy partition to be split", got)
+	}
+	if got := slowFetcher.maxConcurrent.Load(); got > 4 {
+		t.Errorf("got %d concurrent queries, want at most 4", got)
+	}
+	if diff := cmp.Diff(LogFetchProgress{LogCount: 60, Progress: 1}, progresses[len(progresses)-1]); diff != "" {
+		t.Errorf("FetchLogsWithProgress() must report the completed progress at last (-want +got):\n%s", diff)
+	}
+}
+
+func TestTimePartitioningProgressReportableLogFetcher_MergesSparsePartitions(t *testing.T) {
+	beginTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endTime := beginTime.Add(8 * time.Hour)
+	server, wantInsertIDs := newFakeServerLogsAtEveryMinute(t, beginTime, 3)
+	cf, err := googlecloud.NewClientFactory(server.ClientFactoryOption())
+	if err != nil {
+		t.Fatalf("failed to instanciate client factory: %v", err)
+	}
+	fetcher := NewTimePartitioningProgressReportableLogFetcher(NewLogFetcher(cf, googlecloud.NewCallOptionInjector(), 100), 10*time.Millisecond, 8, 1, NewLogFetchConcurrencyBudget(1))
+
+	gotInsertIDs, _, err := fetchLogsWithProgressForTest(t, fetcher, beginTime, endTime)
+	if err != nil {
+		t.Fatalf("FetchLogsWithProgress() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(wantInsertIDs, gotInsertIDs); diff != "" {
+		t.Errorf("FetchLogsWithProgress() returned unexpected logs (-want +got):\n%s", diff)
+	}
+	// The first partition reveals the logs are sparse, then the rest of 7 partitions are fetched in a query.
+	if got := distinctFilterCount(server); got != 2 {
+		t.Errorf("got %d distinct filters, want 2", got)
+	}
+}
+
+func TestTimePartitioningProgressReportableLogFetcher_SharesBudgetAcrossFetchers(t *testing.T) {
+	beginTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endTime := beginTime.Add(time.Hour)
+	server, wantInsertIDs := newFakeServerLogsAtEveryMinute(t, beginTime, 60)
+	cf, err := googlecloud.NewClientFactory(server.ClientFactoryOption())
+	if err != nil {
+		t.Fatalf("failed to instanciate client factory: %v", err)
+	}
+	slowFetcher := &slowLogFetcher{fetcher: NewLogFetcher(cf, googlecloud.NewCallOptionInjector(), 5), delay: time.Millisecond}
+	budget := NewLogFetchConcurrencyBudget(2)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fetcher := NewTimePartitioningProgressReportableLogFetcher(slowFetcher, 10*time.Millisecond, 4, 4, budget)
+			fetcher.splitCheckInterval = 10 * time.Millisecond
+			gotInsertIDs, _, err := fetchLogsWithProgressForTest(t, fetcher, beginTime, endTime)
+			if err != nil {
+				t.Errorf("FetchLogsWithProgress() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(wantInsertIDs, gotInsertIDs); diff != "" {
+				t.Errorf("FetchLogsWithProgress() returned unexpected logs (-want +got):\n%s", diff)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := slowFetcher.maxConcurrent.Load(); got > 2 {
+		t.Errorf("got %d concurrent queries, want at most 2 within the budget", got)
+	}
+}
+
+func TestTimePartitioningProgressReportableLogFetcher_ResumesAfterSplitFromCheckpoints(t *testing.T) {
+	beginTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endTime := beginTime.Add(time.Hour)
+	server, wantInsertIDs := newFakeServerLogsAtEveryMinute(t, beginTime, 60)
+	cf, err := googlecloud.NewClientFactory(server.ClientFactoryOption())
+	if err != nil {
+		t.Fatalf("failed to instanciate client factory: %v", err)
+	}
+	folder := t.TempDir()
+	newFetcher := func(splitCheckInterval time.Duration) (*TimePartitioningProgressReportableLogFetcher, *slowLogFetcher) {
+		slowFetcher := &slowLogFetcher{fetcher: NewLogFetcher(cf, googlecloud.NewCallOptionInjector(), 5), delay: 20 * time.Millisecond}
+		fetcher := NewTimePartitioningProgressReportableLogFetcher(NewCheckpointLogFetcher(slowFetcher, folder, "test-task"), 10*time.Millisecond, 1, 4, NewLogFetchConcurrencyBudget(4))
+		fetcher.splitCheckInterval = splitCheckInterval
+		return fetcher, slowFetcher
+	}
+
+	firstRun, _ := newFetcher(100 * time.Millisecond)
+	gotInsertIDs, _, err := fetchLogsWithProgressForTest(t, firstRun, beginTime, endTime)
+	if err != nil {
+		t.Fatalf("FetchLogsWithProgress() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(wantInsertIDs, gotInsertIDs); diff != "" {
+		t.Errorf("FetchLogsWithProgress() returned unexpected logs (-want +got):\n%s", diff)
+	}
+	splitCount := distinctFilterCount(server)
+	if splitCount < 2 {
+		t.Fatalf("got %d distinct filters, want the busy partition to be split", splitCount)
+	}
+	checkpoints := (&checkpointLogFetcher{folder: folder, keyPrefix: "test-task"}).timeRangeCheckpoints(`LOG_ID("events")`, googlecloud.Project("test-project"), []string{"projects/test-project"})
+	timeRanges, err := checkpoints.list(beginTime, endTime)
+	if err != nil {
+		t.Fatalf("failed to list the checkpoints: %v", err)
+	}
+	// The partitions canceled at the split time are also stored as the checkpoints of the ranges before the split time.
+	if len(timeRanges) != splitCount {
+		t.Fatalf("got %d checkpoints, want %d for every partition", len(timeRanges), splitCount)
+	}
+	for i, timeRange := range timeRanges {
+		wantBegin := beginTime
+		if i > 0 {
+			wantBegin = timeRanges[i-1].end
+		}
+		if !timeRange.begin.Equal(wantBegin) {
+			t.Errorf("checkpoint %d begins at %v, want %v", i, timeRange.begin, wantBegin)
+		}
+	}
+
+	// Remove the checkpoint of the last partition as if the partition failed in the first run.
+	lastRange := timeRanges[len(timeRanges)-1]
+	if err := os.Remove(lastRange.path); err != nil {
+		t.Fatalf("failed to remove the checkpoint: %v", err)
+	}
+	wantFetchedCount := 0
+	for i := 0; i < 60; i++ {
+		if !beginTime.Add(time.Duration(i) * time.Minute).Before(lastRange.begin) {
+			wantFetchedCount++
+		}
+	}
+	requestCountBeforeResume := len(server.Requests())
+
+	// The resumed run doesn't split partitions not to fetch logs after the split time of the canceled partitions.
+	resumedRun, slowFetcher := newFetcher(time.Hour)
+	gotInsertIDs, progresses, err := fetchLogsWithProgressForTest(t, resumedRun, beginTime, endTime)
+	if err != nil {
+		t.Fatalf("FetchLogsWithProgress() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(wantInsertIDs, gotInsertIDs); diff != "" {
+		t.Errorf("FetchLogsWithProgress() returned unexpected logs (-want +got):\n%s", diff)
+	}
+	if got := int(slowFetcher.sentCount.Load()); got != wantFetchedCount {
+		t.Errorf("got %d logs fetched in the resumed run, want %d logs only in the range of the removed checkpoint", got, wantFetchedCount)
+	}
+	if len(server.Requests()) == requestCountBeforeResume {
+		t.Errorf("the resumed run sent no request, want requests for the range of the removed checkpoint")
+	}
+	if diff := cmp.Diff(LogFetchProgress{LogCount: 60, Progress: 1}, progresses[len(progresses)-1]); diff != "" {
+		t.Errorf("FetchLogsWithProgress() must report the completed progress at last (-want +got):\n%s", diff)
+	}
+}
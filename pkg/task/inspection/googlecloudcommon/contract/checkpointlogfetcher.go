@@ -0,0 +1,321 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlecloudcommon_contract
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+// checkpointFileExtension is the extension of the files storing LogEntries fetched by a FetchLogs call.
+const checkpointFileExtension = ".logentries"
+
+// checkpointLogFetcher is a LogFetcher decorator storing the LogEntries fetched by each FetchLogs call in a checkpoint file,
+// and reading them from the file instead of fetching them again for the same call. It's used to resume a failed inspection without fetching logs already fetched.
+// A FetchLogs call is identified by the filter including the time range of a partition, the container and the resource names.
+// TimePartitioningProgressReportableLogFetcher stores the checkpoints keyed on the time ranges actually fetched instead when the partitions are adjusted adaptively. See timeRangeCheckpoints.
+type checkpointLogFetcher struct {
+	fetcher LogFetcher
+	folder  string
+	// keyPrefix distinguishes the calls with the same parameters from different tasks.
+	keyPrefix string
+}
+
+// NewCheckpointLogFetcher returns a LogFetcher storing the checkpoints of the given LogFetcher in the folder.
+// A checkpoint is stored only when the FetchLogs call completes without errors.
+func NewCheckpointLogFetcher(fetcher LogFetcher, folder string, keyPrefix string) LogFetcher {
+	return &checkpointLogFetcher{
+		fetcher:   fetcher,
+		folder:    folder,
+		keyPrefix: keyPrefix,
+	}
+}
+
+// FetchLogs implements LogFetcher.
+func (c *checkpointLogFetcher) FetchLogs(dest chan<- *loggingpb.LogEntry, ctx context.Context, filter string, container googlecloud.ResourceContainer, resourceContainers []string) error {
+	checkpointPath := filepath.Join(c.folder, c.checkpointKey(filter, container, resourceContainers)+checkpointFileExtension)
+	file, err := os.Open(checkpointPath)
+	if err == nil {
+		defer file.Close()
+		return c.readCheckpoint(dest, ctx, file, checkpointPath)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to open the checkpoint %s: %w", checkpointPath, err)
+	}
+	return c.fetchAndWriteCheckpoint(dest, ctx, filter, container, resourceContainers, checkpointPath)
+}
+
+// checkpointKey returns the file name safe key of a FetchLogs call.
+func (c *checkpointLogFetcher) checkpointKey(filter string, container googlecloud.ResourceContainer, resourceContainers []string) string {
+	hash := sha256.New()
+	for _, part := range []string{c.keyPrefix, filter, container.Identifier(), strings.Join(resourceContainers, ",")} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// readCheckpoint sends the LogEntries stored in the checkpoint to dest.
+func (c *checkpointLogFetcher) readCheckpoint(dest chan<- *loggingpb.LogEntry, ctx context.Context, reader io.Reader, checkpointPath string) error {
+	defer close(dest)
+	count, err := readCheckpointEntries(dest, ctx, reader, checkpointPath)
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, fmt.Sprintf("%d logs were read from the checkpoint of the previous run", count))
+	return nil
+}
+
+// readCheckpointEntries sends the LogEntries stored in the checkpoint to dest and returns the count of them.
+// The broken checkpoint is removed to fetch the logs again in the next run.
+func readCheckpointEntries(dest chan<- *loggingpb.LogEntry, ctx context.Context, reader io.Reader, checkpointPath string) (int, error) {
+	bufferedReader := bufio.NewReader(reader)
+	count := 0
+	for {
+		entry := &loggingpb.LogEntry{}
+		err := protodelim.UnmarshalOptions{MaxSize: -1}.UnmarshalFrom(bufferedReader, entry)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			os.Remove(checkpointPath)
+			return count, fmt.Errorf("failed to read the checkpoint %s: %w", checkpointPath, err)
+		}
+		select {
+		case dest <- entry:
+		case <-ctx.Done():
+			return count, ctx.Err()
+		}
+		count++
+	}
+	return count, nil
+}
+
+// fetchAndWriteCheckpoint fetches LogEntries with the underlying LogFetcher and writes them to the checkpoint while sending them to dest.
+// The checkpoint is written in a temporary file first and renamed after the fetch completes not to leave incomplete checkpoints.
+func (c *checkpointLogFetcher) fetchAndWriteCheckpoint(dest chan<- *loggingpb.LogEntry, ctx context.Context, filter string, container googlecloud.ResourceContainer, resourceContainers []string, checkpointPath string) error {
+	if err := os.MkdirAll(c.folder, 0755); err != nil {
+		close(dest)
+		return fmt.Errorf("failed to create the checkpoint folder %s: %w", c.folder, err)
+	}
+	file, err := os.CreateTemp(c.folder, "partial-*")
+	if err != nil {
+		close(dest)
+		return fmt.Errorf("failed to create a checkpoint file in %s: %w", c.folder, err)
+	}
+	defer os.Remove(file.Name()) // no-op after renaming the file.
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+
+	fetcherDest := make(chan *loggingpb.LogEntry)
+	fetchErr := make(chan error, 1)
+	go func() {
+		fetchErr <- c.fetcher.FetchLogs(fetcherDest, ctx, filter, container, resourceContainers)
+	}()
+
+	var writeErr error
+	func() {
+		defer close(dest)
+		for entry := range fetcherDest {
+			if writeErr == nil {
+				_, writeErr = protodelim.MarshalTo(writer, entry)
+			}
+			select {
+			case dest <- entry:
+			case <-ctx.Done():
+				// Drain the remaining entries to let the fetcher finish.
+				for range fetcherDest {
+				}
+				return
+			}
+		}
+	}()
+	if err := <-fetchErr; err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if writeErr == nil {
+		writeErr = writer.Flush()
+	}
+	if writeErr == nil {
+		writeErr = file.Close()
+	}
+	if writeErr == nil {
+		writeErr = os.Rename(file.Name(), checkpointPath)
+	}
+	if writeErr != nil {
+		// Logs were fetched successfully. Failing to write the checkpoint only makes the next run to fetch them again.
+		slog.WarnContext(ctx, "failed to write the checkpoint of fetched logs", "error", writeErr)
+	}
+	return nil
+}
+
+var _ LogFetcher = (*checkpointLogFetcher)(nil)
+
+// timeRangeCheckpoints stores the LogEntries of the time ranges fetched for a filter without the time range.
+// TimePartitioningProgressReportableLogFetcher uses it in the adaptive mode instead of the checkpoints keyed on the filter,
+// because its partitions are split and merged differently from run to run.
+type timeRangeCheckpoints struct {
+	folder string
+	key    string
+}
+
+// checkpointedTimeRange is a time range stored in a checkpoint.
+type checkpointedTimeRange struct {
+	begin time.Time
+	end   time.Time
+	path  string
+}
+
+// timeRangeCheckpoints returns the checkpoints of the time ranges fetched with the filter without the time range.
+func (c *checkpointLogFetcher) timeRangeCheckpoints(filterWithoutTimeRange string, container googlecloud.ResourceContainer, resourceContainers []string) *timeRangeCheckpoints {
+	return &timeRangeCheckpoints{
+		folder: c.folder,
+		key:    c.checkpointKey(filterWithoutTimeRange, container, resourceContainers),
+	}
+}
+
+// path returns the path of the checkpoint storing the time range.
+func (t *timeRangeCheckpoints) path(begin, end time.Time) string {
+	return filepath.Join(t.folder, fmt.Sprintf("%s-%d-%d%s", t.key, begin.UnixNano(), end.UnixNano(), checkpointFileExtension))
+}
+
+// list returns the checkpointed time ranges within the given time range in ascending order of the time.
+// A time range overlapping with the previous one is ignored.
+func (t *timeRangeCheckpoints) list(beginTime, endTime time.Time) ([]checkpointedTimeRange, error) {
+	files, err := os.ReadDir(t.folder)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the checkpoint folder %s: %w", t.folder, err)
+	}
+	var ranges []checkpointedTimeRange
+	for _, file := range files {
+		timeRange, found := strings.CutPrefix(file.Name(), t.key+"-")
+		if !found {
+			continue
+		}
+		timeRange, found = strings.CutSuffix(timeRange, checkpointFileExtension)
+		if !found {
+			continue
+		}
+		beginText, endText, found := strings.Cut(timeRange, "-")
+		if !found {
+			continue
+		}
+		beginNano, beginErr := strconv.ParseInt(beginText, 10, 64)
+		endNano, endErr := strconv.ParseInt(endText, 10, 64)
+		if beginErr != nil || endErr != nil {
+			continue
+		}
+		begin := time.Unix(0, beginNano).UTC()
+		end := time.Unix(0, endNano).UTC()
+		if begin.Before(beginTime) || end.After(endTime) || !begin.Before(end) {
+			continue
+		}
+		ranges = append(ranges, checkpointedTimeRange{begin: begin, end: end, path: filepath.Join(t.folder, file.Name())})
+	}
+	slices.SortFunc(ranges, func(a, b checkpointedTimeRange) int {
+		return a.begin.Compare(b.begin)
+	})
+	var result []checkpointedTimeRange
+	for _, timeRange := range ranges {
+		if len(result) > 0 && timeRange.begin.Before(result[len(result)-1].end) {
+			continue
+		}
+		result = append(result, timeRange)
+	}
+	return result, nil
+}
+
+// read sends the LogEntries stored in the checkpoint of the time range to dest and returns the count of them.
+func (t *timeRangeCheckpoints) read(dest chan<- *loggingpb.LogEntry, ctx context.Context, timeRange checkpointedTimeRange) (int, error) {
+	file, err := os.Open(timeRange.path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open the checkpoint %s: %w", timeRange.path, err)
+	}
+	defer file.Close()
+	return readCheckpointEntries(dest, ctx, file, timeRange.path)
+}
+
+// newWriter returns a timeRangeCheckpointWriter writing the LogEntries of a time range being fetched.
+func (t *timeRangeCheckpoints) newWriter() (*timeRangeCheckpointWriter, error) {
+	if err := os.MkdirAll(t.folder, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the checkpoint folder %s: %w", t.folder, err)
+	}
+	file, err := os.CreateTemp(t.folder, "partial-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a checkpoint file in %s: %w", t.folder, err)
+	}
+	return &timeRangeCheckpointWriter{
+		checkpoints: t,
+		file:        file,
+		writer:      bufio.NewWriter(file),
+	}, nil
+}
+
+// timeRangeCheckpointWriter writes LogEntries in a temporary file and stores them as the checkpoint of the time range after the fetch completes.
+type timeRangeCheckpointWriter struct {
+	checkpoints *timeRangeCheckpoints
+	file        *os.File
+	writer      *bufio.Writer
+	err         error
+}
+
+// write writes the LogEntry. The error is returned from commit.
+func (w *timeRangeCheckpointWriter) write(entry *loggingpb.LogEntry) {
+	if w.err == nil {
+		_, w.err = protodelim.MarshalTo(w.writer, entry)
+	}
+}
+
+// commit stores the written LogEntries as the checkpoint of the time range.
+func (w *timeRangeCheckpointWriter) commit(begin, end time.Time) error {
+	if w.err == nil {
+		w.err = w.writer.Flush()
+	}
+	if w.err == nil {
+		w.err = w.file.Close()
+	}
+	if w.err == nil {
+		w.err = os.Rename(w.file.Name(), w.checkpoints.path(begin, end))
+	}
+	return w.err
+}
+
+// discard removes the temporary file. It's no-op after commit succeeded.
+func (w *timeRangeCheckpointWriter) discard() {
+	w.file.Close()
+	os.Remove(w.file.Name())
+}
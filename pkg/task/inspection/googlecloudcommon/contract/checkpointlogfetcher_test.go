@@ -0,0 +1,124 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlecloudcommon_contract
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud"
+	gcp_test "github.com/GoogleCloudPlatform/khi/pkg/testutil/gcp"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func fetchInsertIDs(t *testing.T, fetcher LogFetcher, filter string) ([]string, error) {
+	t.Helper()
+	destChan := make(chan *loggingpb.LogEntry)
+	gotInsertIDs := []string{}
+	receiveDone := make(chan struct{})
+	go func() {
+		defer close(receiveDone)
+		for entry := range destChan {
+			gotInsertIDs = append(gotInsertIDs, entry.InsertId)
+		}
+	}()
+	err := fetcher.FetchLogs(destChan, t.Context(), filter, googlecloud.Project("test-project"), []string{"projects/test-project"})
+	<-receiveDone
+	return gotInsertIDs, err
+}
+
+func TestCheckpointLogFetcher_ResumeFetchFromCheckpoints(t *testing.T) {
+	baseTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	server := gcp_test.NewFakeLoggingServer(t,
+		&loggingpb.LogEntry{InsertId: "a", LogName: "projects/test-project/logs/foo", Timestamp: timestamppb.New(baseTime)},
+		&loggingpb.LogEntry{InsertId: "b", LogName: "projects/test-project/logs/foo", Timestamp: timestamppb.New(baseTime.Add(time.Second))},
+		&loggingpb.LogEntry{InsertId: "c", LogName: "projects/test-project/logs/bar", Timestamp: timestamppb.New(baseTime.Add(2 * time.Second))},
+	)
+	cf, err := googlecloud.NewClientFactory(server.ClientFactoryOption())
+	if err != nil {
+		t.Fatalf("failed to instanciate client factory: %v", err)
+	}
+	folder := t.TempDir()
+	newFetcher := func() LogFetcher {
+		return NewCheckpointLogFetcher(NewLogFetcher(cf, googlecloud.NewCallOptionInjector(), 2), folder, "test-task")
+	}
+
+	// The first run fetches logs of foo successfully but fails to fetch logs of bar.
+	firstRun := newFetcher()
+	gotFoo, err := fetchInsertIDs(t, firstRun, `LOG_ID("foo")`)
+	if err != nil {
+		t.Fatalf("failed to fetch logs: %v", err)
+	}
+	if diff := cmp.Diff([]string{"a", "b"}, gotFoo); diff != "" {
+		t.Errorf("FetchLogs returned unexpected logs (-want +got):\n%s", diff)
+	}
+	server.InjectError(codes.InvalidArgument, 1)
+	if _, err := fetchInsertIDs(t, firstRun, `LOG_ID("bar")`); err == nil {
+		t.Fatalf("FetchLogs returned no error, want an error from the injected failure")
+	}
+	files, err := os.ReadDir(folder)
+	if err != nil {
+		t.Fatalf("failed to list the checkpoint folder: %v", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("got %d files in the checkpoint folder, want only the checkpoint of the successful fetch", len(files))
+	}
+
+	// The resumed run reads logs of foo from the checkpoint and fetches only logs of bar.
+	requestCountBeforeResume := len(server.Requests())
+	resumedRun := newFetcher()
+	gotFoo, err = fetchInsertIDs(t, resumedRun, `LOG_ID("foo")`)
+	if err != nil {
+		t.Fatalf("failed to read logs from the checkpoint: %v", err)
+	}
+	if diff := cmp.Diff([]string{"a", "b"}, gotFoo); diff != "" {
+		t.Errorf("FetchLogs returned unexpected logs from the checkpoint (-want +got):\n%s", diff)
+	}
+	gotBar, err := fetchInsertIDs(t, resumedRun, `LOG_ID("bar")`)
+	if err != nil {
+		t.Fatalf("failed to fetch logs: %v", err)
+	}
+	if diff := cmp.Diff([]string{"c"}, gotBar); diff != "" {
+		t.Errorf("FetchLogs returned unexpected logs (-want +got):\n%s", diff)
+	}
+	if got := len(server.Requests()) - requestCountBeforeResume; got != 1 {
+		t.Errorf("got %d requests in the resumed run, want 1", got)
+	}
+}
+
+func TestCheckpointLogFetcher_SeparatesCheckpointsByKeyPrefix(t *testing.T) {
+	server := gcp_test.NewFakeLoggingServer(t,
+		&loggingpb.LogEntry{InsertId: "a", LogName: "projects/test-project/logs/foo", Timestamp: timestamppb.Now()},
+	)
+	cf, err := googlecloud.NewClientFactory(server.ClientFactoryOption())
+	if err != nil {
+		t.Fatalf("failed to instanciate client factory: %v", err)
+	}
+	folder := t.TempDir()
+	baseFetcher := NewLogFetcher(cf, googlecloud.NewCallOptionInjector(), 2)
+
+	for _, keyPrefix := range []string{"task-a", "task-b", "task-a"} {
+		if _, err := fetchInsertIDs(t, NewCheckpointLogFetcher(baseFetcher, folder, keyPrefix), `LOG_ID("foo")`); err != nil {
+			t.Fatalf("failed to fetch logs: %v", err)
+		}
+	}
+	if got := len(server.Requests()); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+}
@@ -26,3 +26,8 @@ var APIClientFactoryOptionsContextKey = typedmap.NewTypedKey[*[]googlecloud.Clie
 // APICallOptionsInjectorContextKey is the key to retrieve the list of googlecloud.CallOptionInjectorOption from task context.
 // The value is injected on the task server during the initialization.
 var APICallOptionsInjectorContextKey = typedmap.NewTypedKey[*[]googlecloud.CallOptionInjectorOption]("api-call-option-injector-options")
+
+// FixedLogFetchTimePartitionsContextKey is the key to retrieve whether logs are fetched with the fixed time partitions instead of the partitions adapted to the log density.
+// The value is injected on the task server during the initialization when the responses of Google Cloud APIs are recorded or replayed,
+// because the adaptive partitions depend on the timing of responses and the replayed requests must be the same as the recorded ones.
+var FixedLogFetchTimePartitionsContextKey = typedmap.NewTypedKey[bool]("fixed-log-fetch-time-partitions")
@@ -0,0 +1,143 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlecloudcommon_contract
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud/logconvert"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud/logfilter"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
+)
+
+// exportedLogEntry is a LogEntry read from the exported files with its JSON representation used for evaluating filters.
+type exportedLogEntry struct {
+	entry  *loggingpb.LogEntry
+	fields map[string]any
+}
+
+// exportedLogFetcher is the implementation of LogFetcher reading LogEntries exported from Cloud Logging in JSON instead of calling the Cloud Logging API.
+// Filters are evaluated against the read LogEntries in the same way as Cloud Logging. Resource names are ignored because the exported files don't tell which resource container stored the logs.
+type exportedLogFetcher struct {
+	openReader func() (io.ReadCloser, error)
+	loadLock   sync.Mutex
+	loaded     bool
+	entries    []*exportedLogEntry
+	loadErr    error
+}
+
+// NewExportedLogFetcher returns the instance of LogFetcher reading LogEntries from the uploaded files.
+// The files are read only once and the read LogEntries are reused in the later calls of FetchLogs.
+func NewExportedLogFetcher(uploadResult upload.UploadResult) LogFetcher {
+	return &exportedLogFetcher{
+		openReader: uploadResult.GetReader,
+	}
+}
+
+// FetchLogs implements LogFetcher.
+func (e *exportedLogFetcher) FetchLogs(dest chan<- *loggingpb.LogEntry, ctx context.Context, filter string, container googlecloud.ResourceContainer, resourceContainers []string) error {
+	defer close(dest)
+	entries, err := e.loadEntries(ctx)
+	if err != nil {
+		return err
+	}
+	parsedFilter, err := logfilter.Parse(filter)
+	if err != nil {
+		return fmt.Errorf("failed to parse the filter to read the exported logs: %w", err)
+	}
+	for _, entry := range entries {
+		if !parsedFilter.Match(entry.fields) {
+			continue
+		}
+		select {
+		case dest <- entry.entry:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// loadEntries returns the LogEntries read from the uploaded files, reading them on the first call.
+// The error is kept for the later calls unless it came from the context of the caller, because the other callers can still read the files with their contexts.
+func (e *exportedLogFetcher) loadEntries(ctx context.Context) ([]*exportedLogEntry, error) {
+	e.loadLock.Lock()
+	defer e.loadLock.Unlock()
+	if e.loaded {
+		return e.entries, e.loadErr
+	}
+	entries, err := e.load(ctx)
+	if err != nil && ctx.Err() != nil {
+		return nil, err
+	}
+	e.loaded = true
+	e.entries, e.loadErr = entries, err
+	return e.entries, e.loadErr
+}
+
+// load reads all LogEntries from the uploaded files and returns them sorted by timestamp in the same order as the Cloud Logging API returns.
+// LogEntries not convertible to the LogEntry protobuf message (e.g. the protoPayload in an unknown type) are skipped.
+func (e *exportedLogFetcher) load(ctx context.Context) ([]*exportedLogEntry, error) {
+	reader, err := e.openReader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	result := []*exportedLogEntry{}
+	skippedEntryCount := 0
+	err = upload.WalkUploadedFiles(reader, func(name string, fileReader io.Reader) error {
+		err := logconvert.SplitLogEntriesJSON(fileReader, func(entryJSON []byte) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			entry, err := logconvert.LogEntryFromJSON(entryJSON)
+			if err != nil {
+				skippedEntryCount++
+				return nil
+			}
+			fields, err := logfilter.FieldsFromLogEntry(entry)
+			if err != nil {
+				skippedEntryCount++
+				return nil
+			}
+			result = append(result, &exportedLogEntry{entry: entry, fields: fields})
+			return nil
+		})
+		if err != nil && name != "" {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if skippedEntryCount > 0 {
+		slog.WarnContext(ctx, fmt.Sprintf("%d log entries in the exported files were skipped because they were not convertible to LogEntry", skippedEntryCount))
+	}
+	slices.SortStableFunc(result, func(a, b *exportedLogEntry) int {
+		return a.entry.GetTimestamp().AsTime().Compare(b.entry.GetTimestamp().AsTime())
+	})
+	return result, nil
+}
+
+var _ LogFetcher = (*exportedLogFetcher)(nil)
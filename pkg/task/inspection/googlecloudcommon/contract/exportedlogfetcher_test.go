@@ -0,0 +1,162 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlecloudcommon_contract
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExportedLogFetcher_FetchLogs(t *testing.T) {
+	exported := `[
+  {
+    "insertId": "container-2",
+    "logName": "projects/my-project/logs/stdout",
+    "resource": {"type": "k8s_container", "labels": {"cluster_name": "my-cluster", "namespace_name": "kube-system", "pod_name": "coredns"}},
+    "textPayload": "ready",
+    "timestamp": "2025-01-01T00:00:20Z"
+  },
+  {
+    "insertId": "audit-1",
+    "logName": "projects/my-project/logs/cloudaudit.googleapis.com%2Factivity",
+    "protoPayload": {"@type": "type.googleapis.com/google.cloud.audit.AuditLog", "methodName": "io.k8s.core.v1.pods.create"},
+    "resource": {"type": "k8s_cluster", "labels": {"cluster_name": "my-cluster"}},
+    "timestamp": "2025-01-01T00:00:10Z"
+  },
+  {
+    "insertId": "container-1",
+    "logName": "projects/my-project/logs/stdout",
+    "resource": {"type": "k8s_container", "labels": {"cluster_name": "my-cluster", "namespace_name": "default", "pod_name": "nginx"}},
+    "textPayload": "started",
+    "timestamp": "2025-01-01T00:00:05Z"
+  },
+  {
+    "insertId": "unknown-payload",
+    "logName": "projects/my-project/logs/unknown",
+    "protoPayload": {"@type": "type.googleapis.com/unknown.Type"},
+    "timestamp": "2025-01-01T00:00:00Z"
+  }
+]`
+	testCases := []struct {
+		name   string
+		filter string
+		want   []string
+	}{
+		{
+			name:   "all logs sorted by timestamp",
+			filter: "",
+			want:   []string{"container-1", "audit-1", "container-2"},
+		},
+		{
+			name: "container logs in a namespace",
+			filter: `resource.type="k8s_container"
+resource.labels.cluster_name="my-cluster"
+resource.labels.namespace_name=("default")`,
+			want: []string{"container-1"},
+		},
+		{
+			name: "audit logs in a time range",
+			filter: `LOG_ID("cloudaudit.googleapis.com/activity")
+protoPayload.methodName: ("create" OR "update")
+timestamp >= "2025-01-01T00:00:00+0000"
+timestamp < "2025-01-01T00:01:00+0000"`,
+			want: []string{"audit-1"},
+		},
+		{
+			name: "time range excluding logs",
+			filter: `timestamp >= "2025-01-01T00:00:06+0000"
+timestamp < "2025-01-01T00:00:15+0000"`,
+			want: []string{"audit-1"},
+		},
+	}
+	fetcher := &exportedLogFetcher{
+		openReader: func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader([]byte(exported))), nil
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dest := make(chan *loggingpb.LogEntry)
+			got := []string{}
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for entry := range dest {
+					got = append(got, entry.GetInsertId())
+				}
+			}()
+			err := fetcher.FetchLogs(dest, context.Background(), tc.filter, googlecloud.Project("my-project"), []string{"projects/my-project"})
+			<-done
+			if err != nil {
+				t.Fatalf("FetchLogs() returned an unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("FetchLogs() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExportedLogFetcher_FetchLogsWithInvalidFilter(t *testing.T) {
+	fetcher := &exportedLogFetcher{
+		openReader: func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader([]byte(`{"insertId": "1"}`))), nil
+		},
+	}
+	dest := make(chan *loggingpb.LogEntry, 1)
+	err := fetcher.FetchLogs(dest, context.Background(), `resource.type=`, googlecloud.Project("my-project"), []string{"projects/my-project"})
+	if err == nil {
+		t.Errorf("FetchLogs() returned no error, want an error for the invalid filter")
+	}
+	if _, open := <-dest; open {
+		t.Errorf("FetchLogs() didn't close the destination channel")
+	}
+}
+
+func TestExportedLogFetcher_FetchLogsAfterCanceledCaller(t *testing.T) {
+	readCount := 0
+	fetcher := &exportedLogFetcher{
+		openReader: func() (io.ReadCloser, error) {
+			readCount++
+			return io.NopCloser(bytes.NewReader([]byte(`{"insertId": "1", "timestamp": "2025-01-01T00:00:00Z"}`))), nil
+		},
+	}
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := fetcher.FetchLogs(make(chan *loggingpb.LogEntry, 1), canceledCtx, "", googlecloud.Project("my-project"), []string{"projects/my-project"})
+	if err == nil {
+		t.Fatalf("FetchLogs() returned no error, want the error of the canceled context")
+	}
+
+	for i := 0; i < 2; i++ {
+		dest := make(chan *loggingpb.LogEntry, 1)
+		err = fetcher.FetchLogs(dest, context.Background(), "", googlecloud.Project("my-project"), []string{"projects/my-project"})
+		if err != nil {
+			t.Fatalf("FetchLogs() returned an unexpected error after the canceled call: %v", err)
+		}
+		if entry := <-dest; entry.GetInsertId() != "1" {
+			t.Errorf("FetchLogs() returned %q, want %q", entry.GetInsertId(), "1")
+		}
+	}
+	if readCount != 2 {
+		t.Errorf("the files were read %d times, want 2 times for the canceled call and the first successful call", readCount)
+	}
+}
@@ -182,7 +182,7 @@ func NewLegacyCloudLoggingListLogTask(taskId taskid.TaskImplementationID[[]*log.
 			if taskMode == inspectioncore_contract.TaskModeRun {
 
 				logFetcher := coretask.GetTaskResult(ctx, LoggingFetcherTaskID.Ref())
-				progressReportableLogFetcher := NewTimePartitioningProgressReportableLogFetcher(logFetcher, 500*time.Millisecond, 10, runtime.GOMAXPROCS(0))
+				progressReportableLogFetcher := NewTimePartitioningProgressReportableLogFetcher(logFetcher, 500*time.Millisecond, 10, runtime.GOMAXPROCS(0), nil)
 
 				var wg sync.WaitGroup
 				var logChan = make(chan *loggingpb.LogEntry)
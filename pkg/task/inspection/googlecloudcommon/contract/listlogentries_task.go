@@ -82,7 +82,7 @@ type ListLogEntriesTaskSetting interface {
 func NewListLogEntriesTask(taskSetting ListLogEntriesTaskSetting) coretask.Task[[]*log.Log] {
 	taskID := taskSetting.TaskID()
 	dependencies := taskSetting.Dependencies()
-	dependencies = append(dependencies, InputStartTimeTaskID.Ref(), InputEndTimeTaskID.Ref(), InputLoggingFilterResourceNameTaskID.Ref(), LoggingFetcherTaskID.Ref())
+	dependencies = append(dependencies, InputStartTimeTaskID.Ref(), InputEndTimeTaskID.Ref(), InputLoggingFilterResourceNameTaskID.Ref(), LoggingFetcherTaskID.Ref(), LogFetchConcurrencyBudgetTaskID.Ref())
 	description := taskSetting.Description()
 
 	return inspectiontaskbase.NewProgressReportableInspectionTask(
@@ -131,7 +131,17 @@ func NewListLogEntriesTask(taskSetting ListLogEntriesTaskSetting) coretask.Task[
 				groups = divideGroupByMaximumResourceName(groups, maxResourceNameCountPerRequest)
 
 				logFetcher := coretask.GetTaskResult(ctx, LoggingFetcherTaskID.Ref())
-				progressReportableLogFetcher := NewTimePartitioningProgressReportableLogFetcher(logFetcher, 500*time.Millisecond, timePartitionCount, runtime.GOMAXPROCS(0))
+				// Reuse the logs fetched by the previous run of the inspection when it's resumed.
+				if checkpointFolder, err := khictx.GetValue(ctx, inspectioncore_contract.InspectionCheckpointFolder); err == nil && checkpointFolder != "" {
+					logFetcher = NewCheckpointLogFetcher(logFetcher, checkpointFolder, taskID.String())
+				}
+				// The time partition count is the initial count of partitions adjusted on the observed log density within the budget shared across the tasks.
+				// The partitions are not adjusted when they must be reproducible, e.g. for recording or replaying API fixtures.
+				budget := coretask.GetTaskResult(ctx, LogFetchConcurrencyBudgetTaskID.Ref())
+				if fixed, err := khictx.GetValue(ctx, FixedLogFetchTimePartitionsContextKey); err == nil && fixed {
+					budget = nil
+				}
+				progressReportableLogFetcher := NewTimePartitioningProgressReportableLogFetcher(logFetcher, 500*time.Millisecond, timePartitionCount, runtime.GOMAXPROCS(0), budget)
 
 				for groupIndex, group := range groups {
 					var wg sync.WaitGroup
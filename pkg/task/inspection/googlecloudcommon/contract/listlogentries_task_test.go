@@ -15,14 +15,18 @@
 package googlecloudcommon_contract
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
 	"cloud.google.com/go/logging/apiv2/loggingpb"
 	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud/fixture"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud/options"
 	"github.com/GoogleCloudPlatform/khi/pkg/common/khictx"
 	"github.com/GoogleCloudPlatform/khi/pkg/common/khierrors"
 	"github.com/GoogleCloudPlatform/khi/pkg/common/structured"
@@ -34,10 +38,14 @@ import (
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
 	inspectioncore_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/contract"
+	gcp_test "github.com/GoogleCloudPlatform/khi/pkg/testutil/gcp"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/genproto/googleapis/api/monitoredres"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type mockListLogEntriesTaskSetting struct {
@@ -267,6 +275,7 @@ timestamp < "2025-01-01T01:01:00+0000"`, func(logSource chan<- *loggingpb.LogEnt
 				tasktest.NewTaskDependencyValuePair(InputStartTimeTaskID.Ref(), startTime),
 				tasktest.NewTaskDependencyValuePair(InputEndTimeTaskID.Ref(), endTime),
 				tasktest.NewTaskDependencyValuePair[LogFetcher](LoggingFetcherTaskID.Ref(), fetcher),
+				tasktest.NewTaskDependencyValuePair(LogFetchConcurrencyBudgetTaskID.Ref(), NewLogFetchConcurrencyBudget(4)),
 				tasktest.NewTaskDependencyValuePair(InputLoggingFilterResourceNameTaskID.Ref(), resourceNamesInput))
 			if err != nil {
 				t.Errorf("first NewCloudLoggingFilterTask dry run failed:%v", err)
@@ -282,6 +291,7 @@ timestamp < "2025-01-01T01:01:00+0000"`, func(logSource chan<- *loggingpb.LogEnt
 				tasktest.NewTaskDependencyValuePair(InputStartTimeTaskID.Ref(), startTime),
 				tasktest.NewTaskDependencyValuePair(InputEndTimeTaskID.Ref(), endTime),
 				tasktest.NewTaskDependencyValuePair[LogFetcher](LoggingFetcherTaskID.Ref(), fetcher),
+				tasktest.NewTaskDependencyValuePair(LogFetchConcurrencyBudgetTaskID.Ref(), NewLogFetchConcurrencyBudget(4)),
 				tasktest.NewTaskDependencyValuePair(InputLoggingFilterResourceNameTaskID.Ref(), resourceNamesInput),
 			)
 			if tt.wantError != nil {
@@ -314,6 +324,63 @@ timestamp < "2025-01-01T01:01:00+0000"`, func(logSource chan<- *loggingpb.LogEnt
 	}
 }
 
+func TestNewListLogEntriesTask_WithFakeLoggingServer(t *testing.T) {
+	startTime := time.Date(2025, time.January, 1, 1, 0, 0, 0, time.UTC)
+	endTime := time.Date(2025, time.January, 1, 1, 1, 0, 0, time.UTC)
+	server := gcp_test.NewFakeLoggingServer(t,
+		&loggingpb.LogEntry{InsertId: "before-range", LogName: "projects/foo/logs/events", Timestamp: timestamppb.New(startTime.Add(-time.Second)), Resource: &monitoredres.MonitoredResource{Type: "k8s_cluster"}},
+		&loggingpb.LogEntry{InsertId: "second", LogName: "projects/foo/logs/events", Timestamp: timestamppb.New(startTime.Add(40 * time.Second)), Resource: &monitoredres.MonitoredResource{Type: "k8s_cluster"}},
+		&loggingpb.LogEntry{InsertId: "first", LogName: "projects/foo/logs/events", Timestamp: timestamppb.New(startTime.Add(10 * time.Second)), Resource: &monitoredres.MonitoredResource{Type: "k8s_cluster"}},
+		&loggingpb.LogEntry{InsertId: "other-type", LogName: "projects/foo/logs/events", Timestamp: timestamppb.New(startTime.Add(20 * time.Second)), Resource: &monitoredres.MonitoredResource{Type: "k8s_node"}},
+		&loggingpb.LogEntry{InsertId: "other-project", LogName: "projects/bar/logs/events", Timestamp: timestamppb.New(startTime.Add(30 * time.Second)), Resource: &monitoredres.MonitoredResource{Type: "k8s_cluster"}},
+	)
+	server.InjectError(codes.ResourceExhausted, 1)
+	cf, err := googlecloud.NewClientFactory(server.ClientFactoryOption())
+	if err != nil {
+		t.Fatalf("failed to instanciate client factory: %v", err)
+	}
+	fetcher := NewLogFetcher(cf, googlecloud.NewCallOptionInjector(), 1)
+	task := NewListLogEntriesTask(&mockListLogEntriesTaskSetting{
+		logFilters:         []string{`resource.type="k8s_cluster"`},
+		resourceNames:      []string{"projects/foo"},
+		timePartitionCount: 2,
+		description: &ListLogEntriesTaskDescription{
+			QueryName:      "query-foo",
+			DefaultLogType: enum.LogTypeContainer,
+		},
+	})
+
+	resourceNamesInput := NewResourceNamesInput()
+	firstCtx := inspectiontest.WithDefaultTestInspectionTaskContext(t.Context())
+	_, _, err = inspectiontest.RunInspectionTask(firstCtx, task, inspectioncore_contract.TaskModeDryRun, map[string]any{},
+		tasktest.NewTaskDependencyValuePair(InputStartTimeTaskID.Ref(), startTime),
+		tasktest.NewTaskDependencyValuePair(InputEndTimeTaskID.Ref(), endTime),
+		tasktest.NewTaskDependencyValuePair(LoggingFetcherTaskID.Ref(), fetcher),
+		tasktest.NewTaskDependencyValuePair(LogFetchConcurrencyBudgetTaskID.Ref(), NewLogFetchConcurrencyBudget(4)),
+		tasktest.NewTaskDependencyValuePair(InputLoggingFilterResourceNameTaskID.Ref(), resourceNamesInput))
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	nextCtx := inspectiontest.NextRunTaskContext(t.Context(), firstCtx)
+	gotLogs, _, err := inspectiontest.RunInspectionTask(nextCtx, task, inspectioncore_contract.TaskModeRun, map[string]any{},
+		tasktest.NewTaskDependencyValuePair(InputStartTimeTaskID.Ref(), startTime),
+		tasktest.NewTaskDependencyValuePair(InputEndTimeTaskID.Ref(), endTime),
+		tasktest.NewTaskDependencyValuePair(LoggingFetcherTaskID.Ref(), fetcher),
+		tasktest.NewTaskDependencyValuePair(LogFetchConcurrencyBudgetTaskID.Ref(), NewLogFetchConcurrencyBudget(4)),
+		tasktest.NewTaskDependencyValuePair(InputLoggingFilterResourceNameTaskID.Ref(), resourceNamesInput))
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	gotInsertIDs := []string{}
+	for _, l := range gotLogs {
+		gotInsertIDs = append(gotInsertIDs, l.ReadStringOrDefault("insertId", ""))
+	}
+	if diff := cmp.Diff([]string{"first", "second"}, gotInsertIDs); diff != "" {
+		t.Errorf("logs mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestSetQueryInfo(t *testing.T) {
 	t.Parallel()
 	taskID := "task-foo"
@@ -546,3 +613,67 @@ func TestDivideGroupByMaximumResourceName(t *testing.T) {
 		})
 	}
 }
+
+func TestNewListLogEntriesTask_RecordAndReplayFixture(t *testing.T) {
+	startTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endTime := startTime.Add(8 * time.Hour)
+	// Sparse logs make adaptive partitions merge the rest of partitions after the first one. Requests must not depend on it to be replayed.
+	server, wantInsertIDs := newFakeServerLogsAtEveryMinute(t, startTime, 3)
+	task := NewListLogEntriesTask(&mockListLogEntriesTaskSetting{
+		logFilters:         []string{`LOG_ID("events")`},
+		resourceNames:      []string{"projects/test-project"},
+		timePartitionCount: 8,
+		description: &ListLogEntriesTaskDescription{
+			QueryName:      "query-foo",
+			DefaultLogType: enum.LogTypeContainer,
+		},
+	})
+	runTask := func(t *testing.T, clientFactoryOption googlecloud.ClientFactoryOption) []string {
+		t.Helper()
+		cf, err := googlecloud.NewClientFactory(clientFactoryOption)
+		if err != nil {
+			t.Fatalf("failed to instanciate client factory: %v", err)
+		}
+		dependencies := []tasktest.TaskDependencyValues{
+			tasktest.NewTaskDependencyValuePair(InputStartTimeTaskID.Ref(), startTime),
+			tasktest.NewTaskDependencyValuePair(InputEndTimeTaskID.Ref(), endTime),
+			tasktest.NewTaskDependencyValuePair(LoggingFetcherTaskID.Ref(), NewLogFetcher(cf, googlecloud.NewCallOptionInjector(), 100)),
+			tasktest.NewTaskDependencyValuePair(LogFetchConcurrencyBudgetTaskID.Ref(), NewLogFetchConcurrencyBudget(1)),
+			tasktest.NewTaskDependencyValuePair(InputLoggingFilterResourceNameTaskID.Ref(), NewResourceNamesInput()),
+		}
+		baseCtx := khictx.WithValue(t.Context(), FixedLogFetchTimePartitionsContextKey, true)
+		dryRunCtx := inspectiontest.WithDefaultTestInspectionTaskContext(baseCtx)
+		if _, _, err := inspectiontest.RunInspectionTask(dryRunCtx, task, inspectioncore_contract.TaskModeDryRun, map[string]any{}, dependencies...); err != nil {
+			t.Fatalf("dry run failed: %v", err)
+		}
+		logs, _, err := inspectiontest.RunInspectionTask(inspectiontest.NextRunTaskContext(baseCtx, dryRunCtx), task, inspectioncore_contract.TaskModeRun, map[string]any{}, dependencies...)
+		if err != nil {
+			t.Fatalf("run failed: %v", err)
+		}
+		insertIDs := []string{}
+		for _, l := range logs {
+			insertIDs = append(insertIDs, l.ReadStringOrDefault("insertId", ""))
+		}
+		slices.Sort(insertIDs)
+		return insertIDs
+	}
+
+	fixtureBuffer := &bytes.Buffer{}
+	recorder := fixture.NewRecorder(fixtureBuffer)
+	recordedInsertIDs := runTask(t, server.ClientFactoryOption(grpc.WithChainUnaryInterceptor(recorder.UnaryClientInterceptor())))
+	if diff := cmp.Diff(wantInsertIDs, recordedInsertIDs); diff != "" {
+		t.Errorf("recorded logs mismatch (-want +got):\n%s", diff)
+	}
+	if got := distinctFilterCount(server); got != 8 {
+		t.Errorf("got %d distinct filters, want 8 from the fixed partitions", got)
+	}
+
+	replayer, err := fixture.NewReplayer(fixtureBuffer)
+	if err != nil {
+		t.Fatalf("failed to load the recorded fixture: %v", err)
+	}
+	replayedInsertIDs := runTask(t, options.ReplayFixture(replayer))
+	if diff := cmp.Diff(wantInsertIDs, replayedInsertIDs); diff != "" {
+		t.Errorf("replayed logs mismatch (-want +got):\n%s", diff)
+	}
+}
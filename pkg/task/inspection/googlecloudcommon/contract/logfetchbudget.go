@@ -0,0 +1,49 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlecloudcommon_contract
+
+import (
+	"context"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// LogFetchConcurrencyBudget limits the number of Cloud Logging queries running concurrently.
+// A budget is shared by all the tasks listing logs in an inspection to keep the total parallelism within the limit regardless of the count of the tasks.
+type LogFetchConcurrencyBudget struct {
+	semaphore *semaphore.Weighted
+}
+
+// NewLogFetchConcurrencyBudget returns a LogFetchConcurrencyBudget allowing the given number of concurrent queries.
+func NewLogFetchConcurrencyBudget(size int) *LogFetchConcurrencyBudget {
+	return &LogFetchConcurrencyBudget{
+		semaphore: semaphore.NewWeighted(int64(max(size, 1))),
+	}
+}
+
+// Acquire blocks until a query can be started or the context is cancelled.
+func (b *LogFetchConcurrencyBudget) Acquire(ctx context.Context) error {
+	return b.semaphore.Acquire(ctx, 1)
+}
+
+// TryAcquire returns true when a query can be started immediately. It never blocks.
+func (b *LogFetchConcurrencyBudget) TryAcquire() bool {
+	return b.semaphore.TryAcquire(1)
+}
+
+// Release returns the budget acquired with Acquire or TryAcquire.
+func (b *LogFetchConcurrencyBudget) Release() {
+	b.semaphore.Release(1)
+}
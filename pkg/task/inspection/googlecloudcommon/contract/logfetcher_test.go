@@ -24,6 +24,10 @@ import (
 	"cloud.google.com/go/logging/apiv2/loggingpb"
 	"github.com/GoogleCloudPlatform/khi/internal/testflags"
 	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud"
+	gcp_test "github.com/GoogleCloudPlatform/khi/pkg/testutil/gcp"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestLogFetcherImpl_FetchLogs(t *testing.T) {
@@ -109,3 +113,42 @@ func TestLogFetcherImpl_FetchLogsIsCancellable(t *testing.T) {
 	}
 	close(fetchLogFinished)
 }
+
+func TestLogFetcherImpl_FetchLogsFromFakeServer(t *testing.T) {
+	baseTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	server := gcp_test.NewFakeLoggingServer(t,
+		&loggingpb.LogEntry{InsertId: "c", LogName: "projects/test-project/logs/events", Timestamp: timestamppb.New(baseTime.Add(2 * time.Second))},
+		&loggingpb.LogEntry{InsertId: "a", LogName: "projects/test-project/logs/events", Timestamp: timestamppb.New(baseTime)},
+		&loggingpb.LogEntry{InsertId: "b", LogName: "projects/test-project/logs/events", Timestamp: timestamppb.New(baseTime.Add(time.Second))},
+		&loggingpb.LogEntry{InsertId: "x", LogName: "projects/test-project/logs/excluded", Timestamp: timestamppb.New(baseTime)},
+	)
+	// PermissionDenied errors are retried by the fetcher because Cloud Logging can return them transiently.
+	server.InjectError(codes.PermissionDenied, 2)
+	cf, err := googlecloud.NewClientFactory(server.ClientFactoryOption())
+	if err != nil {
+		t.Fatalf("failed to instanciate client factory: %v", err)
+	}
+	fetcher := NewLogFetcher(cf, googlecloud.NewCallOptionInjector(), 2)
+
+	destChan := make(chan *loggingpb.LogEntry)
+	gotInsertIDs := []string{}
+	receiveDone := make(chan struct{})
+	go func() {
+		defer close(receiveDone)
+		for entry := range destChan {
+			gotInsertIDs = append(gotInsertIDs, entry.InsertId)
+		}
+	}()
+	err = fetcher.FetchLogs(destChan, t.Context(), `-LOG_ID("excluded")`, googlecloud.Project("test-project"), []string{"projects/test-project"})
+	<-receiveDone
+	if err != nil {
+		t.Fatalf("failed to fetch logs: %v", err)
+	}
+	if diff := cmp.Diff([]string{"a", "b", "c"}, gotInsertIDs); diff != "" {
+		t.Errorf("FetchLogs returned unexpected logs (-want +got):\n%s", diff)
+	}
+	// 2 failed requests and 2 pages.
+	if got := len(server.Requests()); got != 4 {
+		t.Errorf("got %d requests, want 4", got)
+	}
+}
@@ -71,6 +71,8 @@ func (s *StandardProgressReportableLogFetcher) FetchLogsWithProgress(dest chan<-
 
 	wg := sync.WaitGroup{}
 	wg.Add(2)
+	// logConsumerDone is closed when all logs received from the fetcher are sent to dest.
+	logConsumerDone := make(chan struct{})
 	logCount := atomic.Int32{}
 	latestLogTime := &beginTime
 	totalDurationInSeconds := endTime.Sub(beginTime).Seconds()
@@ -82,6 +84,7 @@ func (s *StandardProgressReportableLogFetcher) FetchLogsWithProgress(dest chan<-
 	// Consume logs from log fetcher and record count and the latest time for reporting progress
 	go func() {
 		defer wg.Done() // fetcher.FetchLogs is expected to run in sync. But make sure all the logs are consumed in this go routine.
+		defer close(logConsumerDone)
 		for {
 			select {
 			case <-subroutineCtx.Done():
@@ -137,6 +140,11 @@ func (s *StandardProgressReportableLogFetcher) FetchLogsWithProgress(dest chan<-
 		return err
 	}
 
+	// The last log can be still on the way to dest after FetchLogs returns. Wait it before stopping the subroutines not to drop it.
+	select {
+	case <-logConsumerDone:
+	case <-ctx.Done():
+	}
 	cancelSubroutine()
 	wg.Wait()
 
@@ -150,24 +158,44 @@ func (s *StandardProgressReportableLogFetcher) FetchLogsWithProgress(dest chan<-
 
 var _ ProgressReportableLogFetcher = (*StandardProgressReportableLogFetcher)(nil)
 
+// TimePartitioningProgressReportableLogFetcher fetches logs in parallel by dividing the time range into partitions.
+// When a LogFetchConcurrencyBudget is given, the partitions are adjusted adaptively based on the observed log density. See fetchLogsAdaptively for the detail.
 type TimePartitioningProgressReportableLogFetcher struct {
 	client         *StandardProgressReportableLogFetcher
+	fetcher        LogFetcher
 	partitionCount int
 	maxParallelism int
 	reportInterval time.Duration
+	budget         *LogFetchConcurrencyBudget
+	// splitCheckInterval is the interval to check if a running partition should be split.
+	splitCheckInterval time.Duration
+	// minPartitionDuration is the minimum duration of the partitions made by splitting a partition.
+	minPartitionDuration time.Duration
+	// targetLogsPerPartition is the estimated log count of a partition made by merging partitions not started yet.
+	targetLogsPerPartition int
 }
 
-func NewTimePartitioningProgressReportableLogFetcher(fetcher LogFetcher, interval time.Duration, partitionCount int, maxParallelism int) *TimePartitioningProgressReportableLogFetcher {
+// NewTimePartitioningProgressReportableLogFetcher returns a TimePartitioningProgressReportableLogFetcher dividing the time range into partitionCount partitions.
+// The time range is fetched with the fixed partitions when the budget is nil. Otherwise the partitions are split or merged adaptively within the budget.
+func NewTimePartitioningProgressReportableLogFetcher(fetcher LogFetcher, interval time.Duration, partitionCount int, maxParallelism int, budget *LogFetchConcurrencyBudget) *TimePartitioningProgressReportableLogFetcher {
 	return &TimePartitioningProgressReportableLogFetcher{
-		client:         NewStandardProgressReportableLogFetcher(fetcher, interval),
-		partitionCount: partitionCount,
-		maxParallelism: maxParallelism,
-		reportInterval: interval,
+		client:                 NewStandardProgressReportableLogFetcher(fetcher, interval),
+		fetcher:                fetcher,
+		partitionCount:         partitionCount,
+		maxParallelism:         maxParallelism,
+		reportInterval:         interval,
+		budget:                 budget,
+		splitCheckInterval:     10 * time.Second,
+		minPartitionDuration:   time.Minute,
+		targetLogsPerPartition: 10000,
 	}
 }
 
 // FetchLogsWithProgress implements ProgressReportableLogFetcher.
 func (t *TimePartitioningProgressReportableLogFetcher) FetchLogsWithProgress(logChan chan<- *loggingpb.LogEntry, progressChan chan<- LogFetchProgress, ctx context.Context, beginTime time.Time, endTime time.Time, filterWithoutTimeRange string, container googlecloud.ResourceContainer, resourceContainers []string) error {
+	if t.budget != nil {
+		return t.fetchLogsAdaptively(logChan, progressChan, ctx, beginTime, endTime, filterWithoutTimeRange, container, resourceContainers)
+	}
 	defer close(logChan)
 	defer close(progressChan)
 
@@ -17,6 +17,7 @@ package googlecloudcommon_contract
 import (
 	"context"
 	"errors"
+	"fmt"
 	"slices"
 	"sync"
 	"testing"
@@ -24,8 +25,10 @@ import (
 
 	"cloud.google.com/go/logging/apiv2/loggingpb"
 	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud"
+	gcp_test "github.com/GoogleCloudPlatform/khi/pkg/testutil/gcp"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/genproto/googleapis/api/monitoredres"
 	"google.golang.org/protobuf/testing/protocmp"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -521,7 +524,7 @@ timestamp < "2025-01-01T00:20:00+0000"`, func(logSource chan<- *loggingpb.LogEnt
 			logReceiveChan := channelToArrayParallel(t.Context(), &wg, &logs)
 			progressReceiveChan := channelToArrayParallel(t.Context(), &wg, &progresses)
 
-			progressReportableFetcher := NewTimePartitioningProgressReportableLogFetcher(fetcher, tick/2, tc.partitionCount, tc.maxParallelism)
+			progressReportableFetcher := NewTimePartitioningProgressReportableLogFetcher(fetcher, tick/2, tc.partitionCount, tc.maxParallelism, nil)
 			progressReportableFetcher.reportInterval = tick // To make this test stable, the parent progress reporter tick interval is 2 times longer than its child reporter.
 
 			afterFetchDone := make(chan struct{})
@@ -569,3 +572,62 @@ timestamp < "2025-01-01T00:20:00+0000"`, func(logSource chan<- *loggingpb.LogEnt
 		})
 	}
 }
+
+func TestTimePartitioningProgressReportableLogFetcher_FetchLogsFromFakeServer(t *testing.T) {
+	beginTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endTime := beginTime.Add(time.Hour)
+	server := gcp_test.NewFakeLoggingServer(t)
+	var wantInsertIDs []string
+	// Logs at every 5 minutes including the boundaries of partitions. The log at endTime is out of the range.
+	for i := 0; i <= 12; i++ {
+		insertID := fmt.Sprintf("log-%02d", i)
+		resourceType := "k8s_cluster"
+		if i%3 == 0 {
+			resourceType = "k8s_node"
+		}
+		server.AddEntries(t, &loggingpb.LogEntry{
+			InsertId:  insertID,
+			LogName:   "projects/test-project/logs/events",
+			Timestamp: timestamppb.New(beginTime.Add(time.Duration(i) * 5 * time.Minute)),
+			Resource:  &monitoredres.MonitoredResource{Type: resourceType},
+		})
+		if resourceType == "k8s_cluster" && i < 12 {
+			wantInsertIDs = append(wantInsertIDs, insertID)
+		}
+	}
+	cf, err := googlecloud.NewClientFactory(server.ClientFactoryOption())
+	if err != nil {
+		t.Fatalf("failed to instanciate client factory: %v", err)
+	}
+	fetcher := NewTimePartitioningProgressReportableLogFetcher(NewLogFetcher(cf, googlecloud.NewCallOptionInjector(), 2), 10*time.Millisecond, 4, 2, nil)
+
+	wg := sync.WaitGroup{}
+	var logs []*loggingpb.LogEntry
+	var progresses []LogFetchProgress
+	logReceiveChan := channelToArrayParallel(t.Context(), &wg, &logs)
+	progressReceiveChan := channelToArrayParallel(t.Context(), &wg, &progresses)
+	err = fetcher.FetchLogsWithProgress(logReceiveChan, progressReceiveChan, t.Context(), beginTime, endTime, `resource.type="k8s_cluster"`, googlecloud.Project("test-project"), []string{"projects/test-project"})
+	wg.Wait()
+	if err != nil {
+		t.Fatalf("FetchLogsWithProgress() returned unexpected error: %v", err)
+	}
+
+	slices.SortFunc(logs, func(a, b *loggingpb.LogEntry) int { return a.Timestamp.AsTime().Compare(b.Timestamp.AsTime()) })
+	var gotInsertIDs []string
+	for _, l := range logs {
+		gotInsertIDs = append(gotInsertIDs, l.InsertId)
+	}
+	if diff := cmp.Diff(wantInsertIDs, gotInsertIDs); diff != "" {
+		t.Errorf("FetchLogsWithProgress() returned unexpected logs (-want +got):\n%s", diff)
+	}
+	if len(progresses) == 0 || progresses[len(progresses)-1].Progress != 1 || progresses[len(progresses)-1].LogCount != len(wantInsertIDs) {
+		t.Errorf("FetchLogsWithProgress() must report the completed progress at last, got %v", progresses)
+	}
+	filters := map[string]struct{}{}
+	for _, req := range server.Requests() {
+		filters[req.Filter] = struct{}{}
+	}
+	if len(filters) != 4 {
+		t.Errorf("got %d distinct filters, want 4 filters for the partitions", len(filters))
+	}
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud"
 	"github.com/GoogleCloudPlatform/khi/pkg/core/task/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
 )
 
 // GoogleCloudCommonTaskIDPrefix is the prefix for Google Cloud common task IDs.
@@ -44,6 +45,9 @@ var InputEndTimeTaskID = taskid.NewDefaultImplementationID[time.Time](GoogleClou
 // InputStartTimeTaskID is the task ID for the start time of the log query. This is computed from InputDurationTask and InputEndTimeTask.
 var InputStartTimeTaskID = taskid.NewDefaultImplementationID[time.Time](GoogleCloudCommonTaskIDPrefix + "input-start-time")
 
+// InputLogExportFilesTaskID is the task ID for the optional files of LogEntries exported from Cloud Logging used in place of querying Cloud Logging.
+var InputLogExportFilesTaskID = taskid.NewDefaultImplementationID[upload.UploadResult](GoogleCloudCommonTaskIDPrefix + "input-log-export-files")
+
 // InputLocationsTaskID is the task ID for the locations of the target resource.
 var InputLocationsTaskID = taskid.NewDefaultImplementationID[string](GoogleCloudCommonTaskIDPrefix + "input-location")
 
@@ -61,3 +65,6 @@ var LocationFetcherTaskID = taskid.NewDefaultImplementationID[LocationFetcher](G
 
 // LoggingFetcherTaskID is the task ID to inject the instance of LogFetcher.
 var LoggingFetcherTaskID = taskid.NewDefaultImplementationID[LogFetcher](GoogleCloudCommonTaskIDPrefix + "log-fetcher")
+
+// LogFetchConcurrencyBudgetTaskID is the task ID to inject the LogFetchConcurrencyBudget shared by the tasks listing logs in an inspection.
+var LogFetchConcurrencyBudgetTaskID = taskid.NewDefaultImplementationID[*LogFetchConcurrencyBudget](GoogleCloudCommonTaskIDPrefix + "log-fetch-concurrency-budget")
@@ -16,30 +16,56 @@ package googlecloudcommon_impl
 
 import (
 	"context"
+	"errors"
+	"slices"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud"
 	inspectiontaskbase "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/taskbase"
 	coretask "github.com/GoogleCloudPlatform/khi/pkg/core/task"
 	"github.com/GoogleCloudPlatform/khi/pkg/core/task/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
 	googlecloudcommon_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/googlecloudcommon/contract"
+	"google.golang.org/api/option"
 )
 
+// errAPICallsWithExportedLogs is returned on creating Google Cloud API clients while logs are read from the uploaded Cloud Logging export files.
+var errAPICallsWithExportedLogs = errors.New("Google Cloud APIs are not called while logs are read from the uploaded Cloud Logging export files")
+
 // APIClientFactoryTask is a task to inject googlecloud.ClientFactory to the later tasks. The instance is singleton in an inspection and the instance is cached on inspection cache after the first generation.
+// The ClientFactory fails to create any client when LogEntries exported from Cloud Logging are uploaded, so that inspections reading them don't need credentials.
 var APIClientFactoryTask = inspectiontaskbase.NewCachedTask(googlecloudcommon_contract.APIClientFactoryTaskID, []taskid.UntypedTaskReference{
 	googlecloudcommon_contract.APIClientFactoryOptionsTaskID.Ref(),
+	googlecloudcommon_contract.InputLogExportFilesTaskID.Ref(),
 }, func(ctx context.Context, prevValue inspectiontaskbase.CacheableTaskResult[*googlecloud.ClientFactory]) (inspectiontaskbase.CacheableTaskResult[*googlecloud.ClientFactory], error) {
+	exportFiles := coretask.GetTaskResult(ctx, googlecloudcommon_contract.InputLogExportFilesTaskID.Ref())
+	// the client don't need to recreate and it's singleton because the options are not expected to be refresh.
+	dependencyDigest := "singleton"
+	if exportFiles.Status == upload.UploadStatusCompleted {
+		dependencyDigest = "exported-logs"
+	}
 	// Use cached client if it was set already.
-	if prevValue.DependencyDigest != "" {
+	if prevValue.DependencyDigest == dependencyDigest {
 		return prevValue, nil
 	}
 	opts := coretask.GetTaskResult(ctx, googlecloudcommon_contract.APIClientFactoryOptionsTaskID.Ref())
+	if exportFiles.Status == upload.UploadStatusCompleted {
+		opts = append(slices.Clone(opts), rejectAPIClients)
+	}
 
 	clientFactory, err := googlecloud.NewClientFactory(opts...)
 	if err != nil {
 		return inspectiontaskbase.CacheableTaskResult[*googlecloud.ClientFactory]{}, err
 	}
 	return inspectiontaskbase.CacheableTaskResult[*googlecloud.ClientFactory]{
-		DependencyDigest: "singleton", // the client don't need to recreate and it's singleton because the options are not expected to be refresh.
+		DependencyDigest: dependencyDigest,
 		Value:            clientFactory,
 	}, nil
 })
+
+// rejectAPIClients is the ClientFactoryOption to make every client creation of the ClientFactory fail before calling any API.
+func rejectAPIClients(s *googlecloud.ClientFactory) error {
+	s.ClientOptions = append(s.ClientOptions, func(opts []option.ClientOption, container googlecloud.ResourceContainer) ([]option.ClientOption, error) {
+		return nil, errAPICallsWithExportedLogs
+	})
+	return nil
+}
@@ -20,15 +20,22 @@ import (
 	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud"
 	coretask "github.com/GoogleCloudPlatform/khi/pkg/core/task"
 	"github.com/GoogleCloudPlatform/khi/pkg/core/task/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
 	googlecloudcommon_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/googlecloudcommon/contract"
 )
 
 // LocationFetcherTask is the task to inject the reference to LocationFetcher.
+// The LocationFetcher returns no regions without calling the API when LogEntries exported from Cloud Logging are uploaded.
 var LocationFetcherTask = coretask.NewTask(googlecloudcommon_contract.LocationFetcherTaskID, []taskid.UntypedTaskReference{
 	googlecloudcommon_contract.InputProjectIdTaskID.Ref(),
 	googlecloudcommon_contract.APIClientFactoryTaskID.Ref(),
 	googlecloudcommon_contract.APIClientCallOptionsInjectorTaskID.Ref(),
+	googlecloudcommon_contract.InputLogExportFilesTaskID.Ref(),
 }, func(ctx context.Context) (googlecloudcommon_contract.LocationFetcher, error) {
+	exportFiles := coretask.GetTaskResult(ctx, googlecloudcommon_contract.InputLogExportFilesTaskID.Ref())
+	if exportFiles.Status == upload.UploadStatusCompleted {
+		return &noRegionLocationFetcher{}, nil
+	}
 	clientFactory := coretask.GetTaskResult(ctx, googlecloudcommon_contract.APIClientFactoryTaskID.Ref())
 	callOptionInjector := coretask.GetTaskResult(ctx, googlecloudcommon_contract.APIClientCallOptionsInjectorTaskID.Ref())
 	projectID := coretask.GetTaskResult(ctx, googlecloudcommon_contract.InputProjectIdTaskID.Ref())
@@ -39,12 +46,36 @@ var LocationFetcherTask = coretask.NewTask(googlecloudcommon_contract.LocationFe
 	return googlecloudcommon_contract.NewLocationFetcher(regionClient, callOptionInjector), nil
 })
 
+// noRegionLocationFetcher is the LocationFetcher used while logs are read from the uploaded Cloud Logging export files.
+type noRegionLocationFetcher struct{}
+
+// FetchRegions implements googlecloudcommon_contract.LocationFetcher.
+func (n *noRegionLocationFetcher) FetchRegions(ctx context.Context, projectId string) ([]string, error) {
+	return []string{}, nil
+}
+
+var _ googlecloudcommon_contract.LocationFetcher = (*noRegionLocationFetcher)(nil)
+
 // LoggingFetcherTask is a task to inject the reference to LogFetcher.
+// The LogFetcher reads logs from the uploaded files instead of Cloud Logging when LogEntries exported from Cloud Logging are uploaded.
 var LoggingFetcherTask = coretask.NewTask(googlecloudcommon_contract.LoggingFetcherTaskID, []taskid.UntypedTaskReference{
 	googlecloudcommon_contract.APIClientFactoryTaskID.Ref(),
 	googlecloudcommon_contract.APIClientCallOptionsInjectorTaskID.Ref(),
+	googlecloudcommon_contract.InputLogExportFilesTaskID.Ref(),
 }, func(ctx context.Context) (googlecloudcommon_contract.LogFetcher, error) {
+	exportFiles := coretask.GetTaskResult(ctx, googlecloudcommon_contract.InputLogExportFilesTaskID.Ref())
+	if exportFiles.Status == upload.UploadStatusCompleted {
+		return googlecloudcommon_contract.NewExportedLogFetcher(exportFiles), nil
+	}
 	clientFactory := coretask.GetTaskResult(ctx, googlecloudcommon_contract.APIClientFactoryTaskID.Ref())
 	callOptionInjector := coretask.GetTaskResult(ctx, googlecloudcommon_contract.APIClientCallOptionsInjectorTaskID.Ref())
 	return googlecloudcommon_contract.NewLogFetcher(clientFactory, callOptionInjector, 1000), nil
 })
+
+// maxConcurrentLogFetches is the maximum number of Cloud Logging queries running concurrently in an inspection.
+const maxConcurrentLogFetches = 16
+
+// LogFetchConcurrencyBudgetTask is a task to inject the LogFetchConcurrencyBudget shared by all the tasks listing logs in an inspection.
+var LogFetchConcurrencyBudgetTask = coretask.NewTask(googlecloudcommon_contract.LogFetchConcurrencyBudgetTaskID, []taskid.UntypedTaskReference{}, func(ctx context.Context) (*googlecloudcommon_contract.LogFetchConcurrencyBudget, error) {
+	return googlecloudcommon_contract.NewLogFetchConcurrencyBudget(maxConcurrentLogFetches), nil
+})
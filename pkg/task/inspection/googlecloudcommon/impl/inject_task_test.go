@@ -0,0 +1,100 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlecloudcommon_impl
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud"
+	inspectiontest "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/test"
+	tasktest "github.com/GoogleCloudPlatform/khi/pkg/core/task/test"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
+	googlecloudcommon_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/googlecloudcommon/contract"
+	inspectioncore_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/contract"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInjectTasksWithExportedLogsWithoutCredentials(t *testing.T) {
+	// Make sure no credential can be found from the environment.
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "/nonexistent/credentials.json")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("CLOUDSDK_CONFIG", t.TempDir())
+
+	provider := upload.NewLocalUploadFileStoreProvider(t.TempDir())
+	token := provider.GetUploadToken("test")
+	exported := `{"insertId": "1", "logName": "projects/my-project/logs/stdout", "textPayload": "foo", "timestamp": "2025-01-01T00:00:00Z"}`
+	if err := provider.Write(token, strings.NewReader(exported)); err != nil {
+		t.Fatalf("failed to write the test file: %v", err)
+	}
+	exportFiles := upload.UploadResult{
+		Token:         token,
+		StoreProvider: provider,
+		Status:        upload.UploadStatusCompleted,
+	}
+	ctx := inspectiontest.WithDefaultTestInspectionTaskContext(t.Context())
+
+	clientFactory, _, err := inspectiontest.RunInspectionTask(ctx, APIClientFactoryTask, inspectioncore_contract.TaskModeRun, map[string]any{},
+		tasktest.NewTaskDependencyValuePair(googlecloudcommon_contract.APIClientFactoryOptionsTaskID.Ref(), []googlecloud.ClientFactoryOption{}),
+		tasktest.NewTaskDependencyValuePair(googlecloudcommon_contract.InputLogExportFilesTaskID.Ref(), exportFiles),
+	)
+	if err != nil {
+		t.Fatalf("APIClientFactoryTask failed: %v", err)
+	}
+	if _, err := clientFactory.LoggingClient(t.Context(), googlecloud.Project("my-project")); !errors.Is(err, errAPICallsWithExportedLogs) {
+		t.Errorf("LoggingClient() returned %v, want %v", err, errAPICallsWithExportedLogs)
+	}
+
+	injectorDependencies := []tasktest.TaskDependencyValues{
+		tasktest.NewTaskDependencyValuePair(googlecloudcommon_contract.InputProjectIdTaskID.Ref(), "my-project"),
+		tasktest.NewTaskDependencyValuePair(googlecloudcommon_contract.APIClientFactoryTaskID.Ref(), clientFactory),
+		tasktest.NewTaskDependencyValuePair(googlecloudcommon_contract.APIClientCallOptionsInjectorTaskID.Ref(), googlecloud.NewCallOptionInjector()),
+		tasktest.NewTaskDependencyValuePair(googlecloudcommon_contract.InputLogExportFilesTaskID.Ref(), exportFiles),
+	}
+
+	locationFetcher, _, err := inspectiontest.RunInspectionTask(ctx, LocationFetcherTask, inspectioncore_contract.TaskModeRun, map[string]any{}, injectorDependencies...)
+	if err != nil {
+		t.Fatalf("LocationFetcherTask failed: %v", err)
+	}
+	regions, err := locationFetcher.FetchRegions(t.Context(), "my-project")
+	if err != nil {
+		t.Errorf("FetchRegions() returned an unexpected error: %v", err)
+	}
+	if len(regions) != 0 {
+		t.Errorf("FetchRegions() = %v, want no regions", regions)
+	}
+
+	logFetcher, _, err := inspectiontest.RunInspectionTask(ctx, LoggingFetcherTask, inspectioncore_contract.TaskModeRun, map[string]any{}, injectorDependencies...)
+	if err != nil {
+		t.Fatalf("LoggingFetcherTask failed: %v", err)
+	}
+	dest := make(chan *loggingpb.LogEntry)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- logFetcher.FetchLogs(dest, t.Context(), `textPayload:"foo"`, googlecloud.Project("my-project"), []string{"projects/my-project"})
+	}()
+	var got []string
+	for entry := range dest {
+		got = append(got, entry.GetInsertId())
+	}
+	if err := <-errCh; err != nil {
+		t.Errorf("FetchLogs() returned an unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"1"}, got); diff != "" {
+		t.Errorf("FetchLogs() mismatch (-want +got):\n%s", diff)
+	}
+}
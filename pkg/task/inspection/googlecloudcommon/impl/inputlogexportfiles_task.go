@@ -0,0 +1,65 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlecloudcommon_impl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud/logconvert"
+	"github.com/GoogleCloudPlatform/khi/pkg/core/inspection/formtask"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
+	googlecloudcommon_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/googlecloudcommon/contract"
+)
+
+// errFirstLogEntryVerified is returned from the callback to stop reading a file after verifying its first LogEntry.
+var errFirstLogEntryVerified = errors.New("the first log entry was verified")
+
+// InputLogExportFilesTask defines an optional form task for uploading LogEntries exported from Cloud Logging to read logs from them instead of Cloud Logging.
+var InputLogExportFilesTask = formtask.NewFileFormTaskBuilder(googlecloudcommon_contract.InputLogExportFilesTaskID, googlecloudcommon_contract.PriorityForLogSourceGroup+5000, "Cloud Logging export files", &logExportFileVerifier{}).
+	WithDescription("Optional. Upload LogEntries exported from Cloud Logging in JSON (e.g. the output of `gcloud logging read --format=json` or files written to Cloud Storage by log sinks) to read logs from them instead of querying Cloud Logging. The filters of the generated queries are applied to the logs in the files. Multiple files, gzip compressed files and tar or zip archives of them are also accepted.").
+	AsOptional().
+	Build()
+
+// logExportFileVerifier verifies the first LogEntry of every uploaded file is convertible to LogEntry. Empty files are accepted.
+type logExportFileVerifier struct{}
+
+// Verify implements upload.UploadFileVerifier.
+func (l *logExportFileVerifier) Verify(storeProvider upload.UploadFileStoreProvider, token upload.UploadToken) error {
+	reader, err := storeProvider.Read(token)
+	if err != nil {
+		return fmt.Errorf("failed to read the uploded file")
+	}
+	defer reader.Close()
+
+	return upload.WalkUploadedFiles(reader, func(name string, fileReader io.Reader) error {
+		err := logconvert.SplitLogEntriesJSON(fileReader, func(entryJSON []byte) error {
+			if _, err := logconvert.LogEntryFromJSON(entryJSON); err != nil {
+				return fmt.Errorf("the first entry is not a LogEntry exported from Cloud Logging: %w", err)
+			}
+			return errFirstLogEntryVerified
+		})
+		if err != nil && !errors.Is(err, errFirstLogEntryVerified) {
+			if name != "" {
+				return fmt.Errorf("failed to verify %s: %w", name, err)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+var _ upload.UploadFileVerifier = (*logExportFileVerifier)(nil)
@@ -0,0 +1,69 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlecloudcommon_impl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
+)
+
+func TestLogExportFileVerifier_Verify(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "JSON array of LogEntries",
+			content: `[{"insertId": "1", "timestamp": "2025-01-01T00:00:00Z"}, {"insertId": "2", "timestamp": "2025-01-01T00:00:01Z"}]`,
+		},
+		{
+			name:    "JSON lines of LogEntries",
+			content: "{\"insertId\": \"1\"}\n{\"insertId\": \"2\"}\n",
+		},
+		{
+			name:    "empty file",
+			content: "",
+		},
+		{
+			name:    "not a LogEntry",
+			content: `{"insertId": 1}`,
+			wantErr: true,
+		},
+		{
+			name:    "not a JSON",
+			content: "I0101 00:00:00.000000       1 main.go:10] message",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := upload.NewLocalUploadFileStoreProvider(t.TempDir())
+			token := provider.GetUploadToken("test")
+			if err := provider.Write(token, strings.NewReader(tc.content)); err != nil {
+				t.Fatalf("failed to write the test file: %v", err)
+			}
+			err := (&logExportFileVerifier{}).Verify(provider, token)
+			if tc.wantErr && err == nil {
+				t.Errorf("Verify() returned no error, want an error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("Verify() returned an unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -33,6 +33,8 @@ func Register(registry coreinspection.InspectionTaskRegistry) error {
 		APIClientFactoryOptionsTask,
 		APICallOptionsInjectorTask,
 		LocationFetcherTask,
+		InputLogExportFilesTask,
 		LoggingFetcherTask,
+		LogFetchConcurrencyBudgetTask,
 	)
 }
@@ -25,7 +25,7 @@ import (
 )
 
 var inputKindNameAliasMap gcpqueryutil.SetFilterAliasToItemsMap = map[string][]string{
-	"default": strings.Split("pods replicasets daemonsets nodes deployments namespaces statefulsets services servicenetworkendpointgroups ingresses poddisruptionbudgets jobs cronjobs endpointslices persistentvolumes persistentvolumeclaims storageclasses horizontalpodautoscalers verticalpodautoscalers multidimpodautoscalers", " "),
+	"default": strings.Split("pods replicasets daemonsets nodes deployments namespaces statefulsets services servicenetworkendpointgroups ingresses poddisruptionbudgets jobs cronjobs endpointslices persistentvolumes persistentvolumeclaims volumeattachments storageclasses horizontalpodautoscalers verticalpodautoscalers multidimpodautoscalers", " "),
 }
 
 // InputKindFilterTask is a form task for inputting the kind filter.
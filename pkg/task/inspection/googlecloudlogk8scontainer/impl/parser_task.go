@@ -18,6 +18,7 @@ import (
 	"context"
 
 	inspectiontaskbase "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/taskbase"
+	coretask "github.com/GoogleCloudPlatform/khi/pkg/core/task"
 	"github.com/GoogleCloudPlatform/khi/pkg/core/task/taskid"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
@@ -43,14 +44,20 @@ var LogGrouperTask = inspectiontaskbase.NewLogGrouperTask(googlecloudlogk8sconta
 		return containerFields.ResourcePath().Path
 	})
 
-var HistoryModifierTask = inspectiontaskbase.NewHistoryModifierTask[struct{}](googlecloudlogk8scontainer_contract.HistoryModifierTaskID, &containerLogHistoryModifierSetting{},
-	inspectioncore_contract.FeatureTaskLabel(`Kubernetes container logs`,
-		`Gather stdout/stderr logs of containers on the cluster to visualize them on the timeline under an associated Pod. Log volume can be huge when the cluster has many Pods.`,
-		enum.LogTypeContainer,
-		4000,
-		false,
-		googlecloudinspectiontypegroup_contract.GCPK8sClusterInspectionTypes...),
-)
+var HistoryModifierTask = NewHistoryModifierTask(googlecloudlogk8scontainer_contract.HistoryModifierTaskID, googlecloudinspectiontypegroup_contract.GCPK8sClusterInspectionTypes...)
+
+// NewHistoryModifierTask returns the feature task associating container logs with their Pods on the timeline for the given inspection types.
+// Inspection types reading container logs from other sources than Cloud Logging use this with their own implementation of the ListLogEntries task.
+func NewHistoryModifierTask(taskID taskid.TaskImplementationID[struct{}], inspectionTypes ...string) coretask.Task[struct{}] {
+	return inspectiontaskbase.NewHistoryModifierTask[struct{}](taskID, &containerLogHistoryModifierSetting{},
+		inspectioncore_contract.FeatureTaskLabel(`Kubernetes container logs`,
+			`Gather stdout/stderr logs of containers on the cluster to visualize them on the timeline under an associated Pod. Log volume can be huge when the cluster has many Pods.`,
+			enum.LogTypeContainer,
+			4000,
+			false,
+			inspectionTypes...),
+	)
+}
 
 type containerLogHistoryModifierSetting struct {
 }
@@ -61,3 +61,8 @@ var CurrentIOConfig = typedmap.NewTypedKey[*IOConfig]("khi.google.com/inspection
 // CurrentHistoryBuilder is the context key to access the history builder instance
 // used for constructing timeline data during inspection execution.
 var CurrentHistoryBuilder = typedmap.NewTypedKey[*history.Builder]("khi.google.com/inspection/current-history-builder")
+
+// InspectionCheckpointFolder is the context key to access the folder storing checkpoints of the current inspection.
+// Tasks can store partial results there to reuse them when a failed or cancelled inspection is resumed.
+// The folder is removed when the inspection finishes successfully or is deleted.
+var InspectionCheckpointFolder = typedmap.NewTypedKey[string]("khi.google.com/inspection/checkpoint-folder")
@@ -19,6 +19,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
 	"github.com/GoogleCloudPlatform/khi/pkg/parameters"
 )
 
@@ -30,6 +31,8 @@ type IOConfig struct {
 	DataDestination string
 	// TemporaryFolder is the working folder for temporary files
 	TemporaryFolder string
+	// CompressionCodec is the codec to compress the binary chunks in khi files
+	CompressionCodec binarychunk.Codec
 }
 
 // NewIOConfigFromParameter creates an IOConfig from common parameters for production use.
@@ -43,6 +46,14 @@ func NewIOConfigFromParameter(commonParameter *parameters.CommonParameters) (*IO
 	if commonParameter.TemporaryFolder != nil {
 		temporaryFolder = *commonParameter.TemporaryFolder
 	}
+	compressionCodec := binarychunk.CodecGzip
+	if commonParameter.CompressionCodec != nil {
+		codec, err := binarychunk.ParseCodec(*commonParameter.CompressionCodec)
+		if err != nil {
+			return nil, err
+		}
+		compressionCodec = codec
+	}
 	dir, err := os.Getwd()
 	if err != nil {
 		return nil, err
@@ -54,9 +65,10 @@ func NewIOConfigFromParameter(commonParameter *parameters.CommonParameters) (*IO
 		temporaryFolder = filepath.Join(dir, temporaryFolder)
 	}
 	return &IOConfig{
-		ApplicationRoot: dir,
-		DataDestination: dataDestinationFolder,
-		TemporaryFolder: temporaryFolder,
+		ApplicationRoot:  dir,
+		DataDestination:  dataDestinationFolder,
+		TemporaryFolder:  temporaryFolder,
+		CompressionCodec: compressionCodec,
 	}, nil
 }
 
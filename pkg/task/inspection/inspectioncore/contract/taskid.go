@@ -23,3 +23,6 @@ import (
 var InspectionTimeTaskID = taskid.NewDefaultImplementationID[time.Time](InspectionTaskPrefix + "task/time")
 var TimeZoneShiftInputTaskID = taskid.NewDefaultImplementationID[*time.Location](InspectionTaskPrefix + "input-timezone-shift")
 var SerializerTaskID = taskid.NewDefaultImplementationID[*FileSystemStore](InspectionTaskPrefix + "serialize")
+
+// RedactingSerializerTaskID is the ID of the alternative serializer task redacting the inspection result before writing it.
+var RedactingSerializerTaskID = taskid.NewImplementationID(SerializerTaskID.Ref(), "redaction")
@@ -16,7 +16,10 @@ package inspectioncore_impl
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log/slog"
+	"os"
 	"path/filepath"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common/filter"
@@ -24,11 +27,56 @@ import (
 	"github.com/GoogleCloudPlatform/khi/pkg/common/typedmap"
 	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
 	inspectiontaskbase "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/taskbase"
+	coretask "github.com/GoogleCloudPlatform/khi/pkg/core/task"
 	"github.com/GoogleCloudPlatform/khi/pkg/core/task/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/khifile"
 	inspectioncore_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/contract"
 )
 
+// finalizeFunc writes the history built by the builder to the writer and returns the written size.
+type finalizeFunc = func(ctx context.Context, builder *history.Builder, resultMetadata map[string]any, writer io.Writer, progress *inspectionmetadata.TaskProgressMetadata) (int, error)
+
 var SerializeTask = inspectiontaskbase.NewProgressReportableInspectionTask(inspectioncore_contract.SerializerTaskID, []taskid.UntypedTaskReference{}, func(ctx context.Context, taskMode inspectioncore_contract.InspectionTaskModeType, progress *inspectionmetadata.TaskProgressMetadata) (*inspectioncore_contract.FileSystemStore, error) {
+	return serialize(ctx, taskMode, progress, func(ctx context.Context, builder *history.Builder, resultMetadata map[string]any, writer io.Writer, progress *inspectionmetadata.TaskProgressMetadata) (int, error) {
+		return builder.Finalize(ctx, resultMetadata, writer, progress)
+	})
+})
+
+// NewRedactingSerializeTask returns the serializer task applying the transformer to the data of the inspection result before writing it.
+// This task is selected instead of SerializeTask when it's registered.
+func NewRedactingSerializeTask(transformer khifile.Transformer) coretask.Task[*inspectioncore_contract.FileSystemStore] {
+	return inspectiontaskbase.NewProgressReportableInspectionTask(inspectioncore_contract.RedactingSerializerTaskID, []taskid.UntypedTaskReference{}, func(ctx context.Context, taskMode inspectioncore_contract.InspectionTaskModeType, progress *inspectionmetadata.TaskProgressMetadata) (*inspectioncore_contract.FileSystemStore, error) {
+		return serialize(ctx, taskMode, progress, func(ctx context.Context, builder *history.Builder, resultMetadata map[string]any, writer io.Writer, progress *inspectionmetadata.TaskProgressMetadata) (int, error) {
+			ioConfig := khictx.MustGetValue(ctx, inspectioncore_contract.CurrentIOConfig)
+			// The builder can only write the history in the final format. Write it to a temporary file once and rewrite it to the store.
+			tmpFile, err := os.CreateTemp(ioConfig.TemporaryFolder, "khi-*.khi")
+			if err != nil {
+				return 0, fmt.Errorf("failed to create a temporary file for redaction: %w", err)
+			}
+			defer os.Remove(tmpFile.Name())
+			_, err = builder.Finalize(ctx, resultMetadata, tmpFile, progress)
+			if closeErr := tmpFile.Close(); err == nil && closeErr != nil {
+				err = closeErr
+			}
+			if err != nil {
+				return 0, err
+			}
+			reader, err := khifile.Open(tmpFile.Name())
+			if err != nil {
+				return 0, err
+			}
+			defer reader.Close()
+			progress.MarkIndeterminate()
+			progress.Message = "Redacting the inspection result"
+			progress.NotifyChange()
+			return khifile.RewriteTo(ctx, writer, reader, transformer, ioConfig.TemporaryFolder)
+		})
+	}, coretask.WithSelectionPriority(1))
+}
+
+// serialize writes the inspection result to the file system store with the given finalize function.
+func serialize(ctx context.Context, taskMode inspectioncore_contract.InspectionTaskModeType, progress *inspectionmetadata.TaskProgressMetadata, finalize finalizeFunc) (*inspectioncore_contract.FileSystemStore, error) {
 	if taskMode == inspectioncore_contract.TaskModeDryRun {
 		slog.DebugContext(ctx, "Skipping because this is in dryrun mode")
 		return nil, nil
@@ -48,7 +96,7 @@ var SerializeTask = inspectiontaskbase.NewProgressReportableInspectionTask(inspe
 	if err != nil {
 		return nil, err
 	}
-	fileSize, err := builder.Finalize(ctx, resultMetadata, writer, progress)
+	fileSize, err := finalize(ctx, builder, resultMetadata, writer, progress)
 	if err != nil {
 		return nil, err
 	}
@@ -57,4 +105,4 @@ var SerializeTask = inspectiontaskbase.NewProgressReportableInspectionTask(inspe
 		header.FileSize = fileSize
 	}
 	return store, nil
-})
+}
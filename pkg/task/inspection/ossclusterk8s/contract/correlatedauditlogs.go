@@ -0,0 +1,29 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_contract
+
+import (
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	commonlogk8saudit_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/contract"
+)
+
+// CorrelatedAuditLogs is the audit logs correlated by auditID.
+type CorrelatedAuditLogs struct {
+	// Logs are the logs representing each request, sorted by timestamp.
+	// The log of the last stage is used for each request. Logs without auditID are included as they are.
+	Logs []*log.Log
+	// StageTimings is the map from auditID to the timing of the stages of the request.
+	StageTimings map[string]*commonlogk8saudit_contract.AuditLogStageTiming
+}
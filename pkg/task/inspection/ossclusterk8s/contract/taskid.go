@@ -19,6 +19,9 @@ import (
 	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
 	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
 	commonlogk8saudit_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/contract"
+	googlecloudlogk8scontainer_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/googlecloudlogk8scontainer/contract"
+	googlecloudlogk8scontrolplane_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/googlecloudlogk8scontrolplane/contract"
+	googlecloudlogk8snode_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/googlecloudlogk8snode/contract"
 )
 
 // OSSTaskPrefix is the prefixes of IDs used in OSS related tasks.
@@ -27,7 +30,41 @@ const OSSTaskPrefix = "khi.google.com/oss/"
 var OSSK8sAuditLogSourceTaskID = taskid.NewImplementationID(commonlogk8saudit_contract.CommonAuitLogSource, "oss")
 var InputAuditLogFilesFormTaskID = taskid.NewDefaultImplementationID[upload.UploadResult](OSSTaskPrefix + "form/kube-apiserver-audit-log-files")
 var AuditLogFileReaderTaskID = taskid.NewDefaultImplementationID[[]*log.Log](OSSTaskPrefix + "audit-log-reader")
+var AuditLogStageCorrelatorTaskID = taskid.NewDefaultImplementationID[*CorrelatedAuditLogs](OSSTaskPrefix + "audit-log-stage-correlator")
 var NonEventAuditLogFilterTaskID = taskid.NewDefaultImplementationID[[]*log.Log](OSSTaskPrefix + "audit-log-filter-non-event-audit")
 var EventAuditLogFilterTaskID = taskid.NewDefaultImplementationID[[]*log.Log](OSSTaskPrefix + "audit-log-filter-event-audit")
 var OSSK8sAuditLogParserTaskID = taskid.NewDefaultImplementationID[struct{}](OSSTaskPrefix + "audit-parser")
 var OSSK8sEventLogParserTaskID = taskid.NewDefaultImplementationID[struct{}](OSSTaskPrefix + "event-parser")
+
+// InputNodeLogFilesFormTaskID is the ID of the form task to upload journald JSON exports or syslog files of nodes.
+var InputNodeLogFilesFormTaskID = taskid.NewDefaultImplementationID[upload.UploadResult](OSSTaskPrefix + "form/node-log-files")
+
+// NodeLogFileReaderTaskID is the ID of the task reading node logs from the uploaded files.
+// This replaces the task querying node logs from Cloud Logging and the later node log parsers process the read logs.
+var NodeLogFileReaderTaskID = taskid.NewImplementationID(googlecloudlogk8snode_contract.ListLogEntriesTaskID.Ref(), "oss")
+
+// NodeLogFieldSetReaderTaskID is the ID of the task reading the field set used by node log parsers from the journald records.
+var NodeLogFieldSetReaderTaskID = taskid.NewImplementationID(googlecloudlogk8snode_contract.CommonFieldsetReaderTaskID.Ref(), "oss")
+
+// NodeLogTailTaskID is the ID of the feature task to parse node logs from the uploaded files.
+var NodeLogTailTaskID = taskid.NewImplementationID(googlecloudlogk8snode_contract.TailTaskID.Ref(), "oss")
+
+// InputControlPlaneLogFilesFormTaskID is the ID of the form task to upload log files of control plane components.
+var InputControlPlaneLogFilesFormTaskID = taskid.NewDefaultImplementationID[upload.UploadResult](OSSTaskPrefix + "form/control-plane-log-files")
+
+// ControlPlaneLogFileReaderTaskID is the ID of the task reading control plane component logs from the uploaded files.
+// This replaces the task querying control plane component logs from Cloud Logging and the later control plane component log parsers process the read logs.
+var ControlPlaneLogFileReaderTaskID = taskid.NewImplementationID(googlecloudlogk8scontrolplane_contract.ListLogEntriesTaskID.Ref(), "oss")
+
+// ControlPlaneLogTailTaskID is the ID of the feature task to parse control plane component logs from the uploaded files.
+var ControlPlaneLogTailTaskID = taskid.NewImplementationID(googlecloudlogk8scontrolplane_contract.TailTaskID.Ref(), "oss")
+
+// InputContainerLogFilesFormTaskID is the ID of the form task to upload container log files under /var/log/pods of nodes.
+var InputContainerLogFilesFormTaskID = taskid.NewDefaultImplementationID[upload.UploadResult](OSSTaskPrefix + "form/container-log-files")
+
+// ContainerLogFileReaderTaskID is the ID of the task reading container logs from the uploaded files.
+// This replaces the task querying container logs from Cloud Logging and the later container log parser processes the read logs.
+var ContainerLogFileReaderTaskID = taskid.NewImplementationID(googlecloudlogk8scontainer_contract.ListLogEntriesTaskID.Ref(), "oss")
+
+// ContainerLogHistoryModifierTaskID is the ID of the feature task to associate container logs read from the uploaded files with their Pods.
+var ContainerLogHistoryModifierTaskID = taskid.NewImplementationID(googlecloudlogk8scontainer_contract.HistoryModifierTaskID.Ref(), "oss")
@@ -15,24 +15,27 @@
 package ossclusterk8s_impl
 
 import (
+	"container/heap"
 	"context"
 	"fmt"
 	"io"
+	"os"
 	"slices"
 	"strings"
 
-	"github.com/GoogleCloudPlatform/khi/pkg/common/khictx"
-	"github.com/GoogleCloudPlatform/khi/pkg/common/typedmap"
 	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
-	"github.com/GoogleCloudPlatform/khi/pkg/core/inspection/progressutil"
 	inspectiontaskbase "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/taskbase"
 	coretask "github.com/GoogleCloudPlatform/khi/pkg/core/task"
 	"github.com/GoogleCloudPlatform/khi/pkg/core/task/taskid"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
 	inspectioncore_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/contract"
 	ossclusterk8s_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/ossclusterk8s/contract"
 )
 
+// progressReportIntervalLines is the count of lines read between progress updates.
+const progressReportIntervalLines = 1000
+
 var AuditLogFileReaderTask = inspectiontaskbase.NewProgressReportableInspectionTask(
 	ossclusterk8s_contract.AuditLogFileReaderTaskID,
 	[]taskid.UntypedTaskReference{
@@ -50,54 +53,181 @@ var AuditLogFileReaderTask = inspectiontaskbase.NewProgressReportableInspectionT
 		}
 		defer reader.Close()
 
-		logData, err := io.ReadAll(reader)
+		logs, err := readAuditLogFiles(ctx, reader, uploadedFileSize(reader), tp)
 		if err != nil {
 			return nil, err
 		}
 
-		logLines := strings.Split(string(logData), "\n")
-		var logs []*log.Log
+		extendInspectionTimeRange(ctx, logs)
+		return logs, nil
+	},
+)
 
-		progressutil.ReportProgressFromArraySync(tp, logLines, func(i int, line string) error {
-			if strings.TrimSpace(line) == "" {
-				return nil
+// uploadedFileSize returns the size of the uploaded file used to report the progress, or 0 when it's unknown.
+func uploadedFileSize(reader io.Reader) int64 {
+	file, ok := reader.(*os.File)
+	if !ok {
+		return 0
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		return 0
+	}
+	return stat.Size()
+}
+
+// readAuditLogFiles reads the audit logs from every file in the uploaded file line by line, and returns them merged and sorted by timestamp.
+// totalSize is the byte size of the uploaded file used to report the progress. The progress is reported as indeterminate when it's 0.
+func readAuditLogFiles(ctx context.Context, reader io.Reader, totalSize int64, tp *inspectionmetadata.TaskProgressMetadata) ([]*log.Log, error) {
+	counter := &countingReader{reader: reader}
+	if totalSize == 0 {
+		tp.MarkIndeterminate()
+	}
+	var logsPerFile [][]*log.Log
+	lineCount := 0
+	err := upload.WalkUploadedFiles(counter, func(name string, fileReader io.Reader) error {
+		logs := []*log.Log{}
+		scanner := upload.NewLineScanner(fileReader, maxAuditLogLineSizeInBytes)
+		lineNumber := 0
+		for scanner.Scan() {
+			lineNumber++
+			lineCount++
+			if lineCount%progressReportIntervalLines == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				reportReadProgress(tp, counter.count, totalSize, lineCount)
 			}
-
-			l, err := log.NewLogFromYAMLString(line)
-			if err != nil {
-				return fmt.Errorf("failed to read a log: %w", err)
+			line := scanner.Text()
+			if strings.TrimSpace(line) == "" {
+				continue
 			}
-
-			err = l.SetFieldSetReader(&OSSK8sAuditLogCommonFieldSetReader{})
+			l, err := parseAuditLogLine(line)
 			if err != nil {
-				return err
-			}
-
-			// TODO: we may need to consider processing logs not with ResponseComplete stage. All logs not on the ResponseComplete stage will be ignored for now.
-			if l.ReadStringOrDefault("stage", "") != "ResponseComplete" {
-				return nil
+				return fmt.Errorf("failed to read a log at line %d of %s: %w", lineNumber, fileDisplayName(name), err)
 			}
-
 			logs = append(logs, l)
-			return nil
-		})
-
-		slices.SortFunc(logs, func(a, b *log.Log) int {
-			logACommonField := log.MustGetFieldSet(a, &log.CommonFieldSet{})
-			logBCommonField := log.MustGetFieldSet(b, &log.CommonFieldSet{})
-			return int(logACommonField.Timestamp.UnixNano() - logBCommonField.Timestamp.UnixNano())
-		})
-		metadataSet := khictx.MustGetValue(ctx, inspectioncore_contract.InspectionRunMetadata)
-		header := typedmap.GetOrDefault(metadataSet, inspectionmetadata.HeaderMetadataKey, &inspectionmetadata.HeaderMetadata{})
-
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read %s: %w", fileDisplayName(name), err)
+		}
+		// Audit logs in a file are mostly sorted already. Stable sort keeps the order of logs with the same timestamp.
+		slices.SortStableFunc(logs, compareLogTimestamp)
+		logsPerFile = append(logsPerFile, logs)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergeSortedLogs(logsPerFile), nil
+}
+
+// parseAuditLogLine parses a line of the audit log file. Logs of all stages are returned and correlated later by AuditLogStageCorrelatorTask.
+func parseAuditLogLine(line string) (*log.Log, error) {
+	l, err := log.NewLogFromYAMLString(line)
+	if err != nil {
+		return nil, err
+	}
+
+	err = l.SetFieldSetReader(&OSSK8sAuditLogCommonFieldSetReader{})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func reportReadProgress(tp *inspectionmetadata.TaskProgressMetadata, readBytes int64, totalSize int64, lineCount int) {
+	if totalSize == 0 {
+		tp.Message = fmt.Sprintf("%d lines", lineCount)
+		tp.NotifyChange()
+		return
+	}
+	tp.Update(float32(readBytes)/float32(totalSize), fmt.Sprintf("%d lines (%d/%d MiB)", lineCount, readBytes/1024/1024, totalSize/1024/1024))
+}
+
+func fileDisplayName(name string) string {
+	if name == "" {
+		return "the uploaded file"
+	}
+	return name
+}
+
+func compareLogTimestamp(a, b *log.Log) int {
+	logACommonField := log.MustGetFieldSet(a, &log.CommonFieldSet{})
+	logBCommonField := log.MustGetFieldSet(b, &log.CommonFieldSet{})
+	return logACommonField.Timestamp.Compare(logBCommonField.Timestamp)
+}
+
+// mergeSortedLogs merges the lists of logs sorted by timestamp into a sorted list.
+// Logs with the same timestamp are ordered by the index of the list they belong to.
+func mergeSortedLogs(sortedLogs [][]*log.Log) []*log.Log {
+	totalCount := 0
+	h := &logListHeap{}
+	for i, logs := range sortedLogs {
+		totalCount += len(logs)
 		if len(logs) > 0 {
-			startLogCommonField := log.MustGetFieldSet(logs[0], &log.CommonFieldSet{})
-			lastLogCommonField := log.MustGetFieldSet(logs[len(logs)-1], &log.CommonFieldSet{})
-
-			header.StartTimeUnixSeconds = startLogCommonField.Timestamp.Unix()
-			header.EndTimeUnixSeconds = lastLogCommonField.Timestamp.Unix()
+			*h = append(*h, &logListCursor{logs: logs, listIndex: i})
 		}
-
-		return logs, nil
-	},
-)
+	}
+	heap.Init(h)
+	result := make([]*log.Log, 0, totalCount)
+	for h.Len() > 0 {
+		cursor := (*h)[0]
+		result = append(result, cursor.logs[cursor.position])
+		cursor.position++
+		if cursor.position < len(cursor.logs) {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+	return result
+}
+
+// logListCursor is the position of the next log to be merged in a sorted list of logs.
+type logListCursor struct {
+	logs      []*log.Log
+	listIndex int
+	position  int
+}
+
+// logListHeap is a min-heap of logListCursor ordered by the timestamp of the next log.
+type logListHeap []*logListCursor
+
+// Len implements heap.Interface.
+func (h logListHeap) Len() int { return len(h) }
+
+// Less implements heap.Interface.
+func (h logListHeap) Less(i, j int) bool {
+	if c := compareLogTimestamp(h[i].logs[h[i].position], h[j].logs[h[j].position]); c != 0 {
+		return c < 0
+	}
+	return h[i].listIndex < h[j].listIndex
+}
+
+// Swap implements heap.Interface.
+func (h logListHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+// Push implements heap.Interface.
+func (h *logListHeap) Push(x any) { *h = append(*h, x.(*logListCursor)) }
+
+// Pop implements heap.Interface.
+func (h *logListHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// countingReader counts the bytes read from the underlying reader.
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+// Read implements io.Reader.
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.count += int64(n)
+	return n, err
+}
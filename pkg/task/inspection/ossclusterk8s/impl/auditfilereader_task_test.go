@@ -0,0 +1,166 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	"github.com/google/go-cmp/cmp"
+)
+
+func auditLogLine(auditID string, stage string, timestamp string) string {
+	return fmt.Sprintf(`{"auditID":"%s","stage":"%s","stageTimestamp":"%s"}`, auditID, stage, timestamp)
+}
+
+func tarGzipArchive(t *testing.T, files map[string]string, names []string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, name := range names {
+		content := files[name]
+		if err := tarWriter.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Size: int64(len(content)), Mode: 0600}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func auditIDs(logs []*log.Log) []string {
+	result := []string{}
+	for _, l := range logs {
+		result = append(result, l.ReadStringOrDefault("auditID", ""))
+	}
+	return result
+}
+
+func TestReadAuditLogFiles(t *testing.T) {
+	files := map[string]string{
+		"kube-apiserver-1/audit.log": strings.Join([]string{
+			auditLogLine("a1", "ResponseComplete", "2025-01-01T00:00:01Z"),
+			auditLogLine("a3", "ResponseComplete", "2025-01-01T00:00:03Z"),
+			"",
+			auditLogLine("a2", "ResponseComplete", "2025-01-01T00:00:02Z"),
+		}, "\n"),
+		"kube-apiserver-2/audit.log": strings.Join([]string{
+			auditLogLine("b0", "RequestReceived", "2025-01-01T00:00:00Z"),
+			auditLogLine("b2", "ResponseComplete", "2025-01-01T00:00:02Z"),
+			auditLogLine("b4", "ResponseComplete", "2025-01-01T00:00:04Z"),
+		}, "\n"),
+	}
+	archive := tarGzipArchive(t, files, []string{"kube-apiserver-1/audit.log", "kube-apiserver-2/audit.log"})
+
+	testCases := []struct {
+		name      string
+		totalSize int64
+	}{
+		{name: "with known size", totalSize: int64(len(archive))},
+		{name: "with unknown size", totalSize: 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logs, err := readAuditLogFiles(context.Background(), bytes.NewReader(archive), tc.totalSize, inspectionmetadata.NewTaskProgressMetadata("test"))
+			if err != nil {
+				t.Fatalf("readAuditLogFiles() returned an unexpected error: %v", err)
+			}
+			want := []string{"b0", "a1", "a2", "b2", "a3", "b4"}
+			if diff := cmp.Diff(want, auditIDs(logs)); diff != "" {
+				t.Errorf("readAuditLogFiles() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReadAuditLogFiles_InvalidLine(t *testing.T) {
+	files := map[string]string{
+		"audit.log": auditLogLine("a1", "ResponseComplete", "2025-01-01T00:00:01Z") + "\n{invalid",
+	}
+	_, err := readAuditLogFiles(context.Background(), bytes.NewReader(tarGzipArchive(t, files, []string{"audit.log"})), 0, inspectionmetadata.NewTaskProgressMetadata("test"))
+	wantErr := "failed to read a log at line 2 of audit.log"
+	if err == nil || !strings.Contains(err.Error(), wantErr) {
+		t.Errorf("readAuditLogFiles() returned %v, want an error containing %q", err, wantErr)
+	}
+}
+
+func TestMergeSortedLogs(t *testing.T) {
+	newLogs := func(t *testing.T, entries ...string) []*log.Log {
+		result := []*log.Log{}
+		for i := 0; i < len(entries); i += 2 {
+			l, err := parseAuditLogLine(auditLogLine(entries[i], "ResponseComplete", entries[i+1]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result = append(result, l)
+		}
+		return result
+	}
+	testCases := []struct {
+		name  string
+		input func(t *testing.T) [][]*log.Log
+		want  []string
+	}{
+		{
+			name:  "no list",
+			input: func(t *testing.T) [][]*log.Log { return nil },
+			want:  []string{},
+		},
+		{
+			name: "lists with empty list",
+			input: func(t *testing.T) [][]*log.Log {
+				return [][]*log.Log{
+					{},
+					newLogs(t, "a1", "2025-01-01T00:00:01Z", "a2", "2025-01-01T00:00:02Z"),
+				}
+			},
+			want: []string{"a1", "a2"},
+		},
+		{
+			name: "interleaved lists with the same timestamps",
+			input: func(t *testing.T) [][]*log.Log {
+				return [][]*log.Log{
+					newLogs(t, "a1", "2025-01-01T00:00:01Z", "a3", "2025-01-01T00:00:03Z"),
+					newLogs(t, "b1", "2025-01-01T00:00:01Z", "b2", "2025-01-01T00:00:02Z", "b5", "2025-01-01T00:00:05Z"),
+					newLogs(t, "c0", "2025-01-01T00:00:00Z", "c3", "2025-01-01T00:00:03Z"),
+				}
+			},
+			want: []string{"c0", "a1", "b1", "b2", "a3", "c3", "b5"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := auditIDs(mergeSortedLogs(tc.input(t)))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("mergeSortedLogs() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
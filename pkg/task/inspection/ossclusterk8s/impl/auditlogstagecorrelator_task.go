@@ -0,0 +1,108 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"context"
+	"slices"
+
+	inspectiontaskbase "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/taskbase"
+	coretask "github.com/GoogleCloudPlatform/khi/pkg/core/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/core/task/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	commonlogk8saudit_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/contract"
+	inspectioncore_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/contract"
+	ossclusterk8s_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/ossclusterk8s/contract"
+)
+
+// AuditLogStageCorrelatorTask correlates the audit logs of the different stages by auditID.
+// Only the log of the last stage is passed to the later tasks for each request, with the timing of the stages.
+var AuditLogStageCorrelatorTask = inspectiontaskbase.NewInspectionTask(
+	ossclusterk8s_contract.AuditLogStageCorrelatorTaskID,
+	[]taskid.UntypedTaskReference{
+		ossclusterk8s_contract.AuditLogFileReaderTaskID.Ref(),
+	}, func(ctx context.Context, taskMode inspectioncore_contract.InspectionTaskModeType) (*ossclusterk8s_contract.CorrelatedAuditLogs, error) {
+		if taskMode == inspectioncore_contract.TaskModeDryRun {
+			return &ossclusterk8s_contract.CorrelatedAuditLogs{
+				Logs:         []*log.Log{},
+				StageTimings: map[string]*commonlogk8saudit_contract.AuditLogStageTiming{},
+			}, nil
+		}
+		logs := coretask.GetTaskResult(ctx, ossclusterk8s_contract.AuditLogFileReaderTaskID.Ref())
+		return correlateAuditLogStages(logs), nil
+	})
+
+// auditLogStageOrder returns the order of the stage in a request. Logs of the later stage are preferred to represent the request.
+func auditLogStageOrder(stage commonlogk8saudit_contract.AuditLogStage) int {
+	switch stage {
+	case commonlogk8saudit_contract.AuditLogStageRequestReceived:
+		return 1
+	case commonlogk8saudit_contract.AuditLogStageResponseStarted:
+		return 2
+	case commonlogk8saudit_contract.AuditLogStageResponseComplete:
+		return 3
+	case commonlogk8saudit_contract.AuditLogStagePanic:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// correlateAuditLogStages groups the logs sorted by timestamp with their auditID and returns the log of the last stage for each request.
+func correlateAuditLogStages(logs []*log.Log) *ossclusterk8s_contract.CorrelatedAuditLogs {
+	timings := map[string]*commonlogk8saudit_contract.AuditLogStageTiming{}
+	representativeIndices := map[string]int{}
+	result := []*log.Log{}
+	for _, l := range logs {
+		auditID := l.ReadStringOrDefault("auditID", "")
+		if auditID == "" {
+			result = append(result, l)
+			continue
+		}
+		stage := commonlogk8saudit_contract.AuditLogStage(l.ReadStringOrDefault("stage", ""))
+		timestamp := log.MustGetFieldSet(l, &log.CommonFieldSet{}).Timestamp
+
+		timing, found := timings[auditID]
+		if !found {
+			timing = &commonlogk8saudit_contract.AuditLogStageTiming{Stage: stage}
+			timings[auditID] = timing
+		}
+		switch stage {
+		case commonlogk8saudit_contract.AuditLogStageRequestReceived:
+			timing.RequestReceivedTime = timestamp
+		case commonlogk8saudit_contract.AuditLogStageResponseStarted:
+			timing.ResponseStartedTime = timestamp
+		case commonlogk8saudit_contract.AuditLogStageResponseComplete, commonlogk8saudit_contract.AuditLogStagePanic:
+			timing.FinishedTime = timestamp
+		}
+
+		index, found := representativeIndices[auditID]
+		if !found {
+			representativeIndices[auditID] = len(result)
+			result = append(result, l)
+			continue
+		}
+		if auditLogStageOrder(stage) >= auditLogStageOrder(timing.Stage) {
+			result[index] = l
+			timing.Stage = stage
+		}
+	}
+	// The representative log can be later than the logs of other requests placed after the first log of the request.
+	slices.SortStableFunc(result, compareLogTimestamp)
+	return &ossclusterk8s_contract.CorrelatedAuditLogs{
+		Logs:         result,
+		StageTimings: timings,
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	commonlogk8saudit_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/contract"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCorrelateAuditLogStages(t *testing.T) {
+	lines := []string{
+		auditLogLine("completed", "RequestReceived", "2025-01-01T00:00:00Z"),
+		auditLogLine("exec", "RequestReceived", "2025-01-01T00:00:01Z"),
+		auditLogLine("exec", "ResponseStarted", "2025-01-01T00:00:02Z"),
+		auditLogLine("completed", "ResponseComplete", "2025-01-01T00:00:03Z"),
+		`{"stage":"ResponseComplete","stageTimestamp":"2025-01-01T00:00:04Z"}`,
+		auditLogLine("panicked", "RequestReceived", "2025-01-01T00:00:05Z"),
+		auditLogLine("panicked", "Panic", "2025-01-01T00:00:06Z"),
+		auditLogLine("only-completed", "ResponseComplete", "2025-01-01T00:00:07Z"),
+	}
+	logs := []*log.Log{}
+	for _, line := range lines {
+		l, err := parseAuditLogLine(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logs = append(logs, l)
+	}
+	timestamp := func(second int) time.Time {
+		return time.Date(2025, 1, 1, 0, 0, second, 0, time.UTC)
+	}
+
+	result := correlateAuditLogStages(logs)
+
+	gotLogs := []string{}
+	for _, l := range result.Logs {
+		gotLogs = append(gotLogs, l.ReadStringOrDefault("auditID", "")+"/"+l.ReadStringOrDefault("stage", ""))
+	}
+	wantLogs := []string{"exec/ResponseStarted", "completed/ResponseComplete", "/ResponseComplete", "panicked/Panic", "only-completed/ResponseComplete"}
+	if diff := cmp.Diff(wantLogs, gotLogs); diff != "" {
+		t.Errorf("correlateAuditLogStages().Logs mismatch (-want +got):\n%s", diff)
+	}
+	wantTimings := map[string]*commonlogk8saudit_contract.AuditLogStageTiming{
+		"completed": {
+			Stage:               commonlogk8saudit_contract.AuditLogStageResponseComplete,
+			RequestReceivedTime: timestamp(0),
+			FinishedTime:        timestamp(3),
+		},
+		"exec": {
+			Stage:               commonlogk8saudit_contract.AuditLogStageResponseStarted,
+			RequestReceivedTime: timestamp(1),
+			ResponseStartedTime: timestamp(2),
+		},
+		"panicked": {
+			Stage:               commonlogk8saudit_contract.AuditLogStagePanic,
+			RequestReceivedTime: timestamp(5),
+			FinishedTime:        timestamp(6),
+		},
+		"only-completed": {
+			Stage:        commonlogk8saudit_contract.AuditLogStageResponseComplete,
+			FinishedTime: timestamp(7),
+		},
+	}
+	if diff := cmp.Diff(wantTimings, result.StageTimings); diff != "" {
+		t.Errorf("correlateAuditLogStages().StageTimings mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structured"
+	"github.com/GoogleCloudPlatform/khi/pkg/core/inspection/gcpqueryutil"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+)
+
+// containerLogFileNamePattern matches the names of container log files written by kubelet. The number is the restart count of the container.
+// Rotated files have the suffix of the rotation time (e.g. `0.log.20250101-000000`).
+var containerLogFileNamePattern = regexp.MustCompile(`^[0-9]+\.log(\..+)?$`)
+
+// containerLogSource is the container writing a container log file.
+type containerLogSource struct {
+	Namespace     string
+	PodName       string
+	PodUID        string
+	ContainerName string
+}
+
+// containerLogSourceFromPath returns the container writing the log file at the given path.
+// The path must end with the layout of kubelet log directory `<namespace>_<pod name>_<pod uid>/<container name>/<restart count>.log`.
+// See https://github.com/kubernetes/kubernetes/blob/master/pkg/kubelet/kuberuntime/helpers.go
+func containerLogSourceFromPath(filePath string) (*containerLogSource, error) {
+	segments := strings.Split(strings.Trim(path.Clean(filePath), "/"), "/")
+	if len(segments) < 3 {
+		return nil, fmt.Errorf("the path %s doesn't follow the layout `<namespace>_<pod name>_<pod uid>/<container name>/<restart count>.log`", filePath)
+	}
+	fileName := segments[len(segments)-1]
+	containerName := segments[len(segments)-2]
+	podDirectory := segments[len(segments)-3]
+	if !containerLogFileNamePattern.MatchString(fileName) {
+		return nil, fmt.Errorf("the file name of %s is not a container log file name `<restart count>.log`", filePath)
+	}
+	// Namespace and Pod names can't contain underscores.
+	podFields := strings.Split(podDirectory, "_")
+	if len(podFields) != 3 || podFields[0] == "" || podFields[1] == "" || podFields[2] == "" || containerName == "" {
+		return nil, fmt.Errorf("the directory of %s doesn't follow the layout `<namespace>_<pod name>_<pod uid>/<container name>`", filePath)
+	}
+	return &containerLogSource{
+		Namespace:     podFields[0],
+		PodName:       podFields[1],
+		PodUID:        podFields[2],
+		ContainerName: containerName,
+	}, nil
+}
+
+// containerLogParser converts full lines of container log files into logs.
+// Logs are converted to the same structure of container logs on Cloud Logging to be processed by the container log parser.
+type containerLogParser struct{}
+
+// ParseLine converts a full line written by the given container into a log.
+// Content in a JSON object is stored as jsonPayload like the logging agent of GKE, otherwise it's stored as textPayload.
+func (p *containerLogParser) ParseLine(source *containerLogSource, line *criLogLine) (*log.Log, error) {
+	record := map[string]any{
+		"timestamp": line.Timestamp.UTC().Format(time.RFC3339Nano),
+		"severity":  containerLogStreamToGCPSeverity(line.Stream),
+		"resource": map[string]any{
+			"type": "k8s_container",
+			"labels": map[string]any{
+				"namespace_name": source.Namespace,
+				"pod_name":       source.PodName,
+				"container_name": source.ContainerName,
+			},
+		},
+	}
+	var jsonPayload map[string]any
+	if strings.HasPrefix(line.Content, "{") && json.Unmarshal([]byte(line.Content), &jsonPayload) == nil {
+		record["jsonPayload"] = jsonPayload
+	} else {
+		record["textPayload"] = line.Content
+	}
+	node, err := structured.FromGoValue(record, &structured.AlphabeticalGoMapKeyOrderProvider{})
+	if err != nil {
+		return nil, err
+	}
+	l := log.NewLog(structured.NewNodeReader(node))
+	l.LogType = enum.LogTypeContainer
+	if err := l.SetFieldSetReader(&gcpqueryutil.GCPCommonFieldSetReader{}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// containerLogStreamToGCPSeverity returns the severity of a container log line from its stream in the same way as the logging agent of GKE.
+func containerLogStreamToGCPSeverity(stream string) string {
+	if stream == "stderr" {
+		return "ERROR"
+	}
+	return "INFO"
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	googlecloudlogk8scontainer_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/googlecloudlogk8scontainer/contract"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestContainerLogSourceFromPath(t *testing.T) {
+	testCases := []struct {
+		path    string
+		want    *containerLogSource
+		wantErr bool
+	}{
+		{
+			path: "var/log/pods/default_nginx-7c5ddbdf54-abcde_0123-4567/nginx/0.log",
+			want: &containerLogSource{Namespace: "default", PodName: "nginx-7c5ddbdf54-abcde", PodUID: "0123-4567", ContainerName: "nginx"},
+		},
+		{
+			path: "/var/log/pods/kube-system_coredns-1_89ab/coredns/3.log.20250101-000000",
+			want: &containerLogSource{Namespace: "kube-system", PodName: "coredns-1", PodUID: "89ab", ContainerName: "coredns"},
+		},
+		{
+			path: "default_nginx_0123/nginx/0.log",
+			want: &containerLogSource{Namespace: "default", PodName: "nginx", PodUID: "0123", ContainerName: "nginx"},
+		},
+		{
+			path:    "var/log/containers/nginx_default_nginx-0123.log",
+			wantErr: true,
+		},
+		{
+			path:    "var/log/pods/default_nginx_0123/nginx/container.log",
+			wantErr: true,
+		},
+		{
+			path:    "var/log/pods/nginx_0123/nginx/0.log",
+			wantErr: true,
+		},
+		{
+			path:    "0.log",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			got, err := containerLogSourceFromPath(tc.path)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("containerLogSourceFromPath(%q) returned no error, want an error", tc.path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("containerLogSourceFromPath(%q) returned an unexpected error: %v", tc.path, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("containerLogSourceFromPath(%q) mismatch (-want +got):\n%s", tc.path, diff)
+			}
+		})
+	}
+}
+
+func TestContainerLogParser_ParseLine(t *testing.T) {
+	source := &containerLogSource{Namespace: "default", PodName: "nginx", PodUID: "0123", ContainerName: "nginx"}
+	testCases := []struct {
+		name         string
+		line         *criLogLine
+		wantCommon   *log.CommonFieldSet
+		wantMessage  string
+		wantJSONKind bool
+	}{
+		{
+			name: "stdout text line",
+			line: &criLogLine{Timestamp: time.Date(2025, time.January, 1, 0, 0, 1, 123456789, time.UTC), Stream: "stdout", Content: "GET / 200"},
+			wantCommon: &log.CommonFieldSet{
+				Timestamp: time.Date(2025, time.January, 1, 0, 0, 1, 123456789, time.UTC),
+				Severity:  enum.SeverityInfo,
+				DisplayID: "unknown",
+			},
+			wantMessage: "GET / 200",
+		},
+		{
+			name: "stderr text line",
+			line: &criLogLine{Timestamp: time.Date(2025, time.January, 1, 0, 0, 2, 0, time.UTC), Stream: "stderr", Content: "connection refused"},
+			wantCommon: &log.CommonFieldSet{
+				Timestamp: time.Date(2025, time.January, 1, 0, 0, 2, 0, time.UTC),
+				Severity:  enum.SeverityError,
+				DisplayID: "unknown",
+			},
+			wantMessage: "connection refused",
+		},
+		{
+			name: "JSON line",
+			line: &criLogLine{Timestamp: time.Date(2025, time.January, 1, 0, 0, 3, 0, time.UTC), Stream: "stdout", Content: `{"msg":"started","port":8080}`},
+			wantCommon: &log.CommonFieldSet{
+				Timestamp: time.Date(2025, time.January, 1, 0, 0, 3, 0, time.UTC),
+				Severity:  enum.SeverityInfo,
+				DisplayID: "unknown",
+			},
+			wantMessage:  "started",
+			wantJSONKind: true,
+		},
+		{
+			name: "line starting with a brace but not a JSON",
+			line: &criLogLine{Timestamp: time.Date(2025, time.January, 1, 0, 0, 4, 0, time.UTC), Stream: "stdout", Content: `{not json`},
+			wantCommon: &log.CommonFieldSet{
+				Timestamp: time.Date(2025, time.January, 1, 0, 0, 4, 0, time.UTC),
+				Severity:  enum.SeverityInfo,
+				DisplayID: "unknown",
+			},
+			wantMessage: `{not json`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := &containerLogParser{}
+			l, err := parser.ParseLine(source, tc.line)
+			if err != nil {
+				t.Fatalf("ParseLine() returned an unexpected error: %v", err)
+			}
+			if l.LogType != enum.LogTypeContainer {
+				t.Errorf("LogType = %v, want %v", l.LogType, enum.LogTypeContainer)
+			}
+			if diff := cmp.Diff(tc.wantCommon, log.MustGetFieldSet(l, &log.CommonFieldSet{})); diff != "" {
+				t.Errorf("CommonFieldSet mismatch (-want +got):\n%s", diff)
+			}
+			if got := l.Has("jsonPayload"); got != tc.wantJSONKind {
+				t.Errorf("Has(jsonPayload) = %v, want %v", got, tc.wantJSONKind)
+			}
+
+			err = l.SetFieldSetReader(&googlecloudlogk8scontainer_contract.K8sContainerLogFieldSetReader{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := &googlecloudlogk8scontainer_contract.K8sContainerLogFieldSet{
+				Namespace:     "default",
+				PodName:       "nginx",
+				ContainerName: "nginx",
+				Message:       tc.wantMessage,
+			}
+			if diff := cmp.Diff(want, log.MustGetFieldSet(l, &googlecloudlogk8scontainer_contract.K8sContainerLogFieldSet{})); diff != "" {
+				t.Errorf("K8sContainerLogFieldSet mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
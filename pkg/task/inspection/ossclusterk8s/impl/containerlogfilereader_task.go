@@ -0,0 +1,180 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/core/inspection/formtask"
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	inspectiontaskbase "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/taskbase"
+	coretask "github.com/GoogleCloudPlatform/khi/pkg/core/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/core/task/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
+	googlecloudlogk8scontainer_impl "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/googlecloudlogk8scontainer/impl"
+	inspectioncore_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/contract"
+	ossclusterk8s_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/ossclusterk8s/contract"
+)
+
+// maxContainerLogLineSizeInBytes is the maximum size of a line in the uploaded container log files.
+// Long lines are split into partial lines by container runtimes, thus a line in the file is small.
+const maxContainerLogLineSizeInBytes = 1024 * 1024
+
+var InputContainerLogFilesTask = formtask.NewFileFormTaskBuilder(ossclusterk8s_contract.InputContainerLogFilesFormTaskID, 700, "Container Log Files", &containerLogUploadFileVerifier{}).
+	WithDescription("Upload container log files written by kubelet under /var/log/pods of nodes (e.g. a tar.gz archive of /var/log/pods). The namespace, Pod and container are decided from the file path `<namespace>_<pod name>_<pod uid>/<container name>/<restart count>.log`, thus keep the directory structure in the archive. Rotated and gzip compressed files are also accepted.").
+	Build()
+
+// ContainerLogFileReaderTask reads container logs from the uploaded files in place of the task querying them from Cloud Logging.
+var ContainerLogFileReaderTask = inspectiontaskbase.NewProgressReportableInspectionTask(
+	ossclusterk8s_contract.ContainerLogFileReaderTaskID,
+	[]taskid.UntypedTaskReference{
+		ossclusterk8s_contract.InputContainerLogFilesFormTaskID.Ref(),
+	},
+	func(ctx context.Context, taskMode inspectioncore_contract.InspectionTaskModeType, tp *inspectionmetadata.TaskProgressMetadata) ([]*log.Log, error) {
+		if taskMode == inspectioncore_contract.TaskModeDryRun {
+			return []*log.Log{}, nil
+		}
+		result := coretask.GetTaskResult(ctx, ossclusterk8s_contract.InputContainerLogFilesFormTaskID.Ref())
+
+		reader, err := result.GetReader()
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+
+		logs, err := readContainerLogFiles(ctx, reader, uploadedFileSize(reader), &containerLogParser{}, tp)
+		if err != nil {
+			return nil, err
+		}
+		extendInspectionTimeRange(ctx, logs)
+		return logs, nil
+	},
+	inspectioncore_contract.InspectionTypeLabel(ossclusterk8s_contract.InspectionTypeID),
+	coretask.WithSelectionPriority(1),
+)
+
+// ContainerLogHistoryModifierTask is the feature task to associate container logs read from the uploaded files with their Pods.
+var ContainerLogHistoryModifierTask = googlecloudlogk8scontainer_impl.NewHistoryModifierTask(ossclusterk8s_contract.ContainerLogHistoryModifierTaskID, ossclusterk8s_contract.InspectionTypeID)
+
+// readContainerLogFiles reads the container logs from every file in the uploaded file line by line, and returns them merged and sorted by timestamp.
+// Partial lines written by the container runtime are concatenated into a log. Lines not in the CRI log format are skipped.
+func readContainerLogFiles(ctx context.Context, reader io.Reader, totalSize int64, parser *containerLogParser, tp *inspectionmetadata.TaskProgressMetadata) ([]*log.Log, error) {
+	counter := &countingReader{reader: reader}
+	if totalSize == 0 {
+		tp.MarkIndeterminate()
+	}
+	var logsPerFile [][]*log.Log
+	lineCount := 0
+	err := upload.WalkUploadedFiles(counter, func(name string, fileReader io.Reader) error {
+		source, err := containerLogSourceFromPath(name)
+		if err != nil {
+			return err
+		}
+		logs := []*log.Log{}
+		addLog := func(line *criLogLine) error {
+			l, err := parser.ParseLine(source, line)
+			if err != nil {
+				return fmt.Errorf("failed to convert a line in %s to a log: %w", fileDisplayName(name), err)
+			}
+			logs = append(logs, l)
+			return nil
+		}
+		skippedLineCount := 0
+		assembler := newCRILogLineAssembler()
+		scanner := upload.NewLineScanner(fileReader, maxContainerLogLineSizeInBytes)
+		for scanner.Scan() {
+			lineCount++
+			if lineCount%progressReportIntervalLines == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				reportReadProgress(tp, counter.count, totalSize, lineCount)
+			}
+			line := scanner.Text()
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			criLine, err := parseCRILogLine(line)
+			if err != nil {
+				skippedLineCount++
+				continue
+			}
+			if fullLine := assembler.Add(criLine); fullLine != nil {
+				if err := addLog(fullLine); err != nil {
+					return err
+				}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read %s: %w", fileDisplayName(name), err)
+		}
+		for _, fullLine := range assembler.Flush() {
+			if err := addLog(fullLine); err != nil {
+				return err
+			}
+		}
+		if skippedLineCount > 0 {
+			slog.WarnContext(ctx, fmt.Sprintf("%d lines in %s were skipped because they were not in the CRI log format", skippedLineCount, fileDisplayName(name)))
+		}
+		slices.SortStableFunc(logs, compareLogTimestamp)
+		logsPerFile = append(logsPerFile, logs)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergeSortedLogs(logsPerFile), nil
+}
+
+// containerLogUploadFileVerifier verifies every uploaded file is placed in the layout of kubelet log directory and its first non empty line is in the CRI log format. Empty files are accepted.
+type containerLogUploadFileVerifier struct{}
+
+// Verify implements upload.UploadFileVerifier.
+func (c *containerLogUploadFileVerifier) Verify(storeProvider upload.UploadFileStoreProvider, token upload.UploadToken) error {
+	reader, err := storeProvider.Read(token)
+	if err != nil {
+		return fmt.Errorf("failed to read the uploded file")
+	}
+	defer reader.Close()
+
+	return upload.WalkUploadedFiles(reader, func(name string, fileReader io.Reader) error {
+		if _, err := containerLogSourceFromPath(name); err != nil {
+			return err
+		}
+		scanner := upload.NewLineScanner(fileReader, maxContainerLogLineSizeInBytes)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if _, err := parseCRILogLine(line); err != nil {
+				return fmt.Errorf("%s is not a container log file: %w", fileDisplayName(name), err)
+			}
+			return nil
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read %s: %w", fileDisplayName(name), err)
+		}
+		return nil
+	})
+}
+
+var _ upload.UploadFileVerifier = (*containerLogUploadFileVerifier)(nil)
@@ -0,0 +1,76 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadContainerLogFiles(t *testing.T) {
+	nginxLogPath := "var/log/pods/default_nginx_0123/nginx/0.log"
+	sidecarLogPath := "var/log/pods/default_nginx_0123/sidecar/1.log"
+	files := map[string]string{
+		nginxLogPath: strings.Join([]string{
+			`2025-01-01T00:00:00.000000000Z stdout F nginx-0`,
+			`2025-01-01T00:00:02.000000000Z stdout P nginx`,
+			`2025-01-01T00:00:02.500000000Z stderr F nginx-error-3`,
+			`2025-01-01T00:00:02.600000000Z stdout F -2`,
+			`not a CRI line`,
+			`2025-01-01T00:00:05.000000000Z stdout P nginx-incomplete-5`,
+		}, "\n"),
+		sidecarLogPath: strings.Join([]string{
+			`2025-01-01T00:00:01.000000000Z stdout F sidecar-1`,
+			`2025-01-01T00:00:04.000000000Z stderr F sidecar-4`,
+		}, "\n"),
+	}
+	archive := tarGzipArchive(t, files, []string{nginxLogPath, sidecarLogPath})
+
+	logs, err := readContainerLogFiles(context.Background(), bytes.NewReader(archive), int64(len(archive)), &containerLogParser{}, inspectionmetadata.NewTaskProgressMetadata("test"))
+	if err != nil {
+		t.Fatalf("readContainerLogFiles() returned an unexpected error: %v", err)
+	}
+	got := []string{}
+	for _, l := range logs {
+		got = append(got, l.ReadStringOrDefault("resource.labels.container_name", "")+" "+l.ReadStringOrDefault("severity", "")+" "+l.ReadStringOrDefault("textPayload", ""))
+	}
+	want := []string{
+		"nginx INFO nginx-0",
+		"sidecar INFO sidecar-1",
+		"nginx INFO nginx-2",
+		"nginx ERROR nginx-error-3",
+		"sidecar ERROR sidecar-4",
+		"nginx INFO nginx-incomplete-5",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("readContainerLogFiles() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadContainerLogFiles_UnknownPath(t *testing.T) {
+	archive := tarGzipArchive(t, map[string]string{
+		"nginx.log": `2025-01-01T00:00:00.000000000Z stdout F nginx-0`,
+	}, []string{"nginx.log"})
+
+	_, err := readContainerLogFiles(context.Background(), bytes.NewReader(archive), int64(len(archive)), &containerLogParser{}, inspectionmetadata.NewTaskProgressMetadata("test"))
+	if err == nil {
+		t.Errorf("readContainerLogFiles() returned no error, want an error for a file not in the layout of kubelet log directory")
+	}
+}
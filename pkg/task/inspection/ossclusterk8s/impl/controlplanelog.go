@@ -0,0 +1,300 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structured"
+	"github.com/GoogleCloudPlatform/khi/pkg/core/inspection/gcpqueryutil"
+	"github.com/GoogleCloudPlatform/khi/pkg/core/inspection/logutil"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+)
+
+// controlPlaneClusterName is the cluster name used in the resource paths of control plane components.
+// Log files don't contain the cluster name, thus the default cluster name of kubeadm is used.
+const controlPlaneClusterName = "kubernetes"
+
+// wellKnownControlPlaneComponents is the list of pairs of a string found in log file paths and the component name used in the control plane component log parsers.
+var wellKnownControlPlaneComponents = []struct {
+	pathSubstring string
+	componentName string
+}{
+	{pathSubstring: "kube-apiserver", componentName: "apiserver"},
+	{pathSubstring: "kube-scheduler", componentName: "scheduler"},
+	{pathSubstring: "kube-controller-manager", componentName: "controller-manager"},
+	{pathSubstring: "cloud-controller-manager", componentName: "cloud-controller-manager"},
+	{pathSubstring: "etcd", componentName: "etcd"},
+}
+
+// klogTextParser parses the header and fields of klog text format (e.g. `I0101 00:00:00.000000       1 schedule_one.go:286] message`).
+var klogTextParser = logutil.NewKLogTextParser(true)
+
+// klogHeaderTimestampLayout is the layout of timestamps in klog headers. This format doesn't contain the year.
+const klogHeaderTimestampLayout = "0102 15:04:05.000000"
+
+// klogJSONReservedKeys are the keys of the JSON logging format of Kubernetes components not included in the key value pairs of the message.
+// See https://kubernetes.io/docs/concepts/cluster-administration/system-logs/#log-format
+var klogJSONReservedKeys = map[string]struct{}{
+	"ts":     {},
+	"caller": {},
+	"msg":    {},
+	"v":      {},
+}
+
+var errUnknownControlPlaneLogFormat = errors.New("the line is neither a klog text line nor a JSON log")
+
+// controlPlaneComponentNameFromPath returns the name of the control plane component writing the log file at the given path.
+// The base name of the file is used for components not known.
+func controlPlaneComponentNameFromPath(filePath string) string {
+	for _, component := range wellKnownControlPlaneComponents {
+		if strings.Contains(filePath, component.pathSubstring) {
+			return component.componentName
+		}
+	}
+	name := path.Base(filePath)
+	if index := strings.Index(name, "."); index > 0 {
+		name = name[:index]
+	}
+	if name == "" || name == "." || name == "/" {
+		return "unknown"
+	}
+	return name
+}
+
+// controlPlaneLogParser parses lines of control plane component log files in klog text format or JSON logging format into logs.
+// Logs are converted to the same structure of control plane component logs on Cloud Logging to be processed by the control plane component log parsers.
+type controlPlaneLogParser struct {
+	// referenceTime is the time used to guess the year of klog headers. Klog headers are read in the location of the reference time.
+	referenceTime time.Time
+}
+
+// ParseLine parses a line written by the given component. The timestamp is used in place of the timestamp in the line when it's not zero.
+// It's given for lines read from container log files because the timestamps written by the container runtime contain the year.
+func (p *controlPlaneLogParser) ParseLine(componentName string, line string, timestamp time.Time) (*log.Log, error) {
+	var entry *controlPlaneLogEntry
+	var err error
+	if strings.HasPrefix(line, "{") {
+		entry, err = parseKLogJSONLine(line)
+	} else {
+		entry, err = p.parseKLogTextLine(line)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !timestamp.IsZero() {
+		entry.timestamp = timestamp
+	}
+	record := map[string]any{
+		"timestamp": entry.timestamp.UTC().Format(time.RFC3339Nano),
+		"severity":  khiSeverityToGCPSeverity(entry.severity),
+		"jsonPayload": map[string]any{
+			"message": entry.message,
+		},
+		"resource": map[string]any{
+			"type": "k8s_control_plane_component",
+			"labels": map[string]any{
+				"cluster_name":   controlPlaneClusterName,
+				"component_name": componentName,
+			},
+		},
+	}
+	if entry.sourceFile != "" {
+		record["sourceLocation"] = map[string]any{
+			"file": entry.sourceFile,
+			"line": entry.sourceLine,
+		}
+	}
+	node, err := structured.FromGoValue(record, &structured.AlphabeticalGoMapKeyOrderProvider{})
+	if err != nil {
+		return nil, err
+	}
+	l := log.NewLog(structured.NewNodeReader(node))
+	l.LogType = enum.LogTypeControlPlaneComponent
+	if err := l.SetFieldSetReader(&gcpqueryutil.GCPCommonFieldSetReader{}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// controlPlaneLogEntry is the fields read from a line of control plane component logs.
+type controlPlaneLogEntry struct {
+	timestamp  time.Time
+	severity   enum.Severity
+	sourceFile string
+	sourceLine string
+	// message is the klog message without the header (e.g. `"Successfully bound pod to node" pod="default/nginx" node="worker-1"`).
+	message string
+}
+
+func (p *controlPlaneLogParser) parseKLogTextLine(line string) (*controlPlaneLogEntry, error) {
+	result := klogTextParser.TryParse(line)
+	if result == nil {
+		return nil, errUnknownControlPlaneLogFormat
+	}
+	severity, err := result.Severity()
+	if err != nil {
+		return nil, err
+	}
+	date, err := result.StringField(logutil.KLogHeaderDateFieldKey)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := result.StringField(logutil.KLogHeaderTimeFieldKey)
+	if err != nil {
+		return nil, err
+	}
+	source, err := result.StringField(logutil.KLogHeaderSourceLocationFieldKey)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := time.Parse(klogHeaderTimestampLayout, date+" "+clock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the klog timestamp %q: %w", date+" "+clock, err)
+	}
+	sourceFile, sourceLine := source, ""
+	if index := strings.LastIndex(source, ":"); index >= 0 {
+		sourceFile, sourceLine = source[:index], source[index+1:]
+	}
+	// The message is passed to the control plane component log parsers in the same form as Cloud Logging, which doesn't contain the header.
+	_, message, _ := strings.Cut(line, source+"]")
+	return &controlPlaneLogEntry{
+		timestamp:  timeInReferenceYear(timestamp, p.referenceTime),
+		severity:   severity,
+		sourceFile: sourceFile,
+		sourceLine: sourceLine,
+		message:    strings.TrimLeft(message, " "),
+	}, nil
+}
+
+// parseKLogJSONLine parses a line of the JSON logging format of Kubernetes components and converts its message to the klog text format.
+func parseKLogJSONLine(line string) (*controlPlaneLogEntry, error) {
+	fields := map[string]any{}
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse the JSON log: %w", err)
+	}
+	ts, ok := fields["ts"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("the JSON log doesn't have ts field")
+	}
+	result := &controlPlaneLogEntry{
+		timestamp: klogJSONTimestamp(ts),
+		severity:  enum.SeverityInfo,
+	}
+	// Error logs have `err` field without the verbosity.
+	if _, hasVerbosity := fields["v"]; !hasVerbosity {
+		if _, hasError := fields["err"]; hasError {
+			result.severity = enum.SeverityError
+		}
+	}
+	if caller, ok := fields["caller"].(string); ok {
+		file, line, _ := strings.Cut(caller, ":")
+		result.sourceFile = path.Base(file)
+		result.sourceLine = line
+	}
+	msg, _ := fields["msg"].(string)
+	result.message = klogTextMessage(msg, fields)
+	return result, nil
+}
+
+// klogJSONTimestamp converts the ts field of the JSON logging format to time. Kubernetes components write it in milliseconds, but seconds are also accepted.
+func klogJSONTimestamp(ts float64) time.Time {
+	// Timestamps in seconds don't reach this value until year 5138.
+	if ts > 1e11 {
+		return time.UnixMicro(int64(ts * 1000)).UTC()
+	}
+	return time.UnixMicro(int64(ts * 1e6)).UTC()
+}
+
+// klogTextMessage returns the message with the key value pairs in the klog text format from the fields of a JSON log.
+// Kubernetes object references (e.g. `{"name":"nginx","namespace":"default"}`) are written as `namespace/name` like klog.KObj in the text format.
+func klogTextMessage(msg string, fields map[string]any) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		if _, reserved := klogJSONReservedKeys[key]; !reserved {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	var builder strings.Builder
+	builder.WriteString(strconv.Quote(msg))
+	for _, key := range keys {
+		builder.WriteString(" ")
+		builder.WriteString(key)
+		builder.WriteString("=")
+		switch value := fields[key].(type) {
+		case string:
+			builder.WriteString(strconv.Quote(value))
+		case float64, bool, nil:
+			serialized, _ := json.Marshal(value)
+			builder.Write(serialized)
+		case map[string]any:
+			if reference, ok := klogObjectReference(value); ok {
+				builder.WriteString(strconv.Quote(reference))
+				continue
+			}
+			serialized, _ := json.Marshal(value)
+			builder.WriteString(strconv.Quote(string(serialized)))
+		default:
+			serialized, _ := json.Marshal(value)
+			builder.WriteString(strconv.Quote(string(serialized)))
+		}
+	}
+	return builder.String()
+}
+
+// klogObjectReference returns the `namespace/name` notation of an object reference written by klog.KObj in the JSON logging format.
+func klogObjectReference(value map[string]any) (string, bool) {
+	name, ok := value["name"].(string)
+	if !ok {
+		return "", false
+	}
+	switch len(value) {
+	case 1:
+		return name, true
+	case 2:
+		if namespace, ok := value["namespace"].(string); ok {
+			if namespace == "" {
+				return name, true
+			}
+			return namespace + "/" + name, true
+		}
+	}
+	return "", false
+}
+
+// khiSeverityToGCPSeverity converts the severity to the severity notation of Cloud Logging read by gcpqueryutil.GCPCommonFieldSetReader.
+func khiSeverityToGCPSeverity(severity enum.Severity) string {
+	switch severity {
+	case enum.SeverityInfo:
+		return "INFO"
+	case enum.SeverityWarning:
+		return "WARNING"
+	case enum.SeverityError:
+		return "ERROR"
+	case enum.SeverityFatal:
+		return "CRITICAL"
+	default:
+		return "DEFAULT"
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	googlecloudlogk8scontrolplane_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/googlecloudlogk8scontrolplane/contract"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestControlPlaneComponentNameFromPath(t *testing.T) {
+	testCases := []struct {
+		path string
+		want string
+	}{
+		{path: "kube-scheduler.log", want: "scheduler"},
+		{path: "control-plane-1/kube-controller-manager.log.1", want: "controller-manager"},
+		{path: "var/log/pods/kube-system_kube-apiserver-kind-control-plane_0123/kube-apiserver/0.log", want: "apiserver"},
+		{path: "var/log/containers/etcd-kind-control-plane_kube-system_etcd-0123.log", want: "etcd"},
+		{path: "logs/konnectivity-server.log", want: "konnectivity-server"},
+		{path: "", want: "unknown"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			if got := controlPlaneComponentNameFromPath(tc.path); got != tc.want {
+				t.Errorf("controlPlaneComponentNameFromPath(%q) = %q, want %q", tc.path, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestControlPlaneLogParser_ParseLine(t *testing.T) {
+	parser := &controlPlaneLogParser{referenceTime: time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)}
+	testCases := []struct {
+		name          string
+		line          string
+		timestamp     time.Time
+		wantCommon    *log.CommonFieldSet
+		wantMessage   string
+		wantPodName   string
+		wantNamespace string
+		wantErr       bool
+	}{
+		{
+			name: "klog text line",
+			line: `I0101 00:00:01.123456       1 schedule_one.go:286] "Successfully bound pod to node" pod="default/nginx" node="kind-worker"`,
+			wantCommon: &log.CommonFieldSet{
+				Timestamp: time.Date(2025, time.January, 1, 0, 0, 1, 123456000, time.UTC),
+				Severity:  enum.SeverityInfo,
+				DisplayID: "unknown",
+			},
+			wantMessage:   `"Successfully bound pod to node" pod="default/nginx" node="kind-worker"`,
+			wantPodName:   "nginx",
+			wantNamespace: "default",
+		},
+		{
+			name: "klog text line logged in the previous year",
+			line: `E1231 23:59:59.000000       1 reflector.go:10] "Failed to watch" err="timeout"`,
+			wantCommon: &log.CommonFieldSet{
+				Timestamp: time.Date(2024, time.December, 31, 23, 59, 59, 0, time.UTC),
+				Severity:  enum.SeverityError,
+				DisplayID: "unknown",
+			},
+			wantMessage: `"Failed to watch" err="timeout"`,
+		},
+		{
+			name:      "klog text line with the timestamp given from the container runtime",
+			line:      `E1231 23:59:59.000000       1 reflector.go:10] "Failed to watch" err="timeout"`,
+			timestamp: time.Date(2024, time.December, 31, 23, 59, 59, 123456789, time.UTC),
+			wantCommon: &log.CommonFieldSet{
+				Timestamp: time.Date(2024, time.December, 31, 23, 59, 59, 123456789, time.UTC),
+				Severity:  enum.SeverityError,
+				DisplayID: "unknown",
+			},
+			wantMessage: `"Failed to watch" err="timeout"`,
+		},
+		{
+			name: "klog text line with a message not structured",
+			line: `W0101 00:00:02.000000       1 authentication.go:368] Error looking up in-cluster authentication configuration: configmaps "extension-apiserver-authentication" is forbidden`,
+			wantCommon: &log.CommonFieldSet{
+				Timestamp: time.Date(2025, time.January, 1, 0, 0, 2, 0, time.UTC),
+				Severity:  enum.SeverityWarning,
+				DisplayID: "unknown",
+			},
+			wantMessage: `Error looking up in-cluster authentication configuration: configmaps "extension-apiserver-authentication" is forbidden`,
+		},
+		{
+			name: "JSON logging format",
+			line: `{"ts":1735689601123.456,"caller":"scheduler/schedule_one.go:286","msg":"Successfully bound pod to node","v":2,"pod":{"name":"nginx","namespace":"default"},"node":{"name":"kind-worker"},"evaluatedNodes":3}`,
+			wantCommon: &log.CommonFieldSet{
+				Timestamp: time.Date(2025, time.January, 1, 0, 0, 1, 123456000, time.UTC),
+				Severity:  enum.SeverityInfo,
+				DisplayID: "unknown",
+			},
+			wantMessage:   `"Successfully bound pod to node" evaluatedNodes=3 node="kind-worker" pod="default/nginx"`,
+			wantPodName:   "nginx",
+			wantNamespace: "default",
+		},
+		{
+			name: "JSON logging format of an error log",
+			line: `{"ts":1735689601.5,"caller":"cache/reflector.go:10","msg":"Failed to watch","err":"timeout"}`,
+			wantCommon: &log.CommonFieldSet{
+				Timestamp: time.Date(2025, time.January, 1, 0, 0, 1, 500000000, time.UTC),
+				Severity:  enum.SeverityError,
+				DisplayID: "unknown",
+			},
+			wantMessage: `"Failed to watch" err="timeout"`,
+		},
+		{
+			name:    "stack trace line",
+			line:    `goroutine 1 [running]:`,
+			wantErr: true,
+		},
+		{
+			name:    "JSON without timestamp",
+			line:    `{"msg":"foo"}`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l, err := parser.ParseLine("scheduler", tc.line, tc.timestamp)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("ParseLine() returned no error, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLine() returned an unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantCommon, log.MustGetFieldSet(l, &log.CommonFieldSet{})); diff != "" {
+				t.Errorf("CommonFieldSet mismatch (-want +got):\n%s", diff)
+			}
+
+			err = l.SetFieldSetReader(&googlecloudlogk8scontrolplane_contract.K8sControlplaneComponentFieldSetReader{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = l.SetFieldSetReader(&googlecloudlogk8scontrolplane_contract.K8sControlplaneCommonMessageFieldSetReader{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = l.SetFieldSetReader(&googlecloudlogk8scontrolplane_contract.K8sSchedulerComponentFieldSetReader{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			component := log.MustGetFieldSet(l, &googlecloudlogk8scontrolplane_contract.K8sControlplaneComponentFieldSet{})
+			if component.ComponentParserType() != googlecloudlogk8scontrolplane_contract.ComponentParserTypeScheduler {
+				t.Errorf("ComponentParserType() = %v, want %v", component.ComponentParserType(), googlecloudlogk8scontrolplane_contract.ComponentParserTypeScheduler)
+			}
+			if component.ClusterName != controlPlaneClusterName {
+				t.Errorf("ClusterName = %q, want %q", component.ClusterName, controlPlaneClusterName)
+			}
+			message := log.MustGetFieldSet(l, &googlecloudlogk8scontrolplane_contract.K8sControlplaneCommonMessageFieldSet{})
+			if message.Message != tc.wantMessage {
+				t.Errorf("Message = %q, want %q", message.Message, tc.wantMessage)
+			}
+			scheduler := log.MustGetFieldSet(l, &googlecloudlogk8scontrolplane_contract.K8sSchedulerComponentFieldSet{})
+			if scheduler.PodName != tc.wantPodName || scheduler.PodNamespace != tc.wantNamespace {
+				t.Errorf("pod = %s/%s, want %s/%s", scheduler.PodNamespace, scheduler.PodName, tc.wantNamespace, tc.wantPodName)
+			}
+		})
+	}
+}
+
+func TestControlPlaneLogParser_ParseLineWithControllerManagerFieldSet(t *testing.T) {
+	parser := &controlPlaneLogParser{referenceTime: time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)}
+	l, err := parser.ParseLine("controller-manager", `I0101 00:00:01.000000       1 namespace_controller.go:187] "Namespace has been deleted" namespace="test"`, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.ReadStringOrDefault("sourceLocation.file", ""); got != "namespace_controller.go" {
+		t.Errorf("sourceLocation.file = %q, want %q", got, "namespace_controller.go")
+	}
+	if got := l.ReadStringOrDefault("resource.labels.component_name", ""); got != "controller-manager" {
+		t.Errorf("resource.labels.component_name = %q, want %q", got, "controller-manager")
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/core/inspection/formtask"
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	inspectiontaskbase "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/taskbase"
+	coretask "github.com/GoogleCloudPlatform/khi/pkg/core/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/core/task/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
+	googlecloudlogk8scontrolplane_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/googlecloudlogk8scontrolplane/contract"
+	inspectioncore_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/contract"
+	ossclusterk8s_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/ossclusterk8s/contract"
+)
+
+// maxControlPlaneLogLineSizeInBytes is the maximum size of a line in the uploaded control plane component log files.
+const maxControlPlaneLogLineSizeInBytes = 16 * 1024 * 1024
+
+var InputControlPlaneLogFilesTask = formtask.NewFileFormTaskBuilder(ossclusterk8s_contract.InputControlPlaneLogFilesFormTaskID, 800, "Control Plane Log Files", &controlPlaneLogUploadFileVerifier{}).
+	WithDescription("Upload log files of control plane components in klog text format or JSON logging format. The component is decided from the file path containing its name (e.g. kube-scheduler.log, /var/log/pods/kube-system_kube-controller-manager-*/kube-controller-manager/0.log). Container log files written by container runtimes are also accepted. Multiple files, gzip compressed files and tar or zip archives of them are also accepted.").
+	Build()
+
+// ControlPlaneLogFileReaderTask reads control plane component logs from the uploaded files in place of the task querying them from Cloud Logging.
+var ControlPlaneLogFileReaderTask = inspectiontaskbase.NewProgressReportableInspectionTask(
+	ossclusterk8s_contract.ControlPlaneLogFileReaderTaskID,
+	[]taskid.UntypedTaskReference{
+		ossclusterk8s_contract.InputControlPlaneLogFilesFormTaskID.Ref(),
+	},
+	func(ctx context.Context, taskMode inspectioncore_contract.InspectionTaskModeType, tp *inspectionmetadata.TaskProgressMetadata) ([]*log.Log, error) {
+		if taskMode == inspectioncore_contract.TaskModeDryRun {
+			return []*log.Log{}, nil
+		}
+		result := coretask.GetTaskResult(ctx, ossclusterk8s_contract.InputControlPlaneLogFilesFormTaskID.Ref())
+
+		reader, err := result.GetReader()
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+
+		parser := &controlPlaneLogParser{referenceTime: time.Now()}
+		logs, err := readControlPlaneLogFiles(ctx, result.FileName, reader, uploadedFileSize(reader), parser, tp)
+		if err != nil {
+			return nil, err
+		}
+		extendInspectionTimeRange(ctx, logs)
+		return logs, nil
+	},
+	inspectioncore_contract.InspectionTypeLabel(ossclusterk8s_contract.InspectionTypeID),
+	coretask.WithSelectionPriority(1),
+)
+
+// ControlPlaneLogTailTask is the feature task to parse control plane component logs from the uploaded files with the parsers of control plane component logs.
+var ControlPlaneLogTailTask = inspectiontaskbase.NewInspectionTask(ossclusterk8s_contract.ControlPlaneLogTailTaskID,
+	[]taskid.UntypedTaskReference{
+		googlecloudlogk8scontrolplane_contract.SchedulerHistoryModifierTaskID.Ref(),
+		googlecloudlogk8scontrolplane_contract.ControllerManagerHistoryModifierTaskID.Ref(),
+		googlecloudlogk8scontrolplane_contract.OtherHistoryModifierTaskID.Ref(),
+	},
+	func(ctx context.Context, taskMode inspectioncore_contract.InspectionTaskModeType) (struct{}, error) {
+		return struct{}{}, nil
+	},
+	inspectioncore_contract.FeatureTaskLabel(
+		"Kubernetes Control plane component logs",
+		"Parse Kubernetes control plane component(e.g kube-scheduler, kube-controller-manager,api-server) logs from the uploaded files.",
+		enum.LogTypeControlPlaneComponent,
+		9000,
+		false,
+		ossclusterk8s_contract.InspectionTypeID,
+	),
+)
+
+// readControlPlaneLogFiles reads the control plane component logs from every file in the uploaded file line by line, and returns them merged and sorted by timestamp.
+// fileName is the name of the uploaded file used to decide the component when a single log file is uploaded. It's empty when multiple files are uploaded.
+// Lines not parsable as klog (e.g. stack traces) are skipped.
+func readControlPlaneLogFiles(ctx context.Context, fileName string, reader io.Reader, totalSize int64, parser *controlPlaneLogParser, tp *inspectionmetadata.TaskProgressMetadata) ([]*log.Log, error) {
+	counter := &countingReader{reader: reader}
+	if totalSize == 0 {
+		tp.MarkIndeterminate()
+	}
+	var logsPerFile [][]*log.Log
+	lineCount := 0
+	err := upload.WalkUploadedFilesWithName(fileName, counter, func(name string, fileReader io.Reader) error {
+		componentName := controlPlaneComponentNameFromPath(name)
+		logs := []*log.Log{}
+		skippedLineCount := 0
+		parseLine := func(line string, timestamp time.Time) {
+			l, err := parser.ParseLine(componentName, line, timestamp)
+			if err != nil {
+				skippedLineCount++
+				return
+			}
+			logs = append(logs, l)
+		}
+		assembler := newCRILogLineAssembler()
+		scanner := upload.NewLineScanner(fileReader, maxControlPlaneLogLineSizeInBytes)
+		for scanner.Scan() {
+			lineCount++
+			if lineCount%progressReportIntervalLines == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				reportReadProgress(tp, counter.count, totalSize, lineCount)
+			}
+			line := scanner.Text()
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if criLine, err := parseCRILogLine(line); err == nil {
+				if fullLine := assembler.Add(criLine); fullLine != nil {
+					parseLine(fullLine.Content, fullLine.Timestamp)
+				}
+				continue
+			}
+			parseLine(line, time.Time{})
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read %s: %w", fileDisplayName(name), err)
+		}
+		for _, fullLine := range assembler.Flush() {
+			parseLine(fullLine.Content, fullLine.Timestamp)
+		}
+		if skippedLineCount > 0 {
+			slog.WarnContext(ctx, fmt.Sprintf("%d lines in %s were skipped because they were not parsable as control plane component logs", skippedLineCount, fileDisplayName(name)))
+		}
+		slices.SortStableFunc(logs, compareLogTimestamp)
+		logsPerFile = append(logsPerFile, logs)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergeSortedLogs(logsPerFile), nil
+}
+
+// controlPlaneLogUploadFileVerifier verifies the first non empty line of every uploaded file is a klog text line or a JSON log. Empty files are accepted.
+type controlPlaneLogUploadFileVerifier struct{}
+
+// Verify implements upload.UploadFileVerifier.
+func (c *controlPlaneLogUploadFileVerifier) Verify(storeProvider upload.UploadFileStoreProvider, token upload.UploadToken) error {
+	reader, err := storeProvider.Read(token)
+	if err != nil {
+		return fmt.Errorf("failed to read the uploded file")
+	}
+	defer reader.Close()
+
+	parser := &controlPlaneLogParser{referenceTime: time.Now()}
+	return upload.WalkUploadedFiles(reader, func(name string, fileReader io.Reader) error {
+		scanner := upload.NewLineScanner(fileReader, maxControlPlaneLogLineSizeInBytes)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if criLine, err := parseCRILogLine(line); err == nil {
+				line = criLine.Content
+			}
+			if _, err := parser.ParseLine(controlPlaneComponentNameFromPath(name), line, time.Time{}); err != nil {
+				return fmt.Errorf("%s is not a log file of control plane components: %w", fileDisplayName(name), err)
+			}
+			return nil
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read %s: %w", fileDisplayName(name), err)
+		}
+		return nil
+	})
+}
+
+var _ upload.UploadFileVerifier = (*controlPlaneLogUploadFileVerifier)(nil)
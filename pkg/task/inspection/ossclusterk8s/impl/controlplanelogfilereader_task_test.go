@@ -0,0 +1,77 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadControlPlaneLogFiles(t *testing.T) {
+	files := map[string]string{
+		"kube-scheduler.log": strings.Join([]string{
+			`I0101 00:00:02.000000       1 schedule_one.go:286] "scheduler-2"`,
+			`goroutine 1 [running]:`,
+			`I0101 00:00:00.000000       1 schedule_one.go:286] "scheduler-0"`,
+		}, "\n"),
+		"var/log/pods/kube-system_kube-controller-manager-cp_0123/kube-controller-manager/0.log": strings.Join([]string{
+			`2025-01-01T00:00:01.000000000Z stderr P I0101 00:00:01.000000       1 controller.go:10] "controller`,
+			`2025-01-01T00:00:01.000000001Z stderr F -manager-1"`,
+			`2025-01-01T00:00:03.000000000Z stderr F {"ts":1735689603000,"caller":"app/controller.go:10","msg":"controller-manager-3","v":0}`,
+		}, "\n"),
+	}
+	archive := tarGzipArchive(t, files, []string{"kube-scheduler.log", "var/log/pods/kube-system_kube-controller-manager-cp_0123/kube-controller-manager/0.log"})
+	parser := &controlPlaneLogParser{referenceTime: time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)}
+
+	logs, err := readControlPlaneLogFiles(context.Background(), "", bytes.NewReader(archive), int64(len(archive)), parser, inspectionmetadata.NewTaskProgressMetadata("test"))
+	if err != nil {
+		t.Fatalf("readControlPlaneLogFiles() returned an unexpected error: %v", err)
+	}
+	got := []string{}
+	for _, l := range logs {
+		got = append(got, l.ReadStringOrDefault("resource.labels.component_name", "")+" "+l.ReadStringOrDefault("jsonPayload.message", ""))
+	}
+	want := []string{
+		`scheduler "scheduler-0"`,
+		`controller-manager "controller-manager-1"`,
+		`scheduler "scheduler-2"`,
+		`controller-manager "controller-manager-3"`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("readControlPlaneLogFiles() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadControlPlaneLogFiles_SingleFile(t *testing.T) {
+	content := `I0101 00:00:00.000000       1 schedule_one.go:286] "scheduler-0"`
+	parser := &controlPlaneLogParser{referenceTime: time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)}
+
+	logs, err := readControlPlaneLogFiles(context.Background(), "kube-scheduler.log", strings.NewReader(content), int64(len(content)), parser, inspectionmetadata.NewTaskProgressMetadata("test"))
+	if err != nil {
+		t.Fatalf("readControlPlaneLogFiles() returned an unexpected error: %v", err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("readControlPlaneLogFiles() returned %d logs, want 1", len(logs))
+	}
+	if got := logs[0].ReadStringOrDefault("resource.labels.component_name", ""); got != "scheduler" {
+		t.Errorf("resource.labels.component_name = %q, want %q decided from the name of the uploaded file", got, "scheduler")
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// criLogLinePattern matches a line of container log files written by CRI runtimes (e.g. `2025-01-01T00:00:00.000000000Z stdout F message`).
+// See https://github.com/kubernetes/design-proposals-archive/blob/main/node/kubelet-cri-logging.md
+var criLogLinePattern = regexp.MustCompile(`^([0-9]{4}-[0-9]{2}-[0-9]{2}T[^ ]+) (stdout|stderr) ([PF])(?: (.*))?$`)
+
+// criLogLine is a line of container log files written by CRI runtimes.
+type criLogLine struct {
+	Timestamp time.Time
+	Stream    string
+	// Partial is true when the line is a fragment of a long line split by the runtime. The rest follows in the next lines of the same stream.
+	Partial bool
+	Content string
+}
+
+// parseCRILogLine parses a line of container log files written by CRI runtimes.
+func parseCRILogLine(line string) (*criLogLine, error) {
+	match := criLogLinePattern.FindStringSubmatch(line)
+	if match == nil {
+		return nil, fmt.Errorf("the line is not in the CRI log format")
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, match[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the timestamp of the CRI log line: %w", err)
+	}
+	return &criLogLine{
+		Timestamp: timestamp,
+		Stream:    match[2],
+		Partial:   match[3] == "P",
+		Content:   match[4],
+	}, nil
+}
+
+// criLogLineAssembler concatenates partial CRI log lines into full lines.
+// Partial lines are concatenated per stream because stdout and stderr lines can be interleaved.
+type criLogLineAssembler struct {
+	pending map[string]*criLogLine
+}
+
+func newCRILogLineAssembler() *criLogLineAssembler {
+	return &criLogLineAssembler{pending: map[string]*criLogLine{}}
+}
+
+// Add adds a line and returns the full line when the given line completes it, or nil when it's waiting for the rest of the line.
+// The timestamp of the full line is the timestamp of its first fragment.
+func (a *criLogLineAssembler) Add(line *criLogLine) *criLogLine {
+	pending, found := a.pending[line.Stream]
+	if found {
+		pending.Content += line.Content
+	} else {
+		pending = &criLogLine{Timestamp: line.Timestamp, Stream: line.Stream, Content: line.Content}
+	}
+	if line.Partial {
+		a.pending[line.Stream] = pending
+		return nil
+	}
+	delete(a.pending, line.Stream)
+	return pending
+}
+
+// Flush returns the incomplete lines left at the end of a file sorted by timestamp.
+func (a *criLogLineAssembler) Flush() []*criLogLine {
+	result := make([]*criLogLine, 0, len(a.pending))
+	for _, stream := range []string{"stdout", "stderr"} {
+		if pending, found := a.pending[stream]; found {
+			result = append(result, pending)
+		}
+	}
+	a.pending = map[string]*criLogLine{}
+	if len(result) == 2 && result[1].Timestamp.Before(result[0].Timestamp) {
+		result[0], result[1] = result[1], result[0]
+	}
+	return result
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCRILogLine(t *testing.T) {
+	testCases := []struct {
+		name    string
+		line    string
+		want    *criLogLine
+		wantErr bool
+	}{
+		{
+			name: "full line",
+			line: "2025-01-01T00:00:01.123456789Z stdout F hello world",
+			want: &criLogLine{Timestamp: time.Date(2025, time.January, 1, 0, 0, 1, 123456789, time.UTC), Stream: "stdout", Content: "hello world"},
+		},
+		{
+			name: "partial line",
+			line: "2025-01-01T09:00:01+09:00 stderr P hello",
+			want: &criLogLine{Timestamp: time.Date(2025, time.January, 1, 0, 0, 1, 0, time.UTC), Stream: "stderr", Partial: true, Content: "hello"},
+		},
+		{
+			name: "empty line",
+			line: "2025-01-01T00:00:01Z stdout F",
+			want: &criLogLine{Timestamp: time.Date(2025, time.January, 1, 0, 0, 1, 0, time.UTC), Stream: "stdout"},
+		},
+		{
+			name:    "not a CRI log line",
+			line:    "I0101 00:00:01.000000       1 main.go:10] hello",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseCRILogLine(tc.line)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("parseCRILogLine() returned no error, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCRILogLine() returned an unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) })); diff != "" {
+				t.Errorf("parseCRILogLine() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCRILogLineAssembler(t *testing.T) {
+	t1 := time.Date(2025, time.January, 1, 0, 0, 1, 0, time.UTC)
+	t2 := t1.Add(time.Second)
+	assembler := newCRILogLineAssembler()
+	var got []*criLogLine
+	for _, line := range []*criLogLine{
+		{Timestamp: t1, Stream: "stdout", Partial: true, Content: "hello "},
+		{Timestamp: t1, Stream: "stderr", Content: "error"},
+		{Timestamp: t2, Stream: "stdout", Partial: true, Content: "wor"},
+		{Timestamp: t2, Stream: "stdout", Content: "ld"},
+		{Timestamp: t2, Stream: "stderr", Partial: true, Content: "incomplete"},
+	} {
+		if fullLine := assembler.Add(line); fullLine != nil {
+			got = append(got, fullLine)
+		}
+	}
+	got = append(got, assembler.Flush()...)
+	want := []*criLogLine{
+		{Timestamp: t1, Stream: "stderr", Content: "error"},
+		{Timestamp: t1, Stream: "stdout", Content: "hello world"},
+		{Timestamp: t2, Stream: "stderr", Content: "incomplete"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("assembled lines mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -24,7 +24,10 @@ import (
 	commonlogk8saudit_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/contract"
 )
 
-type OSSJSONLAuditLogFieldExtractor struct{}
+type OSSJSONLAuditLogFieldExtractor struct {
+	// StageTimings is the map from auditID to the timing of the stages of the request. The StageTiming field of the result is nil for requests not found in this map.
+	StageTimings map[string]*commonlogk8saudit_contract.AuditLogStageTiming
+}
 
 // ExtractFields implements common.AuditLogFieldExtractor.
 func (g *OSSJSONLAuditLogFieldExtractor) ExtractFields(ctx context.Context, l *log.Log) (*commonlogk8saudit_contract.AuditLogParserInput, error) {
@@ -67,17 +70,24 @@ func (g *OSSJSONLAuditLogFieldExtractor) ExtractFields(ctx context.Context, l *l
 		requestType = commonlogk8saudit_contract.RtypeFromOSSK8sObject(request)
 	}
 
+	stageTiming := g.StageTimings[l.ReadStringOrDefault("auditID", "")]
+	isErrorResponse := responseCode >= 400 // The response code is HTTP response code. Treat 4XX,5XX as error code.
+	if stageTiming.Panicked() {
+		isErrorResponse = true
+	}
+
 	return &commonlogk8saudit_contract.AuditLogParserInput{
 		Log:                  l,
 		Requestor:            requestor,
 		Operation:            &k8sOp,
 		ResponseErrorCode:    responseCode,
 		ResponseErrorMessage: responseMessage,
-		IsErrorResponse:      responseCode >= 400, // The response code is HTTP response code. Treat 4XX,5XX as error code.
+		IsErrorResponse:      isErrorResponse,
 		RequestType:          requestType,
 		Request:              request,
 		ResponseType:         responseType,
 		Response:             response,
+		StageTiming:          stageTiming,
 	}, nil
 }
 
@@ -0,0 +1,62 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"context"
+	"testing"
+
+	commonlogk8saudit_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/contract"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOSSJSONLAuditLogFieldExtractor_StageTiming(t *testing.T) {
+	panicked := &commonlogk8saudit_contract.AuditLogStageTiming{Stage: commonlogk8saudit_contract.AuditLogStagePanic}
+	inFlight := &commonlogk8saudit_contract.AuditLogStageTiming{Stage: commonlogk8saudit_contract.AuditLogStageResponseStarted}
+	extractor := &OSSJSONLAuditLogFieldExtractor{
+		StageTimings: map[string]*commonlogk8saudit_contract.AuditLogStageTiming{
+			"panicked":  panicked,
+			"in-flight": inFlight,
+		},
+	}
+	testCases := []struct {
+		auditID             string
+		stage               string
+		wantStageTiming     *commonlogk8saudit_contract.AuditLogStageTiming
+		wantIsErrorResponse bool
+	}{
+		{auditID: "panicked", stage: "Panic", wantStageTiming: panicked, wantIsErrorResponse: true},
+		{auditID: "in-flight", stage: "ResponseStarted", wantStageTiming: inFlight, wantIsErrorResponse: false},
+		{auditID: "unknown", stage: "ResponseComplete", wantStageTiming: nil, wantIsErrorResponse: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.auditID, func(t *testing.T) {
+			l, err := parseAuditLogLine(auditLogLine(tc.auditID, tc.stage, "2025-01-01T00:00:00Z"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := extractor.ExtractFields(context.Background(), l)
+			if err != nil {
+				t.Fatalf("ExtractFields() returned an unexpected error: %v", err)
+			}
+			if got.StageTiming != tc.wantStageTiming {
+				t.Errorf("StageTiming = %v, want %v", got.StageTiming, tc.wantStageTiming)
+			}
+			if diff := cmp.Diff(tc.wantIsErrorResponse, got.IsErrorResponse); diff != "" {
+				t.Errorf("IsErrorResponse mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
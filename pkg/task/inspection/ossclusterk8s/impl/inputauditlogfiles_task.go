@@ -20,8 +20,11 @@ import (
 	ossclusterk8s_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/ossclusterk8s/contract"
 )
 
+// maxAuditLogLineSizeInBytes is the maximum size of a line in the uploaded audit log files.
+const maxAuditLogLineSizeInBytes = 64 * 1024 * 1024
+
 var InputAuditLogFilesTask = formtask.NewFileFormTaskBuilder(ossclusterk8s_contract.InputAuditLogFilesFormTaskID, 1000, "Audit Log Files", &upload.JSONLineUploadFileVerifier{
-	MaxLineSizeInBytes: 1024 * 1024 * 1024,
+	MaxLineSizeInBytes: maxAuditLogLineSizeInBytes,
 }).
-	WithDescription(`Upload JSONLine format kube-apiserver audit log`).
+	WithDescription(`Upload JSONLine format kube-apiserver audit log. Multiple files, gzip compressed files and tar or zip archives of them are also accepted.`).
 	Build()
@@ -21,9 +21,13 @@ import (
 	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder/commonrecorder"
 	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder/containerstatusrecorder"
 	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder/endpointslicerecorder"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder/hparecorder"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder/jobrecorder"
 	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder/noderecorder"
 	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder/ownerreferencerecorder"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder/rolloutrecorder"
 	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder/statusrecorder"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/impl/recorder/volumerecorder"
 	ossclusterk8s_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/ossclusterk8s/contract"
 )
 
@@ -58,6 +62,22 @@ var RegisterK8sAuditTasks coreinspection.InspectionRegistrationFunc = func(regis
 	if err != nil {
 		return err
 	}
+	err = volumerecorder.Register(manager)
+	if err != nil {
+		return err
+	}
+	err = hparecorder.Register(manager)
+	if err != nil {
+		return err
+	}
+	err = jobrecorder.Register(manager)
+	if err != nil {
+		return err
+	}
+	err = rolloutrecorder.Register(manager)
+	if err != nil {
+		return err
+	}
 
 	err = manager.Register(registry, ossclusterk8s_contract.InspectionTypeID)
 	if err != nil {
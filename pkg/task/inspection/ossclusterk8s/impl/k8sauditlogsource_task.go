@@ -28,14 +28,18 @@ import (
 // OSSK8sAuditLogSourceTask receives logs generated from the previous tasks specific to OSS audit log parsing and inject dependencies specific to this OSS inspection type.
 var OSSK8sAuditLogSourceTask = inspectiontaskbase.NewInspectionTask(ossclusterk8s_contract.OSSK8sAuditLogSourceTaskID, []taskid.UntypedTaskReference{
 	ossclusterk8s_contract.NonEventAuditLogFilterTaskID.Ref(),
+	ossclusterk8s_contract.AuditLogStageCorrelatorTaskID.Ref(),
 }, func(ctx context.Context, taskMode inspectioncore_contract.InspectionTaskModeType) (*commonlogk8saudit_contract.AuditLogParserLogSource, error) {
 	if taskMode == inspectioncore_contract.TaskModeDryRun {
 		return nil, nil
 	}
 	logs := coretask.GetTaskResult(ctx, ossclusterk8s_contract.NonEventAuditLogFilterTaskID.Ref())
+	correlatedLogs := coretask.GetTaskResult(ctx, ossclusterk8s_contract.AuditLogStageCorrelatorTaskID.Ref())
 
 	return &commonlogk8saudit_contract.AuditLogParserLogSource{
-		Logs:      logs,
-		Extractor: &OSSJSONLAuditLogFieldExtractor{},
+		Logs: logs,
+		Extractor: &OSSJSONLAuditLogFieldExtractor{
+			StageTimings: correlatedLogs.StageTimings,
+		},
 	}, nil
 }, inspectioncore_contract.InspectionTypeLabel(ossclusterk8s_contract.InspectionTypeID))
@@ -0,0 +1,214 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structured"
+	"github.com/GoogleCloudPlatform/khi/pkg/core/inspection/logutil"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	googlecloudlogk8snode_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/googlecloudlogk8snode/contract"
+)
+
+// Field names of journald records. Syslog lines are converted to records with the same field names.
+// See https://www.freedesktop.org/software/systemd/man/latest/systemd.journal-fields.html
+const (
+	journaldFieldRealtimeTimestamp = "__REALTIME_TIMESTAMP"
+	journaldFieldCursor            = "__CURSOR"
+	journaldFieldMessage           = "MESSAGE"
+	journaldFieldPriority          = "PRIORITY"
+	journaldFieldSyslogIdentifier  = "SYSLOG_IDENTIFIER"
+	journaldFieldSyslogRaw         = "SYSLOG_RAW"
+	journaldFieldComm              = "_COMM"
+	journaldFieldPID               = "_PID"
+	journaldFieldHostname          = "_HOSTNAME"
+)
+
+// syslogLinePattern matches a line written by syslog daemons in the traditional BSD format (e.g. `Jan  2 15:04:05 host kubelet[123]: message`)
+// or with the RFC3339 timestamp used by rsyslog high precision format (e.g. `2025-01-02T15:04:05.000000+00:00 host kubelet[123]: message`).
+var syslogLinePattern = regexp.MustCompile(`^([A-Z][a-z]{2} [ 0-9][0-9] [0-9]{2}:[0-9]{2}:[0-9]{2}|[0-9]{4}-[0-9]{2}-[0-9]{2}T[^ ]+) +([^ ]+) +([^ \[:]+)(?:\[([0-9]+)\])?: ?(.*)$`)
+
+// bsdSyslogTimestampLayout is the layout of timestamps in the traditional BSD syslog format. This format doesn't contain the year and the timezone.
+const bsdSyslogTimestampLayout = "Jan _2 15:04:05"
+
+var errUnknownNodeLogFormat = errors.New("the line is neither a journald JSON record nor a syslog line")
+
+// nodeLogParser parses lines of journald JSON exports (`journalctl -o json`) or syslog files into logs.
+type nodeLogParser struct {
+	// referenceTime is the time used to guess the year of syslog timestamps without the year. Timestamps are assumed to be in the year of the reference time, or in the previous year when the timestamp is later than the reference time.
+	// These timestamps are read in the location of the reference time.
+	referenceTime time.Time
+}
+
+// ParseLine parses a line of node log files. Journald JSON records are detected with the leading `{`. The other lines are parsed as syslog lines.
+func (p *nodeLogParser) ParseLine(line string) (*log.Log, error) {
+	var record map[string]any
+	var err error
+	if strings.HasPrefix(line, "{") {
+		record, err = parseJournaldJSONRecord(line)
+	} else {
+		record, err = p.parseSyslogLine(line)
+	}
+	if err != nil {
+		return nil, err
+	}
+	node, err := structured.FromGoValue(record, &structured.AlphabeticalGoMapKeyOrderProvider{})
+	if err != nil {
+		return nil, err
+	}
+	l := log.NewLog(structured.NewNodeReader(node))
+	l.LogType = enum.LogTypeNode
+	if err := l.SetFieldSetReader(&OSSNodeLogCommonFieldSetReader{}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// parseJournaldJSONRecord parses a record of `journalctl -o json` output.
+// journald exports fields containing non printable characters as arrays of bytes. These are converted to strings.
+func parseJournaldJSONRecord(line string) (map[string]any, error) {
+	record := map[string]any{}
+	if err := json.Unmarshal([]byte(line), &record); err != nil {
+		return nil, fmt.Errorf("failed to parse the journald JSON record: %w", err)
+	}
+	for key, value := range record {
+		bytes, ok := value.([]any)
+		if !ok {
+			continue
+		}
+		converted := make([]byte, 0, len(bytes))
+		for _, b := range bytes {
+			n, ok := b.(float64)
+			if !ok {
+				break
+			}
+			converted = append(converted, byte(n))
+		}
+		if len(converted) == len(bytes) {
+			record[key] = string(converted)
+		}
+	}
+	if _, found := record[journaldFieldRealtimeTimestamp]; !found {
+		return nil, fmt.Errorf("the journald JSON record doesn't have %s field", journaldFieldRealtimeTimestamp)
+	}
+	return record, nil
+}
+
+// parseSyslogLine converts a syslog line to a record with the field names of journald.
+func (p *nodeLogParser) parseSyslogLine(line string) (map[string]any, error) {
+	match := syslogLinePattern.FindStringSubmatch(line)
+	if match == nil {
+		return nil, errUnknownNodeLogFormat
+	}
+	timestamp, err := p.parseSyslogTimestamp(match[1])
+	if err != nil {
+		return nil, err
+	}
+	record := map[string]any{
+		journaldFieldRealtimeTimestamp: strconv.FormatInt(timestamp.UnixMicro(), 10),
+		journaldFieldHostname:          match[2],
+		journaldFieldSyslogIdentifier:  match[3],
+		journaldFieldMessage:           match[5],
+		journaldFieldSyslogRaw:         line,
+	}
+	if match[4] != "" {
+		record[journaldFieldPID] = match[4]
+	}
+	return record, nil
+}
+
+func (p *nodeLogParser) parseSyslogTimestamp(timestamp string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(bsdSyslogTimestampLayout, timestamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse the syslog timestamp %q: %w", timestamp, err)
+	}
+	return timeInReferenceYear(t, p.referenceTime), nil
+}
+
+// OSSNodeLogCommonFieldSetReader implements log.FieldSetReader for log.CommonFieldSet from journald records.
+type OSSNodeLogCommonFieldSetReader struct{}
+
+// FieldSetKind implements log.FieldSetReader.
+func (o *OSSNodeLogCommonFieldSetReader) FieldSetKind() string {
+	return (&log.CommonFieldSet{}).Kind()
+}
+
+// Read implements log.FieldSetReader.
+func (o *OSSNodeLogCommonFieldSetReader) Read(reader *structured.NodeReader) (log.FieldSet, error) {
+	result := &log.CommonFieldSet{}
+	timestampInMicroseconds, err := strconv.ParseInt(reader.ReadStringOrDefault(journaldFieldRealtimeTimestamp, ""), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the timestamp from the node log: %w", err)
+	}
+	result.Timestamp = time.UnixMicro(timestampInMicroseconds).UTC()
+	result.Severity = journaldPriorityToSeverity(reader.ReadStringOrDefault(journaldFieldPriority, ""))
+	result.DisplayID = reader.ReadStringOrDefault(journaldFieldCursor, "")
+	return result, nil
+}
+
+// journaldPriorityToSeverity converts the syslog priority used in journald to the severity.
+func journaldPriorityToSeverity(priority string) enum.Severity {
+	switch priority {
+	case "0", "1", "2":
+		return enum.SeverityFatal
+	case "3":
+		return enum.SeverityError
+	case "4":
+		return enum.SeverityWarning
+	case "5", "6", "7":
+		return enum.SeverityInfo
+	default:
+		return enum.SeverityUnknown
+	}
+}
+
+var _ log.FieldSetReader = (*OSSNodeLogCommonFieldSetReader)(nil)
+
+// OSSK8sNodeLogFieldSetReader implements log.FieldSetReader for googlecloudlogk8snode_contract.K8sNodeLogCommonFieldSet from journald records.
+// This allows the history modifiers of Kubernetes node logs on Cloud Logging to process node logs of OSS clusters.
+type OSSK8sNodeLogFieldSetReader struct {
+	StructuredLogParser logutil.StructuredLogParser
+}
+
+// FieldSetKind implements log.FieldSetReader.
+func (o *OSSK8sNodeLogFieldSetReader) FieldSetKind() string {
+	return (&googlecloudlogk8snode_contract.K8sNodeLogCommonFieldSet{}).Kind()
+}
+
+// Read implements log.FieldSetReader.
+func (o *OSSK8sNodeLogFieldSetReader) Read(reader *structured.NodeReader) (log.FieldSet, error) {
+	var result googlecloudlogk8snode_contract.K8sNodeLogCommonFieldSet
+	result.Message = o.StructuredLogParser.TryParse(reader.ReadStringOrDefault(journaldFieldMessage, ""))
+	result.Component = reader.ReadStringOrDefault(journaldFieldSyslogIdentifier, "")
+	if result.Component == "" {
+		result.Component = reader.ReadStringOrDefault(journaldFieldComm, "")
+	}
+	result.Component = strings.Trim(result.Component, "()") // Some component can have () around SYSLOG_IDENTIFIER. Remove them for consistency.
+	result.NodeName = reader.ReadStringOrDefault(journaldFieldHostname, "")
+	return &result, nil
+}
+
+var _ log.FieldSetReader = (*OSSK8sNodeLogFieldSetReader)(nil)
@@ -0,0 +1,169 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/core/inspection/logutil"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	googlecloudlogk8snode_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/googlecloudlogk8snode/contract"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNodeLogParser_ParseLine(t *testing.T) {
+	parser := &nodeLogParser{referenceTime: time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)}
+	testCases := []struct {
+		name          string
+		line          string
+		wantCommon    *log.CommonFieldSet
+		wantComponent string
+		wantNodeName  string
+		wantMessage   string
+		wantErr       bool
+	}{
+		{
+			name: "journald JSON record",
+			line: `{"__CURSOR":"s=1;i=2","__REALTIME_TIMESTAMP":"1735689601123456","PRIORITY":"3","_HOSTNAME":"node-1","SYSLOG_IDENTIFIER":"kubelet","_PID":"100","MESSAGE":"E0101 00:00:01.123456     100 kubelet.go:10] \"Error syncing pod\""}`,
+			wantCommon: &log.CommonFieldSet{
+				Timestamp: time.Date(2025, time.January, 1, 0, 0, 1, 123456000, time.UTC),
+				Severity:  enum.SeverityError,
+				DisplayID: "s=1;i=2",
+			},
+			wantComponent: "kubelet",
+			wantNodeName:  "node-1",
+			wantMessage:   `E0101 00:00:01.123456     100 kubelet.go:10] "Error syncing pod"`,
+		},
+		{
+			name: "journald JSON record with the message exported as bytes",
+			line: `{"__REALTIME_TIMESTAMP":"1735689601000000","_HOSTNAME":"node-1","_COMM":"containerd","MESSAGE":[104,105,10]}`,
+			wantCommon: &log.CommonFieldSet{
+				Timestamp: time.Date(2025, time.January, 1, 0, 0, 1, 0, time.UTC),
+				Severity:  enum.SeverityUnknown,
+			},
+			wantComponent: "containerd",
+			wantNodeName:  "node-1",
+			wantMessage:   "hi\n",
+		},
+		{
+			name: "BSD syslog line",
+			line: `Jan  1 00:00:01 node-2 containerd[200]: time="2025-01-01T00:00:01Z" level=info msg="StartContainer"`,
+			wantCommon: &log.CommonFieldSet{
+				Timestamp: time.Date(2025, time.January, 1, 0, 0, 1, 0, time.UTC),
+				Severity:  enum.SeverityUnknown,
+			},
+			wantComponent: "containerd",
+			wantNodeName:  "node-2",
+			wantMessage:   `time="2025-01-01T00:00:01Z" level=info msg="StartContainer"`,
+		},
+		{
+			name: "BSD syslog line written in the previous year",
+			line: `Dec 31 23:59:59 node-2 systemd[1]: Started kubelet.service.`,
+			wantCommon: &log.CommonFieldSet{
+				Timestamp: time.Date(2024, time.December, 31, 23, 59, 59, 0, time.UTC),
+				Severity:  enum.SeverityUnknown,
+			},
+			wantComponent: "systemd",
+			wantNodeName:  "node-2",
+			wantMessage:   "Started kubelet.service.",
+		},
+		{
+			name: "syslog line with RFC3339 timestamp and without pid",
+			line: `2025-01-01T09:00:01.500000+09:00 node-3 kernel: eth0: link up`,
+			wantCommon: &log.CommonFieldSet{
+				Timestamp: time.Date(2025, time.January, 1, 0, 0, 1, 500000000, time.UTC),
+				Severity:  enum.SeverityUnknown,
+			},
+			wantComponent: "kernel",
+			wantNodeName:  "node-3",
+			wantMessage:   "eth0: link up",
+		},
+		{
+			name:    "journald JSON record without timestamp",
+			line:    `{"MESSAGE":"foo"}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown format",
+			line:    `  at foo.bar(baz.go:10)`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l, err := parser.ParseLine(tc.line)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("ParseLine() returned no error, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLine() returned an unexpected error: %v", err)
+			}
+			if l.LogType != enum.LogTypeNode {
+				t.Errorf("LogType = %v, want %v", l.LogType, enum.LogTypeNode)
+			}
+			if diff := cmp.Diff(tc.wantCommon, log.MustGetFieldSet(l, &log.CommonFieldSet{})); diff != "" {
+				t.Errorf("CommonFieldSet mismatch (-want +got):\n%s", diff)
+			}
+
+			err = l.SetFieldSetReader(&OSSK8sNodeLogFieldSetReader{
+				StructuredLogParser: logutil.NewMultiTextLogParser(
+					logutil.NewKLogTextParser(true),
+					logutil.NewLogfmtTextParser(),
+					&logutil.FallbackRawTextLogParser{},
+				),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			nodeFieldSet := log.MustGetFieldSet(l, &googlecloudlogk8snode_contract.K8sNodeLogCommonFieldSet{})
+			if nodeFieldSet.Component != tc.wantComponent {
+				t.Errorf("Component = %q, want %q", nodeFieldSet.Component, tc.wantComponent)
+			}
+			if nodeFieldSet.NodeName != tc.wantNodeName {
+				t.Errorf("NodeName = %q, want %q", nodeFieldSet.NodeName, tc.wantNodeName)
+			}
+			if message := nodeFieldSet.Message.Raw(); message != tc.wantMessage {
+				t.Errorf("Message = %q, want %q", message, tc.wantMessage)
+			}
+		})
+	}
+}
+
+func TestJournaldPriorityToSeverity(t *testing.T) {
+	testCases := []struct {
+		priority string
+		want     enum.Severity
+	}{
+		{priority: "0", want: enum.SeverityFatal},
+		{priority: "2", want: enum.SeverityFatal},
+		{priority: "3", want: enum.SeverityError},
+		{priority: "4", want: enum.SeverityWarning},
+		{priority: "5", want: enum.SeverityInfo},
+		{priority: "7", want: enum.SeverityInfo},
+		{priority: "", want: enum.SeverityUnknown},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.priority, func(t *testing.T) {
+			if got := journaldPriorityToSeverity(tc.priority); got != tc.want {
+				t.Errorf("journaldPriorityToSeverity(%q) = %v, want %v", tc.priority, got, tc.want)
+			}
+		})
+	}
+}
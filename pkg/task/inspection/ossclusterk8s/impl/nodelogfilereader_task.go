@@ -0,0 +1,191 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/core/inspection/formtask"
+	"github.com/GoogleCloudPlatform/khi/pkg/core/inspection/logutil"
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	inspectiontaskbase "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/taskbase"
+	coretask "github.com/GoogleCloudPlatform/khi/pkg/core/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/core/task/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
+	googlecloudlogk8snode_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/googlecloudlogk8snode/contract"
+	inspectioncore_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/contract"
+	ossclusterk8s_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/ossclusterk8s/contract"
+)
+
+// maxNodeLogLineSizeInBytes is the maximum size of a line in the uploaded node log files.
+const maxNodeLogLineSizeInBytes = 16 * 1024 * 1024
+
+var InputNodeLogFilesTask = formtask.NewFileFormTaskBuilder(ossclusterk8s_contract.InputNodeLogFilesFormTaskID, 900, "Node Log Files", &nodeLogUploadFileVerifier{}).
+	WithDescription("Upload journald logs exported with `journalctl -o json` or syslog files (e.g. /var/log/syslog, /var/log/messages) of nodes. Multiple files, gzip compressed files and tar or zip archives of them are also accepted.").
+	Build()
+
+// NodeLogFileReaderTask reads node logs from the uploaded files in place of the task querying node logs from Cloud Logging.
+var NodeLogFileReaderTask = inspectiontaskbase.NewProgressReportableInspectionTask(
+	ossclusterk8s_contract.NodeLogFileReaderTaskID,
+	[]taskid.UntypedTaskReference{
+		ossclusterk8s_contract.InputNodeLogFilesFormTaskID.Ref(),
+	},
+	func(ctx context.Context, taskMode inspectioncore_contract.InspectionTaskModeType, tp *inspectionmetadata.TaskProgressMetadata) ([]*log.Log, error) {
+		if taskMode == inspectioncore_contract.TaskModeDryRun {
+			return []*log.Log{}, nil
+		}
+		result := coretask.GetTaskResult(ctx, ossclusterk8s_contract.InputNodeLogFilesFormTaskID.Ref())
+
+		reader, err := result.GetReader()
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+
+		parser := &nodeLogParser{referenceTime: time.Now()}
+		logs, err := readNodeLogFiles(ctx, reader, uploadedFileSize(reader), parser, tp)
+		if err != nil {
+			return nil, err
+		}
+		extendInspectionTimeRange(ctx, logs)
+		return logs, nil
+	},
+	inspectioncore_contract.InspectionTypeLabel(ossclusterk8s_contract.InspectionTypeID),
+	coretask.WithSelectionPriority(1),
+)
+
+// NodeLogFieldSetReaderTask reads the field set used by the node log parsers from the journald records.
+var NodeLogFieldSetReaderTask = inspectiontaskbase.NewFieldSetReadTask(ossclusterk8s_contract.NodeLogFieldSetReaderTaskID, googlecloudlogk8snode_contract.ListLogEntriesTaskID.Ref(), []log.FieldSetReader{
+	&OSSK8sNodeLogFieldSetReader{
+		StructuredLogParser: logutil.NewMultiTextLogParser(
+			logutil.NewKLogTextParser(true),
+			logutil.NewLogfmtTextParser(),
+			&logutil.FallbackRawTextLogParser{},
+		),
+	},
+},
+	inspectioncore_contract.InspectionTypeLabel(ossclusterk8s_contract.InspectionTypeID),
+	coretask.WithSelectionPriority(1),
+)
+
+// NodeLogTailTask is the feature task to parse node logs from the uploaded files with the parsers of Kubernetes node logs.
+var NodeLogTailTask = inspectiontaskbase.NewInspectionTask(ossclusterk8s_contract.NodeLogTailTaskID,
+	[]taskid.UntypedTaskReference{
+		googlecloudlogk8snode_contract.ContainerdLogHistoryModifierTaskID.Ref(),
+		googlecloudlogk8snode_contract.KubeletLogHistoryModifierTaskID.Ref(),
+		googlecloudlogk8snode_contract.OtherLogHistoryModifierTaskID.Ref(),
+	},
+	func(ctx context.Context, taskMode inspectioncore_contract.InspectionTaskModeType) (struct{}, error) {
+		return struct{}{}, nil
+	},
+	inspectioncore_contract.FeatureTaskLabel(
+		"Kubernetes Node Logs",
+		"Parse node component(e.g kubelet/containerd) logs from the uploaded journald or syslog files.",
+		enum.LogTypeNode,
+		3000,
+		false,
+		ossclusterk8s_contract.InspectionTypeID,
+	),
+)
+
+// readNodeLogFiles reads the node logs from every file in the uploaded file line by line, and returns them merged and sorted by timestamp.
+// Lines not parsable as node logs (e.g. continuation lines of multi-line messages in syslog files) are skipped.
+func readNodeLogFiles(ctx context.Context, reader io.Reader, totalSize int64, parser *nodeLogParser, tp *inspectionmetadata.TaskProgressMetadata) ([]*log.Log, error) {
+	counter := &countingReader{reader: reader}
+	if totalSize == 0 {
+		tp.MarkIndeterminate()
+	}
+	var logsPerFile [][]*log.Log
+	lineCount := 0
+	err := upload.WalkUploadedFiles(counter, func(name string, fileReader io.Reader) error {
+		logs := []*log.Log{}
+		skippedLineCount := 0
+		scanner := upload.NewLineScanner(fileReader, maxNodeLogLineSizeInBytes)
+		for scanner.Scan() {
+			lineCount++
+			if lineCount%progressReportIntervalLines == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				reportReadProgress(tp, counter.count, totalSize, lineCount)
+			}
+			line := scanner.Text()
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			l, err := parser.ParseLine(line)
+			if err != nil {
+				skippedLineCount++
+				continue
+			}
+			logs = append(logs, l)
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read %s: %w", fileDisplayName(name), err)
+		}
+		if skippedLineCount > 0 {
+			slog.WarnContext(ctx, fmt.Sprintf("%d lines in %s were skipped because they were not parsable as node logs", skippedLineCount, fileDisplayName(name)))
+		}
+		// Syslog files can contain lines written out of order by multiple processes. Stable sort keeps the order of logs with the same timestamp.
+		slices.SortStableFunc(logs, compareLogTimestamp)
+		logsPerFile = append(logsPerFile, logs)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergeSortedLogs(logsPerFile), nil
+}
+
+// nodeLogUploadFileVerifier verifies the first non empty line of every uploaded file is a journald JSON record or a syslog line. Empty files are accepted.
+type nodeLogUploadFileVerifier struct{}
+
+// Verify implements upload.UploadFileVerifier.
+func (n *nodeLogUploadFileVerifier) Verify(storeProvider upload.UploadFileStoreProvider, token upload.UploadToken) error {
+	reader, err := storeProvider.Read(token)
+	if err != nil {
+		return fmt.Errorf("failed to read the uploded file")
+	}
+	defer reader.Close()
+
+	parser := &nodeLogParser{referenceTime: time.Now()}
+	return upload.WalkUploadedFiles(reader, func(name string, fileReader io.Reader) error {
+		scanner := upload.NewLineScanner(fileReader, maxNodeLogLineSizeInBytes)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if _, err := parser.ParseLine(line); err != nil {
+				return fmt.Errorf("%s is not a journald JSON export or a syslog file: %w", fileDisplayName(name), err)
+			}
+			return nil
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read %s: %w", fileDisplayName(name), err)
+		}
+		return nil
+	})
+}
+
+var _ upload.UploadFileVerifier = (*nodeLogUploadFileVerifier)(nil)
@@ -0,0 +1,62 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadNodeLogFiles(t *testing.T) {
+	files := map[string]string{
+		"node-1/journal.json": strings.Join([]string{
+			`{"__REALTIME_TIMESTAMP":"1735689602000000","_HOSTNAME":"node-1","SYSLOG_IDENTIFIER":"kubelet","MESSAGE":"n1-2"}`,
+			`{"__REALTIME_TIMESTAMP":"1735689600000000","_HOSTNAME":"node-1","SYSLOG_IDENTIFIER":"kubelet","MESSAGE":"n1-0"}`,
+		}, "\n"),
+		"node-2/syslog": strings.Join([]string{
+			`Jan  1 00:00:01 node-2 containerd[200]: n2-1`,
+			`  continuation line of the previous message`,
+			"",
+			`Jan  1 00:00:03 node-2 containerd[200]: n2-3`,
+		}, "\n"),
+	}
+	archive := tarGzipArchive(t, files, []string{"node-1/journal.json", "node-2/syslog"})
+	parser := &nodeLogParser{referenceTime: time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)}
+
+	logs, err := readNodeLogFiles(context.Background(), bytes.NewReader(archive), int64(len(archive)), parser, inspectionmetadata.NewTaskProgressMetadata("test"))
+	if err != nil {
+		t.Fatalf("readNodeLogFiles() returned an unexpected error: %v", err)
+	}
+	got := []string{}
+	for _, l := range logs {
+		got = append(got, l.ReadStringOrDefault("MESSAGE", ""))
+	}
+	want := []string{"n1-0", "n2-1", "n1-2", "n2-3"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("readNodeLogFiles() mismatch (-want +got):\n%s", diff)
+	}
+	for _, l := range logs {
+		if _, err := log.GetFieldSet(l, &log.CommonFieldSet{}); err != nil {
+			t.Errorf("CommonFieldSet is not set on a log: %v", err)
+		}
+	}
+}
@@ -30,13 +30,13 @@ import (
 var NonEventAuditLogFilterTask = inspectiontaskbase.NewProgressReportableInspectionTask(
 	ossclusterk8s_contract.NonEventAuditLogFilterTaskID,
 	[]taskid.UntypedTaskReference{
-		ossclusterk8s_contract.AuditLogFileReaderTaskID.Ref(),
+		ossclusterk8s_contract.AuditLogStageCorrelatorTaskID.Ref(),
 	}, func(ctx context.Context, taskMode inspectioncore_contract.InspectionTaskModeType, progress *inspectionmetadata.TaskProgressMetadata) ([]*log.Log, error) {
 		if taskMode == inspectioncore_contract.TaskModeDryRun {
 			return []*log.Log{}, nil
 		}
 
-		logs := coretask.GetTaskResult(ctx, ossclusterk8s_contract.AuditLogFileReaderTaskID.Ref())
+		logs := coretask.GetTaskResult(ctx, ossclusterk8s_contract.AuditLogStageCorrelatorTaskID.Ref()).Logs
 
 		var auditLogs []*log.Log
 
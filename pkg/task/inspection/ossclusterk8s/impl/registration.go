@@ -33,9 +33,20 @@ func Register(registry coreinspection.InspectionTaskRegistry) error {
 	return coretask.RegisterTasks(registry,
 		InputAuditLogFilesTask,
 		AuditLogFileReaderTask,
+		AuditLogStageCorrelatorTask,
 		EventAuditLogFilterTask,
 		NonEventAuditLogFilterTask,
 		OSSK8sAuditLogSourceTask,
 		OSSK8sEventLogParserTask,
+		InputNodeLogFilesTask,
+		NodeLogFileReaderTask,
+		NodeLogFieldSetReaderTask,
+		NodeLogTailTask,
+		InputControlPlaneLogFilesTask,
+		ControlPlaneLogFileReaderTask,
+		ControlPlaneLogTailTask,
+		InputContainerLogFilesTask,
+		ContainerLogFileReaderTask,
+		ContainerLogHistoryModifierTask,
 	)
 }
@@ -0,0 +1,70 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/khictx"
+	"github.com/GoogleCloudPlatform/khi/pkg/common/typedmap"
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	inspectioncore_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/contract"
+)
+
+// extendInspectionTimeRange extends the time range of the inspection in the header metadata to include the given logs sorted by timestamp.
+// OSS inspections have no time range given from the form. The time range is decided from the read logs instead.
+// The fields are updated atomically because the reader tasks of the inspection call this in parallel.
+func extendInspectionTimeRange(ctx context.Context, sortedLogs []*log.Log) {
+	if len(sortedLogs) == 0 {
+		return
+	}
+	startTime := log.MustGetFieldSet(sortedLogs[0], &log.CommonFieldSet{}).Timestamp
+	endTime := log.MustGetFieldSet(sortedLogs[len(sortedLogs)-1], &log.CommonFieldSet{}).Timestamp
+
+	metadataSet := khictx.MustGetValue(ctx, inspectioncore_contract.InspectionRunMetadata)
+	header := typedmap.GetOrDefault(metadataSet, inspectionmetadata.HeaderMetadataKey, &inspectionmetadata.HeaderMetadata{})
+
+	storeUnixSecondsIf(&header.StartTimeUnixSeconds, startTime.Unix(), func(current int64) bool { return startTime.Unix() < current })
+	storeUnixSecondsIf(&header.EndTimeUnixSeconds, endTime.Unix(), func(current int64) bool { return endTime.Unix() > current })
+}
+
+// storeUnixSecondsIf atomically stores the value to the field when the field is unset or replace returns true for its current value.
+// The field is 0 before any reader task sets the time range.
+func storeUnixSecondsIf(field *int64, value int64, replace func(current int64) bool) {
+	for {
+		current := atomic.LoadInt64(field)
+		if current != 0 && !replace(current) {
+			return
+		}
+		if atomic.CompareAndSwapInt64(field, current, value) {
+			return
+		}
+	}
+}
+
+// timeInReferenceYear returns the time parsed from a timestamp without the year (e.g. syslog or klog headers) in the year of the reference time.
+// The timestamp is read as a wall clock in the location of the reference time because these timestamps don't contain the time zone either.
+// The previous year is used when the time is later than the reference time, assuming logs are read after they were written.
+func timeInReferenceYear(t time.Time, referenceTime time.Time) time.Time {
+	t = time.Date(referenceTime.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), referenceTime.Location())
+	// Allow a small clock skew between the log writer and the reference time.
+	if t.After(referenceTime.Add(24 * time.Hour)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ossclusterk8s_impl
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/khictx"
+	"github.com/GoogleCloudPlatform/khi/pkg/common/typedmap"
+	inspectionmetadata "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/metadata"
+	inspectiontest "github.com/GoogleCloudPlatform/khi/pkg/core/inspection/test"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	inspectioncore_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/inspectioncore/contract"
+)
+
+func TestExtendInspectionTimeRange(t *testing.T) {
+	baseTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	ctx := inspectiontest.WithDefaultTestInspectionTaskContext(t.Context())
+	logRanges := [][]time.Time{
+		{baseTime.Add(10 * time.Minute), baseTime.Add(20 * time.Minute)},
+		{baseTime, baseTime.Add(5 * time.Minute)},
+		{baseTime.Add(15 * time.Minute), baseTime.Add(time.Hour)},
+		{},
+	}
+
+	var wg sync.WaitGroup
+	for _, timestamps := range logRanges {
+		logs := []*log.Log{}
+		for _, timestamp := range timestamps {
+			logs = append(logs, log.NewLogWithFieldSetsForTest(&log.CommonFieldSet{Timestamp: timestamp}))
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			extendInspectionTimeRange(ctx, logs)
+		}()
+	}
+	wg.Wait()
+
+	metadata := khictx.MustGetValue(ctx, inspectioncore_contract.InspectionRunMetadata)
+	header, found := typedmap.Get(metadata, inspectionmetadata.HeaderMetadataKey)
+	if !found {
+		t.Fatalf("header metadata not found")
+	}
+	if header.StartTimeUnixSeconds != baseTime.Unix() {
+		t.Errorf("StartTimeUnixSeconds = %d, want %d", header.StartTimeUnixSeconds, baseTime.Unix())
+	}
+	if header.EndTimeUnixSeconds != baseTime.Add(time.Hour).Unix() {
+		t.Errorf("EndTimeUnixSeconds = %d, want %d", header.EndTimeUnixSeconds, baseTime.Add(time.Hour).Unix())
+	}
+}
+
+func TestTimeInReferenceYear(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	testCases := []struct {
+		name          string
+		timestamp     time.Time
+		referenceTime time.Time
+		want          time.Time
+	}{
+		{
+			name:          "time before the reference time",
+			timestamp:     time.Date(0, time.January, 1, 10, 0, 0, 0, time.UTC),
+			referenceTime: time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC),
+			want:          time.Date(2025, time.January, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "time later than the reference time is in the previous year",
+			timestamp:     time.Date(0, time.December, 31, 10, 0, 0, 0, time.UTC),
+			referenceTime: time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC),
+			want:          time.Date(2024, time.December, 31, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "time is read in the location of the reference time",
+			timestamp:     time.Date(0, time.January, 1, 10, 0, 0, 0, time.UTC),
+			referenceTime: time.Date(2025, time.January, 2, 0, 0, 0, 0, jst),
+			want:          time.Date(2025, time.January, 1, 10, 0, 0, 0, jst),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := timeInReferenceYear(tc.timestamp, tc.referenceTime)
+			if !got.Equal(tc.want) {
+				t.Errorf("timeInReferenceYear() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcp_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud/logconvert"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud/logfilter"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	// fakeLoggingDefaultPageSize is the page size used when the request doesn't specify it. This is same as Cloud Logging.
+	fakeLoggingDefaultPageSize = 50
+	// fakeLoggingMaxPageSize is the maximum page size accepted by Cloud Logging.
+	fakeLoggingMaxPageSize = 1000
+	// fakeLoggingPageTokenPrefix is the prefix of the page tokens to reject page tokens not issued by the fake.
+	fakeLoggingPageTokenPrefix    = "fake-logging-offset:"
+	fakeLoggingListenerBufferSize = 1024 * 1024
+)
+
+// fakeLogEntry is a LogEntry served by FakeLoggingServer with its JSON representation used for evaluating filters.
+type fakeLogEntry struct {
+	entry  *loggingpb.LogEntry
+	fields map[string]any
+}
+
+// FakeLoggingServer is an in-process fake of the LoggingServiceV2 gRPC API of Cloud Logging serving LogEntry fixtures.
+// It supports ListLogEntries with the subset of the Logging query language supported by the logfilter package, ordering by timestamp, paging and injected errors.
+// The other methods return Unimplemented.
+type FakeLoggingServer struct {
+	loggingpb.UnimplementedLoggingServiceV2Server
+
+	lock           sync.Mutex
+	entries        []*fakeLogEntry
+	injectedErrors []codes.Code
+	requests       []*loggingpb.ListLogEntriesRequest
+
+	listener *bufconn.Listener
+	server   *grpc.Server
+}
+
+// NewFakeLoggingServer starts a FakeLoggingServer serving the given LogEntries. The server is stopped at the end of the test.
+func NewFakeLoggingServer(t *testing.T, entries ...*loggingpb.LogEntry) *FakeLoggingServer {
+	t.Helper()
+	s := &FakeLoggingServer{
+		listener: bufconn.Listen(fakeLoggingListenerBufferSize),
+		server:   grpc.NewServer(),
+	}
+	loggingpb.RegisterLoggingServiceV2Server(s.server, s)
+	go s.server.Serve(s.listener)
+	t.Cleanup(s.server.Stop)
+	s.AddEntries(t, entries...)
+	return s
+}
+
+// AddEntries adds LogEntries served by the server.
+func (s *FakeLoggingServer) AddEntries(t *testing.T, entries ...*loggingpb.LogEntry) {
+	t.Helper()
+	fakeEntries := make([]*fakeLogEntry, 0, len(entries))
+	for _, entry := range entries {
+		fields, err := logfilter.FieldsFromLogEntry(entry)
+		if err != nil {
+			t.Fatalf("failed to convert the LogEntry %s for the fake Cloud Logging server: %v", entry.GetInsertId(), err)
+		}
+		fakeEntries = append(fakeEntries, &fakeLogEntry{entry: entry, fields: fields})
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.entries = append(s.entries, fakeEntries...)
+}
+
+// LoadEntries adds LogEntries read from the file in the format exported from Cloud Logging, a JSON array or JSON lines of LogEntries.
+func (s *FakeLoggingServer) LoadEntries(t *testing.T, path string) {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open the LogEntry fixture %s: %v", path, err)
+	}
+	defer file.Close()
+	var entries []*loggingpb.LogEntry
+	err = logconvert.SplitLogEntriesJSON(file, func(entryJSON []byte) error {
+		entry, err := logconvert.LogEntryFromJSON(entryJSON)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read the LogEntry fixture %s: %v", path, err)
+	}
+	s.AddEntries(t, entries...)
+}
+
+// InjectError makes the next count calls of ListLogEntries fail with the given code, e.g. codes.PermissionDenied or codes.ResourceExhausted.
+// Injected errors are returned in the injected order before evaluating the requests.
+func (s *FakeLoggingServer) InjectError(code codes.Code, count int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for i := 0; i < count; i++ {
+		s.injectedErrors = append(s.injectedErrors, code)
+	}
+}
+
+// Requests returns the ListLogEntries requests received by the server including the requests failed with injected errors.
+func (s *FakeLoggingServer) Requests() []*loggingpb.ListLogEntriesRequest {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// ClientFactoryOption returns a googlecloud.ClientFactoryOption to make the logging clients created by googlecloud.ClientFactory connect to this server.
+// The given dial options are added to the connections, e.g. interceptors recording the calls.
+func (s *FakeLoggingServer) ClientFactoryOption(dialOptions ...grpc.DialOption) googlecloud.ClientFactoryOption {
+	return func(f *googlecloud.ClientFactory) error {
+		f.LoggingClientOptions = append(f.LoggingClientOptions, func(opts []option.ClientOption, c googlecloud.ResourceContainer) ([]option.ClientOption, error) {
+			// A connection is created for each client because closing a client closes its connection.
+			conn, err := grpc.NewClient("passthrough:///fake-logging", append([]grpc.DialOption{
+				grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+					return s.listener.DialContext(ctx)
+				}),
+				grpc.WithTransportCredentials(insecure.NewCredentials()),
+			}, dialOptions...)...)
+			if err != nil {
+				return nil, err
+			}
+			// The options given before are discarded because credentials are not used with the fake.
+			return []option.ClientOption{option.WithGRPCConn(conn)}, nil
+		})
+		return nil
+	}
+}
+
+// ListLogEntries implements loggingpb.LoggingServiceV2Server.
+func (s *FakeLoggingServer) ListLogEntries(ctx context.Context, req *loggingpb.ListLogEntriesRequest) (*loggingpb.ListLogEntriesResponse, error) {
+	s.lock.Lock()
+	s.requests = append(s.requests, req)
+	if len(s.injectedErrors) > 0 {
+		code := s.injectedErrors[0]
+		s.injectedErrors = s.injectedErrors[1:]
+		s.lock.Unlock()
+		return nil, status.Errorf(code, "injected error from the fake Cloud Logging server")
+	}
+	entries := slices.Clone(s.entries)
+	s.lock.Unlock()
+
+	if len(req.GetResourceNames()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "resource_names must not be empty")
+	}
+	pageSize := int(req.GetPageSize())
+	if pageSize < 0 || pageSize > fakeLoggingMaxPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must be between 0 and %d", fakeLoggingMaxPageSize)
+	}
+	if pageSize == 0 {
+		pageSize = fakeLoggingDefaultPageSize
+	}
+	offset, err := parseFakeLoggingPageToken(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	filter, err := logfilter.Parse(req.GetFilter())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid filter: %v", err)
+	}
+
+	var matched []*loggingpb.LogEntry
+	for _, entry := range entries {
+		if logNameInResourceNames(entry.entry.GetLogName(), req.GetResourceNames()) && filter.Match(entry.fields) {
+			matched = append(matched, entry.entry)
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(req.GetOrderBy())) {
+	case "", "timestamp asc":
+		slices.SortStableFunc(matched, func(a, b *loggingpb.LogEntry) int {
+			return a.GetTimestamp().AsTime().Compare(b.GetTimestamp().AsTime())
+		})
+	case "timestamp desc":
+		slices.SortStableFunc(matched, func(a, b *loggingpb.LogEntry) int {
+			return b.GetTimestamp().AsTime().Compare(a.GetTimestamp().AsTime())
+		})
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported order_by %q", req.GetOrderBy())
+	}
+
+	if offset > len(matched) {
+		return nil, status.Error(codes.InvalidArgument, "page_token is out of range")
+	}
+	end := min(offset+pageSize, len(matched))
+	response := &loggingpb.ListLogEntriesResponse{
+		Entries: matched[offset:end],
+	}
+	if end < len(matched) {
+		response.NextPageToken = fakeLoggingPageToken(end)
+	}
+	return response, nil
+}
+
+// fakeLoggingPageToken returns the opaque page token pointing the given offset in the matched LogEntries.
+func fakeLoggingPageToken(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(fakeLoggingPageTokenPrefix + strconv.Itoa(offset)))
+}
+
+// parseFakeLoggingPageToken returns the offset from the page token returned by fakeLoggingPageToken. It returns 0 for the empty token.
+func parseFakeLoggingPageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(token)
+	if err != nil || !strings.HasPrefix(string(decoded), fakeLoggingPageTokenPrefix) {
+		return 0, fmt.Errorf("invalid page_token %q", token)
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(decoded), fakeLoggingPageTokenPrefix))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid page_token %q", token)
+	}
+	return offset, nil
+}
+
+// logNameInResourceNames returns true when the log belongs to one of the resource containers of the given resource names.
+// Resource names are compared only with their containers (e.g. `projects/foo`), so log views and buckets in the container include all logs of the container.
+// LogEntries without logName are included in any resource names.
+func logNameInResourceNames(logName string, resourceNames []string) bool {
+	if logName == "" {
+		return true
+	}
+	logContainer := resourceContainerOfName(logName)
+	for _, resourceName := range resourceNames {
+		if resourceContainerOfName(resourceName) == logContainer {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceContainerOfName returns the first 2 segments of the resource name. (e.g. `projects/foo` for `projects/foo/logs/bar`)
+func resourceContainerOfName(name string) string {
+	segments := strings.SplitN(name, "/", 3)
+	if len(segments) < 2 {
+		return name
+	}
+	return segments[0] + "/" + segments[1]
+}
+
+var _ loggingpb.LoggingServiceV2Server = (*FakeLoggingServer)(nil)
@@ -0,0 +1,241 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcp_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/GoogleCloudPlatform/khi/pkg/api/googlecloud"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/iterator"
+	"google.golang.org/genproto/googleapis/api/monitoredres"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func fakeLoggingTestEntry(insertID string, logName string, resourceType string, clusterName string, timestamp time.Time) *loggingpb.LogEntry {
+	return &loggingpb.LogEntry{
+		InsertId:  insertID,
+		LogName:   logName,
+		Timestamp: timestamppb.New(timestamp),
+		Resource: &monitoredres.MonitoredResource{
+			Type:   resourceType,
+			Labels: map[string]string{"cluster_name": clusterName},
+		},
+	}
+}
+
+// listInsertIDs lists all LogEntries matching the request through the logging client created by googlecloud.ClientFactory and returns their insertIds.
+func listInsertIDs(t *testing.T, server *FakeLoggingServer, req *loggingpb.ListLogEntriesRequest) ([]string, error) {
+	t.Helper()
+	factory, err := googlecloud.NewClientFactory(server.ClientFactoryOption())
+	if err != nil {
+		t.Fatalf("failed to initialize ClientFactory: %v", err)
+	}
+	client, err := factory.LoggingClient(t.Context(), googlecloud.Project("test-project"))
+	if err != nil {
+		t.Fatalf("failed to initialize LoggingClient: %v", err)
+	}
+	defer client.Close()
+	iter := client.ListLogEntries(t.Context(), req, googlecloud.DefaultRetryPolicy)
+	var result []string
+	for {
+		entry, err := iter.Next()
+		if err == iterator.Done {
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+		result = append(result, entry.InsertId)
+	}
+}
+
+func TestFakeLoggingServer_ListLogEntries(t *testing.T) {
+	baseTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	server := NewFakeLoggingServer(t,
+		fakeLoggingTestEntry("c", "projects/test-project/logs/events", "k8s_cluster", "cluster-a", baseTime.Add(2*time.Minute)),
+		fakeLoggingTestEntry("a", "projects/test-project/logs/cloudaudit.googleapis.com%2Factivity", "k8s_cluster", "cluster-a", baseTime),
+		fakeLoggingTestEntry("b", "projects/test-project/logs/events", "k8s_node", "cluster-b", baseTime.Add(time.Minute)),
+		fakeLoggingTestEntry("d", "projects/other-project/logs/events", "k8s_cluster", "cluster-a", baseTime.Add(3*time.Minute)),
+		fakeLoggingTestEntry("e", "projects/test-project/logs/events", "gce_instance", "cluster-a", baseTime.Add(4*time.Minute)),
+	)
+	testCases := []struct {
+		name          string
+		resourceNames []string
+		filter        string
+		orderBy       string
+		pageSize      int32
+		want          []string
+	}{
+		{
+			name:          "empty filter returns logs in the resource names ordered by timestamp",
+			resourceNames: []string{"projects/test-project"},
+			want:          []string{"a", "b", "c", "e"},
+		},
+		{
+			name:          "multiple resource names including a log view",
+			resourceNames: []string{"projects/test-project", "projects/other-project/locations/global/buckets/_Default/views/_AllLogs"},
+			want:          []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:          "paging with a small page size",
+			resourceNames: []string{"projects/test-project"},
+			pageSize:      1,
+			want:          []string{"a", "b", "c", "e"},
+		},
+		{
+			name:          "descending order",
+			resourceNames: []string{"projects/test-project"},
+			orderBy:       "timestamp desc",
+			pageSize:      2,
+			want:          []string{"e", "c", "b", "a"},
+		},
+		{
+			name:          "timestamp range",
+			resourceNames: []string{"projects/test-project"},
+			filter: `timestamp >= "2025-01-01T00:01:00Z"
+timestamp < "2025-01-01T00:04:00Z"`,
+			want: []string{"b", "c"},
+		},
+		{
+			name:          "resource type with OR list and labels",
+			resourceNames: []string{"projects/test-project"},
+			filter: `resource.type=("k8s_cluster" OR "k8s_node")
+resource.labels.cluster_name="cluster-a"`,
+			want: []string{"a", "c"},
+		},
+		{
+			name:          "negation and LOG_ID",
+			resourceNames: []string{"projects/test-project"},
+			filter:        `-LOG_ID("cloudaudit.googleapis.com/activity") AND NOT resource.type="gce_instance"`,
+			want:          []string{"b", "c"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := listInsertIDs(t, server, &loggingpb.ListLogEntriesRequest{
+				ResourceNames: tc.resourceNames,
+				Filter:        tc.filter,
+				OrderBy:       tc.orderBy,
+				PageSize:      tc.pageSize,
+			})
+			if err != nil {
+				t.Fatalf("ListLogEntries returned an unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ListLogEntries returned unexpected logs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFakeLoggingServer_Paging(t *testing.T) {
+	baseTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var entries []*loggingpb.LogEntry
+	for i := 0; i < 5; i++ {
+		entries = append(entries, fakeLoggingTestEntry(string(rune('a'+i)), "projects/test-project/logs/events", "k8s_cluster", "cluster-a", baseTime.Add(time.Duration(i)*time.Second)))
+	}
+	server := NewFakeLoggingServer(t, entries...)
+	_, err := listInsertIDs(t, server, &loggingpb.ListLogEntriesRequest{
+		ResourceNames: []string{"projects/test-project"},
+		PageSize:      2,
+	})
+	if err != nil {
+		t.Fatalf("ListLogEntries returned an unexpected error: %v", err)
+	}
+	var gotPageTokens []bool
+	for _, req := range server.Requests() {
+		gotPageTokens = append(gotPageTokens, req.PageToken != "")
+	}
+	if diff := cmp.Diff([]bool{false, true, true}, gotPageTokens); diff != "" {
+		t.Errorf("unexpected requests with page tokens (-want +got):\n%s", diff)
+	}
+}
+
+func TestFakeLoggingServer_InjectError(t *testing.T) {
+	entry := fakeLoggingTestEntry("a", "projects/test-project/logs/events", "k8s_cluster", "cluster-a", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC))
+	t.Run("retried error", func(t *testing.T) {
+		server := NewFakeLoggingServer(t, entry)
+		server.InjectError(codes.ResourceExhausted, 2)
+		got, err := listInsertIDs(t, server, &loggingpb.ListLogEntriesRequest{ResourceNames: []string{"projects/test-project"}})
+		if err != nil {
+			t.Fatalf("ListLogEntries returned an unexpected error: %v", err)
+		}
+		if diff := cmp.Diff([]string{"a"}, got); diff != "" {
+			t.Errorf("ListLogEntries returned unexpected logs (-want +got):\n%s", diff)
+		}
+		if len(server.Requests()) != 3 {
+			t.Errorf("got %d requests, want 3 requests including 2 retries", len(server.Requests()))
+		}
+	})
+	t.Run("not retried error", func(t *testing.T) {
+		server := NewFakeLoggingServer(t, entry)
+		server.InjectError(codes.PermissionDenied, 1)
+		_, err := listInsertIDs(t, server, &loggingpb.ListLogEntriesRequest{ResourceNames: []string{"projects/test-project"}})
+		if status.Code(err) != codes.PermissionDenied {
+			t.Errorf("got error %v, want PermissionDenied", err)
+		}
+	})
+}
+
+func TestFakeLoggingServer_InvalidRequest(t *testing.T) {
+	server := NewFakeLoggingServer(t)
+	testCases := []struct {
+		name string
+		req  *loggingpb.ListLogEntriesRequest
+	}{
+		{name: "no resource names", req: &loggingpb.ListLogEntriesRequest{}},
+		{name: "invalid filter", req: &loggingpb.ListLogEntriesRequest{ResourceNames: []string{"projects/test-project"}, Filter: `"`}},
+		{name: "invalid page token", req: &loggingpb.ListLogEntriesRequest{ResourceNames: []string{"projects/test-project"}, PageToken: "foo"}},
+		{name: "unsupported order", req: &loggingpb.ListLogEntriesRequest{ResourceNames: []string{"projects/test-project"}, OrderBy: "insert_id"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := listInsertIDs(t, server, tc.req)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("got error %v, want InvalidArgument", err)
+			}
+		})
+	}
+}
+
+func TestFakeLoggingServer_LoadEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "entries.json")
+	fixture := `[
+  {"insertId":"a","logName":"projects/test-project/logs/events","timestamp":"2025-01-01T00:00:00Z","resource":{"type":"k8s_cluster"}},
+  {"insertId":"b","logName":"projects/test-project/logs/events","timestamp":"2025-01-01T00:00:01Z","resource":{"type":"k8s_node"}}
+]`
+	if err := os.WriteFile(path, []byte(fixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+	server := NewFakeLoggingServer(t)
+	server.LoadEntries(t, path)
+	got, err := listInsertIDs(t, server, &loggingpb.ListLogEntriesRequest{
+		ResourceNames: []string{"projects/test-project"},
+		Filter:        `resource.type="k8s_node"`,
+	})
+	if err != nil {
+		t.Fatalf("ListLogEntries returned an unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"b"}, got); diff != "" {
+		t.Errorf("ListLogEntries returned unexpected logs (-want +got):\n%s", diff)
+	}
+}
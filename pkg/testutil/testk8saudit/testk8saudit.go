@@ -0,0 +1,92 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testk8saudit provides audit log fixtures and a step runner for the tests of Kubernetes audit log recorders.
+package testk8saudit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structured"
+	"github.com/GoogleCloudPlatform/khi/pkg/model"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/log"
+	commonlogk8saudit_contract "github.com/GoogleCloudPlatform/khi/pkg/task/inspection/commonlogk8saudit/contract"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil/testchangeset"
+)
+
+// BaseTime is the timestamp of the log given in the first step of RunSteps.
+var BaseTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// MustAuditLog returns an audit log of the given operation with the manifest as its resource body.
+func MustAuditLog(t *testing.T, timestamp time.Time, requestor string, operation *model.KubernetesObjectOperation, manifest string) *commonlogk8saudit_contract.AuditLogParserInput {
+	t.Helper()
+	node, err := structured.FromYAML(manifest)
+	if err != nil {
+		t.Fatalf("failed to parse the manifest: %v", err)
+	}
+	return &commonlogk8saudit_contract.AuditLogParserInput{
+		Log:                log.NewLogWithFieldSetsForTest(&log.CommonFieldSet{Timestamp: timestamp}),
+		Requestor:          requestor,
+		Operation:          operation,
+		ResourceBodyReader: structured.NewNodeReader(node),
+		ResourceBodyYaml:   manifest,
+	}
+}
+
+// Step is an audit log given to a recorder in RunSteps and the assertions for the ChangeSet recorded from it.
+type Step struct {
+	Manifest string
+	// Verb overrides the verb of the operation given to RunSteps when it's specified.
+	Verb enum.RevisionVerb
+	// Timestamp is the timestamp of the log. BaseTime shifted by a minute for each step is used when it's zero.
+	Timestamp time.Time
+	Asserters []testchangeset.ChangeSetAsserter
+}
+
+// RecordFunc records the ChangeSet for an audit log from the state returned for the previous log and returns the next state.
+type RecordFunc[T any] func(ctx context.Context, l *commonlogk8saudit_contract.AuditLogParserInput, prevState T, cs *history.ChangeSet) (T, error)
+
+// RunSteps calls record for each step in order with passing the state returned from the previous step.
+// The operation is used for every step, and its verb defaults to RevisionVerbUpdate when neither the operation nor the step specifies it.
+func RunSteps[T any](t *testing.T, steps []Step, requestor string, operation model.KubernetesObjectOperation, record RecordFunc[T]) {
+	t.Helper()
+	var prevState T
+	for i, step := range steps {
+		stepOperation := operation
+		if step.Verb != enum.RevisionVerbUnknown {
+			stepOperation.Verb = step.Verb
+		}
+		if stepOperation.Verb == enum.RevisionVerbUnknown {
+			stepOperation.Verb = enum.RevisionVerbUpdate
+		}
+		timestamp := step.Timestamp
+		if timestamp.IsZero() {
+			timestamp = BaseTime.Add(time.Duration(i) * time.Minute)
+		}
+		l := MustAuditLog(t, timestamp, requestor, &stepOperation, step.Manifest)
+		cs := history.NewChangeSet(l.Log)
+		nextState, err := record(context.Background(), l, prevState, cs)
+		if err != nil {
+			t.Fatalf("step %d: unexpected error: %v", i, err)
+		}
+		for _, asserter := range step.Asserters {
+			asserter.Assert(t, cs)
+		}
+		prevState = nextState
+	}
+}
@@ -107,6 +107,22 @@ describe('FileParameterComponent', () => {
     expect(uploadButton.attributes['disabled']).toBeFalsy();
   });
 
+  it('shows all filenames when multiple files are selected', () => {
+    fixture.componentInstance.processReceivedFileInfo([
+      new File([], 'audit-1.log'),
+      new File([], 'audit-2.log.gz'),
+    ]);
+    fixture.detectChanges();
+
+    const dropAreaFilename = fixture.debugElement.query(
+      By.css('.drop-area-hint-file-name > span'),
+    );
+    expect(dropAreaFilename.nativeElement.textContent).toBe(
+      'audit-1.log, audit-2.log.gz',
+    );
+    expect(fixture.componentInstance.selectedFiles.length).toBe(2);
+  });
+
   it('shows progress bar with upload status', async () => {
     mockFileUploader.statusProvider = () =>
       of({
@@ -119,7 +135,7 @@ describe('FileParameterComponent', () => {
       ...defaultFileParameterForm,
       status: UploadStatus.Uploading,
     });
-    fixture.componentInstance.selectedFile = new File([], 'a mock file');
+    fixture.componentInstance.selectedFiles = [new File([], 'a mock file')];
     fixture.componentInstance.onClickUploadButton();
     fixture.detectChanges();
     const harnessLoader = TestbedHarnessEnvironment.loader(fixture);
@@ -142,7 +158,7 @@ describe('FileParameterComponent', () => {
       ...defaultFileParameterForm,
       status: UploadStatus.Verifying,
     });
-    fixture.componentInstance.selectedFile = new File([], 'a mock file');
+    fixture.componentInstance.selectedFiles = [new File([], 'a mock file')];
     fixture.componentInstance.onClickUploadButton();
     fixture.detectChanges();
     const harnessLoader = TestbedHarnessEnvironment.loader(fixture);
@@ -177,7 +193,7 @@ describe('FileParameterComponent', () => {
       ...defaultFileParameterForm,
       status: UploadStatus.Verifying,
     });
-    fixture.componentInstance.selectedFile = new File([], 'a mock file');
+    fixture.componentInstance.selectedFiles = [new File([], 'a mock file')];
     fixture.componentInstance.isSelectedFileUploaded.set(false);
     fixture.detectChanges();
     const uploadButton = fixture.debugElement.query(By.css('.upload-button'));
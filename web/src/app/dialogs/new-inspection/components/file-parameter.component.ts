@@ -28,7 +28,6 @@ import { ReactiveFormsModule } from '@angular/forms';
 import { MatButtonModule } from '@angular/material/button';
 import { MatFormFieldModule } from '@angular/material/form-field';
 import { MatIconModule } from '@angular/material/icon';
-import { MatSnackBar, MatSnackBarModule } from '@angular/material/snack-bar';
 import { FILE_UPLOADER } from './service/file-uploader';
 import { MatProgressSpinnerModule } from '@angular/material/progress-spinner';
 import {
@@ -53,7 +52,6 @@ import { interval, Subject, takeUntil, takeWhile } from 'rxjs';
     ReactiveFormsModule,
     MatIconModule,
     MatButtonModule,
-    MatSnackBarModule,
     MatProgressSpinnerModule,
     ParameterHeaderComponent,
     ParameterHintComponent,
@@ -92,7 +90,7 @@ export class FileParameterComponent implements OnDestroy {
   uploadRatio = signal<number | undefined>(0);
 
   /**
-   * The filename uploaded or will be uploaded on this field.
+   * The filename uploaded or will be uploaded on this field. This is the list of the names when multiple files are selected.
    * This state directly hold by FileUploadComponent and not used except for users to know which they uploaded.
    */
   filename = signal('');
@@ -100,12 +98,13 @@ export class FileParameterComponent implements OnDestroy {
   @ViewChild('fileInput')
   fileInput!: ElementRef<HTMLInputElement>;
 
-  selectedFile: File | null = null;
+  /**
+   * The files selected to be uploaded. Multiple files are uploaded at once and stored as an archive on the server.
+   */
+  selectedFiles: File[] = [];
 
   private formStoreRefreshCancel = new Subject();
 
-  private snackBar = inject(MatSnackBar);
-
   private uploader = inject(FILE_UPLOADER);
 
   private store = inject(PARAMETER_STORE);
@@ -162,12 +161,12 @@ export class FileParameterComponent implements OnDestroy {
    * Eventhandler for the upload button.
    */
   onClickUploadButton() {
-    if (this.selectedFile === null) {
+    if (this.selectedFiles.length === 0) {
       return;
     }
     this.isSelectedFileUploading.set(true);
     this.uploader
-      .upload(this.parameter().token, this.selectedFile)
+      .upload(this.parameter().token, this.selectedFiles)
       .subscribe((status) => {
         if (status.completeRatioUnknown) {
           this.uploadRatio.set(undefined);
@@ -185,13 +184,12 @@ export class FileParameterComponent implements OnDestroy {
   }
 
   processReceivedFileInfo(files: File[]) {
-    if (files.length > 1) {
-      this.snackBar.open('2 or more files are specified at once.');
+    if (files.length === 0) {
+      return;
     }
-    const file = files[0];
-    this.filename.set(file.name);
+    this.filename.set(files.map((file) => file.name).join(', '));
     this.isSelectedFileUploaded.set(false);
-    this.selectedFile = file;
+    this.selectedFiles = files;
   }
 
   /**
@@ -194,6 +194,12 @@ export class StartupDialogComponent {
     });
   }
 
+  resumeTask(id: string) {
+    this.backendAPI.resumeInspection(id).subscribe(() => {
+      console.log(`task ${id} was resumed`);
+    });
+  }
+
   openTaskResult(id: string) {
     this.loader.loadInspectionDataFromBackend(id);
     this.dialogRef.close();
@@ -63,6 +63,14 @@ export interface BackendAPI {
    */
   getInspections(): Observable<GetInspectionResponse>;
 
+  /**
+   * Watch the status of tasks with server-sent events. The observable emits the latest list whenever any inspection is changed.
+   * Expected called endpoint: GET /api/v3/inspection/events
+   *
+   * The observable errors when the browser doesn't support EventSource or the connection to the event stream fails.
+   */
+  watchInspections(): Observable<GetInspectionResponse>;
+
   /**
    * Create an inspection.
    * Expected called endpoint: POST /api/v3/inspection/types/<inspection-type>
@@ -150,6 +158,14 @@ export interface BackendAPI {
    */
   cancelInspection(inspectionID: string): Observable<void>;
 
+  /**
+   * Resume the inspection task finished with an error or a cancellation. Logs fetched in the previous run are reused.
+   * Expected called endpoint: POST /api/v3/inspection/<inspection-id>/resume
+   *
+   * @param inspectionID inspection ID to resume
+   */
+  resumeInspection(inspectionID: string): Observable<void>;
+
   /**
    * Get the current popup request.
    * Expected called endpoint: GET /api/v3/popup
@@ -171,7 +187,10 @@ export interface BackendAPI {
   answerPopup(answer: PopupAnswerResponse): Observable<void>;
 
   /**
-   * Upload the file as the one bound to the token.
+   * Upload the files as the one bound to the token. Multiple files are stored as a tar archive on the server.
    */
-  uploadFile(token: UploadToken, file: File): Observable<HttpEvent<unknown>>;
+  uploadFile(
+    token: UploadToken,
+    files: File[],
+  ): Observable<HttpEvent<unknown>>;
 }
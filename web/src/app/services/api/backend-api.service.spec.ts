@@ -38,6 +38,18 @@ import {
 import { BackendAPI } from './backend-api-interface';
 import { of } from 'rxjs';
 
+/**
+ * FakeEventSource is a minimum EventSource dispatching events given from tests.
+ */
+class FakeEventSource extends EventTarget {
+  onerror: ((event: Event) => void) | null = null;
+  closed = false;
+
+  close() {
+    this.closed = true;
+  }
+}
+
 describe('BackendAPIImpl testing', () => {
   let api: BackendAPIImpl;
   let httpTestingController: HttpTestingController;
@@ -116,6 +128,46 @@ describe('BackendAPIImpl testing', () => {
     req.flush(testData);
   });
 
+  it('can call watchInspections', () => {
+    const testData: GetInspectionResponse = {
+      inspections: {},
+      serverStat: {
+        totalMemoryAvailable: 10,
+      },
+    };
+    const fakeEventSource = new FakeEventSource();
+    const eventSourceSpy = spyOn(window, 'EventSource').and.returnValue(
+      fakeEventSource as unknown as EventSource,
+    );
+    const received: GetInspectionResponse[] = [];
+
+    const subscription = api
+      .watchInspections()
+      .subscribe((data) => received.push(data));
+    fakeEventSource.dispatchEvent(
+      new MessageEvent('inspections', { data: JSON.stringify(testData) }),
+    );
+    subscription.unsubscribe();
+
+    expect(eventSourceSpy).toHaveBeenCalledWith('/api/v3/inspection/events');
+    expect(received).toEqual([testData]);
+    expect(fakeEventSource.closed).toBeTrue();
+  });
+
+  it('errors watchInspections when the event stream fails', () => {
+    const fakeEventSource = new FakeEventSource();
+    spyOn(window, 'EventSource').and.returnValue(
+      fakeEventSource as unknown as EventSource,
+    );
+    let failed = false;
+
+    api.watchInspections().subscribe({ error: () => (failed = true) });
+    fakeEventSource.onerror?.(new Event('error'));
+
+    expect(failed).toBeTrue();
+    expect(fakeEventSource.closed).toBeTrue();
+  });
+
   it('can call createInspection', () => {
     const testData: CreateInspectionResponse = {
       inspectionID: 'test',
@@ -284,6 +336,16 @@ describe('BackendAPIImpl testing', () => {
     req.flush('');
   });
 
+  it('can call resumeInspection', () => {
+    api.resumeInspection('test').subscribe(() => {});
+    const req = httpTestingController.expectOne(
+      '/api/v3/inspection/test/resume',
+    );
+    expect(req.request.method).toEqual('POST');
+
+    req.flush('');
+  });
+
   it('can call getPopup', (done) => {
     const testResponse: PopupFormRequest = {
       id: 'test',
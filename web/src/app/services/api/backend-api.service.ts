@@ -124,6 +124,27 @@ export class BackendAPIImpl implements BackendAPI {
     return this.http.get<GetInspectionResponse>(url);
   }
 
+  public watchInspections(): Observable<GetInspectionResponse> {
+    const url = this.baseUrl + '/inspection/events';
+    return new Observable<GetInspectionResponse>((subscriber) => {
+      if (typeof EventSource === 'undefined') {
+        subscriber.error(new Error('EventSource is not supported'));
+        return;
+      }
+      const eventSource = new EventSource(url);
+      eventSource.addEventListener('inspections', (event) => {
+        subscriber.next(JSON.parse((event as MessageEvent<string>).data));
+      });
+      // EventSource reconnects automatically, but report the failure to let the caller decide how to recover from it.
+      eventSource.onerror = () => {
+        subscriber.error(
+          new Error(`Failed to connect to the event stream ${url}`),
+        );
+      };
+      return () => eventSource.close();
+    });
+  }
+
   public createInspection(
     inspectionTypeId: string,
   ): Observable<InspectionClient> {
@@ -260,14 +281,23 @@ export class BackendAPIImpl implements BackendAPI {
       .pipe(map(() => {}));
   }
 
+  public resumeInspection(inspectionID: string) {
+    const url = this.baseUrl + `/inspection/${inspectionID}/resume`;
+    return this.http
+      .post(url, null, { responseType: 'text' })
+      .pipe(map(() => {}));
+  }
+
   public uploadFile(
     token: UploadToken,
-    file: File,
+    files: File[],
   ): Observable<HttpEvent<unknown>> {
     const url = this.baseUrl + `/upload`;
     const formData = new FormData();
     formData.append('upload-token-id', token.id);
-    formData.append('file', file, file.name);
+    for (const file of files) {
+      formData.append('file', file, file.name);
+    }
     return this.http.post(url, formData, {
       reportProgress: true,
       observe: 'events',
@@ -0,0 +1,91 @@
+/**
+ * Copyright 2025 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import {
+  TestBed,
+  discardPeriodicTasks,
+  fakeAsync,
+  tick,
+} from '@angular/core/testing';
+import { Subject, of, throwError } from 'rxjs';
+import { BACKEND_API, BackendAPI } from './backend-api-interface';
+import { BackendConnectionServiceImpl } from './backend-connection.service';
+import { GetInspectionResponse } from 'src/app/common/schema/api-types';
+
+describe('BackendConnectionServiceImpl', () => {
+  const testData: GetInspectionResponse = {
+    inspections: {},
+    serverStat: {
+      totalMemoryAvailable: 10,
+    },
+  };
+  let backendAPISpy: jasmine.SpyObj<BackendAPI>;
+
+  beforeEach(() => {
+    backendAPISpy = jasmine.createSpyObj<BackendAPI>('BackendAPI', [
+      'getInspectionTypes',
+      'getInspections',
+      'watchInspections',
+    ]);
+    backendAPISpy.getInspectionTypes.and.returnValue(of({ types: [] }));
+    backendAPISpy.getInspections.and.returnValue(of(testData));
+  });
+
+  function createService(): BackendConnectionServiceImpl {
+    TestBed.configureTestingModule({
+      providers: [
+        BackendConnectionServiceImpl,
+        { provide: BACKEND_API, useValue: backendAPISpy },
+      ],
+    });
+    return TestBed.inject(BackendConnectionServiceImpl);
+  }
+
+  it('receives the task list from the event stream', () => {
+    const events = new Subject<GetInspectionResponse>();
+    backendAPISpy.watchInspections.and.returnValue(events);
+    const service = createService();
+    const received: GetInspectionResponse[] = [];
+
+    const subscription = service
+      .tasks()
+      .subscribe((data) => received.push(data));
+    events.next(testData);
+    subscription.unsubscribe();
+
+    expect(received).toEqual([testData]);
+    expect(backendAPISpy.getInspections).not.toHaveBeenCalled();
+  });
+
+  it('falls back to polling when the event stream fails', fakeAsync(() => {
+    backendAPISpy.watchInspections.and.returnValue(
+      throwError(() => new Error('test')),
+    );
+    const service = createService();
+    const received: GetInspectionResponse[] = [];
+
+    const subscription = service
+      .tasks()
+      .subscribe((data) => received.push(data));
+    tick(BackendConnectionServiceImpl.PROGRESS_POLLING_INTERVAL);
+    subscription.unsubscribe();
+
+    expect(received).toEqual([testData]);
+    expect(backendAPISpy.getInspections).toHaveBeenCalledTimes(1);
+    // The polling is shared without reference counting and keeps running after unsubscribing.
+    discardPeriodicTasks();
+  }));
+});
@@ -17,6 +17,7 @@
 import { Injectable, InjectionToken, inject } from '@angular/core';
 import {
   Observable,
+  catchError,
   exhaustMap,
   interval,
   retry,
@@ -38,7 +39,8 @@ export const BACKEND_CONNECTION = new InjectionToken<BackendConnectionService>(
 );
 
 /**
- * BackendConnectionService provides observables with polling backend endpoints.
+ * BackendConnectionService provides observables with watching backend endpoints.
+ * The task list is received from the server-sent events and it falls back to polling when the event stream is not available.
  */
 @Injectable()
 export class BackendConnectionServiceImpl implements BackendConnectionService {
@@ -65,7 +67,7 @@ export class BackendConnectionServiceImpl implements BackendConnectionService {
     }),
   );
 
-  private taskProgressObservable = interval(
+  private taskProgressPollingObservable = interval(
     BackendConnectionServiceImpl.PROGRESS_POLLING_INTERVAL,
   ).pipe(
     exhaustMap(() => this.backendApi.getInspections()),
@@ -76,12 +78,22 @@ export class BackendConnectionServiceImpl implements BackendConnectionService {
         );
       },
     }),
+    retry(),
+  );
+
+  private taskProgressObservable = this.backendApi.watchInspections().pipe(
+    catchError((err) => {
+      console.warn(
+        `Failed to watch task progress status. Falling back to polling:\n` +
+          err,
+      );
+      return this.taskProgressPollingObservable;
+    }),
     shareReplay({
       bufferSize: 1,
       // refCount is explcitly false to prevent waiting the next poll when a new subscriber added when there is no subscriber registered.
       refCount: false,
     }),
-    retry(),
   );
 
   inspectionTypes(): Observable<GetInspectionTypesResponse> {
@@ -47,6 +47,35 @@ import {
 import { ToTextReferenceFromKHIFileBinary } from '../common/loader/reference-type';
 import { ProgressUtil } from './progress/progress-util';
 
+/**
+ * The value written in place of the history JSON size of the legacy format to mark the file has the format version field.
+ */
+const KHI_FILE_FORMAT_VERSION_MARKER = 0xffffffff;
+
+/**
+ * The format version of KHI files using 64-bit sizes. All chunks are compressed with gzip.
+ */
+const KHI_FILE_FORMAT_VERSION_64BIT_SIZE = 2;
+
+/**
+ * The format version of KHI files having the compression codec of each chunk in the header.
+ */
+const KHI_FILE_FORMAT_VERSION_CHUNK_CODEC = 3;
+
+/**
+ * The codec ID of gzip recorded in KHI files. This is the only codec browsers can decompress.
+ */
+const KHI_FILE_CHUNK_CODEC_GZIP = 0;
+
+/**
+ * The location of the history JSON and the byte length of the binary chunk sizes read from the header of a KHI file.
+ */
+interface KHIFileHeader {
+  jsonOffset: number;
+  jsonSize: number;
+  chunkSizeLength: number;
+}
+
 @Injectable()
 export class InspectionDataLoaderService {
   private readonly progress = inject<ProgressDialogStatusUpdator>(
@@ -327,20 +356,18 @@ export class InspectionDataLoaderService {
         );
         return;
       }
-      const jsonSizeOffset = 3;
-      const jsonDataOffset = jsonSizeOffset + Uint32Array.BYTES_PER_ELEMENT;
-      const fileDataView = new DataView(rawInspectionData);
-      const metaDataPart = fileDataView.getUint32(jsonSizeOffset, true);
+      const header = this.readFileHeader(new DataView(rawInspectionData));
       const jsonPartBytes = new Uint8Array(
         rawInspectionData,
-        jsonDataOffset,
-        metaDataPart,
+        header.jsonOffset,
+        header.jsonSize,
       );
       const textDecoder = new TextDecoder();
       const parsedJsonData = JSON.parse(textDecoder.decode(jsonPartBytes));
       const textBuffers = await this.decodeBuffers(
         rawInspectionData,
-        jsonDataOffset + metaDataPart,
+        header.jsonOffset + header.jsonSize,
+        header.chunkSizeLength,
       );
 
       const resolver = new ReferenceResolverStore([
@@ -368,9 +395,57 @@ export class InspectionDataLoaderService {
     this.progress.dismiss();
   }
 
+  /**
+   * Reads the header following the magic bytes.
+   * Files without the format version field are read in the legacy format using 32-bit sizes.
+   */
+  private readFileHeader(dv: DataView): KHIFileHeader {
+    const jsonSizeOffset = 3;
+    const legacyJsonSize = dv.getUint32(jsonSizeOffset, true);
+    if (legacyJsonSize !== KHI_FILE_FORMAT_VERSION_MARKER) {
+      return {
+        jsonOffset: jsonSizeOffset + Uint32Array.BYTES_PER_ELEMENT,
+        jsonSize: legacyJsonSize,
+        chunkSizeLength: Uint32Array.BYTES_PER_ELEMENT,
+      };
+    }
+    const version = dv.getUint32(
+      jsonSizeOffset + Uint32Array.BYTES_PER_ELEMENT,
+      true,
+    );
+    if (
+      version !== KHI_FILE_FORMAT_VERSION_64BIT_SIZE &&
+      version !== KHI_FILE_FORMAT_VERSION_CHUNK_CODEC
+    ) {
+      throw new Error(`Unsupported KHI file format version ${version}`);
+    }
+    const versionedJsonSizeOffset =
+      jsonSizeOffset + Uint32Array.BYTES_PER_ELEMENT * 2;
+    let jsonOffset =
+      versionedJsonSizeOffset + BigUint64Array.BYTES_PER_ELEMENT;
+    if (version === KHI_FILE_FORMAT_VERSION_CHUNK_CODEC) {
+      const chunkCount = dv.getUint32(jsonOffset, true);
+      jsonOffset += Uint32Array.BYTES_PER_ELEMENT;
+      for (let i = 0; i < chunkCount; i++) {
+        if (dv.getUint8(jsonOffset + i) !== KHI_FILE_CHUNK_CODEC_GZIP) {
+          throw new Error(
+            'This KHI file contains chunks compressed with a codec not supported in browsers. Please run the inspection again with `--compression-codec=gzip`.',
+          );
+        }
+      }
+      jsonOffset += chunkCount;
+    }
+    return {
+      jsonOffset,
+      jsonSize: Number(dv.getBigUint64(versionedJsonSizeOffset, true)),
+      chunkSizeLength: BigUint64Array.BYTES_PER_ELEMENT,
+    };
+  }
+
   private async decodeBuffers(
     source: ArrayBuffer,
     initialOffset: number,
+    chunkSizeLength: number,
   ): Promise<ArrayBuffer[]> {
     const result: ArrayBuffer[] = [];
     const dv = new DataView(source);
@@ -381,8 +456,11 @@ export class InspectionDataLoaderService {
         percent: (currentOffset / source.byteLength) * 100,
         mode: 'determinate',
       });
-      const size = dv.getUint32(currentOffset);
-      currentOffset += Uint32Array.BYTES_PER_ELEMENT;
+      const size =
+        chunkSizeLength === BigUint64Array.BYTES_PER_ELEMENT
+          ? Number(dv.getBigUint64(currentOffset))
+          : dv.getUint32(currentOffset);
+      currentOffset += chunkSizeLength;
       const decompressedBuffer = await this.decompressGzip(
         new Uint8Array(source, currentOffset, size),
       );